@@ -33,18 +33,7 @@ func connectDatabase() *gorm.DB {
 		panic("failed to connect to database: " + err.Error())
 	}
 
-	err = db.AutoMigrate(
-		&models.User{},
-		&models.Movie{},
-		&models.Genre{},
-		&models.MovieList{},
-		&models.ListMember{},
-		&models.ListMovie{},
-		&models.ListMovieUserData{},
-		&models.RefreshToken{},
-		&models.Comment{},
-		&models.WatchProvider{},
-	)
+	err = db.AutoMigrate(models.Tables()...)
 	if err != nil {
 		panic("failed to migrate database: " + err.Error())
 	}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strings"
+	"time"
 
 	"github.com/8bury/list2gether/controllers"
 	"github.com/8bury/list2gether/daos"
@@ -17,11 +19,28 @@ var (
 	movieListDAO          daos.MovieListDAO
 	movieDAO              daos.MovieDAO
 	watchProviderDAO      daos.WatchProviderDAO
+	recFeedbackDAO        daos.RecommendationFeedbackDAO
+	movieSimilarityDAO    daos.MovieSimilarityDAO
+	reactionDAO           daos.ReactionDAO
+	notificationDAO       daos.NotificationDAO
+	pushSubscriptionDAO   daos.PushSubscriptionDAO
+	webhookDAO            daos.WebhookDAO
+	accountDAO            daos.AccountDAO
+	listHub               services.ListHub
 	authService           services.AuthService
+	notifier              services.Notifier
+	notificationService   services.NotificationService
+	digestService         services.DigestService
+	pushService           services.PushService
+	mockPushService       *services.MockPushService
+	webhookService        services.WebhookService
 	listService           services.ListService
 	searchService         services.SearchService
 	recommendationService services.RecommendationService
 	watchProviderService  services.WatchProviderService
+	similarityService     services.SimilarityService
+	reactionService       services.ReactionService
+	accountService        services.AccountService
 	authMiddleware        *middleware.AuthMiddleware
 )
 
@@ -29,6 +48,7 @@ func InitializeDependencies(router *gin.Engine) {
 	db := connectDatabase()
 	initializeDaos(db)
 	initializeServices()
+	initializeWorkers()
 	middleware.SetupCORS(router)
 	initializeControllers(router)
 }
@@ -39,15 +59,72 @@ func initializeDaos(db *gorm.DB) {
 	movieListDAO = daos.NewMovieListDAO(db)
 	movieDAO = daos.NewMovieDAO(db)
 	watchProviderDAO = daos.NewWatchProviderDAO(db)
+	recFeedbackDAO = daos.NewRecommendationFeedbackDAO(db)
+	movieSimilarityDAO = daos.NewMovieSimilarityDAO(db)
+	reactionDAO = daos.NewReactionDAO(db)
+	notificationDAO = daos.NewNotificationDAO(db)
+	pushSubscriptionDAO = daos.NewPushSubscriptionDAO(db)
+	webhookDAO = daos.NewWebhookDAO(db)
+	accountDAO = daos.NewAccountDAO(db)
 }
 
 func initializeServices() {
-	authService = services.NewAuthService(userDAO, refreshTokenDAO)
-	listService = services.NewListService(movieListDAO, movieDAO, os.Getenv("TMDB_API_TOKEN"))
+	listHub = services.NewListHub(services.NewLocalListBroker())
+	mailer := newMailer()
+	unsubscribeTokens := services.NewUnsubscribeTokens([]byte(os.Getenv("JWT_SECRET")))
+	emailLinks := services.EmailLinks{
+		AppURL: getEnvDefault("FRONTEND_ORIGIN", "http://localhost:5173"),
+		APIURL: getEnvDefault("PUBLIC_API_URL", "http://localhost:8080"),
+	}
+	channels := []services.Notifier{services.NewInAppNotifier(notificationDAO)}
+	if mailer != nil {
+		channels = append(channels, services.NewEmailNotifier(mailer, userDAO, notificationDAO, unsubscribeTokens, emailLinks))
+		digestService = services.NewDigestService(movieListDAO, notificationDAO, mailer, unsubscribeTokens, emailLinks)
+	}
+	vapidKeys := loadVAPIDKeys()
+	if envBool("PUSH_MOCK_ENDPOINT") {
+		mockPushService = services.NewMockPushService(emailLinks.APIURL + "/api/push/mock/endpoints")
+	}
+	var pushSender services.PushSender
+	if vapidKeys != nil {
+		pushSender = services.NewWebPushSender(vapidKeys, nil, mockPushService != nil)
+		channels = append(channels, services.NewPushNotifier(pushSubscriptionDAO, userDAO, notificationDAO, pushSender))
+	}
+	pushService = services.NewPushService(pushSubscriptionDAO, userDAO, vapidKeys, pushSender, mockPushService != nil)
+	notifier = services.NewMultiNotifier(channels...)
+	notificationService = services.NewNotificationService(notificationDAO, unsubscribeTokens)
+	webhookService = services.NewWebhookService(webhookDAO, movieListDAO, userDAO, nil, envBool("WEBHOOK_ALLOW_PRIVATE_TARGETS"))
+	listService = services.NewListService(movieListDAO, movieDAO, userDAO, notifier, listHub, webhookService, services.NewMemoryPresenceStore(), os.Getenv("TMDB_API_TOKEN"))
 	searchService = services.NewSearchService(os.Getenv("TMDB_API_TOKEN"))
-	recommendationService = services.NewRecommendationService(movieListDAO, os.Getenv("TMDB_API_TOKEN"))
+	recommendationService = services.NewRecommendationService(movieListDAO, userDAO, recFeedbackDAO, movieSimilarityDAO, listService, os.Getenv("TMDB_API_TOKEN"))
+	authService = services.NewAuthService(userDAO, refreshTokenDAO, recommendationService)
 	watchProviderService = services.NewWatchProviderService(os.Getenv("TMDB_API_TOKEN"))
-	authMiddleware = middleware.NewAuthMiddleware(authService.JWTSecret())
+	similarityService = services.NewSimilarityService(movieSimilarityDAO)
+	reactionService = services.NewReactionService(movieListDAO, reactionDAO)
+	accountService = services.NewAccountService(userDAO, refreshTokenDAO, accountDAO, listHub)
+	authMiddleware = middleware.NewAuthMiddleware(authService.JWTSecret(), userDAO)
+}
+
+func initializeWorkers() {
+	similarityService.StartRefresher(envDuration("SIMILARITY_REFRESH_INTERVAL", 6*time.Hour))
+	listService.StartRemovalPurger(envDuration("REMOVAL_PURGE_INTERVAL", time.Hour))
+	listService.StartPresenceSweeper(envDuration("PRESENCE_SWEEP_INTERVAL", 15*time.Second))
+	if digestService != nil {
+		digestService.StartDigestScheduler(envDuration("DIGEST_CHECK_INTERVAL", time.Hour))
+	}
+	webhookService.StartDeliveryWorker(envDuration("WEBHOOK_DELIVERY_INTERVAL", 5*time.Second))
+}
+
+func envDuration(key string, def time.Duration) time.Duration {
+	v := strings.TrimSpace(os.Getenv(key))
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
 }
 
 func initializeControllers(router *gin.Engine) {
@@ -58,6 +135,10 @@ func initializeControllers(router *gin.Engine) {
 	router.HEAD("/health", healthHandler)
 
 	controllers.NewAuthController(router, authService, authMiddleware)
-	controllers.NewListController(router, listService, recommendationService, watchProviderService, watchProviderDAO, authMiddleware)
+	controllers.NewListController(router, listService, recommendationService, reactionService, watchProviderService, watchProviderDAO, authMiddleware)
 	controllers.NewSearchController(router, searchService, authMiddleware)
+	controllers.NewNotificationController(router, notificationService, authMiddleware)
+	controllers.NewPushController(router, pushService, mockPushService, authMiddleware)
+	controllers.NewWebhookController(router, webhookService, authMiddleware)
+	controllers.NewAccountController(router, accountService, authMiddleware)
 }
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/8bury/list2gether/services"
+)
+
+// newMailer picks the mailer from MAIL_DRIVER: "smtp" for production, "file" to save
+// messages under MAIL_DIR, or "stdout" to print them. Email is off, and nil returned,
+// when it is unset; printing by default would leak invite codes and unsubscribe links
+// into the logs of a deploy that forgot to configure it.
+func newMailer() services.Mailer {
+	from := getEnvDefault("MAIL_FROM", "list2gether <no-reply@list2gether.local>")
+	switch driver := strings.ToLower(strings.TrimSpace(os.Getenv("MAIL_DRIVER"))); driver {
+	case "smtp":
+		host := strings.TrimSpace(os.Getenv("SMTP_HOST"))
+		if host == "" {
+			panic("SMTP_HOST is required when MAIL_DRIVER is smtp")
+		}
+		port, err := strconv.Atoi(getEnvDefault("SMTP_PORT", "587"))
+		if err != nil || port <= 0 {
+			panic("SMTP_PORT must be a positive number")
+		}
+		return services.NewSMTPMailer(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from)
+	case "file":
+		mailer, err := services.NewFileMailer(getEnvDefault("MAIL_DIR", "mail"), from)
+		if err != nil {
+			panic("failed to create mail directory: " + err.Error())
+		}
+		return mailer
+	case "stdout":
+		return services.NewWriterMailer(os.Stdout, from)
+	case "":
+		fmt.Println("Warning: MAIL_DRIVER is not set, email is disabled")
+		return nil
+	default:
+		panic(fmt.Sprintf("unknown MAIL_DRIVER %q", driver))
+	}
+}
+
+func getEnvDefault(key, def string) string {
+	if v := strings.TrimSpace(os.Getenv(key)); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/8bury/list2gether/services"
+)
+
+// loadVAPIDKeys reads the Web Push key pair; without VAPID_PRIVATE_KEY push stays disabled
+func loadVAPIDKeys() *services.VAPIDKeys {
+	private := strings.TrimSpace(os.Getenv("VAPID_PRIVATE_KEY"))
+	if private == "" {
+		fmt.Println("Web Push disabled: VAPID_PRIVATE_KEY is not set")
+		return nil
+	}
+	keys, err := services.ParseVAPIDKeys(
+		strings.TrimSpace(os.Getenv("VAPID_PUBLIC_KEY")),
+		private,
+		getEnvDefault("VAPID_SUBJECT", "mailto:admin@list2gether.local"),
+	)
+	if err != nil {
+		panic("invalid VAPID keys: " + err.Error())
+	}
+	return keys
+}
+
+func envBool(key string) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(key))) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
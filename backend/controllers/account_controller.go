@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/8bury/list2gether/middleware"
+	"github.com/8bury/list2gether/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController struct {
+	service        services.AccountService
+	authMiddleware *middleware.AuthMiddleware
+}
+
+// NewAccountController registers the routes users download their data and delete
+// their account with
+func NewAccountController(router *gin.Engine, service services.AccountService, authMiddleware *middleware.AuthMiddleware) *AccountController {
+	c := &AccountController{service: service, authMiddleware: authMiddleware}
+	group := router.Group("/api/account", c.authMiddleware.Handler())
+	group.GET("/export", c.export)
+	group.DELETE("", c.delete)
+	return c
+}
+
+type deleteAccountRequest struct {
+	Password string `json:"password"`
+	// OwnedLists is "transfer" (default) or "delete"
+	OwnedLists string `json:"owned_lists"`
+	// Comments is "anonymize" (default) or "delete"
+	Comments string `json:"comments"`
+}
+
+func respondAccountError(ctx *gin.Context, err error, fallback string) {
+	ctx.Header("Cache-Control", "no-store")
+	switch err {
+	case services.ErrInvalidDeletionOption:
+		respondValidationError(ctx, []string{"owned_lists must be transfer or delete and comments must be anonymize or delete"})
+	case services.ErrInvalidPassword:
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error":     "Senha incorreta",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrAccountNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Conta não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     fallback,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// export downloads a ZIP of JSON files with everything stored about the user
+func (c *AccountController) export(ctx *gin.Context) {
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	export, err := c.service.ExportAccount(userID)
+	if err != nil {
+		respondAccountError(ctx, err, "Falha ao exportar dados da conta")
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Content-Type", "application/zip")
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.Filename()}))
+	ctx.Status(http.StatusOK)
+	if err := export.Write(ctx.Writer); err != nil {
+		log.Printf("export_account failed user_id=%d: %v", userID, err)
+	}
+}
+
+// delete erases the account once the user confirms the password
+func (c *AccountController) delete(ctx *gin.Context) {
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	var req deleteAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.Password == "" {
+		respondValidationError(ctx, []string{"password is required"})
+		return
+	}
+
+	log.Printf("delete_account attempt user_id=%d", userID)
+	deletion, err := c.service.DeleteAccount(userID, services.DeleteAccountOptions{
+		Password:   req.Password,
+		OwnedLists: req.OwnedLists,
+		Comments:   req.Comments,
+	})
+	if err != nil {
+		respondAccountError(ctx, err, "Falha ao excluir conta")
+		return
+	}
+
+	transferred := make([]gin.H, 0, len(deletion.TransferredLists))
+	for _, t := range deletion.TransferredLists {
+		transferred = append(transferred, gin.H{"list_id": t.ListID, "new_owner_id": t.NewOwnerID})
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":           true,
+		"message":           "Conta excluída com sucesso",
+		"transferred_lists": transferred,
+		"deleted_list_ids":  deletion.DeletedListIDs,
+		"comments_deleted":  deletion.CommentsDeleted,
+	})
+}
@@ -26,6 +26,9 @@ func NewAuthController(router *gin.Engine, service services.AuthService, authMid
 
 	group.GET("/me", c.authMiddleware.Handler(), c.me)
 	group.PUT("/profile", c.authMiddleware.Handler(), c.updateProfile)
+	group.GET("/preferences/genres", c.authMiddleware.Handler(), c.getGenrePreferences)
+	group.PUT("/preferences/genres", c.authMiddleware.Handler(), c.updateGenrePreferences)
+	group.PUT("/preferences/language", c.authMiddleware.Handler(), c.updateLanguage)
 	return c
 }
 
@@ -53,6 +56,14 @@ type updateProfileRequest struct {
 	AvatarURL string `json:"avatar_url"`
 }
 
+type genrePreferencesRequest struct {
+	GenreIDs []int64 `json:"genre_ids"`
+}
+
+type languageRequest struct {
+	Language string `json:"language"`
+}
+
 func (a *AuthController) register(c *gin.Context) {
 	var req registerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -196,6 +207,101 @@ func (a *AuthController) updateProfile(c *gin.Context) {
 	})
 }
 
+func (a *AuthController) getGenrePreferences(c *gin.Context) {
+	id, ok := authUserID(c)
+	if !ok {
+		respondTokenInvalid(c)
+		return
+	}
+
+	genreIDs, err := a.service.GetGenrePreferences(id)
+	if err != nil {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Failed to load genre preferences",
+			"code":      "INTERNAL_ERROR",
+			"details":   []string{err.Error()},
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"genre_ids": genreIDs,
+	})
+}
+
+func (a *AuthController) updateGenrePreferences(c *gin.Context) {
+	id, ok := authUserID(c)
+	if !ok {
+		respondTokenInvalid(c)
+		return
+	}
+
+	var req genrePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, []string{"Invalid request body"})
+		return
+	}
+
+	genreIDs, err := a.service.UpdateGenrePreferences(id, req.GenreIDs)
+	if err != nil {
+		respondValidationError(c, []string{err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Genre preferences updated successfully",
+		"genre_ids": genreIDs,
+	})
+}
+
+func (a *AuthController) updateLanguage(c *gin.Context) {
+	id, ok := authUserID(c)
+	if !ok {
+		respondTokenInvalid(c)
+		return
+	}
+
+	var req languageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondValidationError(c, []string{"Invalid request body"})
+		return
+	}
+
+	user, err := a.service.UpdateLanguage(id, req.Language)
+	if err != nil {
+		respondValidationError(c, []string{err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Language updated successfully",
+		"language": user.Language,
+	})
+}
+
+// authUserID reads the authenticated user's ID from the JWT claims set by the auth middleware
+func authUserID(c *gin.Context) (int64, bool) {
+	rawClaims, _ := c.Get("auth_claims")
+	claims, ok := rawClaims.(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	sub, ok := claims["sub"].(string)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return id, true
+}
+
 func respondValidationError(c *gin.Context, details []string) {
 	c.Header("Cache-Control", "no-store")
 	c.JSON(http.StatusBadRequest, gin.H{
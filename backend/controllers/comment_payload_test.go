@@ -0,0 +1,31 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentPayload_DeletedParentRendersPlaceholder(t *testing.T) {
+	deletedAt := time.Now()
+	comment := models.Comment{
+		ID:         10,
+		UserID:     3,
+		Content:    "Que final",
+		ReplyCount: 2,
+		DeletedAt:  &deletedAt,
+		User:       models.User{ID: 3, Username: "ana"},
+		Reactions:  []models.ReactionSummary{{Emoji: "👍", Count: 1}},
+	}
+
+	payload := commentPayload(comment, 2)
+
+	assert.Nil(t, payload["user_id"])
+	assert.Equal(t, models.DeletedCommentPlaceholder, payload["content"])
+	assert.Equal(t, true, payload["deleted"])
+	assert.Equal(t, int64(2), payload["reply_count"])
+	assert.NotContains(t, payload, "user")
+	assert.NotContains(t, payload["rendered"], "Que final")
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+	"github.com/8bury/list2gether/services"
+	"github.com/gin-gonic/gin"
+)
+
+type bulkMoviesRequest struct {
+	Operation models.BulkOperation `json:"operation"`
+	MovieIDs  []int64              `json:"movie_ids"`
+	// Status is required by set_status
+	Status models.MovieStatus `json:"status"`
+	// TargetListID is required by copy and move
+	TargetListID int64 `json:"target_list_id"`
+	// Tag is required by tag and untag
+	Tag string `json:"tag"`
+}
+
+func respondBulkError(ctx *gin.Context, err error) {
+	ctx.Header("Cache-Control", "no-store")
+	switch err {
+	case services.ErrInvalidBulkOperation:
+		respondValidationError(ctx, []string{"operation must be one of: set_status, remove, copy, move, tag, untag"})
+	case services.ErrInvalidBulkItems:
+		respondValidationError(ctx, []string{"movie_ids must hold between 1 and " + strconv.Itoa(services.MaxBulkItems) + " ids"})
+	case services.ErrInvalidBulkStatus:
+		respondValidationError(ctx, []string{"status must be one of: not_watched, watching, watched, dropped"})
+	case services.ErrInvalidBulkTarget:
+		respondValidationError(ctx, []string{"target_list_id is required for copy and move and must be another list"})
+	case services.ErrInvalidBulkTag:
+		respondValidationError(ctx, []string{"tag is required for tag and untag and must have at most " + strconv.Itoa(models.MaxTagLength) + " characters"})
+	case services.ErrListNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"success":   false,
+			"error":     "Lista não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrTargetListNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"success":   false,
+			"error":     "Lista de destino não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrForbiddenMembership:
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"success":   false,
+			"error":     "Você não tem permissão para modificar filmes desta lista",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrForbiddenTargetMembership:
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"success":   false,
+			"error":     "Você não tem permissão para adicionar filmes à lista de destino",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"success":   false,
+			"error":     "Failed to update movies",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// bulkMovies applies one operation to many titles of the list: set their status, remove
+// them, copy or move them to another list, or tag or untag them. All of it happens in one transaction and
+// the response says what happened to each title.
+func (c *ListController) bulkMovies(ctx *gin.Context) {
+	listID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	var req bulkMoviesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	results, err := c.service.BulkUpdateMovies(listID, userID, daos.BulkListMovieOperation{
+		Operation:    req.Operation,
+		MovieIDs:     req.MovieIDs,
+		Status:       req.Status,
+		TargetListID: req.TargetListID,
+		Tag:          req.Tag,
+	})
+	if err != nil {
+		respondBulkError(ctx, err)
+		return
+	}
+
+	counts := map[models.BulkItemOutcome]int{}
+	for _, result := range results {
+		counts[result.Outcome]++
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"operation": req.Operation,
+		"results":   results,
+		"applied":   counts[models.BulkItemApplied],
+		"skipped":   len(results) - counts[models.BulkItemApplied],
+	})
+}
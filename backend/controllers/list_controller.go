@@ -23,31 +23,65 @@ import (
 type ListController struct {
 	service               services.ListService
 	recommendationService services.RecommendationService
+	reactionService       services.ReactionService
 	watchProviderService  services.WatchProviderService
 	watchProviderDAO      daos.WatchProviderDAO
 	authMiddleware        *middleware.AuthMiddleware
 }
 
-func NewListController(router *gin.Engine, service services.ListService, recommendationService services.RecommendationService, watchProviderService services.WatchProviderService, watchProviderDAO daos.WatchProviderDAO, authMiddleware *middleware.AuthMiddleware) *ListController {
-	c := &ListController{service: service, recommendationService: recommendationService, watchProviderService: watchProviderService, watchProviderDAO: watchProviderDAO, authMiddleware: authMiddleware}
+func NewListController(router *gin.Engine, service services.ListService, recommendationService services.RecommendationService, reactionService services.ReactionService, watchProviderService services.WatchProviderService, watchProviderDAO daos.WatchProviderDAO, authMiddleware *middleware.AuthMiddleware) *ListController {
+	c := &ListController{service: service, recommendationService: recommendationService, reactionService: reactionService, watchProviderService: watchProviderService, watchProviderDAO: watchProviderDAO, authMiddleware: authMiddleware}
 	group := router.Group("/api/lists")
 	group.POST("", c.authMiddleware.Handler(), c.create)
 	group.GET("", c.authMiddleware.Handler(), c.list)
 	group.POST("/join", c.authMiddleware.Handler(), c.join)
 	group.DELETE("/:id", c.authMiddleware.Handler(), c.delete)
 	group.POST("/:id/leave", c.authMiddleware.Handler(), c.leave)
+	group.POST("/:id/invitations", c.authMiddleware.Handler(), c.invite)
 	group.POST("/:id/movies", c.authMiddleware.Handler(), c.addMovie)
 	group.GET("/:id/movies", c.authMiddleware.Handler(), c.listMovies)
 	group.DELETE("/:id/movies/:movieId", c.authMiddleware.Handler(), c.removeMovie)
+	group.GET("/:id/movies/removed", c.authMiddleware.Handler(), c.listRemovedMovies)
+	group.POST("/:id/movies/removed/undo", c.authMiddleware.Handler(), c.undoRemoval)
+	group.POST("/:id/movies/:movieId/restore", c.authMiddleware.Handler(), c.restoreMovie)
 	group.PATCH("/:id/movies/:movieId", c.authMiddleware.Handler(), c.updateMovie)
 	group.PATCH("/:id/movies/reorder", c.authMiddleware.Handler(), c.reorderMovies)
+	group.POST("/:id/movies/bulk", c.authMiddleware.Handler(), c.bulkMovies)
 	group.GET("/:id/movies/search", c.authMiddleware.Handler(), c.searchMovies)
 	group.GET("/:id/recommendations", c.authMiddleware.Handler(), c.getRecommendations)
+	group.POST("/:id/recommendations/:movieId/feedback", c.authMiddleware.Handler(), c.submitRecommendationFeedback)
+	group.DELETE("/:id/recommendations/:movieId/feedback", c.authMiddleware.Handler(), c.removeRecommendationFeedback)
 	// Comment routes
 	group.GET("/:id/movies/:movieId/comments", c.authMiddleware.Handler(), c.listComments)
 	group.POST("/:id/movies/:movieId/comments", c.authMiddleware.Handler(), c.createComment)
+	group.GET("/:id/movies/:movieId/comments/:commentId/thread", c.authMiddleware.Handler(), c.getCommentThread)
 	group.PATCH("/:id/movies/:movieId/comments/:commentId", c.authMiddleware.Handler(), c.updateComment)
 	group.DELETE("/:id/movies/:movieId/comments/:commentId", c.authMiddleware.Handler(), c.deleteComment)
+	group.GET("/:id/movies/:movieId/comments/:commentId/revisions", c.authMiddleware.Handler(), c.listCommentRevisions)
+	group.POST("/:id/movies/:movieId/comments/:commentId/moderation", c.authMiddleware.Handler(), c.moderateComment)
+	group.GET("/:id/moderation-log", c.authMiddleware.Handler(), c.moderationLog)
+	group.POST("/:id/read", c.authMiddleware.Handler(), c.markRead)
+	group.GET("/:id/activity", c.authMiddleware.Handler(), c.activity)
+	group.POST("/:id/imports", c.authMiddleware.Handler(), c.importList)
+	group.GET("/:id/imports/:importId", c.authMiddleware.Handler(), c.getImport)
+	group.GET("/:id/export", c.authMiddleware.Handler(), c.exportList)
+	group.GET("/:id/stream", c.authMiddleware.Handler(), c.stream)
+	group.GET("/:id/presence", c.authMiddleware.Handler(), c.getPresence)
+	group.POST("/:id/presence", c.authMiddleware.Handler(), c.heartbeatPresence)
+	group.DELETE("/:id/presence", c.authMiddleware.Handler(), c.leavePresence)
+	group.POST("/:id/movies/:movieId/read", c.authMiddleware.Handler(), c.markRead)
+	// The list's discussion board shares the comment handlers, without a movie
+	group.GET("/:id/board/comments", c.authMiddleware.Handler(), c.listBoardComments)
+	group.POST("/:id/board/comments", c.authMiddleware.Handler(), c.createComment)
+	group.GET("/:id/board/comments/:commentId/thread", c.authMiddleware.Handler(), c.getCommentThread)
+	group.PATCH("/:id/board/comments/:commentId", c.authMiddleware.Handler(), c.updateComment)
+	group.DELETE("/:id/board/comments/:commentId", c.authMiddleware.Handler(), c.deleteComment)
+	group.GET("/:id/board/comments/:commentId/revisions", c.authMiddleware.Handler(), c.listCommentRevisions)
+	group.POST("/:id/board/comments/:commentId/moderation", c.authMiddleware.Handler(), c.moderateComment)
+	group.POST("/:id/board/comments/:commentId/reactions", c.authMiddleware.Handler(), c.toggleCommentReaction)
+	// Reaction routes
+	group.POST("/:id/movies/:movieId/reactions", c.authMiddleware.Handler(), c.toggleListMovieReaction)
+	group.POST("/:id/movies/:movieId/comments/:commentId/reactions", c.authMiddleware.Handler(), c.toggleCommentReaction)
 	return c
 }
 
@@ -60,22 +94,40 @@ type joinListRequest struct {
 	InviteCode string `json:"invite_code"`
 }
 
+type inviteUserRequest struct {
+	Username string `json:"username"`
+}
+
 type addMovieRequest struct {
 	ID        string `json:"id"`
 	MediaType string `json:"media_type"`
 }
 
 type updateMovieRequest struct {
-	Status *string `json:"status"`
-	Rating *int    `json:"rating"`
+	Status  *string `json:"status"`
+	Rating  *int    `json:"rating"`
+	Watched *bool   `json:"watched"`
 }
 
 type createCommentRequest struct {
-	Content string `json:"content"`
+	Content  string `json:"content"`
+	ParentID *int64 `json:"parent_id"`
+	Spoiler  bool   `json:"spoiler"`
 }
 
 type updateCommentRequest struct {
 	Content string `json:"content"`
+	Spoiler *bool  `json:"spoiler"`
+}
+
+type toggleReactionRequest struct {
+	Emoji string `json:"emoji"`
+}
+
+type recommendationFeedbackRequest struct {
+	Action    string `json:"action"`
+	MediaType string `json:"media_type"`
+	AddToList bool   `json:"add_to_list"`
 }
 
 type reorderMoviesRequest struct {
@@ -126,7 +178,7 @@ func (c *ListController) list(ctx *gin.Context) {
 		}
 	}
 
-	memberships, memberCounts, movieCounts, total, err := c.service.ListUserLists(userID, roleFilter, limit, offset)
+	memberships, memberCounts, movieCounts, unreadCounts, total, err := c.service.ListUserLists(userID, roleFilter, limit, offset)
 	if err != nil {
 		ctx.Header("Cache-Control", "no-store")
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -151,6 +203,7 @@ func (c *ListController) list(ctx *gin.Context) {
 			"updated_at":   l.UpdatedAt,
 			"member_count": memberCounts[l.ID],
 			"movie_count":  movieCounts[l.ID],
+			"unread":       unreadCounts[l.ID],
 		})
 	}
 
@@ -440,6 +493,94 @@ func (c *ListController) leave(ctx *gin.Context) {
 	})
 }
 
+// invite sends another user the owner's invitation to join the list
+func (c *ListController) invite(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	var req inviteUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+	if strings.TrimSpace(req.Username) == "" {
+		respondValidationError(ctx, []string{"username is required"})
+		return
+	}
+
+	if err := c.service.InviteUser(listID, userID, req.Username); err != nil {
+		switch err {
+		case services.ErrListNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembership:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Você não é membro desta lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrInviteOwnerOnly:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Apenas o dono da lista pode enviar convites",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrInviteRateLimited:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.Header("Retry-After", "3600")
+			ctx.JSON(http.StatusTooManyRequests, gin.H{
+				"error":     "Limite de convites atingido, tente novamente mais tarde",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrInviteeAlreadyMember:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error":     "Usuário já é membro desta lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao enviar convite",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	// The same answer whether or not the username exists
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Convite enviado",
+		"data": gin.H{
+			"list_id":  listID,
+			"username": strings.TrimSpace(req.Username),
+		},
+	})
+}
+
 func (c *ListController) addMovie(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 	listID, err := strconv.ParseInt(idParam, 10, 64)
@@ -616,6 +757,7 @@ func (c *ListController) removeMovie(ctx *gin.Context) {
 		message = "Série removida da lista com sucesso"
 	}
 
+	removedAt := time.Now().UTC()
 	ctx.Header("Cache-Control", "no-store")
 	ctx.JSON(http.StatusOK, gin.H{
 		"success": true,
@@ -623,11 +765,204 @@ func (c *ListController) removeMovie(ctx *gin.Context) {
 		"data": gin.H{
 			"list_id":    listID,
 			"movie_id":   movieID,
-			"removed_at": time.Now().UTC().Format(time.RFC3339),
+			"removed_at": removedAt.Format(time.RFC3339),
+			"undo_until": removedAt.Add(services.RemovalUndoWindow).Format(time.RFC3339),
+		},
+	})
+}
+
+// listRemovedMovies returns the titles removed from the list that can still be restored
+func (c *ListController) listRemovedMovies(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	items, err := c.service.GetRecentlyRemoved(listID, userID)
+	if err != nil {
+		respondRemovalError(ctx, err)
+		return
+	}
+
+	resp := make([]gin.H, 0, len(items))
+	for _, lm := range items {
+		resp = append(resp, removedMoviePayload(lm))
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"movies": resp,
+		"count":  len(resp),
+	})
+}
+
+// restoreMovie puts one removed title back on the list
+func (c *ListController) restoreMovie(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieIdParam := ctx.Param("movieId")
+	movieID, err := strconv.ParseInt(movieIdParam, 10, 64)
+	if err != nil || movieID <= 0 {
+		respondValidationError(ctx, []string{"Invalid movie id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	listMovie, err := c.service.RestoreMovie(listID, userID, movieID)
+	if err != nil {
+		respondRemovalError(ctx, err)
+		return
+	}
+	respondRestored(ctx, listMovie)
+}
+
+// undoRemoval restores the title the caller removed most recently
+func (c *ListController) undoRemoval(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	listMovie, err := c.service.UndoLastRemoval(listID, userID)
+	if err != nil {
+		respondRemovalError(ctx, err)
+		return
+	}
+	respondRestored(ctx, listMovie)
+}
+
+func respondRestored(ctx *gin.Context, listMovie *models.ListMovie) {
+	message := "Filme restaurado na lista com sucesso"
+	if listMovie.Movie.MediaType == "tv" {
+		message = "Série restaurada na lista com sucesso"
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": message,
+		"data": gin.H{
+			"list_id":       listMovie.ListID,
+			"movie_id":      listMovie.MovieID,
+			"status":        listMovie.Status,
+			"display_order": listMovie.DisplayOrder,
+			"restored_at":   time.Now().UTC().Format(time.RFC3339),
 		},
 	})
 }
 
+func removedMoviePayload(lm models.ListMovie) gin.H {
+	movie := lm.Movie
+	posterURL := (*string)(nil)
+	if movie.PosterPath != nil && *movie.PosterPath != "" {
+		u := "https://image.tmdb.org/t/p/w500" + *movie.PosterPath
+		posterURL = &u
+	}
+	m := gin.H{
+		"id":             movie.ID,
+		"title":          movie.Title,
+		"original_title": movie.OriginalTitle,
+		"release_date":   movie.ReleaseDate,
+		"poster_url":     posterURL,
+		"media_type":     movie.MediaType,
+	}
+
+	userPayload := func(user *models.User) gin.H {
+		if user == nil || user.ID == 0 {
+			return nil
+		}
+		return gin.H{
+			"id":         user.ID,
+			"username":   user.Username,
+			"avatar_url": user.AvatarURL,
+		}
+	}
+
+	var undoUntil *time.Time
+	if lm.RemovedAt != nil {
+		t := lm.RemovedAt.Add(services.RemovalUndoWindow)
+		undoUntil = &t
+	}
+	return gin.H{
+		"id":              lm.ID,
+		"list_id":         lm.ListID,
+		"movie_id":        lm.MovieID,
+		"status":          lm.Status,
+		"added_by":        lm.AddedBy,
+		"added_by_user":   userPayload(lm.AddedByUser),
+		"added_at":        lm.AddedAt,
+		"display_order":   lm.DisplayOrder,
+		"removed_by":      lm.RemovedBy,
+		"removed_by_user": userPayload(lm.RemovedByUser),
+		"removed_at":      lm.RemovedAt,
+		"undo_until":      undoUntil,
+		"movie":           m,
+	}
+}
+
+func respondRemovalError(ctx *gin.Context, err error) {
+	switch err {
+	case services.ErrListNotFound:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Lista não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrForbiddenMembership:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error":     "Você não tem permissão para restaurar filmes desta lista",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrRemovalNotFound:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Nenhuma remoção recente para desfazer",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	default:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Falha ao restaurar filme",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
+
 func (c *ListController) updateMovie(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 	listID, err := strconv.ParseInt(idParam, 10, 64)
@@ -667,8 +1002,8 @@ func (c *ListController) updateMovie(ctx *gin.Context) {
 		ratingProvided = true
 	}
 
-	if req.Status == nil && !ratingProvided {
-		respondValidationError(ctx, []string{"Pelo menos um campo deve ser fornecido: status ou rating"})
+	if req.Status == nil && !ratingProvided && req.Watched == nil {
+		respondValidationError(ctx, []string{"Pelo menos um campo deve ser fornecido: status, rating ou watched"})
 		return
 	}
 
@@ -705,7 +1040,7 @@ func (c *ListController) updateMovie(ctx *gin.Context) {
 		return
 	}
 
-	updatedListMovie, movie, oldStatus, oldEntry, newEntry, averageRating, err := c.service.UpdateMovie(listID, userID, movieID, status, req.Rating, ratingProvided)
+	updatedListMovie, movie, oldStatus, oldEntry, newEntry, averageRating, err := c.service.UpdateMovie(listID, userID, movieID, status, req.Rating, ratingProvided, req.Watched)
 	if err != nil {
 		switch err {
 		case services.ErrListNotFound:
@@ -799,9 +1134,11 @@ func (c *ListController) updateMovie(ctx *gin.Context) {
 				}
 				return averageRating
 			}(),
-			"your_entry": buildEntryPayload(newEntry),
-			"watched_at": updatedListMovie.WatchedAt,
-			"updated_at": updatedListMovie.UpdatedAt.Format(time.RFC3339),
+			"your_entry":  buildEntryPayload(newEntry),
+			"watched_at":  updatedListMovie.WatchedAt,
+			"watched_by":  services.WatchedBy(updatedListMovie.Watches),
+			"you_watched": services.HasWatched(updatedListMovie.Watches, userID),
+			"updated_at":  updatedListMovie.UpdatedAt.Format(time.RFC3339),
 		},
 	})
 }
@@ -952,9 +1289,19 @@ func (c *ListController) getWatchProvidersForMovie(ctx *gin.Context, movieID int
 		data["buy"] = buy
 	}
 
+	// Avisar os membros quando o título entra em novos streamings
+	var added []string
+	if cached != nil {
+		var previous models.WatchProviderData
+		if jsonErr := json.Unmarshal([]byte(cached.Data), &previous); jsonErr == nil {
+			added = services.NewStreamingProviders(previous, regionData)
+		}
+	}
+
 	// Salvar no cache (não bloqueia se falhar)
 	go func() {
 		_ = c.watchProviderDAO.UpsertProviders(movieID, mediaType, region, data)
+		c.service.NotifyAvailabilityChange(movieID, region, added)
 	}()
 
 	return data
@@ -1023,149 +1370,26 @@ func (c *ListController) listMovies(ctx *gin.Context) {
 
 	resp := make([]gin.H, 0, len(items))
 	for _, lm := range items {
-		movie := lm.Movie
-		posterURL := (*string)(nil)
-		if movie.PosterPath != nil && *movie.PosterPath != "" {
-			u := "https://image.tmdb.org/t/p/w500" + *movie.PosterPath
-			posterURL = &u
-		}
-		m := gin.H{
-			"id":             movie.ID,
-			"title":          movie.Title,
-			"original_title": movie.OriginalTitle,
-			"original_lang":  movie.OriginalLang,
-			"overview":       movie.Overview,
-			"release_date":   movie.ReleaseDate,
-			"poster_url":     posterURL,
-			"media_type":     movie.MediaType,
-			"seasons_count":  movie.SeasonsCount,
-			"episodes_count": movie.EpisodesCount,
-			"series_status":  movie.SeriesStatus,
-		}
-		if len(movie.Genres) > 0 {
-			genres := make([]gin.H, 0, len(movie.Genres))
-			for _, g := range movie.Genres {
-				genres = append(genres, gin.H{"id": g.ID, "name": g.Name})
-			}
-			m["genres"] = genres
-		}
+		resp = append(resp, services.ListMovieItem(lm, userID))
+	}
 
-		var (
-			sumRatings   int
-			countRatings int
-			yourEntry    *models.ListMovieUserData
-			userEntries  = make([]gin.H, 0, len(lm.UserEntries))
-		)
+	// Buscar watch providers para todos os filmes em paralelo
+	var wg sync.WaitGroup
+	type watchProviderResult struct {
+		index int
+		data  map[string]interface{}
+	}
+	results := make(chan watchProviderResult, len(resp))
 
-		for _, entry := range lm.UserEntries {
-			if entry.Rating != nil {
-				sumRatings += *entry.Rating
-				countRatings++
-			}
-
-			entryPayload := gin.H{
-				"user_id":    entry.UserID,
-				"rating":     entry.Rating,
-				"notes":      entry.Notes,
-				"created_at": entry.CreatedAt,
-				"updated_at": entry.UpdatedAt,
-			}
-			if entry.User.ID != 0 {
-				entryPayload["user"] = gin.H{
-					"id":         entry.User.ID,
-					"username":   entry.User.Username,
-					"email":      entry.User.Email,
-					"avatar_url": entry.User.AvatarURL,
-				}
-			}
-			userEntries = append(userEntries, entryPayload)
-
-			if entry.UserID == userID {
-				copyEntry := entry
-				yourEntry = &copyEntry
-			}
-		}
-
-		var averageRating *float64
-		if countRatings > 0 {
-			avg := float64(sumRatings) / float64(countRatings)
-			averageRating = &avg
-		}
-
-		var yourEntryPayload gin.H
-		if yourEntry != nil {
-			yourEntryPayload = gin.H{
-				"user_id":    yourEntry.UserID,
-				"rating":     yourEntry.Rating,
-				"notes":      yourEntry.Notes,
-				"created_at": yourEntry.CreatedAt,
-				"updated_at": yourEntry.UpdatedAt,
-			}
-			if yourEntry.User.ID != 0 {
-				yourEntryPayload["user"] = gin.H{
-					"id":         yourEntry.User.ID,
-					"username":   yourEntry.User.Username,
-					"email":      yourEntry.User.Email,
-					"avatar_url": yourEntry.User.AvatarURL,
-				}
-			}
-		}
-
-		var ratingCompat *int
-		var notesCompat *string
-		if yourEntry != nil {
-			ratingCompat = yourEntry.Rating
-			notesCompat = yourEntry.Notes
-		}
-
-		var addedByUserPayload gin.H
-		if lm.AddedByUser != nil && lm.AddedByUser.ID != 0 {
-			addedByUserPayload = gin.H{
-				"id":         lm.AddedByUser.ID,
-				"username":   lm.AddedByUser.Username,
-				"email":      lm.AddedByUser.Email,
-				"avatar_url": lm.AddedByUser.AvatarURL,
-			}
-		}
-
-		item := gin.H{
-			"id":             lm.ID,
-			"list_id":        lm.ListID,
-			"movie_id":       lm.MovieID,
-			"status":         lm.Status,
-			"added_by":       lm.AddedBy,
-			"added_by_user":  addedByUserPayload,
-			"added_at":       lm.AddedAt,
-			"watched_at":     lm.WatchedAt,
-			"updated_at":     lm.UpdatedAt,
-			"display_order":  lm.DisplayOrder,
-			"rating":         ratingCompat,
-			"notes":          notesCompat,
-			"average_rating": averageRating,
-			"your_entry":     yourEntryPayload,
-			"user_entries":   userEntries,
-			"movie":          m,
-		}
-		resp = append(resp, item)
-	}
-
-	// Buscar watch providers para todos os filmes em paralelo
-	var wg sync.WaitGroup
-	type watchProviderResult struct {
-		index int
-		data  map[string]interface{}
-	}
-	results := make(chan watchProviderResult, len(resp))
-
-	for i, item := range resp {
-		wg.Add(1)
-		go func(idx int, movieItem gin.H) {
-			defer wg.Done()
-			movieData := movieItem["movie"].(gin.H)
-			movieID := movieData["id"].(int64)
-			mediaType, _ := movieData["media_type"].(string)
-			if mediaType == "" {
-				mediaType = "movie" // default to movie if not specified
+	for i, item := range resp {
+		wg.Add(1)
+		go func(idx int, movieItem gin.H) {
+			defer wg.Done()
+			movieData := movieItem["movie"].(map[string]interface{})
+			movieID := movieData["id"].(int64)
+			mediaType, _ := movieData["media_type"].(string)
+			if mediaType == "" {
+				mediaType = "movie" // default to movie if not specified
 			}
 			providers := c.getWatchProvidersForMovie(ctx, movieID, mediaType, "BR")
 			results <- watchProviderResult{index: idx, data: providers}
@@ -1181,7 +1405,7 @@ func (c *ListController) listMovies(ctx *gin.Context) {
 	// Coletar resultados e adicionar aos filmes
 	for result := range results {
 		if result.data != nil {
-			movieData := resp[result.index]["movie"].(gin.H)
+			movieData := resp[result.index]["movie"].(map[string]interface{})
 			movieData["watch_providers"] = result.data
 		}
 	}
@@ -1390,6 +1614,10 @@ func (c *ListController) searchMovies(ctx *gin.Context) {
 			"average_rating": averageRating,
 			"your_entry":     yourEntryPayload,
 			"user_entries":   userEntries,
+			"reactions":      services.ReactionsPayload(lm.Reactions, userID),
+			"watched_by":     services.WatchedBy(lm.Watches),
+			"you_watched":    services.HasWatched(lm.Watches, userID),
+			"tags":           services.TagNames(lm.Tags),
 			"movie":          m,
 		}
 		resp = append(resp, item)
@@ -1485,22 +1713,7 @@ func (c *ListController) listComments(ctx *gin.Context) {
 
 	commentsPayload := make([]gin.H, 0, len(comments))
 	for _, comment := range comments {
-		payload := gin.H{
-			"id":         comment.ID,
-			"user_id":    comment.UserID,
-			"content":    comment.Content,
-			"created_at": comment.CreatedAt,
-			"updated_at": comment.UpdatedAt,
-		}
-		if comment.User.ID != 0 {
-			payload["user"] = gin.H{
-				"id":         comment.User.ID,
-				"username":   comment.User.Username,
-				"email":      comment.User.Email,
-				"avatar_url": comment.User.AvatarURL,
-			}
-		}
-		commentsPayload = append(commentsPayload, payload)
+		commentsPayload = append(commentsPayload, commentPayload(comment, userID))
 	}
 
 	hasMore := offset+len(comments) < int(total)
@@ -1516,24 +1729,26 @@ func (c *ListController) listComments(ctx *gin.Context) {
 	})
 }
 
-func (c *ListController) createComment(ctx *gin.Context) {
-	idParam := ctx.Param("id")
-	listID, err := strconv.ParseInt(idParam, 10, 64)
-	if err != nil || listID <= 0 {
-		respondValidationError(ctx, []string{"Invalid list id"})
-		return
-	}
-
+// parseCommentScope returns the movie a comment route refers to, or nil on the list's
+// discussion board routes, which have no :movieId
+func parseCommentScope(ctx *gin.Context) (*int64, bool) {
 	movieIdParam := ctx.Param("movieId")
+	if movieIdParam == "" {
+		return nil, true
+	}
 	movieID, err := strconv.ParseInt(movieIdParam, 10, 64)
 	if err != nil || movieID <= 0 {
 		respondValidationError(ctx, []string{"Invalid movie id"})
-		return
+		return nil, false
 	}
+	return &movieID, true
+}
 
-	var req createCommentRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		respondValidationError(ctx, []string{"Invalid request body"})
+func (c *ListController) activity(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
 		return
 	}
 
@@ -1546,7 +1761,26 @@ func (c *ListController) createComment(ctx *gin.Context) {
 		return
 	}
 
-	comment, err := c.service.CreateComment(listID, userID, movieID, req.Content)
+	limit := 50
+	if v := ctx.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			if n > 100 {
+				n = 100
+			}
+			limit = n
+		}
+	}
+	var beforeID int64
+	if v := ctx.Query("before"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			respondValidationError(ctx, []string{"Invalid before cursor"})
+			return
+		}
+		beforeID = n
+	}
+
+	events, next, err := c.service.GetActivity(listID, userID, beforeID, limit)
 	if err != nil {
 		switch err {
 		case services.ErrListNotFound:
@@ -1559,75 +1793,237 @@ func (c *ListController) createComment(ctx *gin.Context) {
 		case services.ErrForbiddenMembership:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusForbidden, gin.H{
-				"error":     "Você não tem permissão para comentar nesta lista",
-				"timestamp": time.Now().UTC().Format(time.RFC3339),
-			})
-			return
-		case services.ErrMovieNotInList:
-			ctx.Header("Cache-Control", "no-store")
-			ctx.JSON(http.StatusNotFound, gin.H{
-				"error":     "Filme não encontrado nesta lista",
+				"error":     "Você não é membro desta lista",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
-		case services.ErrCommentEmpty:
-			respondValidationError(ctx, []string{"O comentário não pode ser vazio"})
-			return
-		case services.ErrCommentTooLong:
-			respondValidationError(ctx, []string{"O comentário não pode ter mais de 2000 caracteres"})
-			return
 		default:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error":     "Falha ao criar comentário",
+				"error":     "Falha ao buscar atividades da lista",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
 		}
 	}
 
-	payload := gin.H{
-		"id":         comment.ID,
-		"user_id":    comment.UserID,
-		"content":    comment.Content,
-		"created_at": comment.CreatedAt,
-		"updated_at": comment.UpdatedAt,
+	eventsPayload := make([]gin.H, 0, len(events))
+	for _, event := range events {
+		eventsPayload = append(eventsPayload, listEventPayload(event))
 	}
-	if comment.User.ID != 0 {
-		payload["user"] = gin.H{
-			"id":         comment.User.ID,
-			"username":   comment.User.Username,
-			"email":      comment.User.Email,
-			"avatar_url": comment.User.AvatarURL,
-		}
+
+	var nextCursor *int64
+	if next > 0 {
+		nextCursor = &next
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"events": eventsPayload,
+		"pagination": gin.H{
+			"limit":       limit,
+			"next_before": nextCursor,
+			"has_more":    nextCursor != nil,
+		},
+	})
+}
+
+// Idle streams get a ping this often so proxies keep the connection open
+const streamHeartbeatInterval = 25 * time.Second
+
+// stream pushes the list's changes to the caller as Server-Sent Events until the client
+// disconnects, the list is deleted or the caller leaves it
+func (c *ListController) stream(ctx *gin.Context) {
+	listID, userID, ok := liveListParams(ctx)
+	if !ok {
+		return
+	}
+
+	subscription, err := c.service.SubscribeToList(listID, userID)
+	if err != nil {
+		respondLiveListError(ctx, err, "Falha ao abrir o stream da lista")
+		return
 	}
+	defer subscription.Close()
 
+	ctx.Header("Content-Type", "text/event-stream")
 	ctx.Header("Cache-Control", "no-store")
-	ctx.JSON(http.StatusCreated, gin.H{
-		"success": true,
-		"message": "Comentário criado com sucesso",
-		"comment": payload,
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("ready", gin.H{"list_id": listID, "user_id": userID})
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case event, ok := <-subscription.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and refetches
+				return false
+			}
+			ctx.SSEvent(string(event.Type), event)
+			if event.Type == models.StreamListDeleted || (event.Type == models.StreamMemberLeft && event.ActorID == userID) {
+				return false
+			}
+			return true
+		case <-heartbeat.C:
+			ctx.SSEvent("ping", gin.H{"at": time.Now().UTC().Format(time.RFC3339)})
+			return true
+		}
 	})
 }
 
-func (c *ListController) updateComment(ctx *gin.Context) {
+// respondLiveListError answers a stream or presence request the service refused
+func respondLiveListError(ctx *gin.Context, err error, fallback string) {
+	ctx.Header("Cache-Control", "no-store")
+	switch err {
+	case services.ErrListNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Lista não encontrada",
+			"code":      "NOT_FOUND",
+			"details":   []string{"The specified list does not exist"},
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrForbiddenMembership:
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error":     "Você não é membro desta lista",
+			"code":      "FORBIDDEN",
+			"details":   []string{"You are not a member of this list"},
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     fallback,
+			"code":      "INTERNAL_ERROR",
+			"details":   []string{err.Error()},
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// liveListParams reads the list id and the caller; false means a response was written
+func liveListParams(ctx *gin.Context) (int64, int64, bool) {
 	idParam := ctx.Param("id")
 	listID, err := strconv.ParseInt(idParam, 10, 64)
 	if err != nil || listID <= 0 {
 		respondValidationError(ctx, []string{"Invalid list id"})
+		return 0, 0, false
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return 0, 0, false
+	}
+	return listID, userID, true
+}
+
+// getPresence returns who is viewing the list
+func (c *ListController) getPresence(ctx *gin.Context) {
+	listID, userID, ok := liveListParams(ctx)
+	if !ok {
+		return
+	}
+	members, err := c.service.GetPresence(listID, userID)
+	if err != nil {
+		respondLiveListError(ctx, err, "Falha ao carregar presença")
 		return
 	}
+	respondPresence(ctx, members)
+}
 
-	commentIdParam := ctx.Param("commentId")
-	commentID, err := strconv.ParseInt(commentIdParam, 10, 64)
-	if err != nil || commentID <= 0 {
-		respondValidationError(ctx, []string{"Invalid comment id"})
+// heartbeatPresence records that the caller is viewing the list and returns who else is
+func (c *ListController) heartbeatPresence(ctx *gin.Context) {
+	listID, userID, ok := liveListParams(ctx)
+	if !ok {
+		return
+	}
+	members, err := c.service.Heartbeat(listID, userID)
+	if err != nil {
+		respondLiveListError(ctx, err, "Falha ao atualizar presença")
 		return
 	}
+	respondPresence(ctx, members)
+}
 
-	var req updateCommentRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		respondValidationError(ctx, []string{"Invalid request body"})
+// leavePresence removes the caller from the list's viewers
+func (c *ListController) leavePresence(ctx *gin.Context) {
+	listID, userID, ok := liveListParams(ctx)
+	if !ok {
+		return
+	}
+	if err := c.service.LeavePresence(listID, userID); err != nil {
+		respondLiveListError(ctx, err, "Falha ao atualizar presença")
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func respondPresence(ctx *gin.Context, members []services.PresentMember) {
+	membersPayload := make([]gin.H, 0, len(members))
+	for _, m := range members {
+		membersPayload = append(membersPayload, gin.H{
+			"user_id":      m.UserID,
+			"username":     m.Username,
+			"avatar_url":   m.AvatarURL,
+			"role":         m.Role,
+			"last_seen_at": m.LastSeenAt,
+		})
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"members":     membersPayload,
+		"count":       len(membersPayload),
+		"ttl_seconds": int(services.PresenceTTL.Seconds()),
+	})
+}
+
+// listEventPayload renders an activity event; before and after are passed through as
+// the JSON objects stored with the event
+func listEventPayload(event models.ListEvent) gin.H {
+	rawValue := func(value *string) interface{} {
+		if value == nil {
+			return nil
+		}
+		return json.RawMessage(*value)
+	}
+	payload := gin.H{
+		"id":          event.ID,
+		"verb":        event.Verb,
+		"actor_id":    event.ActorID,
+		"target_type": event.TargetType,
+		"target_id":   event.TargetID,
+		"before":      rawValue(event.Before),
+		"after":       rawValue(event.After),
+		"created_at":  event.CreatedAt,
+	}
+	if event.Actor != nil {
+		payload["actor"] = gin.H{
+			"id":         event.Actor.ID,
+			"username":   event.Actor.Username,
+			"avatar_url": event.Actor.AvatarURL,
+		}
+	}
+	return payload
+}
+
+// markRead clears the caller's unread counts for the list, or for one movie's comments
+func (c *ListController) markRead(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieID, ok := parseCommentScope(ctx)
+	if !ok {
 		return
 	}
 
@@ -1640,8 +2036,7 @@ func (c *ListController) updateComment(ctx *gin.Context) {
 		return
 	}
 
-	comment, err := c.service.UpdateComment(listID, userID, commentID, req.Content)
-	if err != nil {
+	if err := c.service.MarkRead(listID, userID, movieID); err != nil {
 		switch err {
 		case services.ErrListNotFound:
 			ctx.Header("Cache-Control", "no-store")
@@ -1653,21 +2048,167 @@ func (c *ListController) updateComment(ctx *gin.Context) {
 		case services.ErrForbiddenMembership:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusForbidden, gin.H{
-				"error":     "Você não tem permissão para editar comentários nesta lista",
+				"error":     "Você não é membro desta lista",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
-		case services.ErrCommentNotFound:
+		case services.ErrMovieNotInList:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusNotFound, gin.H{
-				"error":     "Comentário não encontrado",
+				"error":     "Filme não encontrado nesta lista",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
-		case services.ErrCommentNotOwned:
+		default:
 			ctx.Header("Cache-Control", "no-store")
-			ctx.JSON(http.StatusForbidden, gin.H{
-				"error":     "Você só pode editar seus próprios comentários",
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao marcar como lido",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Marcado como lido",
+	})
+}
+
+func (c *ListController) listBoardComments(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	limit := 50
+	if v := ctx.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			if n > 100 {
+				n = 100
+			}
+			limit = n
+		}
+	}
+
+	comments, next, err := c.service.GetBoardComments(listID, userID, ctx.Query("cursor"), limit)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidCursor:
+			respondValidationError(ctx, []string{"Invalid cursor"})
+			return
+		case services.ErrListNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembership:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Você não tem permissão para ver o mural desta lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao buscar mensagens do mural",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	commentsPayload := make([]gin.H, 0, len(comments))
+	for _, comment := range comments {
+		commentsPayload = append(commentsPayload, commentPayload(comment, userID))
+	}
+
+	var nextCursor *string
+	if next != "" {
+		nextCursor = &next
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"comments": commentsPayload,
+		"pagination": gin.H{
+			"limit":       limit,
+			"next_cursor": nextCursor,
+			"has_more":    nextCursor != nil,
+		},
+	})
+}
+
+func (c *ListController) createComment(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieID, ok := parseCommentScope(ctx)
+	if !ok {
+		return
+	}
+
+	var req createCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	comment, err := c.service.CreateComment(listID, userID, movieID, req.ParentID, req.Content, req.Spoiler)
+	if err != nil {
+		switch err {
+		case services.ErrListNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembership:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Você não tem permissão para comentar nesta lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrMovieNotInList:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Filme não encontrado nesta lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrParentNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Comentário respondido não encontrado",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
@@ -1677,41 +2218,30 @@ func (c *ListController) updateComment(ctx *gin.Context) {
 		case services.ErrCommentTooLong:
 			respondValidationError(ctx, []string{"O comentário não pode ter mais de 2000 caracteres"})
 			return
+		case services.ErrCommentLinkNotAllowed:
+			respondValidationError(ctx, []string{"Links devem usar endereços http ou https"})
+			return
 		default:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error":     "Falha ao atualizar comentário",
+				"error":     "Falha ao criar comentário",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
 		}
 	}
 
-	payload := gin.H{
-		"id":         comment.ID,
-		"user_id":    comment.UserID,
-		"content":    comment.Content,
-		"created_at": comment.CreatedAt,
-		"updated_at": comment.UpdatedAt,
-	}
-	if comment.User.ID != 0 {
-		payload["user"] = gin.H{
-			"id":         comment.User.ID,
-			"username":   comment.User.Username,
-			"email":      comment.User.Email,
-			"avatar_url": comment.User.AvatarURL,
-		}
-	}
+	payload := commentPayload(*comment, userID)
 
 	ctx.Header("Cache-Control", "no-store")
-	ctx.JSON(http.StatusOK, gin.H{
+	ctx.JSON(http.StatusCreated, gin.H{
 		"success": true,
-		"message": "Comentário atualizado com sucesso",
+		"message": "Comentário criado com sucesso",
 		"comment": payload,
 	})
 }
 
-func (c *ListController) deleteComment(ctx *gin.Context) {
+func (c *ListController) updateComment(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 	listID, err := strconv.ParseInt(idParam, 10, 64)
 	if err != nil || listID <= 0 {
@@ -1726,6 +2256,12 @@ func (c *ListController) deleteComment(ctx *gin.Context) {
 		return
 	}
 
+	var req updateCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
 	rawClaims, _ := ctx.Get("auth_claims")
 	claims := rawClaims.(jwt.MapClaims)
 	sub, _ := claims["sub"].(string)
@@ -1735,7 +2271,7 @@ func (c *ListController) deleteComment(ctx *gin.Context) {
 		return
 	}
 
-	err = c.service.DeleteComment(listID, userID, commentID)
+	comment, err := c.service.UpdateComment(listID, userID, commentID, req.Content, req.Spoiler)
 	if err != nil {
 		switch err {
 		case services.ErrListNotFound:
@@ -1748,7 +2284,7 @@ func (c *ListController) deleteComment(ctx *gin.Context) {
 		case services.ErrForbiddenMembership:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusForbidden, gin.H{
-				"error":     "Você não tem permissão para excluir comentários nesta lista",
+				"error":     "Você não tem permissão para editar comentários nesta lista",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
@@ -1762,28 +2298,47 @@ func (c *ListController) deleteComment(ctx *gin.Context) {
 		case services.ErrCommentNotOwned:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusForbidden, gin.H{
-				"error":     "Você só pode excluir seus próprios comentários",
+				"error":     "Você só pode editar seus próprios comentários",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrCommentHidden:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error":     "Este comentário foi ocultado pelo dono da lista e não pode ser editado",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
+		case services.ErrCommentEmpty:
+			respondValidationError(ctx, []string{"O comentário não pode ser vazio"})
+			return
+		case services.ErrCommentTooLong:
+			respondValidationError(ctx, []string{"O comentário não pode ter mais de 2000 caracteres"})
+			return
+		case services.ErrCommentLinkNotAllowed:
+			respondValidationError(ctx, []string{"Links devem usar endereços http ou https"})
+			return
 		default:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error":     "Falha ao excluir comentário",
+				"error":     "Falha ao atualizar comentário",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
 		}
 	}
 
+	payload := commentPayload(*comment, userID)
+
 	ctx.Header("Cache-Control", "no-store")
 	ctx.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Comentário excluído com sucesso",
+		"message": "Comentário atualizado com sucesso",
+		"comment": payload,
 	})
 }
 
-func (c *ListController) getRecommendations(ctx *gin.Context) {
+func (c *ListController) deleteComment(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 	listID, err := strconv.ParseInt(idParam, 10, 64)
 	if err != nil || listID <= 0 {
@@ -1791,6 +2346,13 @@ func (c *ListController) getRecommendations(ctx *gin.Context) {
 		return
 	}
 
+	commentIdParam := ctx.Param("commentId")
+	commentID, err := strconv.ParseInt(commentIdParam, 10, 64)
+	if err != nil || commentID <= 0 {
+		respondValidationError(ctx, []string{"Invalid comment id"})
+		return
+	}
+
 	rawClaims, _ := ctx.Get("auth_claims")
 	claims := rawClaims.(jwt.MapClaims)
 	sub, _ := claims["sub"].(string)
@@ -1800,87 +2362,989 @@ func (c *ListController) getRecommendations(ctx *gin.Context) {
 		return
 	}
 
-	// Get limit from query params
-	limit := 15
-	if v := ctx.Query("limit"); v != "" {
-		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			if n > 50 {
-				n = 50
-			}
-			limit = n
-		}
-	}
-
-	recommendations, err := c.recommendationService.GetListRecommendations(ctx, listID, userID, limit)
+	placeholder, err := c.service.DeleteComment(listID, userID, commentID)
 	if err != nil {
 		switch err {
-		case services.ErrListNotFoundRec:
+		case services.ErrListNotFound:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusNotFound, gin.H{
 				"error":     "Lista não encontrada",
-				"code":      "NOT_FOUND",
-				"details":   []string{"A lista especificada não existe"},
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
-		case services.ErrForbiddenMembershipRec:
+		case services.ErrForbiddenMembership:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusForbidden, gin.H{
-				"error":     "Acesso negado",
-				"code":      "FORBIDDEN",
-				"details":   []string{"Você não é membro desta lista"},
+				"error":     "Você não tem permissão para excluir comentários nesta lista",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
-		case services.ErrInsufficientMovies:
+		case services.ErrCommentNotFound:
 			ctx.Header("Cache-Control", "no-store")
-			ctx.JSON(http.StatusBadRequest, gin.H{
-				"error":     "Filmes insuficientes",
-				"code":      "INSUFFICIENT_MOVIES",
-				"details":   []string{"A lista deve ter pelo menos 2 filmes para gerar recomendações"},
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Comentário não encontrado",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrCommentNotOwned:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Você só pode excluir seus próprios comentários",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
 		default:
 			ctx.Header("Cache-Control", "no-store")
 			ctx.JSON(http.StatusInternalServerError, gin.H{
-				"error":     "Falha ao buscar recomendações",
-				"code":      "INTERNAL_ERROR",
-				"details":   []string{err.Error()},
+				"error":     "Falha ao excluir comentário",
 				"timestamp": time.Now().UTC().Format(time.RFC3339),
 			})
 			return
 		}
 	}
 
-	// Build response
-	resp := make([]gin.H, 0, len(recommendations))
-	for _, rec := range recommendations {
-		item := gin.H{
-			"id":         rec.ID,
-			"title":      rec.Title,
-			"media_type": rec.MediaType,
-			"poster_url": rec.PosterURL,
-			"overview":   rec.Overview,
-			"score":      rec.Score,
-			"popularity": rec.Popularity,
-		}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Comentário excluído com sucesso",
+		// The comment stays as a "[deleted]" placeholder while it has replies
+		"placeholder": placeholder,
+	})
+}
 
-		if len(rec.Genres) > 0 {
-			genres := make([]gin.H, 0, len(rec.Genres))
-			for _, g := range rec.Genres {
-				genres = append(genres, gin.H{"id": g.ID})
-			}
-			item["genres"] = genres
-		}
+func (c *ListController) getCommentThread(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
 
-		resp = append(resp, item)
+	movieID, ok := parseCommentScope(ctx)
+	if !ok {
+		return
 	}
 
-	ctx.Header("Cache-Control", "private, max-age=86400") // Cache for 24 hours
-	ctx.JSON(http.StatusOK, gin.H{
-		"recommendations": resp,
-		"count":           len(resp),
-		"generated_at":    time.Now().UTC().Format(time.RFC3339),
+	commentIdParam := ctx.Param("commentId")
+	commentID, err := strconv.ParseInt(commentIdParam, 10, 64)
+	if err != nil || commentID <= 0 {
+		respondValidationError(ctx, []string{"Invalid comment id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if v := ctx.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			if n > 100 {
+				n = 100
+			}
+			limit = n
+		}
+	}
+	if v := ctx.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	parent, replies, total, err := c.service.GetCommentThread(listID, userID, movieID, commentID, limit, offset)
+	if err != nil {
+		switch err {
+		case services.ErrListNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembership:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Você não tem permissão para acessar esta lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrCommentNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Comentário não encontrado",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao buscar respostas",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	repliesPayload := make([]gin.H, 0, len(replies))
+	for _, reply := range replies {
+		repliesPayload = append(repliesPayload, commentPayload(reply, userID))
+	}
+
+	hasMore := offset+len(replies) < int(total)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"comment": commentPayload(*parent, userID),
+		"replies": repliesPayload,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   offset,
+			"has_more": hasMore,
+		},
+	})
+}
+
+// commentPayload renders a comment for the viewer, hiding the author of deleted placeholders
+// and the content of hidden comments the viewer may not read
+func commentPayload(comment models.Comment, viewerID int64) gin.H {
+	payload := gin.H{
+		"id":             comment.ID,
+		"user_id":        comment.UserID,
+		"parent_id":      comment.ParentID,
+		"content":        comment.Content,
+		"reply_count":    comment.ReplyCount,
+		"deleted":        comment.IsDeleted(),
+		"deleted_reason": comment.DeletedReason,
+		"hidden":         comment.IsHidden(),
+		"edited":         comment.IsEdited(),
+		"edited_at":      comment.EditedAt,
+		"reactions":      services.ReactionsPayload(comment.Reactions, viewerID),
+		"created_at":     comment.CreatedAt,
+		"updated_at":     comment.UpdatedAt,
+	}
+	if comment.IsDeleted() || comment.Redacted {
+		placeholder := models.DeletedCommentPlaceholder
+		if !comment.IsDeleted() {
+			placeholder = models.HiddenCommentPlaceholder
+		}
+		payload["user_id"] = nil
+		payload["content"] = placeholder
+		payload["edited"] = false
+		payload["edited_at"] = nil
+		payload["reactions"] = []gin.H{}
+		payload["mentions"] = []gin.H{}
+		payload["spoiler"] = false
+		payload["spoiler_reason"] = nil
+		payload["segments"] = services.SplitSpoilers(placeholder)
+		placeholderNodes := services.FormatComment(placeholder)
+		payload["nodes"] = placeholderNodes
+		payload["rendered"] = services.RenderCommentHTML(placeholderNodes)
+		return payload
+	}
+	// Whole-comment spoilers come from the author's flag or from the reader not having
+	// watched a title the author has; inline ||markup|| is returned as segments
+	var spoilerReason *string
+	if comment.Spoiler {
+		reason := "author"
+		spoilerReason = &reason
+	} else if comment.AutoSpoiler {
+		reason := "watched"
+		spoilerReason = &reason
+	}
+	payload["spoiler"] = spoilerReason != nil
+	payload["spoiler_reason"] = spoilerReason
+	payload["segments"] = services.SplitSpoilers(comment.Content)
+	// Formatting is parsed on the server; rendered is escaped HTML safe to display as is
+	nodes := services.FormatComment(comment.Content)
+	payload["nodes"] = nodes
+	payload["rendered"] = services.RenderCommentHTML(nodes)
+	if comment.User.ID != 0 {
+		payload["user"] = gin.H{
+			"id":         comment.User.ID,
+			"username":   comment.User.Username,
+			"email":      comment.User.Email,
+			"avatar_url": comment.User.AvatarURL,
+		}
+	}
+	// Offsets and lengths count Unicode code points and include the "@"
+	mentions := make([]gin.H, 0, len(comment.Mentions))
+	for _, m := range comment.Mentions {
+		mentions = append(mentions, gin.H{
+			"user_id":  m.UserID,
+			"username": m.User.Username,
+			"offset":   m.Offset,
+			"length":   m.Length,
+		})
+	}
+	payload["mentions"] = mentions
+	return payload
+}
+
+func (c *ListController) listCommentRevisions(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieID, ok := parseCommentScope(ctx)
+	if !ok {
+		return
+	}
+
+	commentIdParam := ctx.Param("commentId")
+	commentID, err := strconv.ParseInt(commentIdParam, 10, 64)
+	if err != nil || commentID <= 0 {
+		respondValidationError(ctx, []string{"Invalid comment id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	comment, revisions, err := c.service.GetCommentRevisions(listID, userID, movieID, commentID)
+	if err != nil {
+		switch err {
+		case services.ErrListNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembership:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Você não tem permissão para ver comentários nesta lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrCommentNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Comentário não encontrado",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrCommentHidden:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Este comentário foi ocultado pelo dono da lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao buscar histórico de edições",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	// Newest first; the current content is the comment itself
+	revisionsPayload := make([]gin.H, 0, len(revisions))
+	for _, revision := range revisions {
+		revisionsPayload = append(revisionsPayload, gin.H{
+			"id":         revision.ID,
+			"content":    revision.Content,
+			"spoiler":    revision.Spoiler,
+			"segments":   services.SplitSpoilers(revision.Content),
+			"rendered":   services.RenderCommentHTML(services.FormatComment(revision.Content)),
+			"created_at": revision.CreatedAt,
+		})
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"comment":   commentPayload(*comment, userID),
+		"revisions": revisionsPayload,
+	})
+}
+
+type moderateCommentRequest struct {
+	Action string  `json:"action"`
+	Reason *string `json:"reason"`
+}
+
+func (c *ListController) moderateComment(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieID, ok := parseCommentScope(ctx)
+	if !ok {
+		return
+	}
+
+	commentIdParam := ctx.Param("commentId")
+	commentID, err := strconv.ParseInt(commentIdParam, 10, 64)
+	if err != nil || commentID <= 0 {
+		respondValidationError(ctx, []string{"Invalid comment id"})
+		return
+	}
+
+	var req moderateCommentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	comment, err := c.service.ModerateComment(listID, userID, movieID, commentID, models.ModerationAction(req.Action), req.Reason)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidModerationAction:
+			respondValidationError(ctx, []string{"action must be one of: hide, unhide, delete, restore"})
+			return
+		case services.ErrModerationReasonTooLong:
+			respondValidationError(ctx, []string{"O motivo não pode ter mais de 500 caracteres"})
+			return
+		case services.ErrListNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembership, services.ErrModeratorOnly:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Apenas o dono da lista pode moderar comentários",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrCommentNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Comentário não encontrado",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrModerationNotApplicable:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error":     "Esta ação não se aplica ao estado atual do comentário",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao moderar comentário",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Comentário moderado com sucesso",
+		"comment": commentPayload(*comment, userID),
+	})
+}
+
+func (c *ListController) moderationLog(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if v := ctx.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			if n > 100 {
+				n = 100
+			}
+			limit = n
+		}
+	}
+	if v := ctx.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	entries, total, err := c.service.GetModerationLog(listID, userID, limit, offset)
+	if err != nil {
+		switch err {
+		case services.ErrListNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembership, services.ErrModeratorOnly:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Apenas o dono da lista pode ver o registro de moderação",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao buscar registro de moderação",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+	entriesPayload := make([]gin.H, 0, len(entries))
+	for _, entry := range entries {
+		entriesPayload = append(entriesPayload, gin.H{
+			"id":         entry.ID,
+			"comment_id": entry.CommentID,
+			"action":     entry.Action,
+			"reason":     entry.Reason,
+			"moderator": gin.H{
+				"id":         entry.Moderator.ID,
+				"username":   entry.Moderator.Username,
+				"avatar_url": entry.Moderator.AvatarURL,
+			},
+			"author": gin.H{
+				"id":         entry.Author.ID,
+				"username":   entry.Author.Username,
+				"avatar_url": entry.Author.AvatarURL,
+			},
+			"created_at": entry.CreatedAt,
+		})
+	}
+
+	hasMore := offset+len(entries) < int(total)
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"entries": entriesPayload,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   offset,
+			"has_more": hasMore,
+		},
+	})
+}
+
+func (c *ListController) getRecommendations(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	// Get limit from query params
+	limit := 15
+	if v := ctx.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			if n > 50 {
+				n = 50
+			}
+			limit = n
+		}
+	}
+
+	mode := services.RecommendationMode(strings.ToLower(strings.TrimSpace(ctx.Query("mode"))))
+	fairness := services.FairnessStrategy(strings.ToLower(strings.TrimSpace(ctx.Query("fairness"))))
+
+	result, err := c.recommendationService.GetListRecommendations(ctx, listID, userID, limit, mode, fairness)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidRecommendationMode:
+			respondValidationError(ctx, []string{"mode must be 'default' or 'group'"})
+			return
+		case services.ErrInvalidFairnessStrategy:
+			respondValidationError(ctx, []string{"fairness must be 'least_misery' or 'average_minus_variance'"})
+			return
+		case services.ErrListNotFoundRec:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"code":      "NOT_FOUND",
+				"details":   []string{"A lista especificada não existe"},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembershipRec:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Acesso negado",
+				"code":      "FORBIDDEN",
+				"details":   []string{"Você não é membro desta lista"},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrInsufficientMovies:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":     "Filmes insuficientes",
+				"code":      "INSUFFICIENT_MOVIES",
+				"details":   []string{"Não há títulos suficientes na lista nem no histórico dos membros para gerar recomendações"},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao buscar recomendações",
+				"code":      "INTERNAL_ERROR",
+				"details":   []string{err.Error()},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	// Build response
+	resp := make([]gin.H, 0, len(result.Items))
+	for _, rec := range result.Items {
+		item := gin.H{
+			"id":         rec.ID,
+			"title":      rec.Title,
+			"media_type": rec.MediaType,
+			"poster_url": rec.PosterURL,
+			"overview":   rec.Overview,
+			"score":      rec.Score,
+			"popularity": rec.Popularity,
+		}
+
+		if len(rec.Genres) > 0 {
+			genres := make([]gin.H, 0, len(rec.Genres))
+			for _, g := range rec.Genres {
+				genre := gin.H{"id": g.ID}
+				if g.Name != "" {
+					genre["name"] = g.Name
+				}
+				genres = append(genres, genre)
+			}
+			item["genres"] = genres
+		}
+
+		seeds := make([]gin.H, 0, len(rec.Reasons.BecauseYouLiked))
+		for _, seed := range rec.Reasons.BecauseYouLiked {
+			seeds = append(seeds, gin.H{
+				"id":         seed.ID,
+				"title":      seed.Title,
+				"media_type": seed.MediaType,
+			})
+		}
+		matchedGenres := make([]gin.H, 0, len(rec.Reasons.MatchedGenres))
+		for _, g := range rec.Reasons.MatchedGenres {
+			matchedGenres = append(matchedGenres, gin.H{"id": g.ID, "name": g.Name})
+		}
+		item["reasons"] = gin.H{
+			"sources":           rec.Reasons.Sources,
+			"because_you_liked": seeds,
+			"matched_genres":    matchedGenres,
+			"contributions": gin.H{
+				"popularity":       rec.Reasons.Contributions.Popularity,
+				"seed_frequency":   rec.Reasons.Contributions.SeedFrequency,
+				"genre_match":      rec.Reasons.Contributions.GenreMatch,
+				"local_similarity": rec.Reasons.Contributions.LocalSimilarity,
+			},
+		}
+
+		if len(rec.MemberFits) > 0 {
+			fits := make([]gin.H, 0, len(rec.MemberFits))
+			for _, fit := range rec.MemberFits {
+				fits = append(fits, gin.H{
+					"user_id":  fit.UserID,
+					"username": fit.Username,
+					"fit":      fit.Fit,
+				})
+			}
+			item["member_fits"] = fits
+		}
+
+		resp = append(resp, item)
+	}
+
+	if mode == "" {
+		mode = services.RecommendationModeDefault
+	}
+	response := gin.H{
+		"recommendations": resp,
+		"count":           len(resp),
+		"mode":            mode,
+		"strategy":        result.Strategy,
+		"generated_at":    time.Now().UTC().Format(time.RFC3339),
+	}
+	if mode == services.RecommendationModeGroup {
+		if fairness == "" {
+			fairness = services.FairnessAverageMinusVariance
+		}
+		response["fairness"] = fairness
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=86400") // Cache for 24 hours
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ListController) submitRecommendationFeedback(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieIdParam := ctx.Param("movieId")
+	movieID, err := strconv.ParseInt(movieIdParam, 10, 64)
+	if err != nil || movieID <= 0 {
+		respondValidationError(ctx, []string{"Invalid movie id"})
+		return
+	}
+
+	var req recommendationFeedbackRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+	action := models.RecommendationFeedbackAction(strings.ToLower(strings.TrimSpace(req.Action)))
+	mediaType := strings.ToLower(strings.TrimSpace(req.MediaType))
+	if mediaType == "" {
+		mediaType = "movie"
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	feedback, listMovie, err := c.recommendationService.SubmitFeedback(ctx, listID, userID, movieID, mediaType, action, req.AddToList)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidFeedbackAction:
+			respondValidationError(ctx, []string{"action must be one of: dismissed, not_interested, already_seen"})
+			return
+		case services.ErrInvalidMediaType:
+			respondValidationError(ctx, []string{"media_type must be 'movie' or 'tv'"})
+			return
+		case services.ErrListNotFoundRec:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"code":      "NOT_FOUND",
+				"details":   []string{"A lista especificada não existe"},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembershipRec:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Acesso negado",
+				"code":      "FORBIDDEN",
+				"details":   []string{"Você não é membro desta lista"},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembership:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Acesso negado",
+				"code":      "FORBIDDEN",
+				"details":   []string{"Você não tem permissão para adicionar filmes nesta lista"},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrMediaNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Mídia não encontrada na base do TMDB",
+				"code":      "NOT_FOUND",
+				"details":   []string{},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrTMDBUnavailable:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusBadGateway, gin.H{
+				"error":     "Erro na consulta externa",
+				"code":      "BAD_GATEWAY",
+				"details":   []string{err.Error()},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao registrar feedback",
+				"code":      "INTERNAL_ERROR",
+				"details":   []string{err.Error()},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	data := gin.H{
+		"list_id":    feedback.ListID,
+		"movie_id":   feedback.MovieID,
+		"media_type": feedback.MediaType,
+		"action":     feedback.Action,
+		"updated_at": feedback.UpdatedAt,
+	}
+	if listMovie != nil {
+		data["list_movie"] = gin.H{
+			"id":         listMovie.ID,
+			"movie_id":   listMovie.MovieID,
+			"status":     listMovie.Status,
+			"watched_at": listMovie.WatchedAt,
+		}
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Feedback registrado com sucesso",
+		"data":    data,
+	})
+}
+
+func (c *ListController) removeRecommendationFeedback(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieIdParam := ctx.Param("movieId")
+	movieID, err := strconv.ParseInt(movieIdParam, 10, 64)
+	if err != nil || movieID <= 0 {
+		respondValidationError(ctx, []string{"Invalid movie id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	if err := c.recommendationService.RemoveFeedback(listID, userID, movieID); err != nil {
+		switch err {
+		case services.ErrListNotFoundRec:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"code":      "NOT_FOUND",
+				"details":   []string{"A lista especificada não existe"},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrForbiddenMembershipRec:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Acesso negado",
+				"code":      "FORBIDDEN",
+				"details":   []string{"Você não é membro desta lista"},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		case services.ErrFeedbackNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Feedback não encontrado",
+				"code":      "NOT_FOUND",
+				"details":   []string{},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao remover feedback",
+				"code":      "INTERNAL_ERROR",
+				"details":   []string{err.Error()},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Status(http.StatusNoContent)
+}
+
+func (c *ListController) toggleListMovieReaction(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieIdParam := ctx.Param("movieId")
+	movieID, err := strconv.ParseInt(movieIdParam, 10, 64)
+	if err != nil || movieID <= 0 {
+		respondValidationError(ctx, []string{"Invalid movie id"})
+		return
+	}
+
+	var req toggleReactionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	added, summaries, err := c.reactionService.ToggleListMovieReaction(listID, userID, movieID, req.Emoji)
+	if err != nil {
+		respondReactionError(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"added":     added,
+		"reactions": services.ReactionsPayload(summaries, userID),
+	})
+}
+
+func (c *ListController) toggleCommentReaction(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	listID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+
+	movieID, ok := parseCommentScope(ctx)
+	if !ok {
+		return
+	}
+
+	commentIdParam := ctx.Param("commentId")
+	commentID, err := strconv.ParseInt(commentIdParam, 10, 64)
+	if err != nil || commentID <= 0 {
+		respondValidationError(ctx, []string{"Invalid comment id"})
+		return
+	}
+
+	var req toggleReactionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	added, summaries, err := c.reactionService.ToggleCommentReaction(listID, userID, movieID, commentID, req.Emoji)
+	if err != nil {
+		respondReactionError(ctx, err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":   true,
+		"added":     added,
+		"reactions": services.ReactionsPayload(summaries, userID),
 	})
 }
+
+func respondReactionError(ctx *gin.Context, err error) {
+	switch err {
+	case services.ErrInvalidReaction:
+		respondValidationError(ctx, []string{"emoji must be one of: 👍 ❤️ 😂 🍿"})
+	case services.ErrListNotFound:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Lista não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrForbiddenMembership:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error":     "Você não tem permissão para reagir nesta lista",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrMovieNotInList:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Filme não encontrado nesta lista",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrCommentNotFound:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Comentário não encontrado",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	default:
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Falha ao registrar reação",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/8bury/list2gether/services"
+	"github.com/gin-gonic/gin"
+)
+
+// exportList streams the list as CSV, JSON or a Letterboxd import CSV, chosen by the
+// format query parameter (csv by default)
+func (c *ListController) exportList(ctx *gin.Context) {
+	listID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	format := models.ExportFormat(strings.ToLower(strings.TrimSpace(ctx.DefaultQuery("format", string(models.ExportCSV)))))
+	export, err := c.service.ExportList(listID, userID, format)
+	if err != nil {
+		ctx.Header("Cache-Control", "no-store")
+		switch err {
+		case services.ErrInvalidExportFormat:
+			respondValidationError(ctx, []string{"format must be one of: csv, json, letterboxd"})
+		case services.ErrListNotFound:
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Lista não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+		case services.ErrForbiddenMembership:
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":     "Você não é membro desta lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao exportar lista",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+		}
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Content-Type", export.ContentType())
+	ctx.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": export.Filename()}))
+	ctx.Status(http.StatusOK)
+	// The status is already sent, so a failure part way can only cut the download short
+	if err := export.Write(ctx.Writer); err != nil {
+		log.Printf("export_list failed user_id=%d list_id=%d format=%s: %v", userID, listID, format, err)
+	}
+}
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/8bury/list2gether/services"
+	"github.com/gin-gonic/gin"
+)
+
+func importPayload(job *models.ListImport) gin.H {
+	return gin.H{
+		"id":              job.ID,
+		"list_id":         job.ListID,
+		"user_id":         job.UserID,
+		"format":          job.Format,
+		"status":          job.Status,
+		"total_rows":      job.TotalRows,
+		"processed_rows":  job.ProcessedRows,
+		"added_count":     job.AddedCount,
+		"updated_count":   job.UpdatedCount,
+		"unmatched_count": job.UnmatchedCount,
+		"error":           job.Error,
+		"created_at":      job.CreatedAt,
+		"updated_at":      job.UpdatedAt,
+		"finished_at":     job.FinishedAt,
+	}
+}
+
+func respondImportError(ctx *gin.Context, err error, fallback string) {
+	ctx.Header("Cache-Control", "no-store")
+	switch err {
+	case services.ErrInvalidImportFormat:
+		respondValidationError(ctx, []string{"format must be one of: letterboxd, imdb, trakt, list2gether"})
+	case services.ErrInvalidImportFile:
+		respondValidationError(ctx, []string{"file is not a Letterboxd, IMDb, Trakt or list2gether export"})
+	case services.ErrImportTooLarge:
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":     "O arquivo excede o limite de " + strconv.Itoa(services.MaxImportRows) + " linhas ou " + strconv.Itoa(services.MaxImportFileSize>>20) + " MB",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrListNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Lista não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrForbiddenMembership:
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error":     "Você não tem permissão para importar filmes nesta lista",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrImportInProgress:
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":     "Já existe uma importação em andamento nesta lista",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrImportNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Importação não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     fallback,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+// importList starts importing an uploaded Letterboxd, IMDb, Trakt or list2gether
+// export. The file is sent as the multipart field "file"; "format" is detected when left
+// out and "status" is given to titles the export does not mark as watched.
+func (c *ListController) importList(ctx *gin.Context) {
+	listID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	// Room for the multipart framing around the file
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, services.MaxImportFileSize+1<<20)
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		if strings.Contains(err.Error(), "request body too large") {
+			respondImportError(ctx, services.ErrImportTooLarge, "")
+			return
+		}
+		respondValidationError(ctx, []string{"file is required"})
+		return
+	}
+	if header.Size > services.MaxImportFileSize {
+		respondImportError(ctx, services.ErrImportTooLarge, "")
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondValidationError(ctx, []string{"file could not be read"})
+		return
+	}
+	data, err := io.ReadAll(file)
+	file.Close()
+	if err != nil {
+		respondValidationError(ctx, []string{"file could not be read"})
+		return
+	}
+
+	format := models.ImportFormat(strings.ToLower(strings.TrimSpace(ctx.PostForm("format"))))
+	if format != "" && !format.IsValid() {
+		respondImportError(ctx, services.ErrInvalidImportFormat, "")
+		return
+	}
+	status := models.StatusNotWatched
+	if v := strings.TrimSpace(ctx.PostForm("status")); v != "" {
+		status = models.MovieStatus(v)
+		if status != models.StatusNotWatched && status != models.StatusWatching && status != models.StatusWatched && status != models.StatusDropped {
+			respondValidationError(ctx, []string{"status must be one of: not_watched, watching, watched, dropped"})
+			return
+		}
+	}
+
+	job, err := c.service.ImportList(listID, userID, format, data, status)
+	if err != nil {
+		respondImportError(ctx, err, "Falha ao iniciar importação")
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"message": "Importação iniciada",
+		"import":  importPayload(job),
+	})
+}
+
+// getImport reports an import's progress and, once rows fail to match, which ones
+func (c *ListController) getImport(ctx *gin.Context) {
+	listID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return
+	}
+	importID, err := strconv.ParseInt(ctx.Param("importId"), 10, 64)
+	if err != nil || importID <= 0 {
+		respondValidationError(ctx, []string{"Invalid import id"})
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	job, err := c.service.GetImport(listID, userID, importID)
+	if err != nil {
+		respondImportError(ctx, err, "Falha ao buscar importação")
+		return
+	}
+	payload := importPayload(job)
+	payload["unmatched"] = job.UnmatchedRows()
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"import": payload})
+}
@@ -0,0 +1,367 @@
+package controllers
+
+import (
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/8bury/list2gether/middleware"
+	"github.com/8bury/list2gether/models"
+	"github.com/8bury/list2gether/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type NotificationController struct {
+	service        services.NotificationService
+	authMiddleware *middleware.AuthMiddleware
+}
+
+func NewNotificationController(router *gin.Engine, service services.NotificationService, authMiddleware *middleware.AuthMiddleware) *NotificationController {
+	c := &NotificationController{service: service, authMiddleware: authMiddleware}
+	group := router.Group("/api/notifications")
+	group.GET("", c.authMiddleware.Handler(), c.list)
+	group.POST("/read-all", c.authMiddleware.Handler(), c.markAllRead)
+	group.POST("/:id/read", c.authMiddleware.Handler(), c.markRead)
+	group.GET("/preferences", c.authMiddleware.Handler(), c.getPreferences)
+	group.PUT("/preferences", c.authMiddleware.Handler(), c.updatePreferences)
+	// Unsubscribe links are opened from emails, without a session; the token is the credential
+	group.GET("/unsubscribe", c.confirmUnsubscribe)
+	group.POST("/unsubscribe", c.unsubscribe)
+	return c
+}
+
+type updateNotificationPreferencesRequest struct {
+	Preferences map[models.NotificationType]bool `json:"preferences"`
+}
+
+func (c *NotificationController) list(ctx *gin.Context) {
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	limit := 20
+	offset := 0
+	if v := ctx.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			if n > 100 {
+				n = 100
+			}
+			limit = n
+		}
+	}
+	if v := ctx.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+	unreadOnly := ctx.Query("unread") == "true"
+
+	notifications, total, unread, err := c.service.ListNotifications(userID, unreadOnly, limit, offset)
+	if err != nil {
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Falha ao buscar notificações",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	items := make([]gin.H, 0, len(notifications))
+	for _, n := range notifications {
+		items = append(items, notificationPayload(n))
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"notifications": items,
+		"unread_count":  unread,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   offset,
+			"has_more": int64(offset+len(items)) < total,
+		},
+	})
+}
+
+// notificationPayload renders a notification; the payload is passed through as the JSON
+// object stored for its type
+func notificationPayload(n models.Notification) gin.H {
+	var payload interface{}
+	if n.Payload != "" {
+		payload = json.RawMessage(n.Payload)
+	}
+	item := gin.H{
+		"id":         n.ID,
+		"type":       n.Type,
+		"list_id":    n.ListID,
+		"actor_id":   n.ActorID,
+		"payload":    payload,
+		"read":       n.IsRead(),
+		"read_at":    n.ReadAt,
+		"created_at": n.CreatedAt,
+	}
+	if n.Actor != nil {
+		item["actor"] = gin.H{
+			"id":         n.Actor.ID,
+			"username":   n.Actor.Username,
+			"avatar_url": n.Actor.AvatarURL,
+		}
+	}
+	return item
+}
+
+func (c *NotificationController) markRead(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	notificationID, err := strconv.ParseInt(idParam, 10, 64)
+	if err != nil || notificationID <= 0 {
+		respondValidationError(ctx, []string{"Invalid notification id"})
+		return
+	}
+
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	if err := c.service.MarkRead(userID, notificationID); err != nil {
+		switch err {
+		case services.ErrNotificationNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Notificação não encontrada",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao marcar notificação como lida",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notificação marcada como lida",
+	})
+}
+
+func (c *NotificationController) markAllRead(ctx *gin.Context) {
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	updated, err := c.service.MarkAllRead(userID)
+	if err != nil {
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Falha ao marcar notificações como lidas",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notificações marcadas como lidas",
+		"data":    gin.H{"updated": updated},
+	})
+}
+
+func (c *NotificationController) getPreferences(ctx *gin.Context) {
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	channel := preferenceChannel(ctx)
+	preferences, err := c.service.GetPreferences(userID, channel)
+	if err == services.ErrInvalidNotificationChannel {
+		respondValidationError(ctx, []string{"channel must be one of: in_app, email, push"})
+		return
+	}
+	if err != nil {
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Falha ao buscar preferências de notificação",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"channel": channel, "preferences": preferences})
+}
+
+// preferenceChannel reads the channel query parameter, defaulting to in-app
+func preferenceChannel(ctx *gin.Context) models.NotificationChannel {
+	if v := ctx.Query("channel"); v != "" {
+		return models.NotificationChannel(v)
+	}
+	return models.NotificationChannelInApp
+}
+
+func (c *NotificationController) updatePreferences(ctx *gin.Context) {
+	rawClaims, _ := ctx.Get("auth_claims")
+	claims := rawClaims.(jwt.MapClaims)
+	sub, _ := claims["sub"].(string)
+	userID, err := strconv.ParseInt(sub, 10, 64)
+	if err != nil {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	var req updateNotificationPreferencesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+	if len(req.Preferences) == 0 {
+		respondValidationError(ctx, []string{"preferences is required"})
+		return
+	}
+
+	channel := preferenceChannel(ctx)
+	preferences, err := c.service.UpdatePreferences(userID, channel, req.Preferences)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidNotificationChannel:
+			respondValidationError(ctx, []string{"channel must be one of: in_app, email, push"})
+			return
+		case services.ErrInvalidNotificationType:
+			respondValidationError(ctx, []string{"preferences keys must be types delivered on the " + string(channel) + " channel"})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao atualizar preferências de notificação",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success":     true,
+		"channel":     channel,
+		"preferences": preferences,
+	})
+}
+
+// unsubscribePage is shown to people who open an unsubscribe link. Opening the link only
+// asks for confirmation: link scanners and prefetchers follow GET links, so the change is
+// left to the form's POST.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><meta name="robots" content="noindex"><title>list2gether</title></head>
+<body style="font-family:sans-serif;max-width:480px;margin:48px auto;padding:0 16px">
+{{if .Done}}<p>Inscrição cancelada. Você não receberá mais estes emails.</p>
+{{else if .Invalid}}<p>Link de cancelamento inválido.</p>
+{{else}}<p>Deseja parar de receber estes emails?</p>
+<form method="post" action="?token={{.Token}}"><button type="submit">Cancelar inscrição</button></form>
+{{end}}</body>
+</html>
+`))
+
+type unsubscribePageData struct {
+	Token   string
+	Done    bool
+	Invalid bool
+}
+
+func renderUnsubscribePage(ctx *gin.Context, status int, data unsubscribePageData) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Content-Type", "text/html; charset=utf-8")
+	ctx.Status(status)
+	if err := unsubscribePage.Execute(ctx.Writer, data); err != nil {
+		log.Printf("unsubscribe_page render failed: %v", err)
+	}
+}
+
+// confirmUnsubscribe shows the page an unsubscribe link opens, with a button that turns
+// the email off
+func (c *NotificationController) confirmUnsubscribe(ctx *gin.Context) {
+	token := ctx.Query("token")
+	if _, err := c.service.CheckUnsubscribe(token); token == "" || err != nil {
+		renderUnsubscribePage(ctx, http.StatusBadRequest, unsubscribePageData{Invalid: true})
+		return
+	}
+	renderUnsubscribePage(ctx, http.StatusOK, unsubscribePageData{Token: token})
+}
+
+// unsubscribe turns off the email an unsubscribe link was sent with. It serves the form
+// on the confirmation page and mail clients' one-click unsubscribe, which both send the
+// token in the query string; browsers get a page back and everything else JSON.
+func (c *NotificationController) unsubscribe(ctx *gin.Context) {
+	html := ctx.NegotiateFormat(gin.MIMEJSON, gin.MIMEHTML) == gin.MIMEHTML
+	token := ctx.Query("token")
+	if token == "" {
+		if html {
+			renderUnsubscribePage(ctx, http.StatusBadRequest, unsubscribePageData{Invalid: true})
+			return
+		}
+		respondValidationError(ctx, []string{"token is required"})
+		return
+	}
+
+	notificationType, err := c.service.Unsubscribe(token)
+	if err != nil {
+		switch {
+		case html && err == services.ErrInvalidUnsubscribeToken:
+			renderUnsubscribePage(ctx, http.StatusBadRequest, unsubscribePageData{Invalid: true})
+			return
+		case err == services.ErrInvalidUnsubscribeToken:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":     "Link de cancelamento inválido",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao cancelar inscrição",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	if html {
+		renderUnsubscribePage(ctx, http.StatusOK, unsubscribePageData{Done: true})
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Inscrição cancelada",
+		"data":    gin.H{"type": notificationType},
+	})
+}
@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/8bury/list2gether/middleware"
+	"github.com/8bury/list2gether/models"
+	"github.com/8bury/list2gether/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PushController struct {
+	service        services.PushService
+	mock           *services.MockPushService
+	authMiddleware *middleware.AuthMiddleware
+}
+
+// NewPushController registers the push subscription routes. When mock is not nil it
+// also serves a mock push service under /api/push/mock for development and tests.
+func NewPushController(router *gin.Engine, service services.PushService, mock *services.MockPushService, authMiddleware *middleware.AuthMiddleware) *PushController {
+	c := &PushController{service: service, mock: mock, authMiddleware: authMiddleware}
+	group := router.Group("/api/push")
+	group.GET("/vapid-public-key", c.publicKey)
+	group.GET("/subscriptions", c.authMiddleware.Handler(), c.listSubscriptions)
+	group.POST("/subscriptions", c.authMiddleware.Handler(), c.subscribe)
+	group.DELETE("/subscriptions/:id", c.authMiddleware.Handler(), c.unsubscribe)
+	group.POST("/test", c.authMiddleware.Handler(), c.sendTest)
+
+	if mock != nil {
+		group.POST("/mock/devices", c.mockCreateDevice)
+		group.GET("/mock/devices/:id/messages", c.mockMessages)
+		group.POST("/mock/devices/:id/expire", c.mockExpire)
+		group.POST("/mock/endpoints/:id", gin.WrapH(mock))
+	}
+	return c
+}
+
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+	DeviceName string `json:"device_name"`
+}
+
+func respondPushNotConfigured(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusServiceUnavailable, gin.H{
+		"error":     "Notificações push não estão configuradas",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+func (c *PushController) publicKey(ctx *gin.Context) {
+	key, err := c.service.PublicKey()
+	if err != nil {
+		respondPushNotConfigured(ctx)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"public_key": key})
+}
+
+func (c *PushController) listSubscriptions(ctx *gin.Context) {
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	subscriptions, err := c.service.ListSubscriptions(userID)
+	if err != nil {
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Falha ao buscar dispositivos",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	items := make([]gin.H, 0, len(subscriptions))
+	for _, s := range subscriptions {
+		items = append(items, pushSubscriptionPayload(s))
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"subscriptions": items})
+}
+
+// pushSubscriptionPayload leaves out the endpoint and keys; they only matter to the device
+func pushSubscriptionPayload(s models.PushSubscription) gin.H {
+	return gin.H{
+		"id":           s.ID,
+		"device_name":  s.DeviceName,
+		"user_agent":   s.UserAgent,
+		"last_used_at": s.LastUsedAt,
+		"created_at":   s.CreatedAt,
+	}
+}
+
+func (c *PushController) subscribe(ctx *gin.Context) {
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	var req pushSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
+	subscription, err := c.service.Subscribe(userID, services.PushSubscriptionInput{
+		Endpoint:   req.Endpoint,
+		P256dh:     req.Keys.P256dh,
+		Auth:       req.Keys.Auth,
+		DeviceName: req.DeviceName,
+		UserAgent:  ctx.GetHeader("User-Agent"),
+	})
+	if err != nil {
+		switch err {
+		case services.ErrPushNotConfigured:
+			respondPushNotConfigured(ctx)
+			return
+		case services.ErrInvalidPushSubscription:
+			respondValidationError(ctx, []string{"endpoint must be an https URL and keys must hold the browser's p256dh and auth values"})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao registrar dispositivo",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusCreated, gin.H{
+		"success":      true,
+		"subscription": pushSubscriptionPayload(*subscription),
+	})
+}
+
+func (c *PushController) unsubscribe(ctx *gin.Context) {
+	subscriptionID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || subscriptionID <= 0 {
+		respondValidationError(ctx, []string{"Invalid subscription id"})
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	if err := c.service.Unsubscribe(userID, subscriptionID); err != nil {
+		switch err {
+		case services.ErrPushSubscriptionNotFound:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error":     "Dispositivo não encontrado",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		default:
+			ctx.Header("Cache-Control", "no-store")
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":     "Falha ao remover dispositivo",
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Dispositivo removido",
+	})
+}
+
+func (c *PushController) sendTest(ctx *gin.Context) {
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	delivered, err := c.service.SendTest(userID)
+	if err != nil {
+		if err == services.ErrPushNotConfigured {
+			respondPushNotConfigured(ctx)
+			return
+		}
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     "Falha ao enviar notificação de teste",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    gin.H{"delivered": delivered},
+	})
+}
+
+// mockCreateDevice returns a subscription pointing at the mock push service, as a
+// browser's pushManager.subscribe would
+func (c *PushController) mockCreateDevice(ctx *gin.Context) {
+	target, err := c.mock.NewSubscription()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, gin.H{
+		"endpoint": target.Endpoint,
+		"keys":     gin.H{"p256dh": target.P256dh, "auth": target.Auth},
+	})
+}
+
+func (c *PushController) mockMessages(ctx *gin.Context) {
+	messages, ok := c.mock.Messages(ctx.Param("id"))
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+		return
+	}
+	items := make([]string, 0, len(messages))
+	for _, m := range messages {
+		items = append(items, string(m))
+	}
+	ctx.JSON(http.StatusOK, gin.H{"messages": items})
+}
+
+func (c *PushController) mockExpire(ctx *gin.Context) {
+	if !c.mock.Expire(ctx.Param("id")) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "device not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,293 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/8bury/list2gether/middleware"
+	"github.com/8bury/list2gether/models"
+	"github.com/8bury/list2gether/services"
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	service        services.WebhookService
+	authMiddleware *middleware.AuthMiddleware
+}
+
+// NewWebhookController registers the routes list owners use to manage the list's
+// webhooks and inspect their delivery log
+func NewWebhookController(router *gin.Engine, service services.WebhookService, authMiddleware *middleware.AuthMiddleware) *WebhookController {
+	c := &WebhookController{service: service, authMiddleware: authMiddleware}
+	group := router.Group("/api/lists/:id/webhooks", c.authMiddleware.Handler())
+	group.GET("", c.list)
+	group.POST("", c.create)
+	group.PATCH("/:webhookId", c.update)
+	group.DELETE("/:webhookId", c.delete)
+	group.GET("/:webhookId/deliveries", c.listDeliveries)
+	group.POST("/:webhookId/deliveries/:deliveryId/redeliver", c.redeliver)
+	return c
+}
+
+type webhookRequest struct {
+	URL          string   `json:"url"`
+	Events       []string `json:"events"`
+	Active       *bool    `json:"active"`
+	RotateSecret bool     `json:"rotate_secret"`
+}
+
+func (r webhookRequest) input() services.WebhookInput {
+	input := services.WebhookInput{URL: r.URL, Active: r.Active, RotateSecret: r.RotateSecret}
+	if r.Events != nil {
+		input.Events = make([]models.WebhookEventType, 0, len(r.Events))
+		for _, e := range r.Events {
+			input.Events = append(input.Events, models.WebhookEventType(e))
+		}
+	}
+	return input
+}
+
+// webhookPayload leaves out the secret, which is only shown when it is created or rotated
+func webhookPayload(w models.ListWebhook) gin.H {
+	return gin.H{
+		"id":         w.ID,
+		"list_id":    w.ListID,
+		"url":        w.URL,
+		"events":     w.EventTypes(),
+		"active":     w.Active,
+		"created_by": w.CreatedBy,
+		"created_at": w.CreatedAt,
+		"updated_at": w.UpdatedAt,
+	}
+}
+
+// webhookIDs parses the list id and, when present, the webhook and delivery ids
+func webhookIDs(ctx *gin.Context) (listID, webhookID, deliveryID int64, ok bool) {
+	listID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil || listID <= 0 {
+		respondValidationError(ctx, []string{"Invalid list id"})
+		return 0, 0, 0, false
+	}
+	if v := ctx.Param("webhookId"); v != "" {
+		webhookID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || webhookID <= 0 {
+			respondValidationError(ctx, []string{"Invalid webhook id"})
+			return 0, 0, 0, false
+		}
+	}
+	if v := ctx.Param("deliveryId"); v != "" {
+		deliveryID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || deliveryID <= 0 {
+			respondValidationError(ctx, []string{"Invalid delivery id"})
+			return 0, 0, 0, false
+		}
+	}
+	return listID, webhookID, deliveryID, true
+}
+
+func respondWebhookError(ctx *gin.Context, err error, fallback string) {
+	ctx.Header("Cache-Control", "no-store")
+	switch err {
+	case services.ErrInvalidWebhookURL:
+		respondValidationError(ctx, []string{"url must be a public http or https URL"})
+	case services.ErrInvalidWebhookEvents:
+		respondValidationError(ctx, []string{"events must list at least one of: movie_added, status_changed, comment_created"})
+	case services.ErrListNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Lista não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrForbiddenMembership, services.ErrWebhookOwnerOnly:
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error":     "Apenas o dono da lista pode gerenciar webhooks",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrWebhookNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Webhook não encontrado",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrWebhookDeliveryNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error":     "Entrega não encontrada",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	case services.ErrTooManyWebhooks:
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":     "A lista já tem o número máximo de webhooks",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":     fallback,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+}
+
+func (c *WebhookController) list(ctx *gin.Context) {
+	listID, _, _, ok := webhookIDs(ctx)
+	if !ok {
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	webhooks, err := c.service.ListWebhooks(listID, userID)
+	if err != nil {
+		respondWebhookError(ctx, err, "Falha ao buscar webhooks")
+		return
+	}
+	items := make([]gin.H, 0, len(webhooks))
+	for _, w := range webhooks {
+		items = append(items, webhookPayload(w))
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"webhooks": items, "event_types": models.WebhookEventTypes})
+}
+
+func (c *WebhookController) create(ctx *gin.Context) {
+	listID, _, _, ok := webhookIDs(ctx)
+	if !ok {
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+	var req webhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
+	webhook, secret, err := c.service.CreateWebhook(listID, userID, req.input())
+	if err != nil {
+		respondWebhookError(ctx, err, "Falha ao criar webhook")
+		return
+	}
+	payload := webhookPayload(*webhook)
+	payload["secret"] = secret
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusCreated, gin.H{"success": true, "webhook": payload})
+}
+
+func (c *WebhookController) update(ctx *gin.Context) {
+	listID, webhookID, _, ok := webhookIDs(ctx)
+	if !ok {
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+	var req webhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondValidationError(ctx, []string{"Invalid request body"})
+		return
+	}
+
+	webhook, secret, err := c.service.UpdateWebhook(listID, userID, webhookID, req.input())
+	if err != nil {
+		respondWebhookError(ctx, err, "Falha ao atualizar webhook")
+		return
+	}
+	payload := webhookPayload(*webhook)
+	if secret != "" {
+		payload["secret"] = secret
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "webhook": payload})
+}
+
+func (c *WebhookController) delete(ctx *gin.Context) {
+	listID, webhookID, _, ok := webhookIDs(ctx)
+	if !ok {
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	if err := c.service.DeleteWebhook(listID, userID, webhookID); err != nil {
+		respondWebhookError(ctx, err, "Falha ao remover webhook")
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{"success": true, "message": "Webhook removido"})
+}
+
+func (c *WebhookController) listDeliveries(ctx *gin.Context) {
+	listID, webhookID, _, ok := webhookIDs(ctx)
+	if !ok {
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	limit := 50
+	offset := 0
+	if v := ctx.Query("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			if n < 1 {
+				n = 1
+			}
+			if n > 100 {
+				n = 100
+			}
+			limit = n
+		}
+	}
+	if v := ctx.Query("offset"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			offset = n
+		}
+	}
+
+	deliveries, total, err := c.service.ListDeliveries(listID, userID, webhookID, limit, offset)
+	if err != nil {
+		respondWebhookError(ctx, err, "Falha ao buscar entregas")
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"pagination": gin.H{
+			"total":    total,
+			"limit":    limit,
+			"offset":   offset,
+			"has_more": offset+len(deliveries) < int(total),
+		},
+	})
+}
+
+func (c *WebhookController) redeliver(ctx *gin.Context) {
+	listID, webhookID, deliveryID, ok := webhookIDs(ctx)
+	if !ok {
+		return
+	}
+	userID, ok := authUserID(ctx)
+	if !ok {
+		respondTokenInvalid(ctx)
+		return
+	}
+
+	delivery, err := c.service.Redeliver(listID, userID, webhookID, deliveryID)
+	if err != nil {
+		respondWebhookError(ctx, err, "Falha ao reenviar entrega")
+		return
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(http.StatusAccepted, gin.H{"success": true, "delivery": delivery})
+}
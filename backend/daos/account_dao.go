@@ -0,0 +1,335 @@
+package daos
+
+import (
+	"errors"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+// AccountDAO reads everything stored about a user across lists, for the account data
+// export, and erases it when the account is deleted
+type AccountDAO interface {
+	FindMemberships(userID int64) ([]AccountMembershipRow, error)
+	FindListMovieData(userID int64) ([]AccountListMovieRow, error)
+	FindWatches(userID int64) ([]AccountListMovieRow, error)
+	FindComments(userID int64) ([]AccountCommentRow, error)
+	FindReactions(userID int64) ([]models.Reaction, error)
+	FindPushSubscriptions(userID int64) ([]models.PushSubscription, error)
+	FindNotifications(userID int64) ([]models.Notification, error)
+	FindRecommendationFeedback(userID int64) ([]models.RecommendationFeedback, error)
+	FindImports(userID int64) ([]models.ListImport, error)
+	FindWebhooks(userID int64) ([]models.ListWebhook, error)
+	DeleteAccount(userID int64, plan AccountDeletionPlan) ([]OwnedListOutcome, error)
+}
+
+// AccountMembershipRow is a list the user belongs to, including lists since deleted
+type AccountMembershipRow struct {
+	ListID        int64
+	ListName      string
+	Role          models.ListMemberRole
+	AddedAt       time.Time
+	ListDeletedAt *time.Time
+}
+
+// AccountListMovieRow is the user's rating, notes or watch of a title in a list
+type AccountListMovieRow struct {
+	ListID    int64
+	ListName  string
+	MovieID   int64
+	Title     string
+	MediaType string
+	Rating    *int
+	Notes     *string
+	WatchedAt *time.Time
+	UpdatedAt *time.Time
+}
+
+// AccountCommentRow is a comment the user wrote, with the names of where it was posted
+type AccountCommentRow struct {
+	ID         int64
+	ListID     int64
+	ListName   string
+	MovieID    *int64
+	MovieTitle *string
+	ParentID   *int64
+	Content    string
+	Spoiler    bool
+	CreatedAt  time.Time
+	EditedAt   *time.Time
+	DeletedAt  *time.Time
+}
+
+// AccountDeletionPlan says what happens to the user's lists and comments and what the
+// user row is overwritten with. The row is kept so the comments, activity and
+// moderation records that point at it stay valid.
+type AccountDeletionPlan struct {
+	// TransferLists hands each owned list to its longest-standing member. Lists without
+	// another member, and every owned list when it is false, are deleted.
+	TransferLists bool
+	// DeleteComments removes the text of the user's comments instead of leaving them
+	// under the anonymized user
+	DeleteComments bool
+	Username       string
+	Email          string
+}
+
+// OwnedListOutcome is what happened to a list the user owned. NewOwnerID is nil when
+// the list was deleted.
+type OwnedListOutcome struct {
+	ListID     int64
+	NewOwnerID *int64
+}
+
+type accountDAO struct {
+	db *gorm.DB
+}
+
+func NewAccountDAO(db *gorm.DB) AccountDAO {
+	return &accountDAO{db: db}
+}
+
+func (d *accountDAO) FindMemberships(userID int64) ([]AccountMembershipRow, error) {
+	var rows []AccountMembershipRow
+	if err := d.db.Table("list_members AS lm").
+		Select("lm.list_id, ml.name AS list_name, lm.role, lm.added_at, ml.deleted_at AS list_deleted_at").
+		Joins("JOIN movie_lists AS ml ON ml.id = lm.list_id").
+		Where("lm.user_id = ?", userID).
+		Order("lm.added_at ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (d *accountDAO) FindListMovieData(userID int64) ([]AccountListMovieRow, error) {
+	var rows []AccountListMovieRow
+	if err := d.db.Table("list_movie_user_data AS ud").
+		Select("ud.list_id, ml.name AS list_name, ud.movie_id, m.title, m.media_type, ud.rating, ud.notes, ud.updated_at").
+		Joins("JOIN movie_lists AS ml ON ml.id = ud.list_id").
+		Joins("JOIN movies AS m ON m.id = ud.movie_id").
+		Where("ud.user_id = ?", userID).
+		Order("ud.list_id ASC, ud.updated_at ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (d *accountDAO) FindWatches(userID int64) ([]AccountListMovieRow, error) {
+	var rows []AccountListMovieRow
+	if err := d.db.Table("list_movie_watches AS w").
+		Select("w.list_id, ml.name AS list_name, w.movie_id, m.title, m.media_type, w.watched_at").
+		Joins("JOIN movie_lists AS ml ON ml.id = w.list_id").
+		Joins("JOIN movies AS m ON m.id = w.movie_id").
+		Where("w.user_id = ?", userID).
+		Order("w.watched_at ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (d *accountDAO) FindComments(userID int64) ([]AccountCommentRow, error) {
+	var rows []AccountCommentRow
+	if err := d.db.Table("comments AS c").
+		Select("c.id, c.list_id, ml.name AS list_name, c.movie_id, m.title AS movie_title, c.parent_id, c.content, c.is_spoiler AS spoiler, c.created_at, c.edited_at, c.deleted_at").
+		Joins("JOIN movie_lists AS ml ON ml.id = c.list_id").
+		Joins("LEFT JOIN movies AS m ON m.id = c.movie_id").
+		Where("c.user_id = ?", userID).
+		Order("c.created_at ASC, c.id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (d *accountDAO) FindReactions(userID int64) ([]models.Reaction, error) {
+	var reactions []models.Reaction
+	if err := d.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&reactions).Error; err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+func (d *accountDAO) FindPushSubscriptions(userID int64) ([]models.PushSubscription, error) {
+	var subscriptions []models.PushSubscription
+	if err := d.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (d *accountDAO) FindNotifications(userID int64) ([]models.Notification, error) {
+	var notifications []models.Notification
+	if err := d.db.Where("user_id = ?", userID).Order("created_at ASC, id ASC").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (d *accountDAO) FindRecommendationFeedback(userID int64) ([]models.RecommendationFeedback, error) {
+	var feedback []models.RecommendationFeedback
+	if err := d.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&feedback).Error; err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+func (d *accountDAO) FindImports(userID int64) ([]models.ListImport, error) {
+	var imports []models.ListImport
+	if err := d.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&imports).Error; err != nil {
+		return nil, err
+	}
+	return imports, nil
+}
+
+// FindWebhooks returns the webhooks the user registered, on lists they may have left since
+func (d *accountDAO) FindWebhooks(userID int64) ([]models.ListWebhook, error) {
+	var webhooks []models.ListWebhook
+	if err := d.db.Where("created_by = ?", userID).Order("created_at ASC").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteAccount settles the user's owned lists, leaves every list, turns off the
+// user's webhooks, erases the user's personal data and anonymizes the user row, all in
+// one transaction
+func (d *accountDAO) DeleteAccount(userID int64, plan AccountDeletionPlan) ([]OwnedListOutcome, error) {
+	var outcomes []OwnedListOutcome
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+
+		var memberships []models.ListMember
+		if err := tx.Model(&models.ListMember{}).
+			Joins("JOIN movie_lists ON movie_lists.id = list_members.list_id AND movie_lists.deleted_at IS NULL").
+			Where("list_members.user_id = ?", userID).
+			Find(&memberships).Error; err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			after := listEventValue(nil)
+			if m.Role == models.RoleOwner {
+				outcome, err := settleOwnedListTx(tx, m.ListID, userID, plan.TransferLists)
+				if err != nil {
+					return err
+				}
+				outcomes = append(outcomes, outcome)
+				if outcome.NewOwnerID == nil {
+					continue
+				}
+				after = listEventValue{"new_owner_id": *outcome.NewOwnerID}
+			}
+			if err := recordListEventTx(tx, m.ListID, &userID, models.EventMemberLeft, models.EventTargetMember, userID,
+				listEventValue{"role": m.Role, "account_deleted": true}, after); err != nil {
+				return err
+			}
+		}
+
+		// Webhooks on lists handed to another member stop posting; the new owner can turn
+		// them back on
+		if err := tx.Model(&models.ListWebhook{}).
+			Where("created_by = ?", userID).
+			Update("active", false).Error; err != nil {
+			return err
+		}
+
+		if plan.DeleteComments {
+			// Threads keep their shape, as when a comment is deleted by hand, but the text
+			// and its earlier versions are gone. They count as deleted by the author so
+			// moderators cannot restore them.
+			if err := tx.Where("comment_id IN (?)", tx.Model(&models.Comment{}).Select("id").Where("user_id = ?", userID)).
+				Delete(&models.CommentRevision{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("comment_id IN (?)", tx.Model(&models.Comment{}).Select("id").Where("user_id = ?", userID)).
+				Delete(&models.CommentMention{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Comment{}).
+				Where("user_id = ?", userID).
+				Updates(map[string]interface{}{
+					"content":        models.DeletedCommentPlaceholder,
+					"deleted_at":     gorm.Expr("COALESCE(deleted_at, ?)", now),
+					"deleted_by":     gorm.Expr("COALESCE(deleted_by, ?)", userID),
+					"deleted_reason": models.CommentDeletedByAuthor,
+				}).Error; err != nil {
+				return err
+			}
+		}
+
+		personal := []interface{}{
+			&models.ListMember{},
+			&models.ListMovieUserData{},
+			&models.ListMovieWatch{},
+			&models.ListReadMarker{},
+			&models.Reaction{},
+			&models.CommentMention{},
+			&models.RecommendationFeedback{},
+			&models.UserGenrePreference{},
+			&models.Notification{},
+			&models.NotificationPreference{},
+			&models.PushSubscription{},
+			&models.ListImport{},
+			&models.RefreshToken{},
+		}
+		for _, model := range personal {
+			if err := tx.Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Notification{}).
+			Where("actor_id = ?", userID).
+			Update("actor_id", nil).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"username":   plan.Username,
+				"email":      plan.Email,
+				"password":   "",
+				"avatar_url": nil,
+				"deleted_at": now,
+			}).Error
+	})
+	return outcomes, err
+}
+
+// settleOwnedListTx hands the list to its longest-standing other member, or deletes it
+// when transfer is off or nobody else is in it
+func settleOwnedListTx(tx *gorm.DB, listID, ownerID int64, transfer bool) (OwnedListOutcome, error) {
+	outcome := OwnedListOutcome{ListID: listID}
+	if transfer {
+		var successor models.ListMember
+		err := tx.Where("list_id = ? AND user_id <> ?", listID, ownerID).
+			Order("added_at ASC, user_id ASC").
+			First(&successor).Error
+		switch {
+		case err == nil:
+			if err := tx.Model(&models.ListMember{}).
+				Where("list_id = ? AND user_id = ?", listID, successor.UserID).
+				Update("role", models.RoleOwner).Error; err != nil {
+				return outcome, err
+			}
+			if err := tx.Model(&models.MovieList{}).
+				Where("id = ?", listID).
+				Update("created_by", successor.UserID).Error; err != nil {
+				return outcome, err
+			}
+			outcome.NewOwnerID = &successor.UserID
+			return outcome, nil
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return outcome, err
+		}
+	}
+
+	// Soft delete, as when the owner deletes the list
+	if err := recordListEventTx(tx, listID, &ownerID, models.EventListDeleted, models.EventTargetList, listID, nil, listEventValue{"account_deleted": true}); err != nil {
+		return outcome, err
+	}
+	return outcome, tx.Where("id = ?", listID).Delete(&models.MovieList{}).Error
+}
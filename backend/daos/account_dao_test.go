@@ -0,0 +1,165 @@
+package daos
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func deletionPlan(user *models.User, transfer bool) AccountDeletionPlan {
+	return AccountDeletionPlan{
+		TransferLists: transfer,
+		Username:      fmt.Sprintf("deleted-%d", user.ID),
+		Email:         fmt.Sprintf("deleted-%d@invalid", user.ID),
+	}
+}
+
+func joinedAgo(t *testing.T, db *gorm.DB, listID, userID int64, ago time.Duration) {
+	t.Helper()
+	if err := db.Model(&models.ListMember{}).Where("list_id = ? AND user_id = ?", listID, userID).
+		Update("added_at", time.Now().UTC().Add(-ago)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteAccountTransfersListToLongestStandingMember(t *testing.T) {
+	db := openTestDB(t)
+	owner, newer, older := seedUser(t, db), seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, newer, older)
+	joinedAgo(t, db, list.ID, newer.ID, time.Hour)
+	joinedAgo(t, db, list.ID, older.ID, 48*time.Hour)
+
+	outcomes, err := NewAccountDAO(db).DeleteAccount(owner.ID, deletionPlan(owner, true))
+	assert.NoError(t, err)
+	assert.Len(t, outcomes, 1)
+	assert.Equal(t, older.ID, *outcomes[0].NewOwnerID)
+
+	var member models.ListMember
+	assert.NoError(t, db.Where("list_id = ? AND user_id = ?", list.ID, older.ID).First(&member).Error)
+	assert.Equal(t, models.RoleOwner, member.Role)
+	var reloaded models.MovieList
+	assert.NoError(t, db.First(&reloaded, list.ID).Error)
+	assert.Equal(t, older.ID, reloaded.CreatedBy)
+	var owners int64
+	assert.NoError(t, db.Model(&models.ListMember{}).Where("list_id = ? AND user_id = ?", list.ID, owner.ID).Count(&owners).Error)
+	assert.Zero(t, owners)
+}
+
+func TestDeleteAccountTurnsOffWebhooks(t *testing.T) {
+	db := openTestDB(t)
+	owner, member := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, member)
+	webhook := &models.ListWebhook{ListID: list.ID, URL: "https://hooks.example/x", Secret: "s", Events: "movie_added", Active: true, CreatedBy: owner.ID}
+	if err := db.Create(webhook).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewAccountDAO(db).DeleteAccount(owner.ID, deletionPlan(owner, true))
+	assert.NoError(t, err)
+
+	var reloaded models.ListWebhook
+	assert.NoError(t, db.First(&reloaded, webhook.ID).Error)
+	assert.False(t, reloaded.Active)
+}
+
+func TestDeleteAccountDeletesListWithoutOtherMembers(t *testing.T) {
+	db := openTestDB(t)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+
+	outcomes, err := NewAccountDAO(db).DeleteAccount(owner.ID, deletionPlan(owner, true))
+	assert.NoError(t, err)
+	assert.Len(t, outcomes, 1)
+	assert.Nil(t, outcomes[0].NewOwnerID)
+
+	var remaining int64
+	assert.NoError(t, db.Model(&models.MovieList{}).Where("id = ?", list.ID).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+	var deleted int64
+	assert.NoError(t, db.Model(&models.ListEvent{}).Where("list_id = ? AND verb = ?", list.ID, models.EventListDeleted).Count(&deleted).Error)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestDeleteAccountDeletesOwnedListsWhenNotTransferring(t *testing.T) {
+	db := openTestDB(t)
+	owner, member := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, member)
+
+	outcomes, err := NewAccountDAO(db).DeleteAccount(owner.ID, deletionPlan(owner, false))
+	assert.NoError(t, err)
+	assert.Len(t, outcomes, 1)
+	assert.Nil(t, outcomes[0].NewOwnerID)
+	var remaining int64
+	assert.NoError(t, db.Model(&models.MovieList{}).Where("id = ?", list.ID).Count(&remaining).Error)
+	assert.Zero(t, remaining)
+}
+
+func TestDeleteAccountEmptiesPersonalTables(t *testing.T) {
+	db := openTestDB(t)
+	owner, user := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, user)
+	listed := seedListMovie(t, db, list, owner)
+	rating := 7
+	now := time.Now().UTC()
+
+	comment := &models.Comment{ListID: list.ID, MovieID: &listed.MovieID, UserID: owner.ID, Content: "@alguem viu?"}
+	seed := []interface{}{
+		comment,
+		&models.ListMovieUserData{ListID: list.ID, MovieID: listed.MovieID, UserID: user.ID, Rating: &rating},
+		&models.ListMovieWatch{ListID: list.ID, MovieID: listed.MovieID, UserID: user.ID, WatchedAt: now},
+		&models.ListReadMarker{ListID: list.ID, UserID: user.ID, MovieID: 0, ReadAt: now},
+		&models.Reaction{ListID: list.ID, TargetType: models.ReactionTargetListMovie, TargetID: listed.ID, UserID: user.ID, Kind: models.ReactionHeart},
+		&models.RecommendationFeedback{ListID: list.ID, UserID: user.ID, MovieID: 348, MediaType: "movie", Action: models.FeedbackDismissed},
+		&models.UserGenrePreference{UserID: user.ID, GenreID: 18},
+		&models.Notification{UserID: user.ID, Type: models.NotificationTitleAdded, ListID: &list.ID, Payload: "{}"},
+		&models.NotificationPreference{UserID: user.ID, Channel: models.NotificationChannelEmail, Type: models.NotificationTitleAdded},
+		&models.PushSubscription{UserID: user.ID, Endpoint: "https://push.example/x", EndpointHash: fmt.Sprintf("%064d", user.ID), P256dh: "k", Auth: "a"},
+		&models.ListImport{ListID: list.ID, UserID: user.ID, Format: models.ImportLetterboxd, Status: models.ImportCompleted},
+		&models.RefreshToken{UserID: user.ID, TokenHash: "hash", ExpiresAt: now.Add(time.Hour)},
+	}
+	for _, row := range seed {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Create(&models.CommentMention{CommentID: comment.ID, Offset: 0, Length: 7, UserID: user.ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewAccountDAO(db).DeleteAccount(user.ID, deletionPlan(user, true))
+	assert.NoError(t, err)
+
+	for _, model := range []interface{}{
+		&models.ListMember{},
+		&models.ListMovieUserData{},
+		&models.ListMovieWatch{},
+		&models.ListReadMarker{},
+		&models.Reaction{},
+		&models.CommentMention{},
+		&models.RecommendationFeedback{},
+		&models.UserGenrePreference{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.PushSubscription{},
+		&models.ListImport{},
+		&models.RefreshToken{},
+	} {
+		var count int64
+		assert.NoError(t, db.Model(model).Where("user_id = ?", user.ID).Count(&count).Error)
+		assert.Zero(t, count, "%T", model)
+	}
+
+	var anonymized models.User
+	assert.NoError(t, db.First(&anonymized, user.ID).Error)
+	assert.NotNil(t, anonymized.DeletedAt)
+	assert.Equal(t, fmt.Sprintf("deleted-%d", user.ID), anonymized.Username)
+	assert.Empty(t, anonymized.Password)
+	// The other member's data is untouched
+	var ownerMemberships int64
+	assert.NoError(t, db.Model(&models.ListMember{}).Where("user_id = ?", owner.ID).Count(&ownerMemberships).Error)
+	assert.Equal(t, int64(1), ownerMemberships)
+}
@@ -0,0 +1,82 @@
+package daos
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// These tests need a MySQL database. Point TEST_DB_DSN at a scratch schema to run
+// them, e.g. TEST_DB_DSN="root:root@tcp(localhost:3306)/list2gether_test?parseTime=true&loc=UTC";
+// every test runs inside a transaction that is rolled back.
+
+var (
+	testDBOnce sync.Once
+	testDB     *gorm.DB
+	testDBErr  error
+	testSeq    atomic.Int64
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+	testDBOnce.Do(func() {
+		testDB, testDBErr = gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		if testDBErr == nil {
+			testDBErr = testDB.AutoMigrate(models.Tables()...)
+		}
+	})
+	if testDBErr != nil {
+		t.Fatalf("test database: %v", testDBErr)
+	}
+	tx := testDB.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+func seedUser(t *testing.T, db *gorm.DB) *models.User {
+	t.Helper()
+	n := testSeq.Add(1)
+	user := &models.User{Username: fmt.Sprintf("user%d", n), Email: fmt.Sprintf("user%d@example.com", n), Password: "hash"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func seedList(t *testing.T, db *gorm.DB, owner *models.User, members ...*models.User) *models.MovieList {
+	t.Helper()
+	list := &models.MovieList{Name: "Lista", InviteCode: fmt.Sprintf("T%09d", testSeq.Add(1)), CreatedBy: owner.ID}
+	if err := NewMovieListDAO(db).CreateWithOwner(list, owner.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, member := range members {
+		if err := db.Create(&models.ListMember{ListID: list.ID, UserID: member.ID, Role: models.RoleParticipant}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return list
+}
+
+func seedListMovie(t *testing.T, db *gorm.DB, list *models.MovieList, addedBy *models.User) *models.ListMovie {
+	t.Helper()
+	movie := &models.Movie{ID: 900000 + testSeq.Add(1), Title: "Filme", MediaType: "movie"}
+	if err := db.Create(movie).Error; err != nil {
+		t.Fatal(err)
+	}
+	listMovie, err := NewMovieListDAO(db).AddMovieToList(list.ID, movie.ID, &addedBy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return listMovie
+}
@@ -0,0 +1,234 @@
+package daos
+
+import (
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BulkListMovieOperation is one operation applied to many of a list's titles
+type BulkListMovieOperation struct {
+	Operation models.BulkOperation
+	MovieIDs  []int64
+	// Status is what BulkSetStatus sets
+	Status models.MovieStatus
+	// TargetListID is the list BulkCopy and BulkMove add the titles to
+	TargetListID int64
+	// Tag is what BulkTag and BulkUntag put on or take off the titles
+	Tag string
+}
+
+// ApplyBulkOperation applies the operation to every title in one transaction and reports
+// what happened to each, in the order the titles were given. Titles that are not in the
+// list, or already in the target list, are skipped; any other error undoes the batch.
+func (d *movieListDAO) ApplyBulkOperation(listID, userID int64, op BulkListMovieOperation) ([]models.BulkItemResult, error) {
+	var results []models.BulkItemResult
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		results = make([]models.BulkItemResult, 0, len(op.MovieIDs))
+
+		var items []models.ListMovie
+		if err := tx.Where("list_id = ? AND movie_id IN ? AND removed_at IS NULL", listID, op.MovieIDs).
+			Find(&items).Error; err != nil {
+			return err
+		}
+		byMovie := make(map[int64]*models.ListMovie, len(items))
+		for i := range items {
+			byMovie[items[i].MovieID] = &items[i]
+		}
+
+		inTarget := map[int64]bool{}
+		nextOrder := 0
+		if op.Operation.NeedsTarget() {
+			var present []int64
+			if err := tx.Model(&models.ListMovie{}).
+				Where("list_id = ? AND movie_id IN ? AND removed_at IS NULL", op.TargetListID, op.MovieIDs).
+				Pluck("movie_id", &present).Error; err != nil {
+				return err
+			}
+			for _, movieID := range present {
+				inTarget[movieID] = true
+			}
+			// As when adding one title, the titles go to the top of the target list, in
+			// the order they were given; the list's own titles, removed ones too, make room
+			incoming := 0
+			for _, movieID := range op.MovieIDs {
+				if byMovie[movieID] != nil && !inTarget[movieID] {
+					incoming++
+				}
+			}
+			if incoming > 0 {
+				if err := tx.Model(&models.ListMovie{}).
+					Where("list_id = ?", op.TargetListID).
+					Update("display_order", gorm.Expr("COALESCE(display_order, 0) + ?", incoming)).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, movieID := range op.MovieIDs {
+			result := models.BulkItemResult{MovieID: movieID, Outcome: models.BulkItemApplied}
+			listMovie, ok := byMovie[movieID]
+			switch {
+			case !ok:
+				result.Outcome = models.BulkItemNotInList
+			case op.Operation == models.BulkSetStatus:
+				previous := listMovie.Status
+				changed, err := setListMovieStatusTx(tx, listMovie, op.Status, userID)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					result.Outcome = models.BulkItemUnchanged
+					break
+				}
+				result.PreviousStatus = &previous
+				// As when the status is changed on one title, becoming watched marks it as
+				// seen by whoever did it; no other status clears a watch
+				if op.Status == models.StatusWatched {
+					if err := setWatchedTx(tx, listID, movieID, userID, true); err != nil {
+						return err
+					}
+				}
+			case op.Operation == models.BulkRemove:
+				if err := removeListMovieTx(tx, listMovie, userID, nil); err != nil {
+					return err
+				}
+			case op.Operation.NeedsTag():
+				changed, err := setTagTx(tx, listID, movieID, op.Tag, userID, op.Operation == models.BulkTag)
+				if err != nil {
+					return err
+				}
+				if !changed {
+					result.Outcome = models.BulkItemUnchanged
+				}
+			case inTarget[movieID]:
+				result.Outcome = models.BulkItemAlreadyInTarget
+			default:
+				copied, err := copyListMovieTx(tx, listMovie, op.TargetListID, userID, nextOrder, op.Operation)
+				if err != nil {
+					return err
+				}
+				nextOrder++
+				if op.Operation == models.BulkMove {
+					if err := moveListMovieTx(tx, listMovie, copied, userID); err != nil {
+						return err
+					}
+				}
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// copyListMovieTx adds the title to the target list with its status and returns it.
+// Ratings, notes, watches and comments are left with the source list.
+func copyListMovieTx(tx *gorm.DB, source *models.ListMovie, targetListID, userID int64, order int, operation models.BulkOperation) (*models.ListMovie, error) {
+	// A removed copy waiting for the purger is replaced, as when adding the title by hand
+	var removed int64
+	if err := tx.Model(&models.ListMovie{}).
+		Where("list_id = ? AND movie_id = ? AND removed_at IS NOT NULL", targetListID, source.MovieID).
+		Count(&removed).Error; err != nil {
+		return nil, err
+	}
+	if removed > 0 {
+		if err := purgeListMovieTx(tx, targetListID, source.MovieID); err != nil {
+			return nil, err
+		}
+	}
+
+	rec := &models.ListMovie{
+		ListID:       targetListID,
+		MovieID:      source.MovieID,
+		Status:       source.Status,
+		AddedBy:      &userID,
+		WatchedAt:    source.WatchedAt,
+		DisplayOrder: &order,
+	}
+	if source.Status != models.StatusNotWatched {
+		now := time.Now().UTC()
+		rec.StatusChangedAt = &now
+		rec.StatusChangedBy = &userID
+	}
+	if err := tx.Create(rec).Error; err != nil {
+		return nil, err
+	}
+	title, err := movieTitleTx(tx, source.MovieID)
+	if err != nil {
+		return nil, err
+	}
+	from := "copied_from"
+	if operation == models.BulkMove {
+		from = "moved_from"
+	}
+	if err := recordListEventTx(tx, targetListID, &userID, models.EventMovieAdded, models.EventTargetMovie, source.MovieID,
+		nil, listEventValue{"title": title, "status": source.Status, from: source.ListID}); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// moveListMovieTx hands the title's ratings, notes, watches, read markers, tags, comments
+// and reactions over to its copy in the target list and deletes it from the source list.
+// The copy replaced any removed one, so the target holds nothing of its own to clash
+// with. Nothing is left behind to restore; moving the title back undoes the move.
+func moveListMovieTx(tx *gorm.DB, source, copied *models.ListMovie, userID int64) error {
+	for _, model := range []interface{}{
+		&models.ListMovieUserData{},
+		&models.ListMovieWatch{},
+		&models.ListReadMarker{},
+		&models.ListMovieTag{},
+		&models.Comment{},
+	} {
+		if err := tx.Model(model).
+			Where("list_id = ? AND movie_id = ?", source.ListID, source.MovieID).
+			Update("list_id", copied.ListID).Error; err != nil {
+			return err
+		}
+	}
+	commentIDs := tx.Model(&models.Comment{}).Select("id").Where("list_id = ? AND movie_id = ?", copied.ListID, source.MovieID)
+	if err := tx.Model(&models.Reaction{}).
+		Where("target_type = ? AND target_id IN (?)", models.ReactionTargetComment, commentIDs).
+		Update("list_id", copied.ListID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Reaction{}).
+		Where("target_type = ? AND target_id = ?", models.ReactionTargetListMovie, source.ID).
+		Updates(map[string]interface{}{"list_id": copied.ListID, "target_id": copied.ID}).Error; err != nil {
+		return err
+	}
+
+	title, err := movieTitleTx(tx, source.MovieID)
+	if err != nil {
+		return err
+	}
+	if err := recordListEventTx(tx, source.ListID, &userID, models.EventMovieRemoved, models.EventTargetMovie, source.MovieID,
+		listEventValue{"title": title, "status": source.Status, "added_by": source.AddedBy}, listEventValue{"moved_to": copied.ListID}); err != nil {
+		return err
+	}
+	return tx.Delete(&models.ListMovie{}, source.ID).Error
+}
+
+// setTagTx puts the tag on the title or takes it off, and records it when it changed
+func setTagTx(tx *gorm.DB, listID, movieID int64, tag string, userID int64, tagged bool) (bool, error) {
+	var res *gorm.DB
+	verb := models.EventTagAdded
+	if tagged {
+		res = tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ListMovieTag{ListID: listID, MovieID: movieID, Tag: tag, CreatedBy: &userID})
+	} else {
+		verb = models.EventTagRemoved
+		res = tx.Where("list_id = ? AND movie_id = ? AND tag = ?", listID, movieID, tag).
+			Delete(&models.ListMovieTag{})
+	}
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	return true, recordListEventTx(tx, listID, &userID, verb, models.EventTargetMovie, movieID, nil, listEventValue{"tag": tag})
+}
@@ -0,0 +1,192 @@
+package daos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func countWatches(t *testing.T, db *gorm.DB, listID, movieID, userID int64) int64 {
+	t.Helper()
+	var count int64
+	if err := db.Model(&models.ListMovieWatch{}).
+		Where("list_id = ? AND movie_id = ? AND user_id = ?", listID, movieID, userID).
+		Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count
+}
+
+func activeInList(t *testing.T, db *gorm.DB, listID, movieID int64) bool {
+	t.Helper()
+	var count int64
+	if err := db.Model(&models.ListMovie{}).
+		Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, movieID).
+		Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	return count > 0
+}
+
+func TestApplyBulkSetStatusMixedOutcomes(t *testing.T) {
+	db := openTestDB(t)
+	owner, member := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, member)
+	unseen := seedListMovie(t, db, list, owner)
+	seen := seedListMovie(t, db, list, owner)
+	dao := NewMovieListDAO(db)
+	watched := models.StatusWatched
+	_, err := dao.UpdateMovie(list.ID, seen.MovieID, &watched, member.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, dao.SetMovieWatched(list.ID, seen.MovieID, member.ID, true))
+
+	results, err := dao.ApplyBulkOperation(list.ID, owner.ID, BulkListMovieOperation{
+		Operation: models.BulkSetStatus,
+		MovieIDs:  []int64{unseen.MovieID, seen.MovieID, 123456789},
+		Status:    models.StatusWatched,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, models.BulkItemApplied, results[0].Outcome)
+	assert.Equal(t, models.StatusNotWatched, *results[0].PreviousStatus)
+	assert.Equal(t, models.BulkItemUnchanged, results[1].Outcome)
+	assert.Equal(t, models.BulkItemNotInList, results[2].Outcome)
+	// Only the title that became watched is marked as seen by whoever changed it
+	assert.Equal(t, int64(1), countWatches(t, db, list.ID, unseen.MovieID, owner.ID))
+	assert.Zero(t, countWatches(t, db, list.ID, seen.MovieID, owner.ID))
+
+	// Moving away from watched keeps everyone's watches
+	_, err = dao.ApplyBulkOperation(list.ID, owner.ID, BulkListMovieOperation{
+		Operation: models.BulkSetStatus,
+		MovieIDs:  []int64{seen.MovieID},
+		Status:    models.StatusWatching,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), countWatches(t, db, list.ID, seen.MovieID, member.ID))
+}
+
+func TestApplyBulkMoveMixedOutcomes(t *testing.T) {
+	db := openTestDB(t)
+	owner := seedUser(t, db)
+	source, target := seedList(t, db, owner), seedList(t, db, owner)
+	moving := seedListMovie(t, db, source, owner)
+	both := seedListMovie(t, db, source, owner)
+	dao := NewMovieListDAO(db)
+	_, err := dao.AddMovieToList(target.ID, both.MovieID, &owner.ID)
+	assert.NoError(t, err)
+	rating := 8
+	comment := &models.Comment{ListID: source.ID, MovieID: &moving.MovieID, UserID: owner.ID, Content: "rever"}
+	for _, row := range []interface{}{
+		&models.ListMovieUserData{ListID: source.ID, MovieID: moving.MovieID, UserID: owner.ID, Rating: &rating},
+		&models.ListMovieWatch{ListID: source.ID, MovieID: moving.MovieID, UserID: owner.ID, WatchedAt: time.Now().UTC()},
+		comment,
+	} {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := dao.ApplyBulkOperation(source.ID, owner.ID, BulkListMovieOperation{
+		Operation:    models.BulkMove,
+		MovieIDs:     []int64{moving.MovieID, both.MovieID, 123456789},
+		TargetListID: target.ID,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, models.BulkItemApplied, results[0].Outcome)
+	assert.Equal(t, models.BulkItemAlreadyInTarget, results[1].Outcome)
+	assert.Equal(t, models.BulkItemNotInList, results[2].Outcome)
+
+	assert.False(t, activeInList(t, db, source.ID, moving.MovieID))
+	assert.True(t, activeInList(t, db, target.ID, moving.MovieID))
+	// The moved title goes to the top of the target list, above the title already there
+	var moved, existing models.ListMovie
+	assert.NoError(t, db.Where("list_id = ? AND movie_id = ?", target.ID, moving.MovieID).First(&moved).Error)
+	assert.NoError(t, db.Where("list_id = ? AND movie_id = ?", target.ID, both.MovieID).First(&existing).Error)
+	assert.Equal(t, 0, *moved.DisplayOrder)
+	assert.Equal(t, 1, *existing.DisplayOrder)
+	// A title already in the target is left where it was
+	assert.True(t, activeInList(t, db, source.ID, both.MovieID))
+
+	// The members' data goes along with the moved title and nothing is left behind
+	var left int64
+	assert.NoError(t, db.Model(&models.ListMovie{}).Where("list_id = ? AND movie_id = ?", source.ID, moving.MovieID).Count(&left).Error)
+	assert.Zero(t, left)
+	var data models.ListMovieUserData
+	assert.NoError(t, db.Where("list_id = ? AND movie_id = ? AND user_id = ?", target.ID, moving.MovieID, owner.ID).First(&data).Error)
+	assert.Equal(t, 8, *data.Rating)
+	assert.Equal(t, int64(1), countWatches(t, db, target.ID, moving.MovieID, owner.ID))
+	var movedComment models.Comment
+	assert.NoError(t, db.First(&movedComment, comment.ID).Error)
+	assert.Equal(t, target.ID, movedComment.ListID)
+}
+
+func TestApplyBulkOperationRollsBackOnError(t *testing.T) {
+	db := openTestDB(t)
+	owner := seedUser(t, db)
+	source := seedList(t, db, owner)
+	first := seedListMovie(t, db, source, owner)
+	second := seedListMovie(t, db, source, owner)
+	dao := NewMovieListDAO(db)
+	var eventsBefore int64
+	assert.NoError(t, db.Model(&models.ListEvent{}).Where("list_id = ?", source.ID).Count(&eventsBefore).Error)
+
+	// The target list does not exist, so adding the titles to it fails
+	_, err := dao.ApplyBulkOperation(source.ID, owner.ID, BulkListMovieOperation{
+		Operation:    models.BulkMove,
+		MovieIDs:     []int64{first.MovieID, second.MovieID},
+		TargetListID: 987654321,
+	})
+	assert.Error(t, err)
+
+	assert.True(t, activeInList(t, db, source.ID, first.MovieID))
+	assert.True(t, activeInList(t, db, source.ID, second.MovieID))
+	var eventsAfter int64
+	assert.NoError(t, db.Model(&models.ListEvent{}).Where("list_id = ?", source.ID).Count(&eventsAfter).Error)
+	assert.Equal(t, eventsBefore, eventsAfter)
+}
+
+func TestApplyBulkTagAndUntag(t *testing.T) {
+	db := openTestDB(t)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+	first := seedListMovie(t, db, list, owner)
+	second := seedListMovie(t, db, list, owner)
+	dao := NewMovieListDAO(db)
+
+	results, err := dao.ApplyBulkOperation(list.ID, owner.ID, BulkListMovieOperation{
+		Operation: models.BulkTag,
+		MovieIDs:  []int64{first.MovieID},
+		Tag:       "halloween",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BulkItemApplied, results[0].Outcome)
+
+	results, err = dao.ApplyBulkOperation(list.ID, owner.ID, BulkListMovieOperation{
+		Operation: models.BulkTag,
+		MovieIDs:  []int64{first.MovieID, second.MovieID},
+		Tag:       "halloween",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BulkItemUnchanged, results[0].Outcome)
+	assert.Equal(t, models.BulkItemApplied, results[1].Outcome)
+
+	item, err := dao.FindListMovieWithMovie(list.ID, first.MovieID)
+	assert.NoError(t, err)
+	assert.Len(t, item.Tags, 1)
+	assert.Equal(t, "halloween", item.Tags[0].Tag)
+
+	results, err = dao.ApplyBulkOperation(list.ID, owner.ID, BulkListMovieOperation{
+		Operation: models.BulkUntag,
+		MovieIDs:  []int64{first.MovieID},
+		Tag:       "halloween",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, models.BulkItemApplied, results[0].Outcome)
+	var left int64
+	assert.NoError(t, db.Model(&models.ListMovieTag{}).Where("list_id = ? AND movie_id = ?", list.ID, first.MovieID).Count(&left).Error)
+	assert.Zero(t, left)
+}
@@ -0,0 +1,121 @@
+package daos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestFindCommentsKeepsDeletedParentWhileRepliesRemain(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner, friend := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, friend)
+	movieID := seedListMovie(t, db, list, owner).MovieID
+
+	parent, err := dao.CreateComment(list.ID, &movieID, owner.ID, nil, "Que final", false, nil)
+	assert.NoError(t, err)
+	reply, err := dao.CreateComment(list.ID, &movieID, friend.ID, &parent.ID, "Concordo", false, nil)
+	assert.NoError(t, err)
+
+	placeholder, err := dao.DeleteComment(parent.ID, owner.ID)
+	assert.NoError(t, err)
+	assert.True(t, placeholder)
+
+	comments, total, err := dao.FindComments(list.ID, movieID, 50, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, parent.ID, comments[0].ID)
+		assert.True(t, comments[0].IsDeleted())
+		assert.Equal(t, int64(1), comments[0].ReplyCount)
+	}
+
+	// Once the last reply goes the placeholder has nothing left to hold up
+	placeholder, err = dao.DeleteComment(reply.ID, friend.ID)
+	assert.NoError(t, err)
+	assert.False(t, placeholder)
+
+	comments, total, err = dao.FindComments(list.ID, movieID, 50, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), total)
+	assert.Empty(t, comments)
+}
+
+func TestFindBoardCommentsDropsDeletedPostWithoutReplies(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+
+	post, err := dao.CreateComment(list.ID, nil, owner.ID, nil, "Sexta tem sessão?", false, nil)
+	assert.NoError(t, err)
+	placeholder, err := dao.DeleteComment(post.ID, owner.ID)
+	assert.NoError(t, err)
+	assert.False(t, placeholder)
+
+	comments, err := dao.FindBoardComments(list.ID, nil, 0, 20)
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+}
+
+func TestPurgeKeepsModerationLogWithoutComment(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner, friend := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, friend)
+	movieID := seedListMovie(t, db, list, owner).MovieID
+
+	comment, err := dao.CreateComment(list.ID, &movieID, friend.ID, nil, "Spoiler sem aviso", false, nil)
+	assert.NoError(t, err)
+	_, err = dao.ModerateComment(&models.CommentModerationLog{ListID: list.ID, CommentID: &comment.ID, ModeratorID: owner.ID, AuthorID: friend.ID, Action: models.ModerationHide})
+	assert.NoError(t, err)
+
+	assert.NoError(t, dao.RemoveMovieFromList(list.ID, movieID, owner.ID))
+	_, err = dao.PurgeRemovedListMovies(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+
+	entries, total, err := dao.FindModerationLog(list.ID, 10, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	if assert.Len(t, entries, 1) {
+		assert.Nil(t, entries[0].CommentID)
+		assert.Equal(t, friend.ID, entries[0].AuthorID)
+		assert.Equal(t, models.ModerationHide, entries[0].Action)
+	}
+	_, err = dao.FindCommentByID(comment.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestRemoveMemberMarksCommentsAsLeft(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner, friend := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, friend)
+	movieID := seedListMovie(t, db, list, owner).MovieID
+
+	kept, err := dao.CreateComment(list.ID, &movieID, friend.ID, nil, "Melhor do ano", false, nil)
+	assert.NoError(t, err)
+	own, err := dao.CreateComment(list.ID, &movieID, friend.ID, nil, "Apaguei", false, nil)
+	assert.NoError(t, err)
+	_, err = dao.DeleteComment(own.ID, friend.ID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, dao.RemoveMember(list.ID, friend.ID))
+
+	left, err := dao.FindCommentByID(kept.ID)
+	assert.NoError(t, err)
+	assert.True(t, left.IsDeleted())
+	assert.False(t, left.IsSelfDeleted())
+	if assert.NotNil(t, left.DeletedReason) {
+		assert.Equal(t, models.CommentDeletedMemberLeft, *left.DeletedReason)
+	}
+
+	// Comments the member had already deleted keep their reason
+	selfDeleted, err := dao.FindCommentByID(own.ID)
+	assert.NoError(t, err)
+	assert.True(t, selfDeleted.IsSelfDeleted())
+}
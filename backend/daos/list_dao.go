@@ -2,6 +2,7 @@ package daos
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,30 +19,61 @@ type MovieListDAO interface {
 	FindMembership(listID, userID int64) (*models.ListMember, error)
 	AddParticipantIfNotExists(listID, userID int64) (bool, error)
 	CountMembers(listID int64) (int64, error)
+	FindMembersWithUser(listID int64) ([]models.ListMember, error)
 	FindByID(id int64) (*models.MovieList, error)
 	DeleteListCascadeIfOwner(listID, userID int64) error
 	FindUserMemberships(userID int64, role *models.ListMemberRole, limit int, offset int) ([]models.ListMember, error)
 	CountUserMemberships(userID int64, role *models.ListMemberRole) (int64, error)
 	CountMembersBatch(listIDs []int64) (map[int64]int64, error)
 	CountMoviesBatch(listIDs []int64) (map[int64]int64, error)
+	CountUnreadBatch(userID int64, listIDs []int64) (map[int64]models.UnreadCounts, error)
+	CountUnreadCommentsByMovie(listID, userID int64) (map[int64]int64, error)
+	MarkRead(listID, userID, movieID int64) error
 	ListMovieExists(listID, movieID int64) (bool, error)
 	AddMovieToList(listID, movieID int64, addedBy *int64) (*models.ListMovie, error)
+	AddSeenMovie(listID, movieID, userID int64, feedback *models.RecommendationFeedback) (*models.ListMovie, bool, *models.MovieStatus, error)
 	FindListMovieByListAndMovie(listID, movieID int64) (*models.ListMovie, error)
-	RemoveMovieFromList(listID, movieID int64) error
-	UpdateMovie(listID, movieID int64, status *models.MovieStatus) (*models.ListMovie, error)
+	RemoveMovieFromList(listID, movieID, removedBy int64) error
+	FindRemovedListMovies(listID int64, since time.Time) ([]models.ListMovie, error)
+	RestoreListMovie(listID, movieID, restoredBy int64) (*models.ListMovie, error)
+	PurgeRemovedListMovies(before time.Time) (int64, error)
+	UpdateMovie(listID, movieID int64, status *models.MovieStatus, changedBy int64) (*models.ListMovie, error)
 	UpsertMovieUserData(listID, movieID, userID int64, rating *int, ratingProvided bool) (*models.ListMovieUserData, error)
 	FindMovieUserData(listID, movieID, userID int64) (*models.ListMovieUserData, error)
+	SetMovieWatched(listID, movieID, userID int64, watched bool) error
+	FindMovieWatches(listID, movieID int64) ([]models.ListMovieWatch, error)
 	GetMovieAverageRating(listID, movieID int64) (*float64, error)
 	FindListMoviesWithMovie(listID int64, status *models.MovieStatus) ([]models.ListMovie, error)
+	FindListMovieWithMovie(listID, movieID int64) (*models.ListMovie, error)
+	FindListMoviesForExport(listID int64, afterOrder *int, afterID int64, limit int) ([]models.ListMovie, error)
+	FindMembersOtherListMovies(userIDs []int64, excludeListID int64, limit int) ([]models.ListMovie, error)
+	FindListsWithMovie(movieID int64) ([]models.MovieList, error)
+	FindListsDueForDigest(coveredBefore time.Time) ([]models.MovieList, error)
+	FindListMoviesAddedSince(listID int64, since time.Time) ([]models.ListMovie, error)
+	FindListMoviesWatchedSince(listID int64, since time.Time) ([]models.ListMovie, error)
+	FindTopRatedListMovies(listID int64, limit int) ([]RatedListMovie, error)
+	MarkDigestSent(listID int64, at time.Time) error
 	SearchListMoviesWithMovie(listID int64, query string, limit int, offset int) ([]models.ListMovie, int64, error)
-	UpdateMovieOrders(listID int64, orderMap map[int64]int) error
+	UpdateMovieOrders(listID int64, orderMap map[int64]int, changedBy int64) error
 	RemoveMember(listID, userID int64) error
 	// Comment methods
-	CreateComment(listID, movieID, userID int64, content string) (*models.Comment, error)
+	CreateComment(listID int64, movieID *int64, userID int64, parentID *int64, content string, spoiler bool, mentions []models.CommentMention) (*models.Comment, error)
 	FindComments(listID, movieID int64, limit, offset int) ([]models.Comment, int64, error)
+	FindBoardComments(listID int64, beforeCreatedAt *time.Time, beforeID int64, limit int) ([]models.Comment, error)
+	FindCommentReplies(parentID int64, limit, offset int) ([]models.Comment, int64, error)
 	FindCommentByID(commentID int64) (*models.Comment, error)
-	UpdateComment(commentID int64, content string) (*models.Comment, error)
-	DeleteComment(commentID int64) error
+	UpdateComment(commentID int64, content string, spoiler *bool, mentions []models.CommentMention) (*models.Comment, error)
+	DeleteComment(commentID, deletedBy int64) (bool, error)
+	FindCommentRevisions(commentID int64) ([]models.CommentRevision, error)
+	ModerateComment(entry *models.CommentModerationLog) (*models.Comment, error)
+	FindModerationLog(listID int64, limit, offset int) ([]models.CommentModerationLog, int64, error)
+	FindListEvents(listID int64, beforeID int64, limit int) ([]models.ListEvent, error)
+	// Import methods
+	StartImport(job *models.ListImport) (bool, error)
+	SaveImportProgress(job *models.ListImport) error
+	FindImport(listID, importID int64) (*models.ListImport, error)
+	ImportListMovie(listID, userID int64, item ImportedListMovie) (bool, error)
+	ApplyBulkOperation(listID, userID int64, op BulkListMovieOperation) ([]models.BulkItemResult, error)
 }
 
 type movieListDAO struct {
@@ -73,7 +105,8 @@ func (d *movieListDAO) CreateWithOwner(list *models.MovieList, ownerUserID int64
 		if err := tx.Create(member).Error; err != nil {
 			return err
 		}
-		return nil
+		return recordListEventTx(tx, list.ID, &ownerUserID, models.EventListCreated, models.EventTargetList, list.ID,
+			nil, listEventValue{"name": list.Name})
 	})
 }
 
@@ -102,15 +135,27 @@ func (d *movieListDAO) FindMembership(listID, userID int64) (*models.ListMember,
 }
 
 func (d *movieListDAO) AddParticipantIfNotExists(listID, userID int64) (bool, error) {
-	m := &models.ListMember{ListID: listID, UserID: userID, Role: models.RoleParticipant}
-	tx := d.db.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "list_id"}, {Name: "user_id"}},
-		DoNothing: true,
-	}).Create(m)
-	if tx.Error != nil {
-		return false, tx.Error
+	inserted := false
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		m := &models.ListMember{ListID: listID, UserID: userID, Role: models.RoleParticipant}
+		res := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "list_id"}, {Name: "user_id"}},
+			DoNothing: true,
+		}).Create(m)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		inserted = true
+		return recordListEventTx(tx, listID, &userID, models.EventMemberJoined, models.EventTargetMember, userID,
+			nil, listEventValue{"role": models.RoleParticipant})
+	})
+	if err != nil {
+		return false, err
 	}
-	return tx.RowsAffected > 0, nil
+	return inserted, nil
 }
 
 func (d *movieListDAO) CountMembers(listID int64) (int64, error) {
@@ -121,6 +166,17 @@ func (d *movieListDAO) CountMembers(listID int64) (int64, error) {
 	return count, nil
 }
 
+func (d *movieListDAO) FindMembersWithUser(listID int64) ([]models.ListMember, error) {
+	var members []models.ListMember
+	if err := d.db.Preload("User").
+		Where("list_id = ?", listID).
+		Order("added_at ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
 func (d *movieListDAO) FindByID(id int64) (*models.MovieList, error) {
 	var list models.MovieList
 	if err := d.db.First(&list, id).Error; err != nil {
@@ -139,12 +195,13 @@ func (d *movieListDAO) DeleteListCascadeIfOwner(listID, userID int64) error {
 			return gorm.ErrInvalidData
 		}
 
-		// Soft delete the list (GORM automatically sets deleted_at)
-		// Related data (members, movies, comments, ratings) are kept intact
-		if err := tx.Where("id = ?", listID).Delete(&models.MovieList{}).Error; err != nil {
+		// The event goes in while the list row is still live
+		if err := recordListEventTx(tx, listID, &userID, models.EventListDeleted, models.EventTargetList, listID, nil, nil); err != nil {
 			return err
 		}
-		return nil
+		// Soft delete the list (GORM automatically sets deleted_at)
+		// Related data (members, movies, comments, ratings) are kept intact
+		return tx.Where("id = ?", listID).Delete(&models.MovieList{}).Error
 	})
 }
 
@@ -218,7 +275,7 @@ func (d *movieListDAO) CountMoviesBatch(listIDs []int64) (map[int64]int64, error
 	var rows []row
 	if err := d.db.Model(&models.ListMovie{}).
 		Select("list_id AS list_id, COUNT(*) AS cnt").
-		Where("list_id IN ?", listIDs).
+		Where("list_id IN ? AND removed_at IS NULL", listIDs).
 		Group("list_id").
 		Scan(&rows).Error; err != nil {
 		return nil, err
@@ -229,10 +286,114 @@ func (d *movieListDAO) CountMoviesBatch(listIDs []int64) (map[int64]int64, error
 	return result, nil
 }
 
+// CountUnreadBatch counts, per list, the comments, added titles and status changes
+// made by other members since the user last read the list. Without a read marker the
+// user's join date is the baseline.
+func (d *movieListDAO) CountUnreadBatch(userID int64, listIDs []int64) (map[int64]models.UnreadCounts, error) {
+	result := make(map[int64]models.UnreadCounts)
+	if len(listIDs) == 0 {
+		return result, nil
+	}
+	type row struct {
+		ListID int64
+		Cnt    int64
+	}
+
+	var comments []row
+	if err := d.db.Table("comments c").
+		Select("c.list_id AS list_id, COUNT(*) AS cnt").
+		Joins("JOIN list_members m ON m.list_id = c.list_id AND m.user_id = ?", userID).
+		Joins("LEFT JOIN list_read_markers lr ON lr.list_id = c.list_id AND lr.user_id = m.user_id AND lr.movie_id = 0").
+		Joins("LEFT JOIN list_read_markers mr ON mr.list_id = c.list_id AND mr.user_id = m.user_id AND mr.movie_id = c.movie_id").
+		Where("c.list_id IN ? AND c.user_id <> ? AND c.deleted_at IS NULL AND c.hidden_at IS NULL", listIDs, userID).
+		Where("c.movie_id IS NULL OR EXISTS (?)", d.db.Table("list_movies lm").Select("1").
+			Where("lm.list_id = c.list_id AND lm.movie_id = c.movie_id AND lm.removed_at IS NULL")).
+		Where("c.created_at > GREATEST(COALESCE(lr.read_at, m.added_at), COALESCE(mr.read_at, m.added_at))").
+		Group("c.list_id").
+		Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	var added []row
+	if err := d.db.Table("list_movies lm").
+		Select("lm.list_id AS list_id, COUNT(*) AS cnt").
+		Joins("JOIN list_members m ON m.list_id = lm.list_id AND m.user_id = ?", userID).
+		Joins("LEFT JOIN list_read_markers lr ON lr.list_id = lm.list_id AND lr.user_id = m.user_id AND lr.movie_id = 0").
+		Where("lm.list_id IN ? AND lm.removed_at IS NULL AND (lm.added_by IS NULL OR lm.added_by <> ?)", listIDs, userID).
+		Where("lm.added_at > COALESCE(lr.read_at, m.added_at)").
+		Group("lm.list_id").
+		Scan(&added).Error; err != nil {
+		return nil, err
+	}
+
+	var statuses []row
+	if err := d.db.Table("list_movies lm").
+		Select("lm.list_id AS list_id, COUNT(*) AS cnt").
+		Joins("JOIN list_members m ON m.list_id = lm.list_id AND m.user_id = ?", userID).
+		Joins("LEFT JOIN list_read_markers lr ON lr.list_id = lm.list_id AND lr.user_id = m.user_id AND lr.movie_id = 0").
+		Where("lm.list_id IN ? AND lm.removed_at IS NULL AND lm.status_changed_by <> ?", listIDs, userID).
+		Where("lm.status_changed_at > COALESCE(lr.read_at, m.added_at)").
+		Group("lm.list_id").
+		Scan(&statuses).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range comments {
+		counts := result[r.ListID]
+		counts.Comments = r.Cnt
+		result[r.ListID] = counts
+	}
+	for _, r := range added {
+		counts := result[r.ListID]
+		counts.AddedTitles = r.Cnt
+		result[r.ListID] = counts
+	}
+	for _, r := range statuses {
+		counts := result[r.ListID]
+		counts.StatusChanges = r.Cnt
+		result[r.ListID] = counts
+	}
+	return result, nil
+}
+
+// CountUnreadCommentsByMovie counts comments by other members that the user has not
+// read yet in each movie thread of the list
+func (d *movieListDAO) CountUnreadCommentsByMovie(listID, userID int64) (map[int64]int64, error) {
+	result := make(map[int64]int64)
+	type row struct {
+		MovieID int64
+		Cnt     int64
+	}
+	var rows []row
+	if err := d.db.Table("comments c").
+		Select("c.movie_id AS movie_id, COUNT(*) AS cnt").
+		Joins("JOIN list_members m ON m.list_id = c.list_id AND m.user_id = ?", userID).
+		Joins("LEFT JOIN list_read_markers lr ON lr.list_id = c.list_id AND lr.user_id = m.user_id AND lr.movie_id = 0").
+		Joins("LEFT JOIN list_read_markers mr ON mr.list_id = c.list_id AND mr.user_id = m.user_id AND mr.movie_id = c.movie_id").
+		Where("c.list_id = ? AND c.movie_id IS NOT NULL AND c.user_id <> ? AND c.deleted_at IS NULL AND c.hidden_at IS NULL", listID, userID).
+		Where("c.created_at > GREATEST(COALESCE(lr.read_at, m.added_at), COALESCE(mr.read_at, m.added_at))").
+		Group("c.movie_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		result[r.MovieID] = r.Cnt
+	}
+	return result, nil
+}
+
+// MarkRead moves the user's read marker for the list (movieID 0) or a movie thread to now
+func (d *movieListDAO) MarkRead(listID, userID, movieID int64) error {
+	marker := &models.ListReadMarker{ListID: listID, UserID: userID, MovieID: movieID, ReadAt: time.Now().UTC()}
+	return d.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"read_at"}),
+	}).Create(marker).Error
+}
+
 func (d *movieListDAO) ListMovieExists(listID, movieID int64) (bool, error) {
 	var count int64
 	if err := d.db.Model(&models.ListMovie{}).
-		Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, movieID).
 		Count(&count).Error; err != nil {
 		return false, err
 	}
@@ -242,77 +403,272 @@ func (d *movieListDAO) ListMovieExists(listID, movieID int64) (bool, error) {
 func (d *movieListDAO) AddMovieToList(listID, movieID int64, addedBy *int64) (*models.ListMovie, error) {
 	var rec *models.ListMovie
 	err := d.db.Transaction(func(tx *gorm.DB) error {
-		// Increment display_order of all existing movies in the list
-		if err := tx.Model(&models.ListMovie{}).
-			Where("list_id = ?", listID).
-			Update("display_order", gorm.Expr("COALESCE(display_order, 0) + 1")).Error; err != nil {
+		var err error
+		rec, err = addListMovieTx(tx, listID, movieID, addedBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// AddSeenMovie stores the member's feedback on a recommendation, adds the title to the
+// list unless it is there and marks it watched by the member, all in one transaction.
+// created says whether the title was added; previous is its status before, if it was
+// already on the list.
+func (d *movieListDAO) AddSeenMovie(listID, movieID, userID int64, feedback *models.RecommendationFeedback) (listMovie *models.ListMovie, created bool, previous *models.MovieStatus, err error) {
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		if err := upsertRecommendationFeedbackTx(tx, feedback); err != nil {
 			return err
 		}
 
-		// Add new movie with display_order = 0 (top of the list)
-		displayOrder := 0
-		rec = &models.ListMovie{
-			ListID:       listID,
-			MovieID:      movieID,
-			AddedBy:      addedBy,
-			DisplayOrder: &displayOrder,
+		var existing models.ListMovie
+		findErr := tx.Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, movieID).First(&existing).Error
+		switch {
+		case errors.Is(findErr, gorm.ErrRecordNotFound):
+			rec, err := addListMovieTx(tx, listID, movieID, &userID)
+			if err != nil {
+				return err
+			}
+			existing = *rec
+			created = true
+		case findErr != nil:
+			return findErr
+		default:
+			status := existing.Status
+			previous = &status
 		}
-		if err := tx.Create(rec).Error; err != nil {
+
+		if _, err := setListMovieStatusTx(tx, &existing, models.StatusWatched, userID); err != nil {
 			return err
 		}
-		return nil
+		if err := setWatchedTx(tx, listID, movieID, userID, true); err != nil {
+			return err
+		}
+		listMovie = &models.ListMovie{}
+		return tx.Where("id = ?", existing.ID).First(listMovie).Error
 	})
+	if err != nil {
+		return nil, false, nil, err
+	}
+	return listMovie, created, previous, nil
+}
+
+// addListMovieTx puts the title at the top of the list and records it
+func addListMovieTx(tx *gorm.DB, listID, movieID int64, addedBy *int64) (*models.ListMovie, error) {
+	// Adding a title again replaces a removed copy still waiting for the purger
+	var removed int64
+	if err := tx.Model(&models.ListMovie{}).
+		Where("list_id = ? AND movie_id = ? AND removed_at IS NOT NULL", listID, movieID).
+		Count(&removed).Error; err != nil {
+		return nil, err
+	}
+	if removed > 0 {
+		if err := purgeListMovieTx(tx, listID, movieID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Increment display_order of all existing movies in the list. Removed titles move
+	// too, so a restore puts them back in the same place relative to the others.
+	if err := tx.Model(&models.ListMovie{}).
+		Where("list_id = ?", listID).
+		Update("display_order", gorm.Expr("COALESCE(display_order, 0) + 1")).Error; err != nil {
+		return nil, err
+	}
+
+	// Add new movie with display_order = 0 (top of the list)
+	displayOrder := 0
+	rec := &models.ListMovie{
+		ListID:       listID,
+		MovieID:      movieID,
+		Status:       models.StatusNotWatched,
+		AddedBy:      addedBy,
+		DisplayOrder: &displayOrder,
+	}
+	if err := tx.Create(rec).Error; err != nil {
+		return nil, err
+	}
+	title, err := movieTitleTx(tx, movieID)
 	if err != nil {
 		return nil, err
 	}
+	if err := recordListEventTx(tx, listID, addedBy, models.EventMovieAdded, models.EventTargetMovie, movieID,
+		nil, listEventValue{"title": title, "status": models.StatusNotWatched}); err != nil {
+		return nil, err
+	}
 	return rec, nil
 }
 
 func (d *movieListDAO) FindListMovieByListAndMovie(listID, movieID int64) (*models.ListMovie, error) {
 	var listMovie models.ListMovie
-	if err := d.db.Where("list_id = ? AND movie_id = ?", listID, movieID).First(&listMovie).Error; err != nil {
+	if err := d.db.Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, movieID).First(&listMovie).Error; err != nil {
 		return nil, err
 	}
 	return &listMovie, nil
 }
 
-func (d *movieListDAO) RemoveMovieFromList(listID, movieID int64) error {
+// RemoveMovieFromList takes the title off the list but keeps it, with its comments,
+// ratings and watches, so it can be restored until PurgeRemovedListMovies deletes it
+func (d *movieListDAO) RemoveMovieFromList(listID, movieID, removedBy int64) error {
 	return d.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
-			Delete(&models.Comment{}).Error; err != nil {
-			return err
-		}
-		if err := tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
-			Delete(&models.ListMovieUserData{}).Error; err != nil {
-			return err
-		}
-		if err := tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
-			Delete(&models.ListMovie{}).Error; err != nil {
+		var listMovie models.ListMovie
+		if err := tx.Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, movieID).First(&listMovie).Error; err != nil {
 			return err
 		}
-		return nil
+		return removeListMovieTx(tx, &listMovie, removedBy, nil)
 	})
 }
 
-func (d *movieListDAO) UpdateMovie(listID, movieID int64, status *models.MovieStatus) (*models.ListMovie, error) {
+// removeListMovieTx marks the title removed and records it. after describes where the
+// title went, when it was moved rather than removed.
+func removeListMovieTx(tx *gorm.DB, listMovie *models.ListMovie, removedBy int64, after listEventValue) error {
+	if err := tx.Model(&models.ListMovie{}).
+		Where("id = ?", listMovie.ID).
+		Updates(map[string]interface{}{"removed_at": time.Now().UTC(), "removed_by": removedBy}).Error; err != nil {
+		return err
+	}
+	title, err := movieTitleTx(tx, listMovie.MovieID)
+	if err != nil {
+		return err
+	}
+	return recordListEventTx(tx, listMovie.ListID, &removedBy, models.EventMovieRemoved, models.EventTargetMovie, listMovie.MovieID,
+		listEventValue{"title": title, "status": listMovie.Status, "added_by": listMovie.AddedBy}, after)
+}
+
+// FindRemovedListMovies returns the titles removed from the list since the given time,
+// most recently removed first
+func (d *movieListDAO) FindRemovedListMovies(listID int64, since time.Time) ([]models.ListMovie, error) {
+	var listMovies []models.ListMovie
+	if err := d.db.
+		Preload("Movie").
+		Preload("Movie.Genres").
+		Preload("AddedByUser").
+		Preload("RemovedByUser").
+		Where("list_id = ? AND removed_at IS NOT NULL AND removed_at >= ?", listID, since).
+		Order("removed_at DESC, id DESC").
+		Find(&listMovies).Error; err != nil {
+		return nil, err
+	}
+	return listMovies, nil
+}
+
+// RestoreListMovie puts a removed title back at its previous display order, moving the
+// titles at or below that position down by one
+func (d *movieListDAO) RestoreListMovie(listID, movieID, restoredBy int64) (*models.ListMovie, error) {
 	var listMovie models.ListMovie
+	if err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ? AND movie_id = ? AND removed_at IS NOT NULL", listID, movieID).First(&listMovie).Error; err != nil {
+			return err
+		}
+		if listMovie.DisplayOrder != nil {
+			if err := tx.Model(&models.ListMovie{}).
+				Where("list_id = ? AND removed_at IS NULL AND display_order >= ?", listID, *listMovie.DisplayOrder).
+				Update("display_order", gorm.Expr("display_order + 1")).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.ListMovie{}).
+			Where("id = ?", listMovie.ID).
+			Updates(map[string]interface{}{"removed_at": nil, "removed_by": nil}).Error; err != nil {
+			return err
+		}
+		title, err := movieTitleTx(tx, movieID)
+		if err != nil {
+			return err
+		}
+		return recordListEventTx(tx, listID, &restoredBy, models.EventMovieRestored, models.EventTargetMovie, movieID,
+			listEventValue{"removed_at": listMovie.RemovedAt, "removed_by": listMovie.RemovedBy},
+			listEventValue{"title": title, "status": listMovie.Status, "display_order": listMovie.DisplayOrder})
+	}); err != nil {
+		return nil, err
+	}
 
-	updates := map[string]interface{}{}
+	if err := d.db.Preload("Movie").Where("id = ?", listMovie.ID).First(&listMovie).Error; err != nil {
+		return nil, err
+	}
+	return &listMovie, nil
+}
 
-	// Update status if provided
-	if status != nil {
-		updates["status"] = *status
-		if *status == models.StatusWatched {
-			now := time.Now()
-			updates["watched_at"] = &now
-		} else {
-			updates["watched_at"] = nil
+// PurgeRemovedListMovies permanently deletes titles removed before the given time,
+// together with their comments, ratings, watches and read markers
+func (d *movieListDAO) PurgeRemovedListMovies(before time.Time) (int64, error) {
+	var removed []models.ListMovie
+	if err := d.db.Select("list_id", "movie_id").
+		Where("removed_at IS NOT NULL AND removed_at < ?", before).
+		Find(&removed).Error; err != nil {
+		return 0, err
+	}
+	var purged int64
+	for _, lm := range removed {
+		if err := d.db.Transaction(func(tx *gorm.DB) error {
+			return purgeListMovieTx(tx, lm.ListID, lm.MovieID)
+		}); err != nil {
+			return purged, err
 		}
+		purged++
 	}
+	return purged, nil
+}
 
-	if err := d.db.Model(&listMovie).
-		Where("list_id = ? AND movie_id = ?", listID, movieID).
-		Updates(updates).Error; err != nil {
+func purgeListMovieTx(tx *gorm.DB, listID, movieID int64) error {
+	commentIDs := tx.Model(&models.Comment{}).Select("id").Where("list_id = ? AND movie_id = ?", listID, movieID)
+	if err := deleteReactionsTx(tx, models.ReactionTargetComment, commentIDs); err != nil {
+		return err
+	}
+	if err := tx.Where("comment_id IN (?)", commentIDs).Delete(&models.CommentMention{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("comment_id IN (?)", commentIDs).Delete(&models.CommentRevision{}).Error; err != nil {
+		return err
+	}
+	// The moderation log is an audit trail and outlives the comments it points at
+	if err := tx.Model(&models.CommentModerationLog{}).Where("comment_id IN (?)", commentIDs).
+		Update("comment_id", nil).Error; err != nil {
+		return err
+	}
+	listMovieIDs := tx.Model(&models.ListMovie{}).Select("id").Where("list_id = ? AND movie_id = ?", listID, movieID)
+	if err := deleteReactionsTx(tx, models.ReactionTargetListMovie, listMovieIDs); err != nil {
+		return err
+	}
+	if err := tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Delete(&models.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Delete(&models.ListMovieUserData{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Delete(&models.ListMovieWatch{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Delete(&models.ListReadMarker{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Delete(&models.ListMovieTag{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Delete(&models.ListMovie{}).Error
+}
+
+func (d *movieListDAO) UpdateMovie(listID, movieID int64, status *models.MovieStatus, changedBy int64) (*models.ListMovie, error) {
+	var listMovie models.ListMovie
+
+	if err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, movieID).First(&listMovie).Error; err != nil {
+			return err
+		}
+		if status == nil {
+			return nil
+		}
+		_, err := setListMovieStatusTx(tx, &listMovie, *status, changedBy)
+		return err
+	}); err != nil {
 		return nil, err
 	}
 
@@ -323,11 +679,34 @@ func (d *movieListDAO) UpdateMovie(listID, movieID int64, status *models.MovieSt
 	return &listMovie, nil
 }
 
-func (d *movieListDAO) UpsertMovieUserData(listID, movieID, userID int64, rating *int, ratingProvided bool) (*models.ListMovieUserData, error) {
-	var existing models.ListMovieUserData
-	err := d.db.Where("list_id = ? AND movie_id = ? AND user_id = ?", listID, movieID, userID).
-		First(&existing).Error
+// setListMovieStatusTx changes the title's status and reports whether it changed. Only an
+// actual change is recorded and counts as unread activity.
+func setListMovieStatusTx(tx *gorm.DB, listMovie *models.ListMovie, status models.MovieStatus, changedBy int64) (bool, error) {
+	if status == listMovie.Status {
+		return false, nil
+	}
 
+	now := time.Now().UTC()
+	updates := map[string]interface{}{
+		"status":            status,
+		"status_changed_at": now,
+		"status_changed_by": changedBy,
+	}
+	if status == models.StatusWatched {
+		updates["watched_at"] = &now
+	} else {
+		updates["watched_at"] = nil
+	}
+	if err := tx.Model(&models.ListMovie{}).
+		Where("id = ?", listMovie.ID).
+		Updates(updates).Error; err != nil {
+		return false, err
+	}
+	return true, recordListEventTx(tx, listMovie.ListID, &changedBy, models.EventStatusChanged, models.EventTargetMovie, listMovie.MovieID,
+		listEventValue{"status": listMovie.Status}, listEventValue{"status": status})
+}
+
+func (d *movieListDAO) UpsertMovieUserData(listID, movieID, userID int64, rating *int, ratingProvided bool) (*models.ListMovieUserData, error) {
 	var cleanRating *int
 	if ratingProvided {
 		if rating != nil {
@@ -341,43 +720,67 @@ func (d *movieListDAO) UpsertMovieUserData(listID, movieID, userID int64, rating
 		}
 	}
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		if !ratingProvided || cleanRating == nil {
-			return nil, nil
+	var result *models.ListMovieUserData
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.ListMovieUserData
+		err := tx.Where("list_id = ? AND movie_id = ? AND user_id = ?", listID, movieID, userID).
+			First(&existing).Error
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if !ratingProvided || cleanRating == nil {
+				return nil
+			}
+			rec := &models.ListMovieUserData{
+				ListID:  listID,
+				MovieID: movieID,
+				UserID:  userID,
+			}
+			if ratingProvided {
+				rec.Rating = cleanRating
+			}
+			if err := tx.Create(rec).Error; err != nil {
+				return err
+			}
+			result = rec
+			return recordRatingChangeTx(tx, listID, movieID, userID, nil, cleanRating)
 		}
-		rec := &models.ListMovieUserData{
-			ListID:  listID,
-			MovieID: movieID,
-			UserID:  userID,
+		if err != nil {
+			return err
 		}
+
+		previous := existing.Rating
 		if ratingProvided {
-			rec.Rating = cleanRating
+			existing.Rating = cleanRating
 		}
-		if err := d.db.Create(rec).Error; err != nil {
-			return nil, err
-		}
-		return rec, nil
-	}
-	if err != nil {
-		return nil, err
-	}
 
-	if ratingProvided {
-		existing.Rating = cleanRating
-	}
+		if existing.Rating == nil {
+			if err := tx.Delete(&existing).Error; err != nil {
+				return err
+			}
+			return recordRatingChangeTx(tx, listID, movieID, userID, previous, nil)
+		}
 
-	if existing.Rating == nil {
-		if err := d.db.Delete(&existing).Error; err != nil {
-			return nil, err
+		existing.UpdatedAt = time.Now()
+		if err := tx.Save(&existing).Error; err != nil {
+			return err
 		}
-		return nil, nil
+		result = &existing
+		return recordRatingChangeTx(tx, listID, movieID, userID, previous, existing.Rating)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
+}
 
-	existing.UpdatedAt = time.Now()
-	if err := d.db.Save(&existing).Error; err != nil {
-		return nil, err
+// recordRatingChangeTx records a member's rating change, skipping ratings set again to
+// the same value
+func recordRatingChangeTx(tx *gorm.DB, listID, movieID, userID int64, before, after *int) error {
+	if (before == nil && after == nil) || (before != nil && after != nil && *before == *after) {
+		return nil
 	}
-	return &existing, nil
+	return recordListEventTx(tx, listID, &userID, models.EventRatingChanged, models.EventTargetMovie, movieID,
+		listEventValue{"rating": before}, listEventValue{"rating": after})
 }
 
 func (d *movieListDAO) FindMovieUserData(listID, movieID, userID int64) (*models.ListMovieUserData, error) {
@@ -390,6 +793,41 @@ func (d *movieListDAO) FindMovieUserData(listID, movieID, userID int64) (*models
 	return &data, nil
 }
 
+func (d *movieListDAO) SetMovieWatched(listID, movieID, userID int64, watched bool) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		return setWatchedTx(tx, listID, movieID, userID, watched)
+	})
+}
+
+// setWatchedTx sets or clears the user's watch mark and records it when it changed
+func setWatchedTx(tx *gorm.DB, listID, movieID, userID int64, watched bool) error {
+	var res *gorm.DB
+	verb := models.EventWatchAdded
+	if watched {
+		watch := &models.ListMovieWatch{ListID: listID, MovieID: movieID, UserID: userID, WatchedAt: time.Now().UTC()}
+		// Keep the first watch date when the mark is set again
+		res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(watch)
+	} else {
+		verb = models.EventWatchRemoved
+		res = tx.Where("list_id = ? AND movie_id = ? AND user_id = ?", listID, movieID, userID).
+			Delete(&models.ListMovieWatch{})
+	}
+	if res.Error != nil || res.RowsAffected == 0 {
+		return res.Error
+	}
+	return recordListEventTx(tx, listID, &userID, verb, models.EventTargetMovie, movieID, nil, nil)
+}
+
+func (d *movieListDAO) FindMovieWatches(listID, movieID int64) ([]models.ListMovieWatch, error) {
+	var watches []models.ListMovieWatch
+	if err := d.db.Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Order("watched_at ASC").
+		Find(&watches).Error; err != nil {
+		return nil, err
+	}
+	return watches, nil
+}
+
 func (d *movieListDAO) FindListMoviesWithMovie(listID int64, status *models.MovieStatus) ([]models.ListMovie, error) {
 	var listMovies []models.ListMovie
 	q := d.db.
@@ -397,17 +835,175 @@ func (d *movieListDAO) FindListMoviesWithMovie(listID int64, status *models.Movi
 		Preload("Movie.Genres").
 		Preload("UserEntries").
 		Preload("UserEntries.User").
+		Preload("Watches").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tag ASC") }).
 		Preload("AddedByUser").
-		Where("list_id = ?", listID)
+		Where("list_id = ? AND removed_at IS NULL", listID)
 	if status != nil {
 		q = q.Where("status = ?", string(*status))
 	}
 	if err := q.Order("display_order ASC, added_at DESC").Find(&listMovies).Error; err != nil {
 		return nil, err
 	}
+	if err := attachListMovieReactions(d.db, listMovies); err != nil {
+		return nil, err
+	}
+	return listMovies, nil
+}
+
+// FindListMovieWithMovie loads one active title of the list with everything
+// FindListMoviesWithMovie loads for each item
+func (d *movieListDAO) FindListMovieWithMovie(listID, movieID int64) (*models.ListMovie, error) {
+	var listMovie models.ListMovie
+	if err := d.db.
+		Preload("Movie").
+		Preload("Movie.Genres").
+		Preload("UserEntries").
+		Preload("UserEntries.User").
+		Preload("Watches").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tag ASC") }).
+		Preload("AddedByUser").
+		Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, movieID).
+		First(&listMovie).Error; err != nil {
+		return nil, err
+	}
+	items := []models.ListMovie{listMovie}
+	if err := attachListMovieReactions(d.db, items); err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// FindListMoviesForExport pages through the list's active titles in display order with
+// their movie, the members' entries and watches, so an export never holds the whole list.
+// Pages start after the (afterOrder, afterID) of the previous page's last title, so
+// titles added, removed or reordered meanwhile do not shift the rest; afterID 0 is the
+// first page. Titles without a display order come first, as MySQL sorts NULL first.
+func (d *movieListDAO) FindListMoviesForExport(listID int64, afterOrder *int, afterID int64, limit int) ([]models.ListMovie, error) {
+	var listMovies []models.ListMovie
+	q := d.db.
+		Preload("Movie").
+		Preload("UserEntries").
+		Preload("UserEntries.User").
+		Preload("Watches").
+		Where("list_id = ? AND removed_at IS NULL", listID)
+	switch {
+	case afterID == 0:
+	case afterOrder == nil:
+		q = q.Where("display_order IS NOT NULL OR id > ?", afterID)
+	default:
+		q = q.Where("display_order > ? OR (display_order = ? AND id > ?)", *afterOrder, *afterOrder, afterID)
+	}
+	if err := q.Order("display_order ASC, id ASC").
+		Limit(limit).
+		Find(&listMovies).Error; err != nil {
+		return nil, err
+	}
+	return listMovies, nil
+}
+
+func (d *movieListDAO) FindMembersOtherListMovies(userIDs []int64, excludeListID int64, limit int) ([]models.ListMovie, error) {
+	var listMovies []models.ListMovie
+	if len(userIDs) == 0 {
+		return listMovies, nil
+	}
+	q := d.db.
+		Preload("Movie").
+		Preload("Movie.Genres").
+		Preload("UserEntries").
+		Joins("JOIN movie_lists ON movie_lists.id = list_movies.list_id AND movie_lists.deleted_at IS NULL").
+		Where("list_movies.list_id <> ? AND list_movies.removed_at IS NULL", excludeListID).
+		Where("list_movies.list_id IN (?)", d.db.Model(&models.ListMember{}).Select("list_id").Where("user_id IN ?", userIDs)).
+		Order("list_movies.added_at DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&listMovies).Error; err != nil {
+		return nil, err
+	}
 	return listMovies, nil
 }
 
+// FindListsWithMovie returns the lists that currently hold the title
+func (d *movieListDAO) FindListsWithMovie(movieID int64) ([]models.MovieList, error) {
+	var lists []models.MovieList
+	if err := d.db.
+		Where("id IN (?)", d.db.Model(&models.ListMovie{}).Select("list_id").Where("movie_id = ? AND removed_at IS NULL", movieID)).
+		Find(&lists).Error; err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// RatedListMovie is a title of a list with the members' average rating for it
+type RatedListMovie struct {
+	MovieID   int64
+	Title     string
+	AvgRating float64
+}
+
+// FindListsDueForDigest returns the lists whose last digest, or creation when none was
+// sent, is older than coveredBefore
+func (d *movieListDAO) FindListsDueForDigest(coveredBefore time.Time) ([]models.MovieList, error) {
+	var lists []models.MovieList
+	if err := d.db.
+		Where("COALESCE(last_digest_at, created_at) <= ?", coveredBefore).
+		Order("id ASC").
+		Find(&lists).Error; err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (d *movieListDAO) FindListMoviesAddedSince(listID int64, since time.Time) ([]models.ListMovie, error) {
+	var listMovies []models.ListMovie
+	if err := d.db.
+		Preload("Movie").
+		Preload("AddedByUser").
+		Where("list_id = ? AND removed_at IS NULL AND added_at >= ?", listID, since).
+		Order("added_at ASC").
+		Find(&listMovies).Error; err != nil {
+		return nil, err
+	}
+	return listMovies, nil
+}
+
+func (d *movieListDAO) FindListMoviesWatchedSince(listID int64, since time.Time) ([]models.ListMovie, error) {
+	var listMovies []models.ListMovie
+	if err := d.db.
+		Preload("Movie").
+		Where("list_id = ? AND removed_at IS NULL AND status = ? AND watched_at >= ?", listID, models.StatusWatched, since).
+		Order("watched_at ASC").
+		Find(&listMovies).Error; err != nil {
+		return nil, err
+	}
+	return listMovies, nil
+}
+
+// FindTopRatedListMovies returns the list's active titles with the highest average rating
+func (d *movieListDAO) FindTopRatedListMovies(listID int64, limit int) ([]RatedListMovie, error) {
+	var rated []RatedListMovie
+	if err := d.db.Table(models.ListMovieUserData{}.TableName()+" AS ud").
+		Select("ud.movie_id AS movie_id, movies.title AS title, AVG(ud.rating) AS avg_rating").
+		Joins("JOIN list_movies lm ON lm.list_id = ud.list_id AND lm.movie_id = ud.movie_id AND lm.removed_at IS NULL").
+		Joins("JOIN movies ON movies.id = ud.movie_id").
+		Where("ud.list_id = ? AND ud.rating IS NOT NULL", listID).
+		Group("ud.movie_id, movies.title").
+		Order("avg_rating DESC, ud.movie_id ASC").
+		Limit(limit).
+		Scan(&rated).Error; err != nil {
+		return nil, err
+	}
+	return rated, nil
+}
+
+// MarkDigestSent records that the digest covered the list up to at; it leaves updated_at alone
+func (d *movieListDAO) MarkDigestSent(listID int64, at time.Time) error {
+	return d.db.Model(&models.MovieList{}).
+		Where("id = ?", listID).
+		UpdateColumn("last_digest_at", at).Error
+}
+
 func (d *movieListDAO) GetMovieAverageRating(listID, movieID int64) (*float64, error) {
 	type result struct {
 		Avg *float64
@@ -429,7 +1025,7 @@ func (d *movieListDAO) SearchListMoviesWithMovie(listID int64, query string, lim
 	var total int64
 	countQ := d.db.Model(&models.ListMovie{}).
 		Joins("JOIN movies ON movies.id = list_movies.movie_id").
-		Where("list_movies.list_id = ?", listID).
+		Where("list_movies.list_id = ? AND list_movies.removed_at IS NULL", listID).
 		Where("movies.title LIKE ? OR movies.original_title LIKE ?", like, like)
 	if err := countQ.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -441,9 +1037,11 @@ func (d *movieListDAO) SearchListMoviesWithMovie(listID int64, query string, lim
 		Preload("Movie.Genres").
 		Preload("UserEntries").
 		Preload("UserEntries.User").
+		Preload("Watches").
+		Preload("Tags", func(db *gorm.DB) *gorm.DB { return db.Order("tag ASC") }).
 		Preload("AddedByUser").
 		Joins("JOIN movies ON movies.id = list_movies.movie_id").
-		Where("list_movies.list_id = ?", listID).
+		Where("list_movies.list_id = ? AND list_movies.removed_at IS NULL", listID).
 		Where("movies.title LIKE ? OR movies.original_title LIKE ?", like, like).
 		Order("list_movies.display_order ASC, list_movies.added_at DESC")
 	if limit > 0 {
@@ -455,19 +1053,44 @@ func (d *movieListDAO) SearchListMoviesWithMovie(listID int64, query string, lim
 	if err := fetchQ.Find(&listMovies).Error; err != nil {
 		return nil, 0, err
 	}
+	if err := attachListMovieReactions(d.db, listMovies); err != nil {
+		return nil, 0, err
+	}
 	return listMovies, total, nil
 }
 
-func (d *movieListDAO) UpdateMovieOrders(listID int64, orderMap map[int64]int) error {
+func (d *movieListDAO) UpdateMovieOrders(listID int64, orderMap map[int64]int, changedBy int64) error {
 	return d.db.Transaction(func(tx *gorm.DB) error {
+		movieIDs := make([]int64, 0, len(orderMap))
+		for movieID := range orderMap {
+			movieIDs = append(movieIDs, movieID)
+		}
+		var previous []models.ListMovie
+		if err := tx.Select("movie_id", "display_order").
+			Where("list_id = ? AND movie_id IN ? AND removed_at IS NULL", listID, movieIDs).
+			Find(&previous).Error; err != nil {
+			return err
+		}
+
 		for movieID, order := range orderMap {
 			if err := tx.Model(&models.ListMovie{}).
-				Where("list_id = ? AND movie_id = ?", listID, movieID).
+				Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, movieID).
 				Update("display_order", order).Error; err != nil {
 				return err
 			}
 		}
-		return nil
+
+		// Orders are keyed by movie id
+		before := listEventValue{}
+		for _, lm := range previous {
+			before[strconv.FormatInt(lm.MovieID, 10)] = lm.DisplayOrder
+		}
+		after := listEventValue{}
+		for movieID, order := range orderMap {
+			after[strconv.FormatInt(movieID, 10)] = order
+		}
+		return recordListEventTx(tx, listID, &changedBy, models.EventMoviesReordered, models.EventTargetList, listID,
+			listEventValue{"orders": before}, listEventValue{"orders": after})
 	})
 }
 
@@ -478,48 +1101,92 @@ func (d *movieListDAO) RemoveMember(listID, userID int64) error {
 			Delete(&models.ListMovieUserData{}).Error; err != nil {
 			return err
 		}
-		// Delete user's comments for movies in this list
+		// Delete user's watch marks in this list
+		if err := tx.Where("list_id = ? AND user_id = ?", listID, userID).
+			Delete(&models.ListMovieWatch{}).Error; err != nil {
+			return err
+		}
+		// Delete user's read markers in this list
+		if err := tx.Where("list_id = ? AND user_id = ?", listID, userID).
+			Delete(&models.ListReadMarker{}).Error; err != nil {
+			return err
+		}
+		// Delete user's reactions in this list
 		if err := tx.Where("list_id = ? AND user_id = ?", listID, userID).
-			Delete(&models.Comment{}).Error; err != nil {
+			Delete(&models.Reaction{}).Error; err != nil {
+			return err
+		}
+		// Soft-delete user's comments for movies in this list; threads they started
+		// keep a placeholder while others' replies remain. The reason sets them apart
+		// from comments the user deleted, so a moderator can bring them back.
+		if err := tx.Model(&models.Comment{}).
+			Where("list_id = ? AND user_id = ? AND deleted_at IS NULL", listID, userID).
+			Updates(map[string]interface{}{
+				"deleted_at":     time.Now().UTC(),
+				"deleted_by":     userID,
+				"deleted_reason": models.CommentDeletedMemberLeft,
+			}).Error; err != nil {
 			return err
 		}
 		// Delete the membership
+		var membership models.ListMember
+		if err := tx.Where("list_id = ? AND user_id = ?", listID, userID).First(&membership).Error; err != nil {
+			return err
+		}
 		if err := tx.Where("list_id = ? AND user_id = ?", listID, userID).
 			Delete(&models.ListMember{}).Error; err != nil {
 			return err
 		}
-		return nil
+		return recordListEventTx(tx, listID, &userID, models.EventMemberLeft, models.EventTargetMember, userID,
+			listEventValue{"role": membership.Role}, nil)
 	})
 }
 
-func (d *movieListDAO) CreateComment(listID, movieID, userID int64, content string) (*models.Comment, error) {
+func (d *movieListDAO) CreateComment(listID int64, movieID *int64, userID int64, parentID *int64, content string, spoiler bool, mentions []models.CommentMention) (*models.Comment, error) {
 	comment := &models.Comment{
-		ListID:  listID,
-		MovieID: movieID,
-		UserID:  userID,
-		Content: content,
+		ListID:   listID,
+		MovieID:  movieID,
+		UserID:   userID,
+		ParentID: parentID,
+		Content:  content,
+		Spoiler:  spoiler,
 	}
-	if err := d.db.Create(comment).Error; err != nil {
+	if err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		if err := replaceCommentMentionsTx(tx, comment.ID, mentions); err != nil {
+			return err
+		}
+		return recordListEventTx(tx, listID, &userID, models.EventCommentCreated, models.EventTargetComment, comment.ID,
+			nil, listEventValue{"movie_id": movieID, "parent_id": parentID, "spoiler": spoiler})
+	}); err != nil {
 		return nil, err
 	}
 	// Reload with user
-	if err := d.db.Preload("User").First(comment, comment.ID).Error; err != nil {
+	if err := d.db.Preload("User").Preload("Mentions.User").First(comment, comment.ID).Error; err != nil {
 		return nil, err
 	}
 	return comment, nil
 }
 
+// visibleCommentCondition keeps deleted comments only as placeholders for replies that remain
+const visibleCommentCondition = "comments.deleted_at IS NULL OR EXISTS (SELECT 1 FROM comments replies WHERE replies.parent_id = comments.id AND replies.deleted_at IS NULL)"
+
 func (d *movieListDAO) FindComments(listID, movieID int64, limit, offset int) ([]models.Comment, int64, error) {
 	var total int64
 	if err := d.db.Model(&models.Comment{}).
-		Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Where("list_id = ? AND movie_id = ? AND parent_id IS NULL", listID, movieID).
+		Where(visibleCommentCondition).
 		Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	var comments []models.Comment
 	q := d.db.Preload("User").
-		Where("list_id = ? AND movie_id = ?", listID, movieID).
+		Preload("Mentions.User").
+		Where("list_id = ? AND movie_id = ? AND parent_id IS NULL", listID, movieID).
+		Where(visibleCommentCondition).
 		Order("created_at DESC")
 	if limit > 0 {
 		q = q.Limit(limit)
@@ -530,33 +1197,330 @@ func (d *movieListDAO) FindComments(listID, movieID int64, limit, offset int) ([
 	if err := q.Find(&comments).Error; err != nil {
 		return nil, 0, err
 	}
+	if err := attachThreadDetails(d.db, comments); err != nil {
+		return nil, 0, err
+	}
 	return comments, total, nil
 }
 
+// FindBoardComments returns top-level posts on the list's discussion board, newest
+// first, starting after the (beforeCreatedAt, beforeID) cursor when one is given
+func (d *movieListDAO) FindBoardComments(listID int64, beforeCreatedAt *time.Time, beforeID int64, limit int) ([]models.Comment, error) {
+	var comments []models.Comment
+	q := d.db.Preload("User").
+		Preload("Mentions.User").
+		Where("list_id = ? AND movie_id IS NULL AND parent_id IS NULL", listID).
+		Where(visibleCommentCondition)
+	if beforeCreatedAt != nil {
+		q = q.Where("created_at < ? OR (created_at = ? AND id < ?)", *beforeCreatedAt, *beforeCreatedAt, beforeID)
+	}
+	q = q.Order("created_at DESC, id DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	if err := attachThreadDetails(d.db, comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// attachThreadDetails fills in reply counts and reactions for top-level comments
+func attachThreadDetails(db *gorm.DB, comments []models.Comment) error {
+	ids := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	counts, err := countRepliesBatch(db, ids)
+	if err != nil {
+		return err
+	}
+	for i := range comments {
+		comments[i].ReplyCount = counts[comments[i].ID]
+	}
+	return attachCommentReactions(db, comments)
+}
+
+func (d *movieListDAO) FindCommentReplies(parentID int64, limit, offset int) ([]models.Comment, int64, error) {
+	var total int64
+	if err := d.db.Model(&models.Comment{}).
+		Where("parent_id = ? AND deleted_at IS NULL", parentID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var replies []models.Comment
+	q := d.db.Preload("User").
+		Preload("Mentions.User").
+		Where("parent_id = ? AND deleted_at IS NULL", parentID).
+		Order("created_at ASC, id ASC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	if err := q.Find(&replies).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := attachCommentReactions(d.db, replies); err != nil {
+		return nil, 0, err
+	}
+	return replies, total, nil
+}
+
+// attachCommentReactions fills the reaction summaries of the given comments
+func attachCommentReactions(db *gorm.DB, comments []models.Comment) error {
+	ids := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	summaries, err := findReactionSummaries(db, models.ReactionTargetComment, ids)
+	if err != nil {
+		return err
+	}
+	for i := range comments {
+		comments[i].Reactions = summaries[comments[i].ID]
+	}
+	return nil
+}
+
+// attachListMovieReactions fills the reaction summaries of the given list entries
+func attachListMovieReactions(db *gorm.DB, listMovies []models.ListMovie) error {
+	ids := make([]int64, 0, len(listMovies))
+	for _, lm := range listMovies {
+		ids = append(ids, lm.ID)
+	}
+	summaries, err := findReactionSummaries(db, models.ReactionTargetListMovie, ids)
+	if err != nil {
+		return err
+	}
+	for i := range listMovies {
+		listMovies[i].Reactions = summaries[listMovies[i].ID]
+	}
+	return nil
+}
+
 func (d *movieListDAO) FindCommentByID(commentID int64) (*models.Comment, error) {
 	var comment models.Comment
-	if err := d.db.Preload("User").First(&comment, commentID).Error; err != nil {
+	if err := d.db.Preload("User").Preload("Mentions.User").First(&comment, commentID).Error; err != nil {
 		return nil, err
 	}
 	return &comment, nil
 }
 
-func (d *movieListDAO) UpdateComment(commentID int64, content string) (*models.Comment, error) {
+func (d *movieListDAO) UpdateComment(commentID int64, content string, spoiler *bool, mentions []models.CommentMention) (*models.Comment, error) {
 	var comment models.Comment
-	if err := d.db.First(&comment, commentID).Error; err != nil {
+	if err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&comment, commentID).Error; err != nil {
+			return err
+		}
+		newSpoiler := comment.Spoiler
+		if spoiler != nil {
+			newSpoiler = *spoiler
+		}
+		// Keep the previous version whenever the visible content changes
+		if content != comment.Content || newSpoiler != comment.Spoiler {
+			revision := &models.CommentRevision{
+				CommentID: comment.ID,
+				Content:   comment.Content,
+				Spoiler:   comment.Spoiler,
+			}
+			if err := tx.Create(revision).Error; err != nil {
+				return err
+			}
+			now := time.Now().UTC()
+			comment.EditedAt = &now
+			// Content stays in the revisions, where moderation still applies
+			if err := recordListEventTx(tx, comment.ListID, &comment.UserID, models.EventCommentEdited, models.EventTargetComment, comment.ID,
+				listEventValue{"spoiler": comment.Spoiler}, listEventValue{"spoiler": newSpoiler, "revision_id": revision.ID}); err != nil {
+				return err
+			}
+		}
+		comment.Content = content
+		comment.Spoiler = newSpoiler
+		if err := tx.Save(&comment).Error; err != nil {
+			return err
+		}
+		return replaceCommentMentionsTx(tx, commentID, mentions)
+	}); err != nil {
 		return nil, err
 	}
-	comment.Content = content
-	if err := d.db.Save(&comment).Error; err != nil {
+	// Reload with user
+	if err := d.db.Preload("User").Preload("Mentions.User").First(&comment, commentID).Error; err != nil {
 		return nil, err
 	}
-	// Reload with user
-	if err := d.db.Preload("User").First(&comment, commentID).Error; err != nil {
+	return &comment, nil
+}
+
+// replaceCommentMentionsTx swaps the stored mentions of a comment for the given ones
+func replaceCommentMentionsTx(tx *gorm.DB, commentID int64, mentions []models.CommentMention) error {
+	if err := tx.Where("comment_id = ?", commentID).Delete(&models.CommentMention{}).Error; err != nil {
+		return err
+	}
+	if len(mentions) == 0 {
+		return nil
+	}
+	rows := make([]models.CommentMention, 0, len(mentions))
+	for _, m := range mentions {
+		m.CommentID = commentID
+		rows = append(rows, m)
+	}
+	return tx.Omit("User").Create(&rows).Error
+}
+
+// DeleteComment soft-deletes a comment so it can be restored by a moderator. It
+// reports whether the comment stays visible as a placeholder for its replies.
+func (d *movieListDAO) DeleteComment(commentID, deletedBy int64) (bool, error) {
+	if err := d.db.Transaction(func(tx *gorm.DB) error {
+		var comment models.Comment
+		if err := tx.First(&comment, commentID).Error; err != nil {
+			return err
+		}
+		res := tx.Model(&models.Comment{}).
+			Where("id = ? AND deleted_at IS NULL", commentID).
+			Updates(map[string]interface{}{
+				"deleted_at":     time.Now().UTC(),
+				"deleted_by":     deletedBy,
+				"deleted_reason": models.CommentDeletedByAuthor,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		return recordListEventTx(tx, comment.ListID, &deletedBy, models.EventCommentDeleted, models.EventTargetComment, commentID,
+			listEventValue{"movie_id": comment.MovieID, "author_id": comment.UserID}, nil)
+	}); err != nil {
+		return false, err
+	}
+	counts, err := countRepliesBatch(d.db, []int64{commentID})
+	if err != nil {
+		return false, err
+	}
+	return counts[commentID] > 0, nil
+}
+
+func (d *movieListDAO) FindCommentRevisions(commentID int64) ([]models.CommentRevision, error) {
+	var revisions []models.CommentRevision
+	if err := d.db.Where("comment_id = ?", commentID).
+		Order("created_at DESC, id DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// ModerateComment applies a moderation action and writes its audit record in one
+// transaction, returning the updated comment
+func (d *movieListDAO) ModerateComment(entry *models.CommentModerationLog) (*models.Comment, error) {
+	now := time.Now().UTC()
+	var updates map[string]interface{}
+	switch entry.Action {
+	case models.ModerationHide:
+		updates = map[string]interface{}{"hidden_at": now, "hidden_by": entry.ModeratorID}
+	case models.ModerationUnhide:
+		updates = map[string]interface{}{"hidden_at": nil, "hidden_by": nil}
+	case models.ModerationDelete:
+		updates = map[string]interface{}{"deleted_at": now, "deleted_by": entry.ModeratorID, "deleted_reason": models.CommentDeletedByModerator}
+	case models.ModerationRestore:
+		updates = map[string]interface{}{"deleted_at": nil, "deleted_by": nil, "deleted_reason": nil}
+	default:
+		return nil, gorm.ErrInvalidData
+	}
+	if entry.CommentID == nil {
+		return nil, gorm.ErrInvalidData
+	}
+	commentID := *entry.CommentID
+
+	if err := d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Comment{}).Where("id = ?", commentID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Moderator", "Author").Create(entry).Error; err != nil {
+			return err
+		}
+		return recordListEventTx(tx, entry.ListID, &entry.ModeratorID, models.EventCommentModerated, models.EventTargetComment, commentID,
+			nil, listEventValue{"action": entry.Action, "author_id": entry.AuthorID})
+	}); err != nil {
 		return nil, err
 	}
+
+	var comment models.Comment
+	if err := d.db.Preload("User").Preload("Mentions.User").First(&comment, commentID).Error; err != nil {
+		return nil, err
+	}
+	counts, err := countRepliesBatch(d.db, []int64{comment.ID})
+	if err != nil {
+		return nil, err
+	}
+	comment.ReplyCount = counts[comment.ID]
 	return &comment, nil
 }
 
-func (d *movieListDAO) DeleteComment(commentID int64) error {
-	return d.db.Delete(&models.Comment{}, commentID).Error
+func (d *movieListDAO) FindModerationLog(listID int64, limit, offset int) ([]models.CommentModerationLog, int64, error) {
+	var total int64
+	if err := d.db.Model(&models.CommentModerationLog{}).
+		Where("list_id = ?", listID).
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.CommentModerationLog
+	q := d.db.Preload("Moderator").
+		Preload("Author").
+		Where("list_id = ?", listID).
+		Order("created_at DESC, id DESC")
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if offset > 0 {
+		q = q.Offset(offset)
+	}
+	if err := q.Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// countRepliesBatch returns the number of replies that were not deleted for each of the given comments
+func countRepliesBatch(db *gorm.DB, commentIDs []int64) (map[int64]int64, error) {
+	result := make(map[int64]int64, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+	type row struct {
+		ParentID int64
+		Count    int64
+	}
+	var rows []row
+	if err := db.Model(&models.Comment{}).
+		Select("parent_id, COUNT(*) as count").
+		Where("parent_id IN ? AND deleted_at IS NULL", commentIDs).
+		Group("parent_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, r := range rows {
+		result[r.ParentID] = r.Count
+	}
+	return result, nil
+}
+
+// FindListEvents returns the list's events newest first, starting below beforeID when it is set
+func (d *movieListDAO) FindListEvents(listID int64, beforeID int64, limit int) ([]models.ListEvent, error) {
+	var events []models.ListEvent
+	q := d.db.Preload("Actor").
+		Where("list_id = ?", listID).
+		Order("id DESC")
+	if beforeID > 0 {
+		q = q.Where("id < ?", beforeID)
+	}
+	if limit > 0 {
+		q = q.Limit(limit)
+	}
+	if err := q.Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
 }
@@ -0,0 +1,52 @@
+package daos
+
+import (
+	"encoding/json"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+// listEventValue is the before or after snapshot of a list event
+type listEventValue map[string]interface{}
+
+// recordListEventTx appends an event to the list's activity log inside tx, so the event
+// exists exactly when the change it describes was committed
+func recordListEventTx(tx *gorm.DB, listID int64, actorID *int64, verb models.ListEventVerb, targetType models.ListEventTarget, targetID int64, before, after listEventValue) error {
+	event := &models.ListEvent{
+		ListID:     listID,
+		ActorID:    actorID,
+		Verb:       verb,
+		TargetType: targetType,
+		TargetID:   targetID,
+	}
+	if before != nil {
+		raw, err := json.Marshal(before)
+		if err != nil {
+			return err
+		}
+		encoded := string(raw)
+		event.Before = &encoded
+	}
+	if after != nil {
+		raw, err := json.Marshal(after)
+		if err != nil {
+			return err
+		}
+		encoded := string(raw)
+		event.After = &encoded
+	}
+	return tx.Omit("Actor").Create(event).Error
+}
+
+// movieTitleTx returns the stored title of a movie so events stay readable after removal
+func movieTitleTx(tx *gorm.DB, movieID int64) (string, error) {
+	var titles []string
+	if err := tx.Model(&models.Movie{}).Where("id = ?", movieID).Limit(1).Pluck("title", &titles).Error; err != nil {
+		return "", err
+	}
+	if len(titles) == 0 {
+		return "", nil
+	}
+	return titles[0], nil
+}
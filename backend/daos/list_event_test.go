@@ -0,0 +1,122 @@
+package daos
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// eventsOf returns the list's events oldest first, whether or not the list was deleted
+func eventsOf(t *testing.T, db *gorm.DB, listID int64) []models.ListEvent {
+	t.Helper()
+	var events []models.ListEvent
+	if err := db.Where("list_id = ?", listID).Order("id ASC").Find(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func verbsOf(events []models.ListEvent) []models.ListEventVerb {
+	verbs := make([]models.ListEventVerb, 0, len(events))
+	for _, event := range events {
+		verbs = append(verbs, event.Verb)
+	}
+	return verbs
+}
+
+func eventValue(t *testing.T, raw *string) map[string]interface{} {
+	t.Helper()
+	if raw == nil {
+		return nil
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal([]byte(*raw), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestRatingChangesAreRecorded(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+	movieID := seedListMovie(t, db, list, owner).MovieID
+
+	eight, nine := 8, 9
+	_, err := dao.UpsertMovieUserData(list.ID, movieID, owner.ID, &eight, true)
+	assert.NoError(t, err)
+	_, err = dao.UpsertMovieUserData(list.ID, movieID, owner.ID, &eight, true)
+	assert.NoError(t, err)
+	_, err = dao.UpsertMovieUserData(list.ID, movieID, owner.ID, &nine, true)
+	assert.NoError(t, err)
+	_, err = dao.UpsertMovieUserData(list.ID, movieID, owner.ID, nil, true)
+	assert.NoError(t, err)
+
+	var ratings []models.ListEvent
+	for _, event := range eventsOf(t, db, list.ID) {
+		if event.Verb == models.EventRatingChanged {
+			ratings = append(ratings, event)
+		}
+	}
+	// Setting the same rating again is not a change
+	if assert.Len(t, ratings, 3) {
+		assert.Equal(t, map[string]interface{}{"rating": nil}, eventValue(t, ratings[0].Before))
+		assert.Equal(t, map[string]interface{}{"rating": float64(8)}, eventValue(t, ratings[0].After))
+		assert.Equal(t, map[string]interface{}{"rating": float64(9)}, eventValue(t, ratings[1].After))
+		assert.Equal(t, map[string]interface{}{"rating": nil}, eventValue(t, ratings[2].After))
+		assert.Equal(t, movieID, ratings[2].TargetID)
+	}
+}
+
+func TestWatchChangesAreRecorded(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+	movieID := seedListMovie(t, db, list, owner).MovieID
+
+	assert.NoError(t, dao.SetMovieWatched(list.ID, movieID, owner.ID, true))
+	assert.NoError(t, dao.SetMovieWatched(list.ID, movieID, owner.ID, true))
+	assert.NoError(t, dao.SetMovieWatched(list.ID, movieID, owner.ID, false))
+	assert.NoError(t, dao.SetMovieWatched(list.ID, movieID, owner.ID, false))
+
+	// Marks that were already in place are not recorded again
+	assert.Equal(t, []models.ListEventVerb{
+		models.EventListCreated,
+		models.EventMovieAdded,
+		models.EventWatchAdded,
+		models.EventWatchRemoved,
+	}, verbsOf(eventsOf(t, db, list.ID)))
+}
+
+func TestDeleteListRecordsEventWhileListIsLive(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+
+	assert.NoError(t, dao.DeleteListCascadeIfOwner(list.ID, owner.ID))
+
+	events := eventsOf(t, db, list.ID)
+	if assert.NotEmpty(t, events) {
+		last := events[len(events)-1]
+		assert.Equal(t, models.EventListDeleted, last.Verb)
+		assert.Equal(t, owner.ID, *last.ActorID)
+	}
+	_, err := dao.FindByID(list.ID)
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestDeleteListByParticipantRecordsNothing(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner, friend := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, friend)
+
+	assert.ErrorIs(t, dao.DeleteListCascadeIfOwner(list.ID, friend.ID), gorm.ErrInvalidData)
+	assert.NotContains(t, verbsOf(eventsOf(t, db, list.ID)), models.EventListDeleted)
+}
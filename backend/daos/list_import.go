@@ -0,0 +1,210 @@
+package daos
+
+import (
+	"errors"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ImportedListMovie is a title brought into a list from another service's export, with
+// the importing member's history of it
+type ImportedListMovie struct {
+	MovieID int64
+	Status  models.MovieStatus
+	// Watched records a watch for the importing member. A watched status alone does not,
+	// as in a list2gether export it says the list watched the title, not the member.
+	Watched bool
+	// WatchedAt is when the title was watched, if the export says so
+	WatchedAt *time.Time
+	Rating    *int
+	Notes     *string
+}
+
+// staleImportAfter is how long a running import can go without saving progress
+const staleImportAfter = 15 * time.Minute
+
+// SaveImportProgress stores the job's counters, status and report
+func (d *movieListDAO) SaveImportProgress(job *models.ListImport) error {
+	return d.db.Model(&models.ListImport{}).
+		Where("id = ?", job.ID).
+		Updates(map[string]interface{}{
+			"status":          job.Status,
+			"total_rows":      job.TotalRows,
+			"processed_rows":  job.ProcessedRows,
+			"added_count":     job.AddedCount,
+			"updated_count":   job.UpdatedCount,
+			"unmatched_count": job.UnmatchedCount,
+			"unmatched":       job.Unmatched,
+			"error":           job.Error,
+			"finished_at":     job.FinishedAt,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+}
+
+func (d *movieListDAO) FindImport(listID, importID int64) (*models.ListImport, error) {
+	var job models.ListImport
+	if err := d.db.Where("id = ? AND list_id = ?", importID, listID).First(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// StartImport creates the job unless the list already has an import in progress, in
+// which case it reports false. The list row is locked while checking, so two uploads at
+// once cannot both start. An import whose progress has not moved for staleImportAfter
+// was cut short, such as by a restart, and is marked failed instead.
+func (d *movieListDAO) StartImport(job *models.ListImport) (bool, error) {
+	started := false
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var list models.MovieList
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			Where("id = ?", job.ListID).
+			First(&list).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ListImport{}).
+			Where("list_id = ? AND status = ? AND updated_at < ?", job.ListID, models.ImportRunning, time.Now().UTC().Add(-staleImportAfter)).
+			Updates(map[string]interface{}{"status": models.ImportFailed, "error": "interrupted", "updated_at": time.Now().UTC()}).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&models.ListImport{}).
+			Where("list_id = ? AND status = ?", job.ListID, models.ImportRunning).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		started = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return started, nil
+}
+
+// ImportListMovie adds the title to the list, or reports that it was already there, and
+// records the member's watch, rating and notes. A rating or notes the member already
+// left in the list win over the imported ones.
+func (d *movieListDAO) ImportListMovie(listID, userID int64, item ImportedListMovie) (bool, error) {
+	created := false
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.ListMovie
+		err := tx.Where("list_id = ? AND movie_id = ? AND removed_at IS NULL", listID, item.MovieID).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := createImportedListMovieTx(tx, listID, userID, item); err != nil {
+				return err
+			}
+			created = true
+		case err != nil:
+			return err
+		}
+
+		if item.Watched {
+			watchedAt := time.Now().UTC()
+			if item.WatchedAt != nil {
+				watchedAt = *item.WatchedAt
+			}
+			watch := &models.ListMovieWatch{ListID: listID, MovieID: item.MovieID, UserID: userID, WatchedAt: watchedAt}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(watch).Error; err != nil {
+				return err
+			}
+		}
+		if item.Rating == nil && item.Notes == nil {
+			return nil
+		}
+
+		var data models.ListMovieUserData
+		err = tx.Where("list_id = ? AND movie_id = ? AND user_id = ?", listID, item.MovieID, userID).First(&data).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&models.ListMovieUserData{
+				ListID:  listID,
+				MovieID: item.MovieID,
+				UserID:  userID,
+				Rating:  item.Rating,
+				Notes:   item.Notes,
+			}).Error
+		}
+		if err != nil {
+			return err
+		}
+		if data.Rating != nil && data.Notes != nil {
+			return nil
+		}
+		if data.Rating == nil {
+			data.Rating = item.Rating
+		}
+		if data.Notes == nil {
+			data.Notes = item.Notes
+		}
+		return tx.Omit("User").Save(&data).Error
+	})
+	return created, err
+}
+
+func createImportedListMovieTx(tx *gorm.DB, listID, userID int64, item ImportedListMovie) error {
+	// A removed copy waiting for the purger is replaced, as when adding the title by hand
+	var removed int64
+	if err := tx.Model(&models.ListMovie{}).
+		Where("list_id = ? AND movie_id = ? AND removed_at IS NOT NULL", listID, item.MovieID).
+		Count(&removed).Error; err != nil {
+		return err
+	}
+	if removed > 0 {
+		if err := purgeListMovieTx(tx, listID, item.MovieID); err != nil {
+			return err
+		}
+	}
+
+	// Imported titles go after the list's own, in the order of the file
+	var last *int
+	if err := tx.Model(&models.ListMovie{}).
+		Where("list_id = ?", listID).
+		Select("MAX(display_order)").
+		Scan(&last).Error; err != nil {
+		return err
+	}
+	order := 0
+	if last != nil {
+		order = *last + 1
+	}
+
+	rec := &models.ListMovie{
+		ListID:       listID,
+		MovieID:      item.MovieID,
+		Status:       item.Status,
+		AddedBy:      &userID,
+		DisplayOrder: &order,
+	}
+	if item.Status != models.StatusNotWatched {
+		now := time.Now().UTC()
+		rec.StatusChangedAt = &now
+		rec.StatusChangedBy = &userID
+	}
+	if item.Status == models.StatusWatched {
+		watchedAt := time.Now().UTC()
+		if item.WatchedAt != nil {
+			watchedAt = *item.WatchedAt
+		}
+		rec.WatchedAt = &watchedAt
+	}
+	if err := tx.Create(rec).Error; err != nil {
+		return err
+	}
+	title, err := movieTitleTx(tx, item.MovieID)
+	if err != nil {
+		return err
+	}
+	return recordListEventTx(tx, listID, &userID, models.EventMovieAdded, models.EventTargetMovie, item.MovieID,
+		nil, listEventValue{"title": title, "status": item.Status, "imported": true})
+}
@@ -0,0 +1,90 @@
+package daos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartImportAllowsOneRunningImport(t *testing.T) {
+	db := openTestDB(t)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+	dao := NewMovieListDAO(db)
+
+	first := &models.ListImport{ListID: list.ID, UserID: owner.ID, Format: models.ImportLetterboxd, Status: models.ImportRunning}
+	started, err := dao.StartImport(first)
+	assert.NoError(t, err)
+	assert.True(t, started)
+	assert.NotZero(t, first.ID)
+
+	second := &models.ListImport{ListID: list.ID, UserID: owner.ID, Format: models.ImportLetterboxd, Status: models.ImportRunning}
+	started, err = dao.StartImport(second)
+	assert.NoError(t, err)
+	assert.False(t, started)
+	assert.Zero(t, second.ID)
+
+	// A running import that stopped saving progress no longer blocks the list
+	assert.NoError(t, db.Model(&models.ListImport{}).Where("id = ?", first.ID).
+		UpdateColumn("updated_at", time.Now().UTC().Add(-staleImportAfter-time.Minute)).Error)
+	started, err = dao.StartImport(second)
+	assert.NoError(t, err)
+	assert.True(t, started)
+
+	stale, err := dao.FindImport(list.ID, first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportFailed, stale.Status)
+}
+
+func TestImportListMovieOnlyRecordsTheMembersOwnWatch(t *testing.T) {
+	db := openTestDB(t)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+	listed := seedListMovie(t, db, list, owner)
+	dao := NewMovieListDAO(db)
+
+	// A list2gether export says the list watched it, not the member
+	_, err := dao.ImportListMovie(list.ID, owner.ID, ImportedListMovie{MovieID: listed.MovieID, Status: models.StatusWatched})
+	assert.NoError(t, err)
+	var watches int64
+	assert.NoError(t, db.Model(&models.ListMovieWatch{}).Where("list_id = ? AND movie_id = ?", list.ID, listed.MovieID).Count(&watches).Error)
+	assert.Zero(t, watches)
+
+	_, err = dao.ImportListMovie(list.ID, owner.ID, ImportedListMovie{MovieID: listed.MovieID, Status: models.StatusWatched, Watched: true})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Model(&models.ListMovieWatch{}).Where("list_id = ? AND movie_id = ? AND user_id = ?", list.ID, listed.MovieID, owner.ID).Count(&watches).Error)
+	assert.Equal(t, int64(1), watches)
+}
+
+func TestFindListMoviesForExportPagesByOrderAndID(t *testing.T) {
+	db := openTestDB(t)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+	var want []int64
+	for i := 0; i < 5; i++ {
+		want = append(want, seedListMovie(t, db, list, owner).ID)
+	}
+	dao := NewMovieListDAO(db)
+
+	var got []int64
+	var afterOrder *int
+	var afterID int64
+	for {
+		page, err := dao.FindListMoviesForExport(list.ID, afterOrder, afterID, 2)
+		assert.NoError(t, err)
+		for _, lm := range page {
+			got = append(got, lm.ID)
+		}
+		if len(page) < 2 {
+			break
+		}
+		afterOrder, afterID = page[len(page)-1].DisplayOrder, page[len(page)-1].ID
+		// Removing a title already exported does not make the next page skip one
+		if len(got) == 2 {
+			assert.NoError(t, dao.RemoveMovieFromList(list.ID, page[0].MovieID, owner.ID))
+		}
+	}
+	assert.ElementsMatch(t, want, got)
+}
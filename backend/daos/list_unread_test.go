@@ -0,0 +1,131 @@
+package daos
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// backdateMember makes the member's join date the baseline an hour ago, so anything
+// created during the test is newer than it
+func backdateMember(t *testing.T, db *gorm.DB, listID, userID int64) {
+	t.Helper()
+	if err := db.Model(&models.ListMember{}).Where("list_id = ? AND user_id = ?", listID, userID).
+		Update("added_at", time.Now().UTC().Add(-time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// moveReadMarker sets the read marker to a given time, since markers written with MarkRead
+// and rows created right after could share a timestamp
+func moveReadMarker(t *testing.T, db *gorm.DB, listID, userID, movieID int64, at time.Time) {
+	t.Helper()
+	if err := db.Model(&models.ListReadMarker{}).Where("list_id = ? AND user_id = ? AND movie_id = ?", listID, userID, movieID).
+		Update("read_at", at).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCountUnreadBatch(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner, friend := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, friend)
+	backdateMember(t, db, list.ID, owner.ID)
+	backdateMember(t, db, list.ID, friend.ID)
+
+	movieID := seedListMovie(t, db, list, owner).MovieID
+	seedListMovie(t, db, list, friend)
+	watched := models.StatusWatched
+	_, err := dao.UpdateMovie(list.ID, movieID, &watched, owner.ID)
+	assert.NoError(t, err)
+
+	_, err = dao.CreateComment(list.ID, &movieID, owner.ID, nil, "Que final", false, nil)
+	assert.NoError(t, err)
+	_, err = dao.CreateComment(list.ID, nil, owner.ID, nil, "Sexta?", false, nil)
+	assert.NoError(t, err)
+	_, err = dao.CreateComment(list.ID, &movieID, friend.ID, nil, "Meu próprio", false, nil)
+	assert.NoError(t, err)
+	deleted, err := dao.CreateComment(list.ID, &movieID, owner.ID, nil, "Apagado", false, nil)
+	assert.NoError(t, err)
+	_, err = dao.DeleteComment(deleted.ID, owner.ID)
+	assert.NoError(t, err)
+	hidden, err := dao.CreateComment(list.ID, &movieID, owner.ID, nil, "Oculto", false, nil)
+	assert.NoError(t, err)
+	_, err = dao.ModerateComment(&models.CommentModerationLog{ListID: list.ID, CommentID: &hidden.ID, ModeratorID: owner.ID, AuthorID: owner.ID, Action: models.ModerationHide})
+	assert.NoError(t, err)
+
+	counts, err := dao.CountUnreadBatch(friend.ID, []int64{list.ID})
+	assert.NoError(t, err)
+	// The friend's own title and comment do not count, nor do deleted or hidden comments
+	assert.Equal(t, models.UnreadCounts{Comments: 2, AddedTitles: 1, StatusChanges: 1}, counts[list.ID])
+
+	// Each member only counts the others' activity
+	counts, err = dao.CountUnreadBatch(owner.ID, []int64{list.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), counts[list.ID].Comments)
+
+	assert.NoError(t, dao.MarkRead(list.ID, friend.ID, 0))
+	moveReadMarker(t, db, list.ID, friend.ID, 0, time.Now().UTC().Add(time.Minute))
+	counts, err = dao.CountUnreadBatch(friend.ID, []int64{list.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, models.UnreadCounts{}, counts[list.ID])
+}
+
+func TestCountUnreadCommentsByMovie(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner, friend := seedUser(t, db), seedUser(t, db)
+	list := seedList(t, db, owner, friend)
+	backdateMember(t, db, list.ID, friend.ID)
+	first := seedListMovie(t, db, list, owner).MovieID
+	second := seedListMovie(t, db, list, owner).MovieID
+
+	for _, movieID := range []int64{first, first, second} {
+		movieID := movieID
+		_, err := dao.CreateComment(list.ID, &movieID, owner.ID, nil, "Comentário", false, nil)
+		assert.NoError(t, err)
+	}
+	_, err := dao.CreateComment(list.ID, &first, friend.ID, nil, "Meu", false, nil)
+	assert.NoError(t, err)
+
+	unread, err := dao.CountUnreadCommentsByMovie(list.ID, friend.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{first: 2, second: 1}, unread)
+
+	// Reading one thread leaves the others unread
+	assert.NoError(t, dao.MarkRead(list.ID, friend.ID, first))
+	moveReadMarker(t, db, list.ID, friend.ID, first, time.Now().UTC().Add(time.Minute))
+	unread, err = dao.CountUnreadCommentsByMovie(list.ID, friend.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int64]int64{second: 1}, unread)
+
+	// Reading the list covers every thread in it
+	assert.NoError(t, dao.MarkRead(list.ID, friend.ID, 0))
+	moveReadMarker(t, db, list.ID, friend.ID, 0, time.Now().UTC().Add(time.Minute))
+	unread, err = dao.CountUnreadCommentsByMovie(list.ID, friend.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, unread)
+}
+
+func TestMarkReadAdvancesMarker(t *testing.T) {
+	db := openTestDB(t)
+	dao := NewMovieListDAO(db)
+	owner := seedUser(t, db)
+	list := seedList(t, db, owner)
+
+	assert.NoError(t, dao.MarkRead(list.ID, owner.ID, 0))
+	old := time.Now().UTC().Add(-24 * time.Hour)
+	moveReadMarker(t, db, list.ID, owner.ID, 0, old)
+
+	assert.NoError(t, dao.MarkRead(list.ID, owner.ID, 0))
+
+	var markers []models.ListReadMarker
+	assert.NoError(t, db.Where("list_id = ? AND user_id = ?", list.ID, owner.ID).Find(&markers).Error)
+	if assert.Len(t, markers, 1) {
+		assert.True(t, markers[0].ReadAt.After(old.Add(time.Hour)))
+	}
+}
@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMovieDAO is a mock implementation of MovieDAO interface.
+type MockMovieDAO struct {
+	mock.Mock
+}
+
+// FindByIDAndType mocks the FindByIDAndType method.
+func (m *MockMovieDAO) FindByIDAndType(id int64, mediaType string) (*models.Movie, error) {
+	args := m.Called(id, mediaType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Movie), args.Error(1)
+}
+
+// FindByID mocks the FindByID method.
+func (m *MockMovieDAO) FindByID(id int64) (*models.Movie, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Movie), args.Error(1)
+}
+
+// FindByIMDbID mocks the FindByIMDbID method.
+func (m *MockMovieDAO) FindByIMDbID(imdbID string) (*models.Movie, error) {
+	args := m.Called(imdbID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Movie), args.Error(1)
+}
+
+// SetIMDbID mocks the SetIMDbID method.
+func (m *MockMovieDAO) SetIMDbID(id int64, imdbID string) error {
+	args := m.Called(id, imdbID)
+	return args.Error(0)
+}
+
+// CreateMovieWithGenres mocks the CreateMovieWithGenres method.
+func (m *MockMovieDAO) CreateMovieWithGenres(movie *models.Movie, genres []models.Genre) error {
+	args := m.Called(movie, genres)
+	return args.Error(0)
+}
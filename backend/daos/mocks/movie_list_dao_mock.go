@@ -0,0 +1,509 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockMovieListDAO is a mock implementation of MovieListDAO interface.
+type MockMovieListDAO struct {
+	mock.Mock
+}
+
+// InviteCodeExists mocks the InviteCodeExists method.
+func (m *MockMovieListDAO) InviteCodeExists(code string) (bool, error) {
+	args := m.Called(code)
+	return args.Bool(0), args.Error(1)
+}
+
+// CreateWithOwner mocks the CreateWithOwner method.
+func (m *MockMovieListDAO) CreateWithOwner(list *models.MovieList, ownerUserID int64) error {
+	args := m.Called(list, ownerUserID)
+	return args.Error(0)
+}
+
+// FindByIDWithCreator mocks the FindByIDWithCreator method.
+func (m *MockMovieListDAO) FindByIDWithCreator(id int64) (*models.MovieList, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MovieList), args.Error(1)
+}
+
+// FindByInviteCodeWithCreator mocks the FindByInviteCodeWithCreator method.
+func (m *MockMovieListDAO) FindByInviteCodeWithCreator(code string) (*models.MovieList, error) {
+	args := m.Called(code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MovieList), args.Error(1)
+}
+
+// FindMembership mocks the FindMembership method.
+func (m *MockMovieListDAO) FindMembership(listID, userID int64) (*models.ListMember, error) {
+	args := m.Called(listID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListMember), args.Error(1)
+}
+
+// AddParticipantIfNotExists mocks the AddParticipantIfNotExists method.
+func (m *MockMovieListDAO) AddParticipantIfNotExists(listID, userID int64) (bool, error) {
+	args := m.Called(listID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+// CountMembers mocks the CountMembers method.
+func (m *MockMovieListDAO) CountMembers(listID int64) (int64, error) {
+	args := m.Called(listID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// FindMembersWithUser mocks the FindMembersWithUser method.
+func (m *MockMovieListDAO) FindMembersWithUser(listID int64) ([]models.ListMember, error) {
+	args := m.Called(listID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMember), args.Error(1)
+}
+
+// FindByID mocks the FindByID method.
+func (m *MockMovieListDAO) FindByID(id int64) (*models.MovieList, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MovieList), args.Error(1)
+}
+
+// DeleteListCascadeIfOwner mocks the DeleteListCascadeIfOwner method.
+func (m *MockMovieListDAO) DeleteListCascadeIfOwner(listID, userID int64) error {
+	args := m.Called(listID, userID)
+	return args.Error(0)
+}
+
+// FindUserMemberships mocks the FindUserMemberships method.
+func (m *MockMovieListDAO) FindUserMemberships(userID int64, role *models.ListMemberRole, limit int, offset int) ([]models.ListMember, error) {
+	args := m.Called(userID, role, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMember), args.Error(1)
+}
+
+// CountUserMemberships mocks the CountUserMemberships method.
+func (m *MockMovieListDAO) CountUserMemberships(userID int64, role *models.ListMemberRole) (int64, error) {
+	args := m.Called(userID, role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// CountMembersBatch mocks the CountMembersBatch method.
+func (m *MockMovieListDAO) CountMembersBatch(listIDs []int64) (map[int64]int64, error) {
+	args := m.Called(listIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]int64), args.Error(1)
+}
+
+// CountMoviesBatch mocks the CountMoviesBatch method.
+func (m *MockMovieListDAO) CountMoviesBatch(listIDs []int64) (map[int64]int64, error) {
+	args := m.Called(listIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]int64), args.Error(1)
+}
+
+// CountUnreadBatch mocks the CountUnreadBatch method.
+func (m *MockMovieListDAO) CountUnreadBatch(userID int64, listIDs []int64) (map[int64]models.UnreadCounts, error) {
+	args := m.Called(userID, listIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]models.UnreadCounts), args.Error(1)
+}
+
+// CountUnreadCommentsByMovie mocks the CountUnreadCommentsByMovie method.
+func (m *MockMovieListDAO) CountUnreadCommentsByMovie(listID, userID int64) (map[int64]int64, error) {
+	args := m.Called(listID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[int64]int64), args.Error(1)
+}
+
+// MarkRead mocks the MarkRead method.
+func (m *MockMovieListDAO) MarkRead(listID, userID, movieID int64) error {
+	args := m.Called(listID, userID, movieID)
+	return args.Error(0)
+}
+
+// ListMovieExists mocks the ListMovieExists method.
+func (m *MockMovieListDAO) ListMovieExists(listID, movieID int64) (bool, error) {
+	args := m.Called(listID, movieID)
+	return args.Bool(0), args.Error(1)
+}
+
+// AddMovieToList mocks the AddMovieToList method.
+func (m *MockMovieListDAO) AddMovieToList(listID, movieID int64, addedBy *int64) (*models.ListMovie, error) {
+	args := m.Called(listID, movieID, addedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListMovie), args.Error(1)
+}
+
+// AddSeenMovie mocks the AddSeenMovie method.
+func (m *MockMovieListDAO) AddSeenMovie(listID, movieID, userID int64, feedback *models.RecommendationFeedback) (*models.ListMovie, bool, *models.MovieStatus, error) {
+	args := m.Called(listID, movieID, userID, feedback)
+	var r0 *models.ListMovie
+	if v := args.Get(0); v != nil {
+		r0 = v.(*models.ListMovie)
+	}
+	var r2 *models.MovieStatus
+	if v := args.Get(2); v != nil {
+		r2 = v.(*models.MovieStatus)
+	}
+	return r0, args.Bool(1), r2, args.Error(3)
+}
+
+// FindListMovieByListAndMovie mocks the FindListMovieByListAndMovie method.
+func (m *MockMovieListDAO) FindListMovieByListAndMovie(listID, movieID int64) (*models.ListMovie, error) {
+	args := m.Called(listID, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListMovie), args.Error(1)
+}
+
+// RemoveMovieFromList mocks the RemoveMovieFromList method.
+func (m *MockMovieListDAO) RemoveMovieFromList(listID, movieID, removedBy int64) error {
+	args := m.Called(listID, movieID, removedBy)
+	return args.Error(0)
+}
+
+// FindRemovedListMovies mocks the FindRemovedListMovies method.
+func (m *MockMovieListDAO) FindRemovedListMovies(listID int64, since time.Time) ([]models.ListMovie, error) {
+	args := m.Called(listID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMovie), args.Error(1)
+}
+
+// RestoreListMovie mocks the RestoreListMovie method.
+func (m *MockMovieListDAO) RestoreListMovie(listID, movieID, restoredBy int64) (*models.ListMovie, error) {
+	args := m.Called(listID, movieID, restoredBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListMovie), args.Error(1)
+}
+
+// PurgeRemovedListMovies mocks the PurgeRemovedListMovies method.
+func (m *MockMovieListDAO) PurgeRemovedListMovies(before time.Time) (int64, error) {
+	args := m.Called(before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// UpdateMovie mocks the UpdateMovie method.
+func (m *MockMovieListDAO) UpdateMovie(listID, movieID int64, status *models.MovieStatus, changedBy int64) (*models.ListMovie, error) {
+	args := m.Called(listID, movieID, status, changedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListMovie), args.Error(1)
+}
+
+// UpsertMovieUserData mocks the UpsertMovieUserData method.
+func (m *MockMovieListDAO) UpsertMovieUserData(listID, movieID, userID int64, rating *int, ratingProvided bool) (*models.ListMovieUserData, error) {
+	args := m.Called(listID, movieID, userID, rating, ratingProvided)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListMovieUserData), args.Error(1)
+}
+
+// FindMovieUserData mocks the FindMovieUserData method.
+func (m *MockMovieListDAO) FindMovieUserData(listID, movieID, userID int64) (*models.ListMovieUserData, error) {
+	args := m.Called(listID, movieID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListMovieUserData), args.Error(1)
+}
+
+// SetMovieWatched mocks the SetMovieWatched method.
+func (m *MockMovieListDAO) SetMovieWatched(listID, movieID, userID int64, watched bool) error {
+	args := m.Called(listID, movieID, userID, watched)
+	return args.Error(0)
+}
+
+// FindMovieWatches mocks the FindMovieWatches method.
+func (m *MockMovieListDAO) FindMovieWatches(listID, movieID int64) ([]models.ListMovieWatch, error) {
+	args := m.Called(listID, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMovieWatch), args.Error(1)
+}
+
+// GetMovieAverageRating mocks the GetMovieAverageRating method.
+func (m *MockMovieListDAO) GetMovieAverageRating(listID, movieID int64) (*float64, error) {
+	args := m.Called(listID, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*float64), args.Error(1)
+}
+
+// FindListMoviesWithMovie mocks the FindListMoviesWithMovie method.
+func (m *MockMovieListDAO) FindListMoviesWithMovie(listID int64, status *models.MovieStatus) ([]models.ListMovie, error) {
+	args := m.Called(listID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMovie), args.Error(1)
+}
+
+// FindListMovieWithMovie mocks the FindListMovieWithMovie method.
+func (m *MockMovieListDAO) FindListMovieWithMovie(listID, movieID int64) (*models.ListMovie, error) {
+	args := m.Called(listID, movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListMovie), args.Error(1)
+}
+
+// FindListMoviesForExport mocks the FindListMoviesForExport method.
+func (m *MockMovieListDAO) FindListMoviesForExport(listID int64, afterOrder *int, afterID int64, limit int) ([]models.ListMovie, error) {
+	args := m.Called(listID, afterOrder, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMovie), args.Error(1)
+}
+
+// FindMembersOtherListMovies mocks the FindMembersOtherListMovies method.
+func (m *MockMovieListDAO) FindMembersOtherListMovies(userIDs []int64, excludeListID int64, limit int) ([]models.ListMovie, error) {
+	args := m.Called(userIDs, excludeListID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMovie), args.Error(1)
+}
+
+// FindListsWithMovie mocks the FindListsWithMovie method.
+func (m *MockMovieListDAO) FindListsWithMovie(movieID int64) ([]models.MovieList, error) {
+	args := m.Called(movieID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.MovieList), args.Error(1)
+}
+
+// FindListsDueForDigest mocks the FindListsDueForDigest method.
+func (m *MockMovieListDAO) FindListsDueForDigest(coveredBefore time.Time) ([]models.MovieList, error) {
+	args := m.Called(coveredBefore)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.MovieList), args.Error(1)
+}
+
+// FindListMoviesAddedSince mocks the FindListMoviesAddedSince method.
+func (m *MockMovieListDAO) FindListMoviesAddedSince(listID int64, since time.Time) ([]models.ListMovie, error) {
+	args := m.Called(listID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMovie), args.Error(1)
+}
+
+// FindListMoviesWatchedSince mocks the FindListMoviesWatchedSince method.
+func (m *MockMovieListDAO) FindListMoviesWatchedSince(listID int64, since time.Time) ([]models.ListMovie, error) {
+	args := m.Called(listID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListMovie), args.Error(1)
+}
+
+// FindTopRatedListMovies mocks the FindTopRatedListMovies method.
+func (m *MockMovieListDAO) FindTopRatedListMovies(listID int64, limit int) ([]daos.RatedListMovie, error) {
+	args := m.Called(listID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]daos.RatedListMovie), args.Error(1)
+}
+
+// MarkDigestSent mocks the MarkDigestSent method.
+func (m *MockMovieListDAO) MarkDigestSent(listID int64, at time.Time) error {
+	args := m.Called(listID, at)
+	return args.Error(0)
+}
+
+// SearchListMoviesWithMovie mocks the SearchListMoviesWithMovie method.
+func (m *MockMovieListDAO) SearchListMoviesWithMovie(listID int64, query string, limit int, offset int) ([]models.ListMovie, int64, error) {
+	args := m.Called(listID, query, limit, offset)
+	var r0 []models.ListMovie
+	if v := args.Get(0); v != nil {
+		r0 = v.([]models.ListMovie)
+	}
+	return r0, args.Get(1).(int64), args.Error(2)
+}
+
+// UpdateMovieOrders mocks the UpdateMovieOrders method.
+func (m *MockMovieListDAO) UpdateMovieOrders(listID int64, orderMap map[int64]int, changedBy int64) error {
+	args := m.Called(listID, orderMap, changedBy)
+	return args.Error(0)
+}
+
+// RemoveMember mocks the RemoveMember method.
+func (m *MockMovieListDAO) RemoveMember(listID, userID int64) error {
+	args := m.Called(listID, userID)
+	return args.Error(0)
+}
+
+// CreateComment mocks the CreateComment method.
+func (m *MockMovieListDAO) CreateComment(listID int64, movieID *int64, userID int64, parentID *int64, content string, spoiler bool, mentions []models.CommentMention) (*models.Comment, error) {
+	args := m.Called(listID, movieID, userID, parentID, content, spoiler, mentions)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Comment), args.Error(1)
+}
+
+// FindComments mocks the FindComments method.
+func (m *MockMovieListDAO) FindComments(listID, movieID int64, limit, offset int) ([]models.Comment, int64, error) {
+	args := m.Called(listID, movieID, limit, offset)
+	var r0 []models.Comment
+	if v := args.Get(0); v != nil {
+		r0 = v.([]models.Comment)
+	}
+	return r0, args.Get(1).(int64), args.Error(2)
+}
+
+// FindBoardComments mocks the FindBoardComments method.
+func (m *MockMovieListDAO) FindBoardComments(listID int64, beforeCreatedAt *time.Time, beforeID int64, limit int) ([]models.Comment, error) {
+	args := m.Called(listID, beforeCreatedAt, beforeID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Comment), args.Error(1)
+}
+
+// FindCommentReplies mocks the FindCommentReplies method.
+func (m *MockMovieListDAO) FindCommentReplies(parentID int64, limit, offset int) ([]models.Comment, int64, error) {
+	args := m.Called(parentID, limit, offset)
+	var r0 []models.Comment
+	if v := args.Get(0); v != nil {
+		r0 = v.([]models.Comment)
+	}
+	return r0, args.Get(1).(int64), args.Error(2)
+}
+
+// FindCommentByID mocks the FindCommentByID method.
+func (m *MockMovieListDAO) FindCommentByID(commentID int64) (*models.Comment, error) {
+	args := m.Called(commentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Comment), args.Error(1)
+}
+
+// UpdateComment mocks the UpdateComment method.
+func (m *MockMovieListDAO) UpdateComment(commentID int64, content string, spoiler *bool, mentions []models.CommentMention) (*models.Comment, error) {
+	args := m.Called(commentID, content, spoiler, mentions)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Comment), args.Error(1)
+}
+
+// DeleteComment mocks the DeleteComment method.
+func (m *MockMovieListDAO) DeleteComment(commentID, deletedBy int64) (bool, error) {
+	args := m.Called(commentID, deletedBy)
+	return args.Bool(0), args.Error(1)
+}
+
+// FindCommentRevisions mocks the FindCommentRevisions method.
+func (m *MockMovieListDAO) FindCommentRevisions(commentID int64) ([]models.CommentRevision, error) {
+	args := m.Called(commentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CommentRevision), args.Error(1)
+}
+
+// ModerateComment mocks the ModerateComment method.
+func (m *MockMovieListDAO) ModerateComment(entry *models.CommentModerationLog) (*models.Comment, error) {
+	args := m.Called(entry)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Comment), args.Error(1)
+}
+
+// FindModerationLog mocks the FindModerationLog method.
+func (m *MockMovieListDAO) FindModerationLog(listID int64, limit, offset int) ([]models.CommentModerationLog, int64, error) {
+	args := m.Called(listID, limit, offset)
+	var r0 []models.CommentModerationLog
+	if v := args.Get(0); v != nil {
+		r0 = v.([]models.CommentModerationLog)
+	}
+	return r0, args.Get(1).(int64), args.Error(2)
+}
+
+// FindListEvents mocks the FindListEvents method.
+func (m *MockMovieListDAO) FindListEvents(listID int64, beforeID int64, limit int) ([]models.ListEvent, error) {
+	args := m.Called(listID, beforeID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ListEvent), args.Error(1)
+}
+
+// StartImport mocks the StartImport method.
+func (m *MockMovieListDAO) StartImport(job *models.ListImport) (bool, error) {
+	args := m.Called(job)
+	return args.Bool(0), args.Error(1)
+}
+
+// SaveImportProgress mocks the SaveImportProgress method.
+func (m *MockMovieListDAO) SaveImportProgress(job *models.ListImport) error {
+	args := m.Called(job)
+	return args.Error(0)
+}
+
+// FindImport mocks the FindImport method.
+func (m *MockMovieListDAO) FindImport(listID, importID int64) (*models.ListImport, error) {
+	args := m.Called(listID, importID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ListImport), args.Error(1)
+}
+
+// ImportListMovie mocks the ImportListMovie method.
+func (m *MockMovieListDAO) ImportListMovie(listID, userID int64, item daos.ImportedListMovie) (bool, error) {
+	args := m.Called(listID, userID, item)
+	return args.Bool(0), args.Error(1)
+}
+
+// ApplyBulkOperation mocks the ApplyBulkOperation method.
+func (m *MockMovieListDAO) ApplyBulkOperation(listID, userID int64, op daos.BulkListMovieOperation) ([]models.BulkItemResult, error) {
+	args := m.Called(listID, userID, op)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BulkItemResult), args.Error(1)
+}
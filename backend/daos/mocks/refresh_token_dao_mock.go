@@ -59,3 +59,12 @@ func (m *MockRefreshTokenDAO) RevokeAllByUserID(userID int64) error {
 	args := m.Called(userID)
 	return args.Error(0)
 }
+
+// FindByUserID mocks the FindByUserID method.
+func (m *MockRefreshTokenDAO) FindByUserID(userID int64) ([]models.RefreshToken, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RefreshToken), args.Error(1)
+}
@@ -43,8 +43,29 @@ func (m *MockUserDAO) FindByID(id int64) (*models.User, error) {
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+// IsActive mocks the IsActive method.
+func (m *MockUserDAO) IsActive(id int64) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
 // Update mocks the Update method.
 func (m *MockUserDAO) Update(user *models.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
+
+// FindGenrePreferences mocks the FindGenrePreferences method.
+func (m *MockUserDAO) FindGenrePreferences(userIDs []int64) ([]models.UserGenrePreference, error) {
+	args := m.Called(userIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UserGenrePreference), args.Error(1)
+}
+
+// ReplaceGenrePreferences mocks the ReplaceGenrePreferences method.
+func (m *MockUserDAO) ReplaceGenrePreferences(userID int64, genreIDs []int64) error {
+	args := m.Called(userID, genreIDs)
+	return args.Error(0)
+}
@@ -9,6 +9,8 @@ import (
 type MovieDAO interface {
 	FindByIDAndType(id int64, mediaType string) (*models.Movie, error)
 	FindByID(id int64) (*models.Movie, error)
+	FindByIMDbID(imdbID string) (*models.Movie, error)
+	SetIMDbID(id int64, imdbID string) error
 	CreateMovieWithGenres(movie *models.Movie, genres []models.Genre) error
 }
 
@@ -36,6 +38,21 @@ func (d *movieDAO) FindByID(id int64) (*models.Movie, error) {
 	return &m, nil
 }
 
+func (d *movieDAO) FindByIMDbID(imdbID string) (*models.Movie, error) {
+	var m models.Movie
+	if err := d.db.Where("imdb_id = ?", imdbID).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SetIMDbID fills in the IMDb id of a title stored before ids were recorded
+func (d *movieDAO) SetIMDbID(id int64, imdbID string) error {
+	return d.db.Model(&models.Movie{}).
+		Where("id = ? AND imdb_id IS NULL", id).
+		UpdateColumn("imdb_id", imdbID).Error
+}
+
 func (d *movieDAO) CreateMovieWithGenres(movie *models.Movie, genres []models.Genre) error {
 	return d.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(movie).Error; err != nil {
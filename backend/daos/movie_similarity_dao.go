@@ -0,0 +1,82 @@
+package daos
+
+import (
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+// ListMovieSignal is one title in one list together with the list's average rating for it
+type ListMovieSignal struct {
+	ListID    int64
+	MovieID   int64
+	AvgRating *float64
+}
+
+type MovieSimilarityDAO interface {
+	FindListMovieSignals() ([]ListMovieSignal, error)
+	ReplaceAll(similarities []models.MovieSimilarity) error
+	FindSimilar(movieIDs []int64, perMovie int) ([]models.MovieSimilarity, error)
+}
+
+type movieSimilarityDAO struct {
+	db *gorm.DB
+}
+
+func NewMovieSimilarityDAO(db *gorm.DB) MovieSimilarityDAO {
+	return &movieSimilarityDAO{db: db}
+}
+
+func (d *movieSimilarityDAO) FindListMovieSignals() ([]ListMovieSignal, error) {
+	var rows []ListMovieSignal
+	if err := d.db.Table("list_movies").
+		Select("list_movies.list_id AS list_id, list_movies.movie_id AS movie_id, AVG(list_movie_user_data.rating) AS avg_rating").
+		Joins("JOIN movie_lists ON movie_lists.id = list_movies.list_id AND movie_lists.deleted_at IS NULL").
+		Joins("LEFT JOIN list_movie_user_data ON list_movie_user_data.list_id = list_movies.list_id AND list_movie_user_data.movie_id = list_movies.movie_id AND list_movie_user_data.rating IS NOT NULL").
+		Where("list_movies.removed_at IS NULL").
+		Group("list_movies.list_id, list_movies.movie_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (d *movieSimilarityDAO) ReplaceAll(similarities []models.MovieSimilarity) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).
+			Delete(&models.MovieSimilarity{}).Error; err != nil {
+			return err
+		}
+		if len(similarities) == 0 {
+			return nil
+		}
+		return tx.Omit("SimilarMovie").CreateInBatches(similarities, 500).Error
+	})
+}
+
+func (d *movieSimilarityDAO) FindSimilar(movieIDs []int64, perMovie int) ([]models.MovieSimilarity, error) {
+	if len(movieIDs) == 0 {
+		return nil, nil
+	}
+	var similarities []models.MovieSimilarity
+	if err := d.db.Preload("SimilarMovie").
+		Preload("SimilarMovie.Genres").
+		Where("movie_id IN ?", movieIDs).
+		Order("movie_id ASC, score DESC").
+		Find(&similarities).Error; err != nil {
+		return nil, err
+	}
+	if perMovie <= 0 {
+		return similarities, nil
+	}
+
+	result := make([]models.MovieSimilarity, 0, len(similarities))
+	counts := make(map[int64]int)
+	for _, sim := range similarities {
+		if counts[sim.MovieID] >= perMovie {
+			continue
+		}
+		counts[sim.MovieID]++
+		result = append(result, sim)
+	}
+	return result, nil
+}
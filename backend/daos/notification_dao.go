@@ -0,0 +1,132 @@
+package daos
+
+import (
+	"errors"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationDAO interface {
+	CreateBatch(notifications []models.Notification) error
+	FindByUser(userID int64, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error)
+	CountUnread(userID int64) (int64, error)
+	MarkRead(userID, notificationID int64) (bool, error)
+	MarkAllRead(userID int64) (int64, error)
+	FindPreferences(userID int64, channel models.NotificationChannel) ([]models.NotificationPreference, error)
+	UpsertPreferences(preferences []models.NotificationPreference) error
+	FindOptedOutUsers(userIDs []int64, notificationType models.NotificationType, channel models.NotificationChannel) ([]int64, error)
+}
+
+type notificationDAO struct {
+	db *gorm.DB
+}
+
+func NewNotificationDAO(db *gorm.DB) NotificationDAO {
+	return &notificationDAO{db: db}
+}
+
+func (d *notificationDAO) CreateBatch(notifications []models.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return d.db.Omit("User", "Actor").Create(&notifications).Error
+}
+
+func (d *notificationDAO) FindByUser(userID int64, unreadOnly bool, limit, offset int) ([]models.Notification, int64, error) {
+	scope := func(q *gorm.DB) *gorm.DB {
+		q = q.Where("user_id = ?", userID)
+		if unreadOnly {
+			q = q.Where("read_at IS NULL")
+		}
+		return q
+	}
+
+	var total int64
+	if err := d.db.Model(&models.Notification{}).Scopes(scope).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []models.Notification
+	if err := d.db.Scopes(scope).
+		Preload("Actor").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (d *notificationDAO) CountUnread(userID int64) (int64, error) {
+	var count int64
+	if err := d.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications as read and reports whether it exists
+func (d *notificationDAO) MarkRead(userID, notificationID int64) (bool, error) {
+	var notification models.Notification
+	if err := d.db.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if notification.IsRead() {
+		return true, nil
+	}
+	if err := d.db.Model(&models.Notification{}).
+		Where("id = ?", notificationID).
+		Update("read_at", time.Now().UTC()).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and returns how many
+func (d *notificationDAO) MarkAllRead(userID int64) (int64, error) {
+	res := d.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now().UTC())
+	return res.RowsAffected, res.Error
+}
+
+func (d *notificationDAO) FindPreferences(userID int64, channel models.NotificationChannel) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	if err := d.db.Where("user_id = ? AND channel = ?", userID, channel).
+		Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (d *notificationDAO) UpsertPreferences(preferences []models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return d.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&preferences).Error
+}
+
+// FindOptedOutUsers returns the users among userIDs who turned the type off for the channel
+func (d *notificationDAO) FindOptedOutUsers(userIDs []int64, notificationType models.NotificationType, channel models.NotificationChannel) ([]int64, error) {
+	var optedOut []int64
+	if len(userIDs) == 0 {
+		return optedOut, nil
+	}
+	if err := d.db.Model(&models.NotificationPreference{}).
+		Where("user_id IN ? AND type = ? AND channel = ? AND enabled = ?", userIDs, notificationType, channel, false).
+		Pluck("user_id", &optedOut).Error; err != nil {
+		return nil, err
+	}
+	return optedOut, nil
+}
@@ -0,0 +1,75 @@
+package daos
+
+import (
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PushSubscriptionDAO interface {
+	Upsert(subscription *models.PushSubscription) error
+	FindByUser(userID int64) ([]models.PushSubscription, error)
+	FindByUsers(userIDs []int64) ([]models.PushSubscription, error)
+	DeleteForUser(userID, subscriptionID int64) (bool, error)
+	DeleteByEndpointHash(endpointHash string) error
+	MarkUsed(subscriptionID int64, at time.Time) error
+}
+
+type pushSubscriptionDAO struct {
+	db *gorm.DB
+}
+
+func NewPushSubscriptionDAO(db *gorm.DB) PushSubscriptionDAO {
+	return &pushSubscriptionDAO{db: db}
+}
+
+// Upsert stores the subscription. A browser keeps its endpoint across logins, so an
+// existing endpoint moves to the subscribing user with the new keys.
+func (d *pushSubscriptionDAO) Upsert(subscription *models.PushSubscription) error {
+	if err := d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint_hash"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "device_name", "user_agent", "updated_at"}),
+	}).Create(subscription).Error; err != nil {
+		return err
+	}
+	return d.db.Where("endpoint_hash = ?", subscription.EndpointHash).First(subscription).Error
+}
+
+func (d *pushSubscriptionDAO) FindByUser(userID int64) ([]models.PushSubscription, error) {
+	var subscriptions []models.PushSubscription
+	if err := d.db.Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (d *pushSubscriptionDAO) FindByUsers(userIDs []int64) ([]models.PushSubscription, error) {
+	var subscriptions []models.PushSubscription
+	if len(userIDs) == 0 {
+		return subscriptions, nil
+	}
+	if err := d.db.Where("user_id IN ?", userIDs).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// DeleteForUser removes one of the user's subscriptions and reports whether it existed
+func (d *pushSubscriptionDAO) DeleteForUser(userID, subscriptionID int64) (bool, error) {
+	res := d.db.Where("id = ? AND user_id = ?", subscriptionID, userID).Delete(&models.PushSubscription{})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (d *pushSubscriptionDAO) DeleteByEndpointHash(endpointHash string) error {
+	return d.db.Where("endpoint_hash = ?", endpointHash).Delete(&models.PushSubscription{}).Error
+}
+
+func (d *pushSubscriptionDAO) MarkUsed(subscriptionID int64, at time.Time) error {
+	return d.db.Model(&models.PushSubscription{}).
+		Where("id = ?", subscriptionID).
+		UpdateColumn("last_used_at", at).Error
+}
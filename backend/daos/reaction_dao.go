@@ -0,0 +1,94 @@
+package daos
+
+import (
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionDAO interface {
+	Toggle(reaction *models.Reaction) (bool, error)
+	FindSummaries(targetType models.ReactionTargetType, targetIDs []int64) (map[int64][]models.ReactionSummary, error)
+}
+
+type reactionDAO struct {
+	db *gorm.DB
+}
+
+func NewReactionDAO(db *gorm.DB) ReactionDAO {
+	return &reactionDAO{db: db}
+}
+
+// Toggle removes the reaction when the user already left it and adds it otherwise.
+// It reports whether the reaction was added.
+func (d *reactionDAO) Toggle(reaction *models.Reaction) (bool, error) {
+	added := false
+	err := d.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("target_type = ? AND target_id = ? AND user_id = ? AND kind = ?",
+			reaction.TargetType, reaction.TargetID, reaction.UserID, reaction.Kind).
+			Delete(&models.Reaction{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			return nil
+		}
+		added = true
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction).Error
+	})
+	return added, err
+}
+
+func (d *reactionDAO) FindSummaries(targetType models.ReactionTargetType, targetIDs []int64) (map[int64][]models.ReactionSummary, error) {
+	return findReactionSummaries(d.db, targetType, targetIDs)
+}
+
+// findReactionSummaries loads the reactions of many targets in one query and groups them
+// per target and kind, in display order
+func findReactionSummaries(db *gorm.DB, targetType models.ReactionTargetType, targetIDs []int64) (map[int64][]models.ReactionSummary, error) {
+	result := make(map[int64][]models.ReactionSummary, len(targetIDs))
+	if len(targetIDs) == 0 {
+		return result, nil
+	}
+
+	var reactions []models.Reaction
+	if err := db.Where("target_type = ? AND target_id IN ?", targetType, targetIDs).
+		Order("created_at ASC, id ASC").
+		Find(&reactions).Error; err != nil {
+		return nil, err
+	}
+
+	byTarget := make(map[int64]map[models.ReactionKind][]int64)
+	for _, r := range reactions {
+		kinds, ok := byTarget[r.TargetID]
+		if !ok {
+			kinds = make(map[models.ReactionKind][]int64)
+			byTarget[r.TargetID] = kinds
+		}
+		kinds[r.Kind] = append(kinds[r.Kind], r.UserID)
+	}
+
+	for targetID, kinds := range byTarget {
+		summaries := make([]models.ReactionSummary, 0, len(kinds))
+		for _, kind := range models.ReactionKinds {
+			userIDs, ok := kinds[kind]
+			if !ok {
+				continue
+			}
+			summaries = append(summaries, models.ReactionSummary{
+				Kind:    kind,
+				Emoji:   models.ReactionEmojis[kind],
+				Count:   int64(len(userIDs)),
+				UserIDs: userIDs,
+			})
+		}
+		result[targetID] = summaries
+	}
+	return result, nil
+}
+
+// deleteReactionsTx removes every reaction left on the given targets
+func deleteReactionsTx(tx *gorm.DB, targetType models.ReactionTargetType, targetIDs interface{}) error {
+	return tx.Where("target_type = ? AND target_id IN (?)", targetType, targetIDs).
+		Delete(&models.Reaction{}).Error
+}
@@ -0,0 +1,61 @@
+package daos
+
+import (
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RecommendationFeedbackDAO interface {
+	Upsert(feedback *models.RecommendationFeedback) error
+	FindByList(listID int64) ([]models.RecommendationFeedback, error)
+	Delete(listID, userID, movieID int64) (bool, error)
+}
+
+type recommendationFeedbackDAO struct {
+	db *gorm.DB
+}
+
+func NewRecommendationFeedbackDAO(db *gorm.DB) RecommendationFeedbackDAO {
+	return &recommendationFeedbackDAO{db: db}
+}
+
+func (d *recommendationFeedbackDAO) Upsert(feedback *models.RecommendationFeedback) error {
+	return upsertRecommendationFeedbackTx(d.db, feedback)
+}
+
+// upsertRecommendationFeedbackTx stores the member's feedback on the title, replacing
+// what they said about it before, and reloads it
+func upsertRecommendationFeedbackTx(tx *gorm.DB, feedback *models.RecommendationFeedback) error {
+	if err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "list_id"}, {Name: "user_id"}, {Name: "movie_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"action":     feedback.Action,
+			"media_type": feedback.MediaType,
+			"updated_at": time.Now(),
+		}),
+	}).Create(feedback).Error; err != nil {
+		return err
+	}
+	return tx.Where("list_id = ? AND user_id = ? AND movie_id = ?", feedback.ListID, feedback.UserID, feedback.MovieID).
+		First(feedback).Error
+}
+
+func (d *recommendationFeedbackDAO) FindByList(listID int64) ([]models.RecommendationFeedback, error) {
+	var feedback []models.RecommendationFeedback
+	if err := d.db.Where("list_id = ?", listID).Find(&feedback).Error; err != nil {
+		return nil, err
+	}
+	return feedback, nil
+}
+
+func (d *recommendationFeedbackDAO) Delete(listID, userID, movieID int64) (bool, error) {
+	tx := d.db.Where("list_id = ? AND user_id = ? AND movie_id = ?", listID, userID, movieID).
+		Delete(&models.RecommendationFeedback{})
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return tx.RowsAffected > 0, nil
+}
@@ -16,6 +16,7 @@ type RefreshTokenDAO interface {
 	RevokeFamily(familyID string) error
 	RevokeByHash(hash string) error
 	RevokeAllByUserID(userID int64) error
+	FindByUserID(userID int64) ([]models.RefreshToken, error)
 }
 
 type refreshTokenDAO struct {
@@ -80,3 +81,12 @@ func (d *refreshTokenDAO) RevokeAllByUserID(userID int64) error {
 		Where("user_id = ? AND is_revoked = ? AND expires_at > ?", userID, false, time.Now()).
 		Update("is_revoked", true).Error
 }
+
+// FindByUserID lists every session the user has signed in with, newest first
+func (d *refreshTokenDAO) FindByUserID(userID int64) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	if err := d.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
@@ -12,7 +12,10 @@ type UserDAO interface {
 	FindByEmail(email string) (*models.User, error)
 	FindByUsername(username string) (*models.User, error)
 	FindByID(id int64) (*models.User, error)
+	IsActive(id int64) (bool, error)
 	Update(user *models.User) error
+	FindGenrePreferences(userIDs []int64) ([]models.UserGenrePreference, error)
+	ReplaceGenrePreferences(userID int64, genreIDs []int64) error
 }
 
 type userDAO struct {
@@ -29,7 +32,7 @@ func (d *userDAO) Create(user *models.User) error {
 
 func (d *userDAO) FindByEmail(email string) (*models.User, error) {
 	var user models.User
-	err := d.db.Where("email = ?", email).First(&user).Error
+	err := d.db.Where("email = ? AND deleted_at IS NULL", email).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, gorm.ErrRecordNotFound
 	}
@@ -38,7 +41,7 @@ func (d *userDAO) FindByEmail(email string) (*models.User, error) {
 
 func (d *userDAO) FindByUsername(username string) (*models.User, error) {
 	var user models.User
-	err := d.db.Where("username = ?", username).First(&user).Error
+	err := d.db.Where("username = ? AND deleted_at IS NULL", username).First(&user).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, gorm.ErrRecordNotFound
 	}
@@ -54,6 +57,42 @@ func (d *userDAO) FindByID(id int64) (*models.User, error) {
 	return &user, err
 }
 
+// IsActive reports whether the user exists and has not deleted their account
+func (d *userDAO) IsActive(id int64) (bool, error) {
+	var count int64
+	err := d.db.Model(&models.User{}).Where("id = ? AND deleted_at IS NULL", id).Count(&count).Error
+	return count > 0, err
+}
+
 func (d *userDAO) Update(user *models.User) error {
 	return d.db.Save(user).Error
 }
+
+func (d *userDAO) FindGenrePreferences(userIDs []int64) ([]models.UserGenrePreference, error) {
+	var prefs []models.UserGenrePreference
+	if len(userIDs) == 0 {
+		return prefs, nil
+	}
+	if err := d.db.Where("user_id IN ?", userIDs).
+		Order("user_id ASC, genre_id ASC").
+		Find(&prefs).Error; err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+func (d *userDAO) ReplaceGenrePreferences(userID int64, genreIDs []int64) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserGenrePreference{}).Error; err != nil {
+			return err
+		}
+		if len(genreIDs) == 0 {
+			return nil
+		}
+		prefs := make([]models.UserGenrePreference, 0, len(genreIDs))
+		for _, id := range genreIDs {
+			prefs = append(prefs, models.UserGenrePreference{UserID: userID, GenreID: id})
+		}
+		return tx.Omit("User").Create(&prefs).Error
+	})
+}
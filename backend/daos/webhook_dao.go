@@ -0,0 +1,167 @@
+package daos
+
+import (
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+type WebhookDAO interface {
+	Create(webhook *models.ListWebhook) error
+	Update(webhook *models.ListWebhook) error
+	Delete(webhookID int64) error
+	FindByID(webhookID int64) (*models.ListWebhook, error)
+	FindByList(listID int64) ([]models.ListWebhook, error)
+	FindActiveByList(listID int64) ([]models.ListWebhook, error)
+	CountByList(listID int64) (int64, error)
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	FindDelivery(webhookID, deliveryID int64) (*models.WebhookDelivery, error)
+	FindDeliveries(webhookID int64, limit, offset int) ([]models.WebhookDelivery, int64, error)
+	FindDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error)
+	ClaimDelivery(delivery *models.WebhookDelivery, until time.Time) (bool, error)
+	SaveDeliveryAttempt(delivery *models.WebhookDelivery) error
+	PurgeDeliveries(before time.Time) (int64, error)
+}
+
+type webhookDAO struct {
+	db *gorm.DB
+}
+
+func NewWebhookDAO(db *gorm.DB) WebhookDAO {
+	return &webhookDAO{db: db}
+}
+
+func (d *webhookDAO) Create(webhook *models.ListWebhook) error {
+	return d.db.Create(webhook).Error
+}
+
+func (d *webhookDAO) Update(webhook *models.ListWebhook) error {
+	return d.db.Model(webhook).Select("url", "secret", "events", "active", "updated_at").Updates(webhook).Error
+}
+
+// Delete removes the webhook together with its delivery log
+func (d *webhookDAO) Delete(webhookID int64) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhookID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.ListWebhook{}, webhookID).Error
+	})
+}
+
+func (d *webhookDAO) FindByID(webhookID int64) (*models.ListWebhook, error) {
+	var webhook models.ListWebhook
+	if err := d.db.First(&webhook, webhookID).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (d *webhookDAO) FindByList(listID int64) ([]models.ListWebhook, error) {
+	var webhooks []models.ListWebhook
+	if err := d.db.Where("list_id = ?", listID).
+		Order("created_at ASC, id ASC").
+		Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (d *webhookDAO) FindActiveByList(listID int64) ([]models.ListWebhook, error) {
+	var webhooks []models.ListWebhook
+	if err := d.db.Where("list_id = ? AND active = ?", listID, true).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (d *webhookDAO) CountByList(listID int64) (int64, error) {
+	var count int64
+	err := d.db.Model(&models.ListWebhook{}).Where("list_id = ?", listID).Count(&count).Error
+	return count, err
+}
+
+func (d *webhookDAO) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return d.db.Create(&deliveries).Error
+}
+
+func (d *webhookDAO) FindDelivery(webhookID, deliveryID int64) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := d.db.Where("id = ? AND webhook_id = ?", deliveryID, webhookID).First(&delivery).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FindDeliveries returns a page of the webhook's delivery log, newest first
+func (d *webhookDAO) FindDeliveries(webhookID int64, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	var total int64
+	if err := d.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var deliveries []models.WebhookDelivery
+	if err := d.db.Where("webhook_id = ?", webhookID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// FindDueDeliveries returns pending deliveries whose next attempt is due, oldest
+// first, with their webhook
+func (d *webhookDAO) FindDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	if err := d.db.Preload("Webhook").
+		Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery pushes the delivery's next attempt to until, unless another worker
+// already did, so that only one instance sends it. It reports whether the claim won.
+func (d *webhookDAO) ClaimDelivery(delivery *models.WebhookDelivery, until time.Time) (bool, error) {
+	res := d.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, models.WebhookDeliveryPending, delivery.NextAttemptAt).
+		UpdateColumn("next_attempt_at", until)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	delivery.NextAttemptAt = &until
+	return true, nil
+}
+
+// SaveDeliveryAttempt stores the outcome of an attempt and when to retry, if at all
+func (d *webhookDAO) SaveDeliveryAttempt(delivery *models.WebhookDelivery) error {
+	return d.db.Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Updates(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"last_attempt_at": delivery.LastAttemptAt,
+			"response_status": delivery.ResponseStatus,
+			"last_error":      delivery.LastError,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+}
+
+// PurgeDeliveries deletes finished deliveries created before the given time
+func (d *webhookDAO) PurgeDeliveries(before time.Time) (int64, error) {
+	res := d.db.Where("status <> ? AND created_at < ?", models.WebhookDeliveryPending, before).
+		Delete(&models.WebhookDelivery{})
+	return res.RowsAffected, res.Error
+}
@@ -1,20 +1,78 @@
 package middleware
 
 import (
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// ActiveUsers tells whether an account still exists, so access tokens issued before the
+// account was deleted stop working at once instead of when they expire
+type ActiveUsers interface {
+	IsActive(id int64) (bool, error)
+}
+
+const (
+	// activeUserTTL is how long an account found active is trusted without asking again;
+	// deleting the account also revokes its refresh tokens, so it is signed out for good
+	// once this and the access token run out
+	activeUserTTL = 30 * time.Second
+	// activeUserSweepSize is how many cached accounts are kept before expired ones are
+	// dropped
+	activeUserSweepSize = 10000
+)
+
 type AuthMiddleware struct {
 	secret []byte
+	users  ActiveUsers
+	now    func() time.Time
+
+	mu sync.Mutex
+	// activeUntil holds, per account, until when it is known to be active. Deleted
+	// accounts never come back, so they are kept with a zero time.
+	activeUntil map[int64]time.Time
 }
 
-func NewAuthMiddleware(secret []byte) *AuthMiddleware {
-	return &AuthMiddleware{secret: secret}
+func NewAuthMiddleware(secret []byte, users ActiveUsers) *AuthMiddleware {
+	return &AuthMiddleware{secret: secret, users: users, now: time.Now, activeUntil: make(map[int64]time.Time)}
+}
+
+// isActive answers from the cache while it is fresh, so requests and stream reconnects
+// do not each cost a query
+func (m *AuthMiddleware) isActive(userID int64) (bool, error) {
+	now := m.now()
+	m.mu.Lock()
+	until, cached := m.activeUntil[userID]
+	m.mu.Unlock()
+	if cached && (until.IsZero() || now.Before(until)) {
+		return !until.IsZero(), nil
+	}
+
+	active, err := m.users.IsActive(userID)
+	if err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.activeUntil) >= activeUserSweepSize {
+		for id, until := range m.activeUntil {
+			if !until.IsZero() && !now.Before(until) {
+				delete(m.activeUntil, id)
+			}
+		}
+	}
+	if active {
+		m.activeUntil[userID] = now.Add(activeUserTTL)
+	} else {
+		m.activeUntil[userID] = time.Time{}
+	}
+	return active, nil
 }
 
 func (m *AuthMiddleware) Handler() gin.HandlerFunc {
@@ -61,6 +119,37 @@ func (m *AuthMiddleware) Handler() gin.HandlerFunc {
 			})
 			return
 		}
+		sub, _ := claims["sub"].(string)
+		userID, err := strconv.ParseInt(sub, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":     "Invalid token",
+				"code":      "TOKEN_INVALID",
+				"details":   []string{},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+		active, err := m.isActive(userID)
+		if err != nil {
+			log.Printf("auth active user check failed user=%d: %v", userID, err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error":     "Internal server error",
+				"code":      "INTERNAL_ERROR",
+				"details":   []string{},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":     "Account deleted",
+				"code":      "TOKEN_INVALID",
+				"details":   []string{},
+				"timestamp": time.Now().UTC().Format(time.RFC3339),
+			})
+			return
+		}
 		c.Set("auth_claims", claims)
 		c.Next()
 	}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+type activeUsers map[int64]bool
+
+func (a activeUsers) IsActive(id int64) (bool, error) {
+	return a[id], nil
+}
+
+// countingUsers reports every account active until deleted and counts the lookups
+type countingUsers struct {
+	deleted map[int64]bool
+	calls   int
+}
+
+func (u *countingUsers) IsActive(id int64) (bool, error) {
+	u.calls++
+	return !u.deleted[id], nil
+}
+
+func TestAuthMiddlewareRejectsDeletedAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	secret := []byte("test-secret")
+	router := gin.New()
+	router.GET("/me", NewAuthMiddleware(secret, activeUsers{1: true}).Handler(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	request := func(sub string) int {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":  sub,
+			"type": "access",
+			"exp":  time.Now().Add(time.Minute).Unix(),
+		}).SignedString(secret)
+		assert.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusNoContent, request("1"))
+	// The token is still valid, but the account behind it was deleted
+	assert.Equal(t, http.StatusUnauthorized, request("2"))
+	assert.Equal(t, http.StatusForbidden, request("not-a-number"))
+}
+
+func TestAuthMiddlewareCachesActiveCheck(t *testing.T) {
+	users := &countingUsers{deleted: map[int64]bool{}}
+	m := NewAuthMiddleware(nil, users)
+	now := time.Date(2024, 11, 9, 12, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		active, err := m.isActive(1)
+		assert.NoError(t, err)
+		assert.True(t, active)
+	}
+	assert.Equal(t, 1, users.calls)
+
+	// Once the cached answer is stale a deletion is seen
+	users.deleted[1] = true
+	now = now.Add(activeUserTTL)
+	active, err := m.isActive(1)
+	assert.NoError(t, err)
+	assert.False(t, active)
+	// A deleted account stays deleted without asking again
+	now = now.Add(time.Hour)
+	active, _ = m.isActive(1)
+	assert.False(t, active)
+	assert.Equal(t, 2, users.calls)
+}
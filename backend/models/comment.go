@@ -2,18 +2,97 @@ package models
 
 import "time"
 
+const (
+	// DeletedCommentPlaceholder is shown instead of a deleted comment that still has replies
+	DeletedCommentPlaceholder = "[deleted]"
+	// HiddenCommentPlaceholder is shown instead of a comment hidden by the list owner
+	HiddenCommentPlaceholder = "[hidden]"
+)
+
+// CommentDeletionReason says why a comment was deleted
+type CommentDeletionReason string
+
+const (
+	// CommentDeletedByAuthor is a comment its author took down
+	CommentDeletedByAuthor CommentDeletionReason = "author"
+	// CommentDeletedByModerator is a comment the list owner took down
+	CommentDeletedByModerator CommentDeletionReason = "moderator"
+	// CommentDeletedMemberLeft is a comment taken down because its author left the list
+	// or was removed from it; moderators may restore it
+	CommentDeletedMemberLeft CommentDeletionReason = "member_left"
+)
+
 type Comment struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
-	ListID    int64     `gorm:"not null;column:list_id;index:idx_comment_list_movie" json:"list_id"`
-	MovieID   int64     `gorm:"not null;column:movie_id;index:idx_comment_list_movie" json:"movie_id"`
-	UserID    int64     `gorm:"not null;column:user_id" json:"user_id"`
-	Content   string    `gorm:"type:text;not null;column:content" json:"content"`
-	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
-
-	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ID     int64 `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ListID int64 `gorm:"not null;column:list_id;index:idx_comment_list_movie" json:"list_id"`
+	// MovieID is nil for posts on the list's discussion board
+	MovieID   *int64     `gorm:"column:movie_id;index:idx_comment_list_movie" json:"movie_id"`
+	UserID    int64      `gorm:"not null;column:user_id" json:"user_id"`
+	ParentID  *int64     `gorm:"column:parent_id;index:idx_comment_parent" json:"parent_id"`
+	Content   string     `gorm:"type:text;not null;column:content" json:"content"`
+	Spoiler   bool       `gorm:"not null;default:false;column:is_spoiler" json:"spoiler"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
+	EditedAt  *time.Time `gorm:"column:edited_at" json:"edited_at"`
+	HiddenAt  *time.Time `gorm:"column:hidden_at" json:"hidden_at,omitempty"`
+	HiddenBy  *int64     `gorm:"column:hidden_by" json:"hidden_by,omitempty"`
+	DeletedAt *time.Time `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
+	DeletedBy *int64     `gorm:"column:deleted_by" json:"deleted_by,omitempty"`
+	// DeletedReason is nil for comments deleted before reasons were recorded
+	DeletedReason *CommentDeletionReason `gorm:"size:20;column:deleted_reason" json:"deleted_reason,omitempty"`
+
+	// ReplyCount is filled in by the DAO and counts replies that were not deleted
+	ReplyCount int64 `gorm:"-" json:"reply_count"`
+	// AutoSpoiler is set per reader when the author has watched the title and the reader has not
+	AutoSpoiler bool `gorm:"-" json:"auto_spoiler"`
+	// Redacted is set when the reader may not see a hidden comment's content
+	Redacted bool `gorm:"-" json:"redacted"`
+	// Reactions is filled in by the DAO when listing comments
+	Reactions []ReactionSummary `gorm:"-" json:"reactions,omitempty"`
+
+	User     User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Mentions []CommentMention `gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE" json:"mentions,omitempty"`
 }
 
 func (Comment) TableName() string {
 	return "comments"
 }
+
+// InScope reports whether the comment belongs to the movie's thread in the list, or to
+// the list's discussion board when movieID is nil
+func (c *Comment) InScope(listID int64, movieID *int64) bool {
+	if c.ListID != listID {
+		return false
+	}
+	if movieID == nil || c.MovieID == nil {
+		return movieID == nil && c.MovieID == nil
+	}
+	return *c.MovieID == *movieID
+}
+
+// IsDeleted reports whether the comment was soft-deleted by its author or a moderator
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// IsSelfDeleted reports whether the author took the comment down themselves, as
+// opposed to a moderator or their leaving the list
+func (c *Comment) IsSelfDeleted() bool {
+	if !c.IsDeleted() {
+		return false
+	}
+	if c.DeletedReason != nil {
+		return *c.DeletedReason == CommentDeletedByAuthor
+	}
+	return c.DeletedBy != nil && *c.DeletedBy == c.UserID
+}
+
+// IsHidden reports whether the list owner hid the comment
+func (c *Comment) IsHidden() bool {
+	return c.HiddenAt != nil
+}
+
+// IsEdited reports whether the content changed after the comment was posted
+func (c *Comment) IsEdited() bool {
+	return c.EditedAt != nil
+}
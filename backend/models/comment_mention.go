@@ -0,0 +1,16 @@
+package models
+
+// CommentMention is an @username in a comment resolved to a list member. Offset and
+// Length count Unicode code points and cover the leading "@".
+type CommentMention struct {
+	CommentID int64 `gorm:"primaryKey;column:comment_id" json:"comment_id"`
+	Offset    int   `gorm:"primaryKey;column:start_pos" json:"offset"`
+	Length    int   `gorm:"not null;column:length" json:"length"`
+	UserID    int64 `gorm:"not null;column:user_id;index" json:"user_id"`
+
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (CommentMention) TableName() string {
+	return "comment_mentions"
+}
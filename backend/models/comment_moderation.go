@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type ModerationAction string
+
+const (
+	ModerationHide    ModerationAction = "hide"
+	ModerationUnhide  ModerationAction = "unhide"
+	ModerationDelete  ModerationAction = "delete"
+	ModerationRestore ModerationAction = "restore"
+)
+
+// CommentModerationLog is the audit record of a list owner acting on a comment
+type CommentModerationLog struct {
+	ID     int64 `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ListID int64 `gorm:"not null;column:list_id;index" json:"list_id"`
+	// CommentID is nil once the comment was purged together with its title; the
+	// record itself is kept
+	CommentID   *int64           `gorm:"column:comment_id;index" json:"comment_id"`
+	ModeratorID int64            `gorm:"not null;column:moderator_id" json:"moderator_id"`
+	AuthorID    int64            `gorm:"not null;column:author_id" json:"author_id"`
+	Action      ModerationAction `gorm:"not null;size:20;column:action;check:action IN ('hide', 'unhide', 'delete', 'restore')" json:"action"`
+	Reason      *string          `gorm:"size:500;column:reason" json:"reason"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+
+	Moderator User `gorm:"foreignKey:ModeratorID" json:"moderator,omitempty"`
+	Author    User `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+}
+
+func (CommentModerationLog) TableName() string {
+	return "comment_moderation_logs"
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// CommentRevision keeps a comment's content as it was before an edit
+type CommentRevision struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	CommentID int64     `gorm:"not null;column:comment_id;index" json:"comment_id"`
+	Content   string    `gorm:"type:text;not null;column:content" json:"content"`
+	Spoiler   bool      `gorm:"not null;default:false;column:is_spoiler" json:"spoiler"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+}
+
+func (CommentRevision) TableName() string {
+	return "comment_revisions"
+}
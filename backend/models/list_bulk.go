@@ -0,0 +1,68 @@
+package models
+
+type BulkOperation string
+
+const (
+	BulkSetStatus BulkOperation = "set_status"
+	BulkRemove    BulkOperation = "remove"
+	// BulkCopy adds the titles to another list and leaves them where they are
+	BulkCopy BulkOperation = "copy"
+	// BulkMove takes the titles to another list together with their ratings, notes,
+	// watches, comments and tags
+	BulkMove BulkOperation = "move"
+	// BulkTag puts a tag on the titles
+	BulkTag BulkOperation = "tag"
+	// BulkUntag takes a tag off the titles
+	BulkUntag BulkOperation = "untag"
+)
+
+// BulkOperations lists the operations that can be applied to many titles at once
+var BulkOperations = []BulkOperation{
+	BulkSetStatus,
+	BulkRemove,
+	BulkCopy,
+	BulkMove,
+	BulkTag,
+	BulkUntag,
+}
+
+func (o BulkOperation) IsValid() bool {
+	for _, known := range BulkOperations {
+		if o == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsTarget reports whether the operation writes to a second list
+func (o BulkOperation) NeedsTarget() bool {
+	return o == BulkCopy || o == BulkMove
+}
+
+// NeedsTag reports whether the operation takes a tag
+func (o BulkOperation) NeedsTag() bool {
+	return o == BulkTag || o == BulkUntag
+}
+
+// BulkItemOutcome is what a bulk operation did to one title
+type BulkItemOutcome string
+
+const (
+	BulkItemApplied BulkItemOutcome = "applied"
+	// BulkItemUnchanged is a title that already had the requested status, or already
+	// had or lacked the tag
+	BulkItemUnchanged BulkItemOutcome = "unchanged"
+	BulkItemNotInList BulkItemOutcome = "not_in_list"
+	// BulkItemAlreadyInTarget is a title the target list already has. A move leaves it
+	// in the source list.
+	BulkItemAlreadyInTarget BulkItemOutcome = "already_in_target"
+)
+
+// BulkItemResult is the outcome of a bulk operation for one title
+type BulkItemResult struct {
+	MovieID int64           `json:"movie_id"`
+	Outcome BulkItemOutcome `json:"outcome"`
+	// PreviousStatus is set when a status change applied
+	PreviousStatus *MovieStatus `json:"previous_status,omitempty"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+type ListEventVerb string
+
+const (
+	EventListCreated      ListEventVerb = "list_created"
+	EventListDeleted      ListEventVerb = "list_deleted"
+	EventMemberJoined     ListEventVerb = "member_joined"
+	EventMemberLeft       ListEventVerb = "member_left"
+	EventMovieAdded       ListEventVerb = "movie_added"
+	EventMovieRemoved     ListEventVerb = "movie_removed"
+	EventMovieRestored    ListEventVerb = "movie_restored"
+	EventStatusChanged    ListEventVerb = "status_changed"
+	EventRatingChanged    ListEventVerb = "rating_changed"
+	EventWatchAdded       ListEventVerb = "watch_added"
+	EventWatchRemoved     ListEventVerb = "watch_removed"
+	EventTagAdded         ListEventVerb = "tag_added"
+	EventTagRemoved       ListEventVerb = "tag_removed"
+	EventMoviesReordered  ListEventVerb = "movies_reordered"
+	EventCommentCreated   ListEventVerb = "comment_created"
+	EventCommentEdited    ListEventVerb = "comment_edited"
+	EventCommentDeleted   ListEventVerb = "comment_deleted"
+	EventCommentModerated ListEventVerb = "comment_moderated"
+)
+
+type ListEventTarget string
+
+const (
+	EventTargetList    ListEventTarget = "list"
+	EventTargetMember  ListEventTarget = "member"
+	EventTargetMovie   ListEventTarget = "movie"
+	EventTargetComment ListEventTarget = "comment"
+)
+
+// ListEvent is an append-only record of a change to a list, written in the same
+// transaction as the change. Before and After hold JSON snapshots of what changed.
+type ListEvent struct {
+	ID         int64           `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ListID     int64           `gorm:"not null;column:list_id;index" json:"list_id"`
+	ActorID    *int64          `gorm:"column:actor_id" json:"actor_id"`
+	Verb       ListEventVerb   `gorm:"not null;size:40;column:verb" json:"verb"`
+	TargetType ListEventTarget `gorm:"not null;size:20;column:target_type" json:"target_type"`
+	TargetID   int64           `gorm:"not null;column:target_id" json:"target_id"`
+	Before     *string         `gorm:"type:text;column:before_value" json:"before"`
+	After      *string         `gorm:"type:text;column:after_value" json:"after"`
+	CreatedAt  time.Time       `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
+
+func (ListEvent) TableName() string {
+	return "list_events"
+}
@@ -0,0 +1,26 @@
+package models
+
+type ExportFormat string
+
+const (
+	ExportCSV  ExportFormat = "csv"
+	ExportJSON ExportFormat = "json"
+	// ExportLetterboxd is a CSV in the layout Letterboxd's importer reads
+	ExportLetterboxd ExportFormat = "letterboxd"
+)
+
+// ExportFormats lists the formats a list can be exported to
+var ExportFormats = []ExportFormat{
+	ExportCSV,
+	ExportJSON,
+	ExportLetterboxd,
+}
+
+func (f ExportFormat) IsValid() bool {
+	for _, known := range ExportFormats {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type ImportFormat string
+
+const (
+	ImportLetterboxd ImportFormat = "letterboxd"
+	ImportIMDb       ImportFormat = "imdb"
+	ImportTrakt      ImportFormat = "trakt"
+	// ImportList2gether is the app's own JSON list export
+	ImportList2gether ImportFormat = "list2gether"
+)
+
+// ImportFormats lists the export files a list can be imported from
+var ImportFormats = []ImportFormat{
+	ImportLetterboxd,
+	ImportIMDb,
+	ImportTrakt,
+	ImportList2gether,
+}
+
+func (f ImportFormat) IsValid() bool {
+	for _, known := range ImportFormats {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+type ImportStatus string
+
+const (
+	ImportRunning   ImportStatus = "running"
+	ImportCompleted ImportStatus = "completed"
+	ImportFailed    ImportStatus = "failed"
+)
+
+// ListImport is a background job bringing another service's export into a list. The
+// counters track its progress; Unmatched holds the JSON encoding of the rows that could
+// not be matched to a TMDB title.
+type ListImport struct {
+	ID             int64        `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ListID         int64        `gorm:"not null;index:idx_list_imports_list_id;column:list_id" json:"list_id"`
+	UserID         int64        `gorm:"not null;column:user_id" json:"user_id"`
+	Format         ImportFormat `gorm:"not null;size:20;column:format" json:"format"`
+	Status         ImportStatus `gorm:"not null;size:16;column:status" json:"status"`
+	TotalRows      int          `gorm:"not null;default:0;column:total_rows" json:"total_rows"`
+	ProcessedRows  int          `gorm:"not null;default:0;column:processed_rows" json:"processed_rows"`
+	AddedCount     int          `gorm:"not null;default:0;column:added_count" json:"added_count"`
+	UpdatedCount   int          `gorm:"not null;default:0;column:updated_count" json:"updated_count"`
+	UnmatchedCount int          `gorm:"not null;default:0;column:unmatched_count" json:"unmatched_count"`
+	Unmatched      string       `gorm:"type:mediumtext;column:unmatched" json:"-"`
+	Error          *string      `gorm:"size:500;column:error" json:"error"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+	UpdatedAt      time.Time    `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
+	FinishedAt     *time.Time   `gorm:"column:finished_at" json:"finished_at"`
+
+	List MovieList `gorm:"foreignKey:ListID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (ListImport) TableName() string {
+	return "list_imports"
+}
+
+// UnmatchedImportRow is a row of the uploaded file that did not become a list title
+type UnmatchedImportRow struct {
+	Line   int    `json:"line"`
+	Title  string `json:"title"`
+	Year   int    `json:"year,omitempty"`
+	IMDbID string `json:"imdb_id,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// UnmatchedRows decodes the report of rows that could not be matched
+func (i *ListImport) UnmatchedRows() []UnmatchedImportRow {
+	rows := []UnmatchedImportRow{}
+	if i.Unmatched != "" {
+		_ = json.Unmarshal([]byte(i.Unmatched), &rows)
+	}
+	return rows
+}
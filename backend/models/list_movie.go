@@ -14,22 +14,40 @@ const (
 )
 
 type ListMovie struct {
-	ID           int64       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
-	ListID       int64       `gorm:"not null;column:list_id;uniqueIndex:idx_list_movie" json:"list_id"`
-	MovieID      int64       `gorm:"not null;column:movie_id;uniqueIndex:idx_list_movie" json:"movie_id"`
-	Status       MovieStatus `gorm:"not null;default:not_watched;size:20;column:status;check:status IN ('not_watched', 'watching', 'watched', 'dropped')" json:"status"`
-	AddedBy      *int64      `gorm:"column:added_by" json:"added_by"`
-	AddedAt      time.Time   `gorm:"autoCreateTime;column:added_at" json:"added_at"`
-	WatchedAt    *time.Time  `gorm:"column:watched_at" json:"watched_at"`
-	UpdatedAt    time.Time   `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
-	DisplayOrder *int        `gorm:"column:display_order" json:"display_order"`
-
-	List        MovieList           `gorm:"foreignKey:ListID" json:"list,omitempty"`
-	Movie       Movie               `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
-	AddedByUser *User               `gorm:"foreignKey:AddedBy" json:"added_by_user,omitempty"`
-	UserEntries []ListMovieUserData `gorm:"foreignKey:ListID,MovieID;references:ListID,MovieID" json:"user_entries,omitempty"`
+	ID              int64       `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ListID          int64       `gorm:"not null;column:list_id;uniqueIndex:idx_list_movie" json:"list_id"`
+	MovieID         int64       `gorm:"not null;column:movie_id;uniqueIndex:idx_list_movie" json:"movie_id"`
+	Status          MovieStatus `gorm:"not null;default:not_watched;size:20;column:status;check:status IN ('not_watched', 'watching', 'watched', 'dropped')" json:"status"`
+	AddedBy         *int64      `gorm:"column:added_by" json:"added_by"`
+	AddedAt         time.Time   `gorm:"autoCreateTime;column:added_at" json:"added_at"`
+	WatchedAt       *time.Time  `gorm:"column:watched_at" json:"watched_at"`
+	UpdatedAt       time.Time   `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
+	DisplayOrder    *int        `gorm:"column:display_order" json:"display_order"`
+	StatusChangedAt *time.Time  `gorm:"column:status_changed_at" json:"status_changed_at"`
+	StatusChangedBy *int64      `gorm:"column:status_changed_by" json:"status_changed_by"`
+	// RemovedAt marks a title taken off the list. It keeps its comments and ratings
+	// until the undo window passes and the purger deletes it.
+	RemovedAt *time.Time `gorm:"column:removed_at;index" json:"removed_at,omitempty"`
+	RemovedBy *int64     `gorm:"column:removed_by" json:"removed_by,omitempty"`
+
+	// Reactions is filled in by the DAO when listing the list's titles
+	Reactions []ReactionSummary `gorm:"-" json:"reactions,omitempty"`
+	// UnreadComments is set per reader when listing the list's titles
+	UnreadComments int64 `gorm:"-" json:"unread_comments"`
+
+	List          MovieList           `gorm:"foreignKey:ListID" json:"list,omitempty"`
+	Movie         Movie               `gorm:"foreignKey:MovieID" json:"movie,omitempty"`
+	AddedByUser   *User               `gorm:"foreignKey:AddedBy" json:"added_by_user,omitempty"`
+	RemovedByUser *User               `gorm:"foreignKey:RemovedBy" json:"removed_by_user,omitempty"`
+	UserEntries   []ListMovieUserData `gorm:"foreignKey:ListID,MovieID;references:ListID,MovieID" json:"user_entries,omitempty"`
+	Watches       []ListMovieWatch    `gorm:"foreignKey:ListID,MovieID;references:ListID,MovieID" json:"watches,omitempty"`
+	Tags          []ListMovieTag      `gorm:"foreignKey:ListID,MovieID;references:ListID,MovieID" json:"tags,omitempty"`
 }
 
 func (ListMovie) TableName() string {
 	return "list_movies"
 }
+
+func (lm *ListMovie) IsRemoved() bool {
+	return lm.RemovedAt != nil
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// MaxTagLength is the longest tag a title can be given, in characters
+const MaxTagLength = 40
+
+// ListMovieTag is a label the list's members put on a title to group it, such as
+// "halloween" or "for the kids". Tags belong to the list, not to whoever added them.
+type ListMovieTag struct {
+	ListID    int64     `gorm:"primaryKey;column:list_id" json:"list_id"`
+	MovieID   int64     `gorm:"primaryKey;column:movie_id" json:"movie_id"`
+	Tag       string    `gorm:"primaryKey;size:40;column:tag" json:"tag"`
+	CreatedBy *int64    `gorm:"column:created_by" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+}
+
+func (ListMovieTag) TableName() string {
+	return "list_movie_tags"
+}
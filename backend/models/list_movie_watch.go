@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ListMovieWatch records that a member has watched a title in a list. The list-level
+// status on ListMovie says where the group is; this says who has actually seen it.
+type ListMovieWatch struct {
+	ListID    int64     `gorm:"primaryKey;column:list_id" json:"list_id"`
+	MovieID   int64     `gorm:"primaryKey;column:movie_id" json:"movie_id"`
+	UserID    int64     `gorm:"primaryKey;column:user_id" json:"user_id"`
+	WatchedAt time.Time `gorm:"not null;column:watched_at" json:"watched_at"`
+}
+
+func (ListMovieWatch) TableName() string {
+	return "list_movie_watches"
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ListReadMarker records when a member last caught up with a list (MovieID 0) or with
+// one movie's comment thread. Reading the list also covers every thread in it.
+type ListReadMarker struct {
+	ListID  int64     `gorm:"primaryKey;column:list_id" json:"list_id"`
+	UserID  int64     `gorm:"primaryKey;column:user_id" json:"user_id"`
+	MovieID int64     `gorm:"primaryKey;column:movie_id" json:"movie_id"`
+	ReadAt  time.Time `gorm:"not null;column:read_at" json:"read_at"`
+}
+
+func (ListReadMarker) TableName() string {
+	return "list_read_markers"
+}
+
+// UnreadCounts summarizes what other members changed in a list since the member last read it
+type UnreadCounts struct {
+	Comments      int64 `json:"comments"`
+	AddedTitles   int64 `json:"added_titles"`
+	StatusChanges int64 `json:"status_changes"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type ListStreamEventType string
+
+const (
+	StreamMovieAdded      ListStreamEventType = "movie_added"
+	StreamMovieRemoved    ListStreamEventType = "movie_removed"
+	StreamMovieRestored   ListStreamEventType = "movie_restored"
+	StreamMovieUpdated    ListStreamEventType = "movie_updated"
+	StreamMoviesReordered ListStreamEventType = "movies_reordered"
+	StreamCommentCreated  ListStreamEventType = "comment_created"
+	StreamCommentUpdated  ListStreamEventType = "comment_updated"
+	StreamCommentDeleted  ListStreamEventType = "comment_deleted"
+	StreamMemberJoined    ListStreamEventType = "member_joined"
+	StreamMemberLeft      ListStreamEventType = "member_left"
+	StreamListDeleted     ListStreamEventType = "list_deleted"
+	StreamPresenceChanged ListStreamEventType = "presence_changed"
+	StreamListImported    ListStreamEventType = "list_imported"
+	// StreamMoviesBulkUpdated carries the operation and the ids of the titles it applied to
+	StreamMoviesBulkUpdated ListStreamEventType = "movies_bulk_updated"
+)
+
+// ListStreamEvent is pushed live to the members who have the list open. It is not
+// stored; Data only identifies what changed so clients can refetch it.
+type ListStreamEvent struct {
+	Type       ListStreamEventType    `json:"type"`
+	ListID     int64                  `json:"list_id"`
+	ActorID    int64                  `json:"actor_id"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	OccurredAt time.Time              `json:"occurred_at"`
+}
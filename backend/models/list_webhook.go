@@ -0,0 +1,104 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type WebhookEventType string
+
+const (
+	WebhookMovieAdded     WebhookEventType = "movie_added"
+	WebhookStatusChanged  WebhookEventType = "status_changed"
+	WebhookCommentCreated WebhookEventType = "comment_created"
+)
+
+// WebhookEventTypes lists every event a webhook can subscribe to
+var WebhookEventTypes = []WebhookEventType{
+	WebhookMovieAdded,
+	WebhookStatusChanged,
+	WebhookCommentCreated,
+}
+
+func (t WebhookEventType) IsValid() bool {
+	for _, known := range WebhookEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ListWebhook is a URL the list owner registered to receive the list's events. Events
+// holds the subscribed event types separated by commas; Secret signs every delivery.
+type ListWebhook struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ListID    int64     `gorm:"not null;index:idx_list_webhooks_list_id;column:list_id" json:"list_id"`
+	URL       string    `gorm:"not null;type:text;column:url" json:"url"`
+	Secret    string    `gorm:"not null;size:64;column:secret" json:"-"`
+	Events    string    `gorm:"not null;size:255;column:events" json:"-"`
+	Active    bool      `gorm:"not null;default:true;column:active" json:"active"`
+	CreatedBy int64     `gorm:"not null;column:created_by" json:"created_by"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
+
+	List MovieList `gorm:"foreignKey:ListID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (ListWebhook) TableName() string {
+	return "list_webhooks"
+}
+
+// EventTypes returns the event types the webhook subscribed to
+func (w *ListWebhook) EventTypes() []WebhookEventType {
+	types := make([]WebhookEventType, 0, len(WebhookEventTypes))
+	for _, name := range strings.Split(w.Events, ",") {
+		if t := WebhookEventType(strings.TrimSpace(name)); t.IsValid() {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+func (w *ListWebhook) Subscribes(eventType WebhookEventType) bool {
+	for _, t := range w.EventTypes() {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed is final: the delivery ran out of attempts
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent, or still to be sent, to a webhook. It doubles as
+// the delivery log the owner can inspect. Payload holds the exact JSON body signed and
+// posted on every attempt.
+type WebhookDelivery struct {
+	ID             int64                 `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	WebhookID      int64                 `gorm:"not null;index:idx_webhook_deliveries_webhook_id;column:webhook_id" json:"webhook_id"`
+	EventID        string                `gorm:"not null;size:32;column:event_id" json:"event_id"`
+	Event          WebhookEventType      `gorm:"not null;size:40;column:event" json:"event"`
+	Payload        string                `gorm:"not null;type:text;column:payload" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"not null;size:16;default:'pending';index:idx_webhook_deliveries_due,priority:1;column:status" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0;column:attempts" json:"attempts"`
+	NextAttemptAt  *time.Time            `gorm:"index:idx_webhook_deliveries_due,priority:2;column:next_attempt_at" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `gorm:"column:last_attempt_at" json:"last_attempt_at"`
+	ResponseStatus *int                  `gorm:"column:response_status" json:"response_status"`
+	LastError      *string               `gorm:"size:500;column:last_error" json:"last_error"`
+	DeliveredAt    *time.Time            `gorm:"column:delivered_at" json:"delivered_at"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+
+	Webhook ListWebhook `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
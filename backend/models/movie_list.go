@@ -15,6 +15,8 @@ type MovieList struct {
 	CreatedAt   time.Time      `gorm:"autoCreateTime;column:created_at" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index;column:deleted_at" json:"deleted_at,omitempty"`
+	// LastDigestAt is when the weekly email digest last covered the list
+	LastDigestAt *time.Time `gorm:"column:last_digest_at" json:"-"`
 
 	Creator User `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
 
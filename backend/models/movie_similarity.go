@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// MovieSimilarity is an item-item similarity computed from how titles co-occur across lists
+type MovieSimilarity struct {
+	MovieID        int64     `gorm:"primaryKey;column:movie_id" json:"movie_id"`
+	SimilarMovieID int64     `gorm:"primaryKey;column:similar_movie_id" json:"similar_movie_id"`
+	Score          float64   `gorm:"not null;column:score" json:"score"`
+	CoOccurrences  int       `gorm:"not null;column:co_occurrences" json:"co_occurrences"`
+	UpdatedAt      time.Time `gorm:"not null;column:updated_at" json:"updated_at"`
+
+	SimilarMovie Movie `gorm:"foreignKey:SimilarMovieID" json:"similar_movie,omitempty"`
+}
+
+func (MovieSimilarity) TableName() string {
+	return "movie_similarities"
+}
@@ -0,0 +1,172 @@
+package models
+
+import "time"
+
+type NotificationType string
+
+const (
+	NotificationMemberJoined        NotificationType = "member_joined"
+	NotificationTitleAdded          NotificationType = "title_added"
+	NotificationComment             NotificationType = "comment"
+	NotificationMention             NotificationType = "mention"
+	NotificationAvailabilityChanged NotificationType = "availability_changed"
+	NotificationInvitationReceived  NotificationType = "invitation_received"
+	// NotificationWeeklyDigest is only sent by email; it is never stored in the center
+	NotificationWeeklyDigest NotificationType = "weekly_digest"
+)
+
+// NotificationTypes lists every type a user can receive, in display order
+var NotificationTypes = []NotificationType{
+	NotificationMemberJoined,
+	NotificationTitleAdded,
+	NotificationComment,
+	NotificationMention,
+	NotificationAvailabilityChanged,
+	NotificationInvitationReceived,
+}
+
+func (t NotificationType) IsValid() bool {
+	for _, known := range NotificationTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is an event addressed to a single user. Payload holds the JSON
+// encoding of the type's payload struct.
+type Notification struct {
+	ID        int64            `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID    int64            `gorm:"not null;column:user_id;index:idx_notification_user_read" json:"user_id"`
+	Type      NotificationType `gorm:"not null;size:40;column:type" json:"type"`
+	ListID    *int64           `gorm:"column:list_id" json:"list_id"`
+	ActorID   *int64           `gorm:"column:actor_id" json:"actor_id"`
+	Payload   string           `gorm:"type:text;column:payload" json:"payload"`
+	ReadAt    *time.Time       `gorm:"column:read_at;index:idx_notification_user_read" json:"read_at"`
+	CreatedAt time.Time        `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+
+	User  User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// MemberJoinedPayload describes a user who joined one of the user's lists
+type MemberJoinedPayload struct {
+	ListID   int64  `json:"list_id"`
+	ListName string `json:"list_name"`
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// TitleAddedPayload describes a title another member added to the list
+type TitleAddedPayload struct {
+	ListID    int64  `json:"list_id"`
+	ListName  string `json:"list_name"`
+	MovieID   int64  `json:"movie_id"`
+	Title     string `json:"title"`
+	MediaType string `json:"media_type"`
+}
+
+// CommentPayload describes a new comment in one of the user's lists
+type CommentPayload struct {
+	ListID    int64  `json:"list_id"`
+	MovieID   *int64 `json:"movie_id"`
+	CommentID int64  `json:"comment_id"`
+	ParentID  *int64 `json:"parent_id"`
+	Excerpt   string `json:"excerpt"`
+}
+
+// MentionPayload describes a comment that mentioned the user
+type MentionPayload struct {
+	ListID    int64  `json:"list_id"`
+	MovieID   *int64 `json:"movie_id"`
+	CommentID int64  `json:"comment_id"`
+	Excerpt   string `json:"excerpt"`
+}
+
+// AvailabilityChangedPayload describes streaming providers that started offering a
+// title from the user's list
+type AvailabilityChangedPayload struct {
+	ListID    int64    `json:"list_id"`
+	ListName  string   `json:"list_name"`
+	MovieID   int64    `json:"movie_id"`
+	Title     string   `json:"title"`
+	Region    string   `json:"region"`
+	Providers []string `json:"providers"`
+}
+
+// InvitationPayload describes an invitation to join a list
+type InvitationPayload struct {
+	ListID     int64  `json:"list_id"`
+	ListName   string `json:"list_name"`
+	InviteCode string `json:"invite_code"`
+}
+
+type NotificationChannel string
+
+const (
+	NotificationChannelInApp NotificationChannel = "in_app"
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelPush  NotificationChannel = "push"
+)
+
+// NotificationChannels lists every delivery channel with preferences
+var NotificationChannels = []NotificationChannel{
+	NotificationChannelInApp,
+	NotificationChannelEmail,
+	NotificationChannelPush,
+}
+
+func (c NotificationChannel) IsValid() bool {
+	for _, known := range NotificationChannels {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Types lists the notification types the channel delivers, in display order
+func (c NotificationChannel) Types() []NotificationType {
+	switch c {
+	case NotificationChannelInApp:
+		return NotificationTypes
+	case NotificationChannelEmail:
+		return []NotificationType{NotificationInvitationReceived, NotificationWeeklyDigest}
+	case NotificationChannelPush:
+		return []NotificationType{NotificationTitleAdded, NotificationMention}
+	}
+	return nil
+}
+
+// Delivers reports whether the channel sends notifications of the type
+func (c NotificationChannel) Delivers(t NotificationType) bool {
+	for _, known := range c.Types() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreference turns one notification type off or on for a channel.
+// Types without a row are delivered.
+type NotificationPreference struct {
+	UserID    int64               `gorm:"primaryKey;column:user_id" json:"user_id"`
+	Channel   NotificationChannel `gorm:"primaryKey;size:20;column:channel" json:"channel"`
+	Type      NotificationType    `gorm:"primaryKey;size:40;column:type" json:"type"`
+	Enabled   bool                `gorm:"not null;column:enabled" json:"enabled"`
+	UpdatedAt time.Time           `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
+}
+
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
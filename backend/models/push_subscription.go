@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// PushSubscription is one browser or installed app of a user that accepts Web Push
+// messages. Endpoint is the push service URL the browser handed out; P256dh and Auth are
+// the keys messages are encrypted with.
+type PushSubscription struct {
+	ID           int64      `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	UserID       int64      `gorm:"not null;index:idx_push_subscriptions_user_id;column:user_id" json:"user_id"`
+	Endpoint     string     `gorm:"not null;type:text;column:endpoint" json:"endpoint"`
+	EndpointHash string     `gorm:"not null;size:64;uniqueIndex;column:endpoint_hash" json:"-"`
+	P256dh       string     `gorm:"not null;size:255;column:p256dh" json:"-"`
+	Auth         string     `gorm:"not null;size:64;column:auth" json:"-"`
+	DeviceName   string     `gorm:"not null;default:'';size:100;column:device_name" json:"device_name"`
+	UserAgent    string     `gorm:"not null;default:'';size:255;column:user_agent" json:"user_agent"`
+	LastUsedAt   *time.Time `gorm:"column:last_used_at" json:"last_used_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (PushSubscription) TableName() string {
+	return "push_subscriptions"
+}
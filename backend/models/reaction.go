@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type ReactionTargetType string
+
+const (
+	ReactionTargetComment   ReactionTargetType = "comment"
+	ReactionTargetListMovie ReactionTargetType = "list_movie"
+)
+
+type ReactionKind string
+
+const (
+	ReactionThumbsUp ReactionKind = "thumbs_up"
+	ReactionHeart    ReactionKind = "heart"
+	ReactionLaugh    ReactionKind = "laugh"
+	ReactionPopcorn  ReactionKind = "popcorn"
+)
+
+// ReactionKinds lists the supported reactions in display order
+var ReactionKinds = []ReactionKind{ReactionThumbsUp, ReactionHeart, ReactionLaugh, ReactionPopcorn}
+
+// ReactionEmojis maps each reaction to the emoji shown to users. Reactions are stored by
+// kind so the unique index does not depend on how the database collates emoji.
+var ReactionEmojis = map[ReactionKind]string{
+	ReactionThumbsUp: "👍",
+	ReactionHeart:    "❤️",
+	ReactionLaugh:    "😂",
+	ReactionPopcorn:  "🍿",
+}
+
+type Reaction struct {
+	ID         int64              `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ListID     int64              `gorm:"not null;column:list_id;index" json:"list_id"`
+	TargetType ReactionTargetType `gorm:"not null;size:20;column:target_type;uniqueIndex:idx_reaction_target_user_kind;check:target_type IN ('comment', 'list_movie')" json:"target_type"`
+	TargetID   int64              `gorm:"not null;column:target_id;uniqueIndex:idx_reaction_target_user_kind" json:"target_id"`
+	UserID     int64              `gorm:"not null;column:user_id;uniqueIndex:idx_reaction_target_user_kind" json:"user_id"`
+	Kind       ReactionKind       `gorm:"not null;size:20;column:kind;uniqueIndex:idx_reaction_target_user_kind;check:kind IN ('thumbs_up', 'heart', 'laugh', 'popcorn')" json:"kind"`
+	CreatedAt  time.Time          `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+}
+
+func (Reaction) TableName() string {
+	return "reactions"
+}
+
+// ReactionSummary aggregates the reactions of one kind on a target
+type ReactionSummary struct {
+	Kind  ReactionKind `json:"kind"`
+	Emoji string       `json:"emoji"`
+	Count int64        `json:"count"`
+	// UserIDs lets callers tell whether the viewer is among the reactors
+	UserIDs []int64 `json:"-"`
+}
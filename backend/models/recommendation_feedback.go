@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type RecommendationFeedbackAction string
+
+const (
+	FeedbackDismissed     RecommendationFeedbackAction = "dismissed"
+	FeedbackNotInterested RecommendationFeedbackAction = "not_interested"
+	FeedbackAlreadySeen   RecommendationFeedbackAction = "already_seen"
+)
+
+type RecommendationFeedback struct {
+	ID        int64                        `gorm:"primaryKey;autoIncrement;column:id" json:"id"`
+	ListID    int64                        `gorm:"not null;column:list_id;uniqueIndex:idx_rec_feedback_list_user_movie" json:"list_id"`
+	UserID    int64                        `gorm:"not null;column:user_id;uniqueIndex:idx_rec_feedback_list_user_movie" json:"user_id"`
+	MovieID   int64                        `gorm:"not null;column:movie_id;uniqueIndex:idx_rec_feedback_list_user_movie" json:"movie_id"`
+	MediaType string                       `gorm:"type:enum('movie','tv');not null;default:'movie';column:media_type" json:"media_type"`
+	Action    RecommendationFeedbackAction `gorm:"not null;size:20;column:action;check:action IN ('dismissed', 'not_interested', 'already_seen')" json:"action"`
+	CreatedAt time.Time                    `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+	UpdatedAt time.Time                    `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
+}
+
+func (RecommendationFeedback) TableName() string {
+	return "recommendation_feedback"
+}
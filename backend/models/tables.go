@@ -0,0 +1,34 @@
+package models
+
+// Tables lists every model that is migrated into the database
+func Tables() []interface{} {
+	return []interface{}{
+		&User{},
+		&Movie{},
+		&Genre{},
+		&MovieList{},
+		&ListMember{},
+		&ListMovie{},
+		&ListMovieUserData{},
+		&RefreshToken{},
+		&Comment{},
+		&WatchProvider{},
+		&RecommendationFeedback{},
+		&MovieSimilarity{},
+		&UserGenrePreference{},
+		&Reaction{},
+		&CommentMention{},
+		&CommentRevision{},
+		&CommentModerationLog{},
+		&Notification{},
+		&NotificationPreference{},
+		&PushSubscription{},
+		&ListWebhook{},
+		&WebhookDelivery{},
+		&ListImport{},
+		&ListMovieWatch{},
+		&ListMovieTag{},
+		&ListReadMarker{},
+		&ListEvent{},
+	}
+}
@@ -10,8 +10,12 @@ type User struct {
 	Email     string    `gorm:"uniqueIndex;not null;size:255;column:email" json:"email"`
 	Password  string    `gorm:"not null;type:text;column:password" json:"-"`
 	AvatarURL *string   `gorm:"size:500;column:avatar_url" json:"avatar_url,omitempty"`
+	Language  string    `gorm:"not null;default:pt;size:5;column:language" json:"language"`
 	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime;column:updated_at" json:"updated_at"`
+	// DeletedAt is set when the account was deleted. The row stays, anonymized, so the
+	// comments and activity that point at it still resolve.
+	DeletedAt *time.Time `gorm:"column:deleted_at" json:"deleted_at,omitempty"`
 
 	CreatedLists []MovieList  `gorm:"foreignKey:CreatedBy" json:"created_lists,omitempty"`
 	ListMembers  []ListMember `gorm:"foreignKey:UserID" json:"list_members,omitempty"`
@@ -21,3 +25,22 @@ type User struct {
 func (User) TableName() string {
 	return "users"
 }
+
+// Languages the app and its emails are translated to
+const (
+	LanguagePortuguese = "pt"
+	LanguageEnglish    = "en"
+	DefaultLanguage    = LanguagePortuguese
+)
+
+func IsSupportedLanguage(language string) bool {
+	return language == LanguagePortuguese || language == LanguageEnglish
+}
+
+// PreferredLanguage returns the user's language, falling back to the default
+func (u *User) PreferredLanguage() string {
+	if IsSupportedLanguage(u.Language) {
+		return u.Language
+	}
+	return DefaultLanguage
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// UserGenrePreference is a TMDB genre a user picked as a favourite during onboarding
+type UserGenrePreference struct {
+	UserID    int64     `gorm:"primaryKey;column:user_id" json:"user_id"`
+	GenreID   int64     `gorm:"primaryKey;column:genre_id" json:"genre_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;column:created_at" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (UserGenrePreference) TableName() string {
+	return "user_genre_preferences"
+}
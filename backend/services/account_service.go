@@ -0,0 +1,480 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAccountNotFound       = errors.New("account_not_found")
+	ErrInvalidPassword       = errors.New("invalid_password")
+	ErrInvalidDeletionOption = errors.New("invalid_deletion_option")
+)
+
+// What happens to the lists a deleted account owned
+const (
+	OwnedListsTransfer = "transfer"
+	OwnedListsDelete   = "delete"
+)
+
+// What happens to the comments a deleted account wrote
+const (
+	CommentsAnonymize = "anonymize"
+	CommentsDelete    = "delete"
+)
+
+// DeleteAccountOptions is what the user confirmed when asking to delete the account.
+// Empty options transfer owned lists and anonymize comments.
+type DeleteAccountOptions struct {
+	Password   string
+	OwnedLists string
+	Comments   string
+}
+
+// AccountDeletion reports what happened to the lists the deleted account owned
+type AccountDeletion struct {
+	TransferredLists []daos.OwnedListOutcome
+	DeletedListIDs   []int64
+	CommentsDeleted  bool
+}
+
+type AccountService interface {
+	ExportAccount(userID int64) (*AccountExport, error)
+	DeleteAccount(userID int64, options DeleteAccountOptions) (*AccountDeletion, error)
+}
+
+type accountService struct {
+	users         daos.UserDAO
+	refreshTokens daos.RefreshTokenDAO
+	accounts      daos.AccountDAO
+	hub           ListHub
+}
+
+func NewAccountService(users daos.UserDAO, refreshTokens daos.RefreshTokenDAO, accounts daos.AccountDAO, hub ListHub) AccountService {
+	return &accountService{users: users, refreshTokens: refreshTokens, accounts: accounts, hub: hub}
+}
+
+// AccountExport is everything stored about a user, as the JSON files of a ZIP archive
+type AccountExport struct {
+	Username string
+	files    []accountExportFile
+}
+
+type accountExportFile struct {
+	name string
+	data interface{}
+}
+
+func (s *accountService) ExportAccount(userID int64) (*AccountExport, error) {
+	user, err := s.activeUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	var data accountExportData
+	if data.prefs, err = s.users.FindGenrePreferences([]int64{userID}); err != nil {
+		return nil, err
+	}
+	if data.memberships, err = s.accounts.FindMemberships(userID); err != nil {
+		return nil, err
+	}
+	if data.entries, err = s.accounts.FindListMovieData(userID); err != nil {
+		return nil, err
+	}
+	if data.watches, err = s.accounts.FindWatches(userID); err != nil {
+		return nil, err
+	}
+	if data.comments, err = s.accounts.FindComments(userID); err != nil {
+		return nil, err
+	}
+	if data.reactions, err = s.accounts.FindReactions(userID); err != nil {
+		return nil, err
+	}
+	if data.sessions, err = s.refreshTokens.FindByUserID(userID); err != nil {
+		return nil, err
+	}
+	if data.subscriptions, err = s.accounts.FindPushSubscriptions(userID); err != nil {
+		return nil, err
+	}
+	if data.notifications, err = s.accounts.FindNotifications(userID); err != nil {
+		return nil, err
+	}
+	if data.feedback, err = s.accounts.FindRecommendationFeedback(userID); err != nil {
+		return nil, err
+	}
+	if data.imports, err = s.accounts.FindImports(userID); err != nil {
+		return nil, err
+	}
+	if data.webhooks, err = s.accounts.FindWebhooks(userID); err != nil {
+		return nil, err
+	}
+	return buildAccountExport(user, data), nil
+}
+
+// accountExportData is everything read about the user for the export
+type accountExportData struct {
+	prefs         []models.UserGenrePreference
+	memberships   []daos.AccountMembershipRow
+	entries       []daos.AccountListMovieRow
+	watches       []daos.AccountListMovieRow
+	comments      []daos.AccountCommentRow
+	reactions     []models.Reaction
+	sessions      []models.RefreshToken
+	subscriptions []models.PushSubscription
+	notifications []models.Notification
+	feedback      []models.RecommendationFeedback
+	imports       []models.ListImport
+	webhooks      []models.ListWebhook
+}
+
+// buildAccountExport lays the user's data out as the files of the archive. Password and
+// token hashes, push encryption keys and webhook secrets are left out.
+func buildAccountExport(user *models.User, data accountExportData) *AccountExport {
+	genreIDs := make([]int64, 0, len(data.prefs))
+	for _, p := range data.prefs {
+		genreIDs = append(genreIDs, p.GenreID)
+	}
+	profile := map[string]interface{}{
+		"id":               user.ID,
+		"username":         user.Username,
+		"email":            user.Email,
+		"avatar_url":       user.AvatarURL,
+		"language":         user.Language,
+		"favourite_genres": genreIDs,
+		"created_at":       user.CreatedAt,
+		"updated_at":       user.UpdatedAt,
+		"exported_at":      time.Now().UTC(),
+	}
+
+	membershipRows := make([]map[string]interface{}, 0, len(data.memberships))
+	for _, m := range data.memberships {
+		membershipRows = append(membershipRows, map[string]interface{}{
+			"list_id":         m.ListID,
+			"list_name":       m.ListName,
+			"role":            m.Role,
+			"joined_at":       m.AddedAt,
+			"list_deleted_at": m.ListDeletedAt,
+		})
+	}
+
+	ratings := make([]map[string]interface{}, 0, len(data.entries))
+	notes := make([]map[string]interface{}, 0, len(data.entries))
+	for _, e := range data.entries {
+		row := map[string]interface{}{
+			"list_id":    e.ListID,
+			"list_name":  e.ListName,
+			"movie_id":   e.MovieID,
+			"title":      e.Title,
+			"media_type": e.MediaType,
+			"updated_at": e.UpdatedAt,
+		}
+		if e.Rating != nil {
+			rating := copyExportRow(row)
+			rating["rating"] = *e.Rating
+			ratings = append(ratings, rating)
+		}
+		if e.Notes != nil && *e.Notes != "" {
+			note := copyExportRow(row)
+			note["notes"] = *e.Notes
+			notes = append(notes, note)
+		}
+	}
+
+	watchRows := make([]map[string]interface{}, 0, len(data.watches))
+	for _, w := range data.watches {
+		watchRows = append(watchRows, map[string]interface{}{
+			"list_id":    w.ListID,
+			"list_name":  w.ListName,
+			"movie_id":   w.MovieID,
+			"title":      w.Title,
+			"media_type": w.MediaType,
+			"watched_at": w.WatchedAt,
+		})
+	}
+
+	commentRows := make([]map[string]interface{}, 0, len(data.comments))
+	for _, c := range data.comments {
+		commentRows = append(commentRows, map[string]interface{}{
+			"id":          c.ID,
+			"list_id":     c.ListID,
+			"list_name":   c.ListName,
+			"movie_id":    c.MovieID,
+			"movie_title": c.MovieTitle,
+			"parent_id":   c.ParentID,
+			"content":     c.Content,
+			"spoiler":     c.Spoiler,
+			"created_at":  c.CreatedAt,
+			"edited_at":   c.EditedAt,
+			"deleted_at":  c.DeletedAt,
+		})
+	}
+
+	reactionRows := make([]map[string]interface{}, 0, len(data.reactions))
+	for _, r := range data.reactions {
+		reactionRows = append(reactionRows, map[string]interface{}{
+			"list_id":     r.ListID,
+			"target_type": r.TargetType,
+			"target_id":   r.TargetID,
+			"kind":        r.Kind,
+			"created_at":  r.CreatedAt,
+		})
+	}
+
+	sessionRows := make([]map[string]interface{}, 0, len(data.sessions))
+	for _, t := range data.sessions {
+		sessionRows = append(sessionRows, map[string]interface{}{
+			"id":           t.ID,
+			"created_at":   t.CreatedAt,
+			"last_used_at": t.LastUsedAt,
+			"expires_at":   t.ExpiresAt,
+			"revoked":      t.IsRevoked,
+		})
+	}
+
+	subscriptionRows := make([]map[string]interface{}, 0, len(data.subscriptions))
+	for _, p := range data.subscriptions {
+		subscriptionRows = append(subscriptionRows, map[string]interface{}{
+			"id":           p.ID,
+			"endpoint":     p.Endpoint,
+			"device_name":  p.DeviceName,
+			"user_agent":   p.UserAgent,
+			"created_at":   p.CreatedAt,
+			"last_used_at": p.LastUsedAt,
+		})
+	}
+
+	notificationRows := make([]map[string]interface{}, 0, len(data.notifications))
+	for _, n := range data.notifications {
+		var payload interface{}
+		if n.Payload != "" && json.Valid([]byte(n.Payload)) {
+			payload = json.RawMessage(n.Payload)
+		}
+		notificationRows = append(notificationRows, map[string]interface{}{
+			"id":         n.ID,
+			"type":       n.Type,
+			"list_id":    n.ListID,
+			"actor_id":   n.ActorID,
+			"payload":    payload,
+			"read_at":    n.ReadAt,
+			"created_at": n.CreatedAt,
+		})
+	}
+
+	feedbackRows := make([]map[string]interface{}, 0, len(data.feedback))
+	for _, f := range data.feedback {
+		feedbackRows = append(feedbackRows, map[string]interface{}{
+			"list_id":    f.ListID,
+			"movie_id":   f.MovieID,
+			"media_type": f.MediaType,
+			"action":     f.Action,
+			"created_at": f.CreatedAt,
+			"updated_at": f.UpdatedAt,
+		})
+	}
+
+	importRows := make([]map[string]interface{}, 0, len(data.imports))
+	for _, i := range data.imports {
+		importRows = append(importRows, map[string]interface{}{
+			"id":              i.ID,
+			"list_id":         i.ListID,
+			"format":          i.Format,
+			"status":          i.Status,
+			"total_rows":      i.TotalRows,
+			"added_count":     i.AddedCount,
+			"updated_count":   i.UpdatedCount,
+			"unmatched_count": i.UnmatchedCount,
+			"error":           i.Error,
+			"created_at":      i.CreatedAt,
+			"finished_at":     i.FinishedAt,
+		})
+	}
+
+	webhookRows := make([]map[string]interface{}, 0, len(data.webhooks))
+	for _, w := range data.webhooks {
+		webhookRows = append(webhookRows, map[string]interface{}{
+			"id":         w.ID,
+			"list_id":    w.ListID,
+			"url":        w.URL,
+			"events":     w.EventTypes(),
+			"active":     w.Active,
+			"created_at": w.CreatedAt,
+		})
+	}
+
+	return &AccountExport{
+		Username: user.Username,
+		files: []accountExportFile{
+			{"profile.json", profile},
+			{"memberships.json", membershipRows},
+			{"ratings.json", ratings},
+			{"notes.json", notes},
+			{"watches.json", watchRows},
+			{"comments.json", commentRows},
+			{"reactions.json", reactionRows},
+			{"sessions.json", sessionRows},
+			{"push_subscriptions.json", subscriptionRows},
+			{"notifications.json", notificationRows},
+			{"recommendation_feedback.json", feedbackRows},
+			{"imports.json", importRows},
+			{"webhooks.json", webhookRows},
+		},
+	}
+}
+
+func copyExportRow(row map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(row)+1)
+	for k, v := range row {
+		copied[k] = v
+	}
+	return copied
+}
+
+// Filename names the archive after the user and the day it was exported
+func (e *AccountExport) Filename() string {
+	name := exportSlug(e.Username)
+	if name == "" {
+		name = "account"
+	}
+	return "list2gether-" + name + "-" + time.Now().UTC().Format("2006-01-02") + ".zip"
+}
+
+// Write streams the archive to w
+func (e *AccountExport) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+	for _, file := range e.files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// DeleteAccount erases the account after the user confirms the password. Sessions are
+// revoked first, so the account is signed out everywhere even if erasing fails.
+func (s *accountService) DeleteAccount(userID int64, options DeleteAccountOptions) (*AccountDeletion, error) {
+	plan, err := accountDeletionPlan(options)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.activeUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(options.Password)) != nil {
+		return nil, ErrInvalidPassword
+	}
+	memberships, err := s.accounts.FindMemberships(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshTokens.RevokeAllByUserID(userID); err != nil {
+		return nil, err
+	}
+	suffix, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	plan.Username = "deleted-" + suffix
+	plan.Email = plan.Username + "@deleted.invalid"
+	outcomes, err := s.accounts.DeleteAccount(userID, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	deletion := summarizeAccountDeletion(outcomes, plan.DeleteComments)
+	s.publishAccountDeletion(userID, memberships, deletion)
+	log.Printf("delete_account success user_id=%d transferred=%d deleted=%d", userID, len(deletion.TransferredLists), len(deletion.DeletedListIDs))
+	return deletion, nil
+}
+
+func (s *accountService) activeUser(userID int64) (*models.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	if user.DeletedAt != nil {
+		return nil, ErrAccountNotFound
+	}
+	return user, nil
+}
+
+// accountDeletionPlan checks the options, filling in the defaults
+func accountDeletionPlan(options DeleteAccountOptions) (daos.AccountDeletionPlan, error) {
+	var plan daos.AccountDeletionPlan
+	switch options.OwnedLists {
+	case "", OwnedListsTransfer:
+		plan.TransferLists = true
+	case OwnedListsDelete:
+	default:
+		return plan, ErrInvalidDeletionOption
+	}
+	switch options.Comments {
+	case "", CommentsAnonymize:
+	case CommentsDelete:
+		plan.DeleteComments = true
+	default:
+		return plan, ErrInvalidDeletionOption
+	}
+	return plan, nil
+}
+
+func summarizeAccountDeletion(outcomes []daos.OwnedListOutcome, commentsDeleted bool) *AccountDeletion {
+	deletion := &AccountDeletion{
+		TransferredLists: []daos.OwnedListOutcome{},
+		DeletedListIDs:   []int64{},
+		CommentsDeleted:  commentsDeleted,
+	}
+	for _, o := range outcomes {
+		if o.NewOwnerID != nil {
+			deletion.TransferredLists = append(deletion.TransferredLists, o)
+		} else {
+			deletion.DeletedListIDs = append(deletion.DeletedListIDs, o.ListID)
+		}
+	}
+	return deletion
+}
+
+// publishAccountDeletion tells members with a list open that the user left it, or that
+// the list is gone
+func (s *accountService) publishAccountDeletion(userID int64, memberships []daos.AccountMembershipRow, deletion *AccountDeletion) {
+	if s.hub == nil {
+		return
+	}
+	deleted := make(map[int64]bool, len(deletion.DeletedListIDs))
+	for _, id := range deletion.DeletedListIDs {
+		deleted[id] = true
+		s.hub.Publish(models.ListStreamEvent{Type: models.StreamListDeleted, ListID: id, ActorID: userID})
+	}
+	newOwners := make(map[int64]int64, len(deletion.TransferredLists))
+	for _, t := range deletion.TransferredLists {
+		newOwners[t.ListID] = *t.NewOwnerID
+	}
+	for _, m := range memberships {
+		if deleted[m.ListID] || m.ListDeletedAt != nil {
+			continue
+		}
+		data := map[string]interface{}{"user_id": userID}
+		if owner, ok := newOwners[m.ListID]; ok {
+			data["new_owner_id"] = owner
+		}
+		s.hub.Publish(models.ListStreamEvent{Type: models.StreamMemberLeft, ListID: m.ListID, ActorID: userID, Data: data})
+	}
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAccountDeletionPlanDefaults(t *testing.T) {
+	plan, err := accountDeletionPlan(DeleteAccountOptions{})
+	assert.NoError(t, err)
+	assert.True(t, plan.TransferLists)
+	assert.False(t, plan.DeleteComments)
+
+	plan, err = accountDeletionPlan(DeleteAccountOptions{OwnedLists: OwnedListsDelete, Comments: CommentsDelete})
+	assert.NoError(t, err)
+	assert.False(t, plan.TransferLists)
+	assert.True(t, plan.DeleteComments)
+
+	_, err = accountDeletionPlan(DeleteAccountOptions{OwnedLists: "archive"})
+	assert.ErrorIs(t, err, ErrInvalidDeletionOption)
+	_, err = accountDeletionPlan(DeleteAccountOptions{Comments: "keep"})
+	assert.ErrorIs(t, err, ErrInvalidDeletionOption)
+}
+
+func TestSummarizeAccountDeletion(t *testing.T) {
+	newOwner := int64(5)
+	deletion := summarizeAccountDeletion([]daos.OwnedListOutcome{
+		{ListID: 1, NewOwnerID: &newOwner},
+		{ListID: 2},
+	}, true)
+	assert.Len(t, deletion.TransferredLists, 1)
+	assert.Equal(t, int64(1), deletion.TransferredLists[0].ListID)
+	assert.Equal(t, []int64{2}, deletion.DeletedListIDs)
+	assert.True(t, deletion.CommentsDeleted)
+}
+
+func TestAccountExportWritesJSONFiles(t *testing.T) {
+	rating := 8
+	notes := "Rever com legendas"
+	user := &models.User{ID: 3, Username: "ana", Email: "ana@example.com", Password: "hash", Language: "pt"}
+	export := buildAccountExport(user, accountExportData{
+		prefs:       []models.UserGenrePreference{{UserID: 3, GenreID: 18}},
+		memberships: []daos.AccountMembershipRow{{ListID: 1, ListName: "Sexta", Role: models.RoleOwner}},
+		entries: []daos.AccountListMovieRow{
+			{ListID: 1, MovieID: 949, Title: "Heat", Rating: &rating, Notes: &notes},
+			{ListID: 1, MovieID: 603, Title: "The Matrix"},
+		},
+		comments:      []daos.AccountCommentRow{{ID: 10, ListID: 1, Content: "Que filme"}},
+		sessions:      []models.RefreshToken{{ID: 4, TokenHash: "secret-hash", ExpiresAt: time.Now()}},
+		subscriptions: []models.PushSubscription{{ID: 2, Endpoint: "https://push.example/abc", P256dh: "key", Auth: "auth"}},
+		notifications: []models.Notification{{ID: 5, Type: models.NotificationTitleAdded, Payload: `{"title":"Alien"}`}},
+		feedback:      []models.RecommendationFeedback{{ListID: 1, MovieID: 348, MediaType: "movie", Action: "not_interested"}},
+		imports:       []models.ListImport{{ID: 6, ListID: 1, Format: models.ImportLetterboxd, Status: models.ImportCompleted}},
+		webhooks:      []models.ListWebhook{{ID: 7, ListID: 1, URL: "https://hooks.example/list", Secret: "webhook-secret", Events: "movie_added"}},
+	})
+	assert.Equal(t, "list2gether-ana-"+time.Now().UTC().Format("2006-01-02")+".zip", export.Filename())
+
+	var buf bytes.Buffer
+	assert.NoError(t, export.Write(&buf))
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(r)
+		r.Close()
+		assert.True(t, json.Valid(data), f.Name)
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{"profile.json", "memberships.json", "ratings.json", "notes.json", "watches.json", "comments.json", "reactions.json", "sessions.json", "push_subscriptions.json",
+		"notifications.json", "recommendation_feedback.json", "imports.json", "webhooks.json"} {
+		assert.Contains(t, files, name)
+	}
+	assert.Contains(t, files["profile.json"], "ana@example.com")
+	assert.Contains(t, files["ratings.json"], "Heat")
+	assert.NotContains(t, files["ratings.json"], "The Matrix")
+	assert.Contains(t, files["notes.json"], "Rever com legendas")
+	assert.Equal(t, "[]\n", files["watches.json"])
+	assert.Contains(t, files["notifications.json"], `"title": "Alien"`)
+	assert.Contains(t, files["recommendation_feedback.json"], "not_interested")
+	assert.Contains(t, files["imports.json"], "letterboxd")
+	assert.Contains(t, files["webhooks.json"], "https://hooks.example/list")
+
+	// Secrets never leave the server
+	for _, data := range files {
+		assert.NotContains(t, data, "secret-hash")
+		assert.NotContains(t, data, "\"hash\"")
+		assert.NotContains(t, data, "p256dh")
+		assert.NotContains(t, data, "webhook-secret")
+	}
+}
+
+func TestDeleteAccountRequiresPassword(t *testing.T) {
+	userDAO := &mocks.MockUserDAO{}
+	refreshDAO := &mocks.MockRefreshTokenDAO{}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	userDAO.On("FindByID", int64(3)).Return(&models.User{ID: 3, Password: string(hash)}, nil)
+
+	service := NewAccountService(userDAO, refreshDAO, nil, nil)
+	_, err := service.DeleteAccount(3, DeleteAccountOptions{Password: "wrong-password"})
+
+	assert.ErrorIs(t, err, ErrInvalidPassword)
+	refreshDAO.AssertNotCalled(t, "RevokeAllByUserID", int64(3))
+}
+
+func TestDeleteAccountRejectsDeletedAccount(t *testing.T) {
+	userDAO := &mocks.MockUserDAO{}
+	refreshDAO := &mocks.MockRefreshTokenDAO{}
+	deletedAt := time.Now()
+	userDAO.On("FindByID", int64(3)).Return(&models.User{ID: 3, DeletedAt: &deletedAt}, nil)
+
+	service := NewAccountService(userDAO, refreshDAO, nil, nil)
+	_, err := service.DeleteAccount(3, DeleteAccountOptions{Password: "anything"})
+
+	assert.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+// deletingAccountDAO records the deletion plan and settles every owned list as given
+type deletingAccountDAO struct {
+	daos.AccountDAO
+	outcomes []daos.OwnedListOutcome
+	deleted  int64
+	plan     daos.AccountDeletionPlan
+}
+
+func (d *deletingAccountDAO) FindMemberships(userID int64) ([]daos.AccountMembershipRow, error) {
+	return nil, nil
+}
+
+func (d *deletingAccountDAO) DeleteAccount(userID int64, plan daos.AccountDeletionPlan) ([]daos.OwnedListOutcome, error) {
+	d.deleted = userID
+	d.plan = plan
+	return d.outcomes, nil
+}
+
+func TestDeleteAccountRevokesSessionsAndAnonymizes(t *testing.T) {
+	userDAO := &mocks.MockUserDAO{}
+	refreshDAO := &mocks.MockRefreshTokenDAO{}
+	hash, _ := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	userDAO.On("FindByID", int64(3)).Return(&models.User{ID: 3, Username: "ana", Password: string(hash)}, nil)
+	refreshDAO.On("RevokeAllByUserID", int64(3)).Return(nil)
+	newOwner := int64(8)
+	accounts := &deletingAccountDAO{outcomes: []daos.OwnedListOutcome{{ListID: 1, NewOwnerID: &newOwner}, {ListID: 2}}}
+
+	service := NewAccountService(userDAO, refreshDAO, accounts, nil)
+	deletion, err := service.DeleteAccount(3, DeleteAccountOptions{Password: "correct-password"})
+
+	assert.NoError(t, err)
+	refreshDAO.AssertCalled(t, "RevokeAllByUserID", int64(3))
+	assert.Equal(t, int64(3), accounts.deleted)
+	assert.True(t, accounts.plan.TransferLists)
+	assert.NotContains(t, accounts.plan.Username, "ana")
+	assert.Equal(t, accounts.plan.Username+"@deleted.invalid", accounts.plan.Email)
+	assert.Len(t, deletion.TransferredLists, 1)
+	assert.Equal(t, []int64{2}, deletion.DeletedListIDs)
+}
@@ -17,6 +17,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// Upper bound on the favourite genres a user can pick
+const maxGenrePreferences = 20
+
 type AuthService interface {
 	Register(username string, email string, password string) (*models.User, error)
 	Login(email string, password string) (*models.User, string, string, int64, int64, error)
@@ -24,19 +27,28 @@ type AuthService interface {
 	Logout(refreshToken string) error
 	FindUserByID(id int64) (*models.User, error)
 	UpdateProfile(userID int64, username string, avatarURL string) (*models.User, error)
+	GetGenrePreferences(userID int64) ([]int64, error)
+	UpdateGenrePreferences(userID int64, genreIDs []int64) ([]int64, error)
+	UpdateLanguage(userID int64, language string) (*models.User, error)
 	JWTSecret() []byte
 }
 
+// GenrePreferencesListener is told when a user changes their favourite genres
+type GenrePreferencesListener interface {
+	GenrePreferencesChanged(userID int64)
+}
+
 type authService struct {
 	users            daos.UserDAO
 	refreshTokens    daos.RefreshTokenDAO
+	preferences      GenrePreferencesListener
 	jwtSecret        []byte
 	accessExpiresIn  time.Duration
 	refreshExpiresIn time.Duration
 	bcryptCost       int
 }
 
-func NewAuthService(users daos.UserDAO, refreshTokens daos.RefreshTokenDAO) AuthService {
+func NewAuthService(users daos.UserDAO, refreshTokens daos.RefreshTokenDAO, preferences GenrePreferencesListener) AuthService {
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		panic("JWT_SECRET environment variable is required")
@@ -48,6 +60,7 @@ func NewAuthService(users daos.UserDAO, refreshTokens daos.RefreshTokenDAO) Auth
 	return &authService{
 		users:            users,
 		refreshTokens:    refreshTokens,
+		preferences:      preferences,
 		jwtSecret:        secret,
 		accessExpiresIn:  accessDur,
 		refreshExpiresIn: refreshDur,
@@ -218,13 +231,17 @@ func (s *authService) FindUserByID(id int64) (*models.User, error) {
 	if err != nil {
 		return nil, err
 	}
+	// A deleted account's access token stays valid until it expires
+	if user.DeletedAt != nil {
+		return nil, gorm.ErrRecordNotFound
+	}
 	user.Password = ""
 	return user, nil
 }
 
 func (s *authService) UpdateProfile(userID int64, username string, avatarURL string) (*models.User, error) {
 	user, err := s.users.FindByID(userID)
-	if err != nil {
+	if err != nil || user.DeletedAt != nil {
 		return nil, errors.New("user not found")
 	}
 
@@ -261,6 +278,51 @@ func (s *authService) UpdateProfile(userID int64, username string, avatarURL str
 	return user, nil
 }
 
+// GetGenrePreferences returns the genres the user picked as favourites
+func (s *authService) GetGenrePreferences(userID int64) ([]int64, error) {
+	prefs, err := s.users.FindGenrePreferences([]int64{userID})
+	if err != nil {
+		return nil, err
+	}
+	genreIDs := make([]int64, 0, len(prefs))
+	for _, p := range prefs {
+		genreIDs = append(genreIDs, p.GenreID)
+	}
+	return genreIDs, nil
+}
+
+// UpdateGenrePreferences replaces the user's favourite genres, used to seed recommendations for new lists
+func (s *authService) UpdateGenrePreferences(userID int64, genreIDs []int64) ([]int64, error) {
+	unique, err := validateGenreIDs(genreIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.users.ReplaceGenrePreferences(userID, unique); err != nil {
+		return nil, err
+	}
+	if s.preferences != nil {
+		s.preferences.GenrePreferencesChanged(userID)
+	}
+	return unique, nil
+}
+
+// UpdateLanguage sets the language the user's emails are written in
+func (s *authService) UpdateLanguage(userID int64, language string) (*models.User, error) {
+	if !models.IsSupportedLanguage(language) {
+		return nil, errors.New("language must be one of: pt, en")
+	}
+	user, err := s.users.FindByID(userID)
+	if err != nil || user.DeletedAt != nil {
+		return nil, errors.New("user not found")
+	}
+	user.Language = language
+	if err := s.users.Update(user); err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
 func (s *authService) generateAccessToken(user *models.User) (string, int64, error) {
 	now := time.Now().UTC()
 	exp := now.Add(s.accessExpiresIn)
@@ -356,6 +418,25 @@ func validateEmail(e string) error {
 	return nil
 }
 
+func validateGenreIDs(genreIDs []int64) ([]int64, error) {
+	seen := make(map[int64]bool, len(genreIDs))
+	unique := make([]int64, 0, len(genreIDs))
+	for _, id := range genreIDs {
+		if id <= 0 {
+			return nil, errors.New("genre IDs must be positive")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	if len(unique) > maxGenrePreferences {
+		return nil, errors.New("at most 20 genres can be selected")
+	}
+	return unique, nil
+}
+
 func validateAvatarURL(url string) error {
 	if url == "" {
 		return nil
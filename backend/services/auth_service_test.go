@@ -36,7 +36,7 @@ func TestNewAuthService(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	assert.NotNil(t, service)
 	assert.NotNil(t, service.JWTSecret())
@@ -50,7 +50,7 @@ func TestRegister_Success(t *testing.T) {
 	userDAO.On("FindByUsername", "testuser").Return(nil, gorm.ErrRecordNotFound)
 	userDAO.On("Create", mock.AnythingOfType("*models.User")).Return(nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	user, err := service.Register("testuser", "test@example.com", "password123")
 
@@ -66,7 +66,7 @@ func TestRegister_Success(t *testing.T) {
 func TestRegister_UsernameValidation(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	tests := []struct {
 		name        string
@@ -101,7 +101,7 @@ func TestRegister_UsernameValidation(t *testing.T) {
 func TestRegister_EmailValidation(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	tests := []struct {
 		name        string
@@ -137,7 +137,7 @@ func TestRegister_EmailValidation(t *testing.T) {
 func TestRegister_PasswordValidation(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, err := service.Register("testuser", "test@example.com", "short")
 
@@ -152,7 +152,7 @@ func TestRegister_DuplicateEmail(t *testing.T) {
 	existingUser := &models.User{ID: 1, Email: "test@example.com"}
 	userDAO.On("FindByEmail", "test@example.com").Return(existingUser, nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, err := service.Register("testuser", "test@example.com", "password123")
 
@@ -169,7 +169,7 @@ func TestRegister_DuplicateUsername(t *testing.T) {
 	existingUser := &models.User{ID: 1, Username: "testuser"}
 	userDAO.On("FindByUsername", "testuser").Return(existingUser, nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, err := service.Register("testuser", "test@example.com", "password123")
 
@@ -193,7 +193,7 @@ func TestLogin_Success(t *testing.T) {
 	userDAO.On("FindByEmail", "test@example.com").Return(user, nil)
 	refreshDAO.On("Create", mock.AnythingOfType("*models.RefreshToken")).Return(nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	resultUser, accessToken, refreshToken, expiresIn, accessExp, err := service.Login("test@example.com", "password123")
 
@@ -216,7 +216,7 @@ func TestLogin_InvalidEmail(t *testing.T) {
 
 	userDAO.On("FindByEmail", "wrong@example.com").Return(nil, gorm.ErrRecordNotFound)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, _, _, _, _, err := service.Login("wrong@example.com", "password123")
 
@@ -238,7 +238,7 @@ func TestLogin_InvalidPassword(t *testing.T) {
 
 	userDAO.On("FindByEmail", "test@example.com").Return(user, nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, _, _, _, _, err := service.Login("test@example.com", "wrongpassword")
 
@@ -251,7 +251,7 @@ func TestRefresh_Success(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 	secret := service.JWTSecret()
 
 	// Create a valid refresh token.
@@ -304,7 +304,7 @@ func TestRefresh_InvalidToken(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, _, _, _, err := service.Refresh("invalid.token.here")
 
@@ -316,7 +316,7 @@ func TestRefresh_RevokedToken(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 	secret := service.JWTSecret()
 
 	exp := time.Now().UTC().Add(24 * time.Hour)
@@ -357,7 +357,7 @@ func TestLogout_Success(t *testing.T) {
 
 	refreshDAO.On("RevokeByHash", mock.Anything).Return(nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	err := service.Logout("some.refresh.token")
 
@@ -378,7 +378,7 @@ func TestFindUserByID_Success(t *testing.T) {
 
 	userDAO.On("FindByID", int64(1)).Return(user, nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	resultUser, err := service.FindUserByID(1)
 
@@ -396,7 +396,7 @@ func TestFindUserByID_NotFound(t *testing.T) {
 
 	userDAO.On("FindByID", int64(999)).Return(nil, gorm.ErrRecordNotFound)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, err := service.FindUserByID(999)
 
@@ -418,7 +418,7 @@ func TestUpdateProfile_Success(t *testing.T) {
 	userDAO.On("FindByUsername", "newuser").Return(nil, gorm.ErrRecordNotFound)
 	userDAO.On("Update", mock.AnythingOfType("*models.User")).Return(nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	avatarURL := "https://example.com/avatar.png"
 	resultUser, err := service.UpdateProfile(1, "newuser", avatarURL)
@@ -445,7 +445,7 @@ func TestUpdateProfile_InvalidUsername(t *testing.T) {
 
 	userDAO.On("FindByID", int64(1)).Return(user, nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, err := service.UpdateProfile(1, "ab", "")
 
@@ -472,7 +472,7 @@ func TestUpdateProfile_DuplicateUsername(t *testing.T) {
 	userDAO.On("FindByID", int64(1)).Return(user, nil)
 	userDAO.On("FindByUsername", "takenuser").Return(existingUser, nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	_, err := service.UpdateProfile(1, "takenuser", "")
 
@@ -493,7 +493,7 @@ func TestUpdateProfile_InvalidAvatarURL(t *testing.T) {
 
 	userDAO.On("FindByID", int64(1)).Return(user, nil)
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 
 	// Test non-HTTPS URL.
 	_, err := service.UpdateProfile(1, "testuser", "http://example.com/avatar.png")
@@ -645,7 +645,7 @@ func TestGenerateAccessToken(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO).(*authService)
+	service := NewAuthService(userDAO, refreshDAO, nil).(*authService)
 
 	user := &models.User{
 		ID:       1,
@@ -679,7 +679,7 @@ func TestGenerateRefreshToken(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO).(*authService)
+	service := NewAuthService(userDAO, refreshDAO, nil).(*authService)
 
 	user := &models.User{
 		ID:       1,
@@ -711,7 +711,7 @@ func TestRefresh_WrongTokenType(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 	secret := service.JWTSecret()
 
 	// Create an access token instead of refresh token.
@@ -735,7 +735,7 @@ func TestRefresh_ExpiredToken(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 	secret := service.JWTSecret()
 
 	// Create an expired refresh token.
@@ -759,7 +759,7 @@ func TestRefresh_UserIDMismatch(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 	secret := service.JWTSecret()
 
 	exp := time.Now().UTC().Add(24 * time.Hour)
@@ -798,7 +798,7 @@ func TestRefresh_TokenNotInDatabase(t *testing.T) {
 	userDAO := &mocks.MockUserDAO{}
 	refreshDAO := &mocks.MockRefreshTokenDAO{}
 
-	service := NewAuthService(userDAO, refreshDAO)
+	service := NewAuthService(userDAO, refreshDAO, nil)
 	secret := service.JWTSecret()
 
 	exp := time.Now().UTC().Add(24 * time.Hour)
@@ -822,3 +822,67 @@ func TestRefresh_TokenNotInDatabase(t *testing.T) {
 	assert.Equal(t, "invalid or expired refresh token", err.Error())
 	refreshDAO.AssertExpectations(t)
 }
+
+func TestUpdateGenrePreferences_Success(t *testing.T) {
+	userDAO := &mocks.MockUserDAO{}
+	refreshDAO := &mocks.MockRefreshTokenDAO{}
+
+	userDAO.On("ReplaceGenrePreferences", int64(1), []int64{27, 35}).Return(nil)
+
+	service := NewAuthService(userDAO, refreshDAO, nil)
+
+	genreIDs, err := service.UpdateGenrePreferences(1, []int64{27, 35, 27})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{27, 35}, genreIDs)
+	userDAO.AssertExpectations(t)
+}
+
+type recordingPreferencesListener struct {
+	changed []int64
+}
+
+func (l *recordingPreferencesListener) GenrePreferencesChanged(userID int64) {
+	l.changed = append(l.changed, userID)
+}
+
+func TestUpdateGenrePreferences_NotifiesListener(t *testing.T) {
+	userDAO := &mocks.MockUserDAO{}
+	userDAO.On("ReplaceGenrePreferences", int64(1), []int64{18}).Return(nil)
+	listener := &recordingPreferencesListener{}
+
+	service := NewAuthService(userDAO, &mocks.MockRefreshTokenDAO{}, listener)
+	_, err := service.UpdateGenrePreferences(1, []int64{18})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, listener.changed)
+}
+
+func TestUpdateGenrePreferences_Validation(t *testing.T) {
+	tooMany := make([]int64, 0, maxGenrePreferences+1)
+	for i := 1; i <= maxGenrePreferences+1; i++ {
+		tooMany = append(tooMany, int64(i))
+	}
+
+	tests := []struct {
+		name     string
+		genreIDs []int64
+		expected string
+	}{
+		{"non-positive ID", []int64{27, 0}, "genre IDs must be positive"},
+		{"too many genres", tooMany, "at most 20 genres can be selected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userDAO := &mocks.MockUserDAO{}
+			service := NewAuthService(userDAO, &mocks.MockRefreshTokenDAO{}, nil)
+
+			_, err := service.UpdateGenrePreferences(1, tt.genreIDs)
+
+			assert.Error(t, err)
+			assert.Equal(t, tt.expected, err.Error())
+			userDAO.AssertNotCalled(t, "ReplaceGenrePreferences", mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+)
+
+var ErrInvalidCursor = errors.New("invalid_cursor")
+
+// boardCursor points just past the last post of a board page
+type boardCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// encodeBoardCursor returns an opaque token for the position after the comment
+func encodeBoardCursor(comment models.Comment) string {
+	raw := fmt.Sprintf("%d:%d", comment.CreatedAt.UnixNano(), comment.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeBoardCursor(token string) (*boardCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	ts, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	commentID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || commentID <= 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &boardCursor{CreatedAt: time.Unix(0, ts).UTC(), ID: commentID}, nil
+}
+
+// GetBoardComments returns a page of the list's discussion board, newest first. The
+// returned cursor fetches the next page and is empty on the last one.
+func (s *listService) GetBoardComments(listID, userID int64, cursor string, limit int) ([]models.Comment, string, error) {
+	var before *boardCursor
+	if cursor != "" {
+		decoded, err := decodeBoardCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		before = decoded
+	}
+
+	role, err := s.commentReaderRole(listID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Sanitize pagination
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	var beforeCreatedAt *time.Time
+	var beforeID int64
+	if before != nil {
+		beforeCreatedAt = &before.CreatedAt
+		beforeID = before.ID
+	}
+	// Fetch one extra post to know whether another page exists
+	comments, err := s.lists.FindBoardComments(listID, beforeCreatedAt, beforeID, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		next = encodeBoardCursor(comments[limit-1])
+	}
+	redactHiddenComments(comments, userID, role)
+	return comments, next, nil
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoardCursor_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 10, 31, 20, 15, 0, 123000000, time.UTC)
+
+	token := encodeBoardCursor(models.Comment{ID: 42, CreatedAt: createdAt})
+	cursor, err := decodeBoardCursor(token)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), cursor.ID)
+	assert.True(t, createdAt.Equal(cursor.CreatedAt))
+}
+
+func TestDecodeBoardCursor_Invalid(t *testing.T) {
+	for _, token := range []string{"%%%", "bm9wZQ", "MTIzOmFiYw", "MTIzOjA"} {
+		_, err := decodeBoardCursor(token)
+		assert.Equal(t, ErrInvalidCursor, err, token)
+	}
+}
+
+func TestCommentInScope(t *testing.T) {
+	movieID := int64(7)
+	otherID := int64(8)
+	board := models.Comment{ListID: 1}
+	thread := models.Comment{ListID: 1, MovieID: &movieID}
+
+	assert.True(t, board.InScope(1, nil))
+	assert.False(t, board.InScope(1, &movieID))
+	assert.True(t, thread.InScope(1, &movieID))
+	assert.False(t, thread.InScope(1, &otherID))
+	assert.False(t, thread.InScope(1, nil))
+	assert.False(t, thread.InScope(2, &movieID))
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"html"
+	"net/url"
+	"strings"
+)
+
+// Comment formatting is a small Markdown subset:
+//
+//	**bold**  *italic*  [text](https://example.com)  ||spoiler||  and line breaks
+//
+// Anything that does not form a complete span is kept as plain text, so every input
+// parses. Spans of the same kind do not nest.
+
+var ErrCommentLinkNotAllowed = errors.New("comment_link_not_allowed")
+
+const (
+	boldDelimiter   = "**"
+	italicDelimiter = "*"
+	maxLinkLength   = 2048
+)
+
+type FormatNodeType string
+
+const (
+	NodeText      FormatNodeType = "text"
+	NodeBold      FormatNodeType = "bold"
+	NodeItalic    FormatNodeType = "italic"
+	NodeLink      FormatNodeType = "link"
+	NodeSpoiler   FormatNodeType = "spoiler"
+	NodeLineBreak FormatNodeType = "line_break"
+)
+
+// FormatNode is a node of a parsed comment. Text nodes carry Text; links carry Href;
+// bold, italic, link and spoiler nodes carry Children.
+type FormatNode struct {
+	Type     FormatNodeType `json:"type"`
+	Text     string         `json:"text,omitempty"`
+	Href     string         `json:"href,omitempty"`
+	Children []FormatNode   `json:"children,omitempty"`
+}
+
+type formatParser struct {
+	rejectedLinks []string
+}
+
+// FormatComment parses comment content into nodes. Links with a disallowed target are
+// kept as plain text.
+func FormatComment(content string) []FormatNode {
+	p := &formatParser{}
+	return p.parse(normalizeLineBreaks(content), nil)
+}
+
+// RenderCommentHTML renders parsed nodes as HTML. All text is escaped and only the tags
+// produced here can appear in the output.
+func RenderCommentHTML(nodes []FormatNode) string {
+	var b strings.Builder
+	renderNodes(&b, nodes)
+	return b.String()
+}
+
+// validateCommentFormat rejects content whose links point anywhere but http(s) URLs
+func validateCommentFormat(content string) error {
+	p := &formatParser{}
+	p.parse(normalizeLineBreaks(content), nil)
+	if len(p.rejectedLinks) > 0 {
+		return ErrCommentLinkNotAllowed
+	}
+	return nil
+}
+
+func normalizeLineBreaks(content string) string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	return strings.ReplaceAll(content, "\r", "\n")
+}
+
+// parse turns s into nodes. disabled holds the span kinds already open around s.
+func (p *formatParser) parse(s string, disabled map[FormatNodeType]bool) []FormatNode {
+	nodes := make([]FormatNode, 0, 1)
+	appendText := func(text string) {
+		if text == "" {
+			return
+		}
+		if n := len(nodes); n > 0 && nodes[n-1].Type == NodeText {
+			nodes[n-1].Text += text
+			return
+		}
+		nodes = append(nodes, FormatNode{Type: NodeText, Text: text})
+	}
+	within := func(kind FormatNodeType) map[FormatNodeType]bool {
+		next := make(map[FormatNodeType]bool, len(disabled)+1)
+		for k := range disabled {
+			next[k] = true
+		}
+		next[kind] = true
+		return next
+	}
+
+	text := 0
+	for i := 0; i < len(s); {
+		var node *FormatNode
+		consumed := 0
+
+		switch {
+		case s[i] == '\n':
+			node, consumed = &FormatNode{Type: NodeLineBreak}, 1
+		case strings.HasPrefix(s[i:], spoilerDelimiter) && !disabled[NodeSpoiler]:
+			if inner, ok := delimitedSpan(s[i:], spoilerDelimiter, true); ok {
+				node = &FormatNode{Type: NodeSpoiler, Children: p.parse(inner, within(NodeSpoiler))}
+				consumed = len(inner) + 2*len(spoilerDelimiter)
+			}
+		case strings.HasPrefix(s[i:], boldDelimiter) && !disabled[NodeBold]:
+			if inner, ok := delimitedSpan(s[i:], boldDelimiter, false); ok {
+				node = &FormatNode{Type: NodeBold, Children: p.parse(inner, within(NodeBold))}
+				consumed = len(inner) + 2*len(boldDelimiter)
+			}
+		case s[i] == '*' && !strings.HasPrefix(s[i:], boldDelimiter) && !disabled[NodeItalic]:
+			if inner, ok := delimitedSpan(s[i:], italicDelimiter, false); ok {
+				node = &FormatNode{Type: NodeItalic, Children: p.parse(inner, within(NodeItalic))}
+				consumed = len(inner) + 2*len(italicDelimiter)
+			}
+		case s[i] == '[' && !disabled[NodeLink]:
+			if label, href, n, ok := linkSpan(s[i:]); ok {
+				if isAllowedLink(href) {
+					node = &FormatNode{Type: NodeLink, Href: href, Children: p.parse(label, within(NodeLink))}
+					consumed = n
+				} else {
+					p.rejectedLinks = append(p.rejectedLinks, href)
+				}
+			}
+		}
+
+		if node == nil {
+			i++
+			continue
+		}
+		appendText(s[text:i])
+		nodes = append(nodes, *node)
+		i += consumed
+		text = i
+	}
+	appendText(s[text:])
+	return nodes
+}
+
+// delimitedSpan returns the text between the delimiter at the start of s and its next
+// occurrence. Bold and italic stay on one line and may not start or end with a space.
+func delimitedSpan(s, delimiter string, multiline bool) (string, bool) {
+	rest := s[len(delimiter):]
+	end := strings.Index(rest, delimiter)
+	// "**" closes a bold span, never an italic one
+	for delimiter == italicDelimiter && end >= 0 && strings.HasPrefix(rest[end:], boldDelimiter) {
+		next := strings.Index(rest[end+len(boldDelimiter):], delimiter)
+		if next < 0 {
+			return "", false
+		}
+		end += len(boldDelimiter) + next
+	}
+	if end <= 0 {
+		return "", false
+	}
+	// In "***" the bold span closes on the last two stars, leaving one for an inner italic
+	for delimiter == boldDelimiter && end+len(delimiter) < len(rest) && rest[end+len(delimiter)] == '*' {
+		end++
+	}
+	inner := rest[:end]
+	if !multiline {
+		if strings.Contains(inner, "\n") || strings.TrimSpace(inner) != inner {
+			return "", false
+		}
+	}
+	return inner, true
+}
+
+// linkSpan matches [label](href) at the start of s and reports how many bytes it spans
+func linkSpan(s string) (string, string, int, bool) {
+	closeLabel := strings.Index(s, "](")
+	if closeLabel <= 1 || strings.Contains(s[:closeLabel], "\n") {
+		return "", "", 0, false
+	}
+	rest := s[closeLabel+2:]
+	closeHref := strings.IndexByte(rest, ')')
+	if closeHref <= 0 {
+		return "", "", 0, false
+	}
+	href := rest[:closeHref]
+	if strings.ContainsAny(href, " \t\n") {
+		return "", "", 0, false
+	}
+	return s[1:closeLabel], href, closeLabel + 2 + closeHref + 1, true
+}
+
+// isAllowedLink accepts absolute http and https URLs only
+func isAllowedLink(href string) bool {
+	if len(href) > maxLinkLength {
+		return false
+	}
+	u, err := url.Parse(href)
+	if err != nil {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	return (scheme == "http" || scheme == "https") && u.Host != "" && u.User == nil
+}
+
+func renderNodes(b *strings.Builder, nodes []FormatNode) {
+	for _, node := range nodes {
+		switch node.Type {
+		case NodeText:
+			b.WriteString(html.EscapeString(node.Text))
+		case NodeLineBreak:
+			b.WriteString("<br>")
+		case NodeBold:
+			b.WriteString("<strong>")
+			renderNodes(b, node.Children)
+			b.WriteString("</strong>")
+		case NodeItalic:
+			b.WriteString("<em>")
+			renderNodes(b, node.Children)
+			b.WriteString("</em>")
+		case NodeSpoiler:
+			b.WriteString(`<span class="spoiler">`)
+			renderNodes(b, node.Children)
+			b.WriteString("</span>")
+		case NodeLink:
+			b.WriteString(`<a href="`)
+			b.WriteString(html.EscapeString(node.Href))
+			b.WriteString(`" rel="nofollow noopener noreferrer" target="_blank">`)
+			renderNodes(b, node.Children)
+			b.WriteString("</a>")
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/8bury/list2gether/models"
+)
+
+// Length of the comment excerpt stored in comment and mention notifications, in characters
+const mentionExcerptLength = 140
+
+// parseMentions finds @username mentions of the given members. Usernames may contain
+// spaces, so each "@" is matched against the longest member username that follows it.
+// An "@" preceded by a letter or digit (as in an email address) is not a mention.
+func parseMentions(content string, members []models.User) []models.CommentMention {
+	candidates := make([]models.User, 0, len(members))
+	for _, m := range members {
+		if m.Username != "" {
+			candidates = append(candidates, m)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len([]rune(candidates[i].Username)) > len([]rune(candidates[j].Username))
+	})
+
+	runes := []rune(content)
+	mentions := make([]models.CommentMention, 0)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isMentionRune(runes[i-1])) {
+			continue
+		}
+		for _, member := range candidates {
+			name := []rune(member.Username)
+			end := i + 1 + len(name)
+			if end > len(runes) || !strings.EqualFold(string(runes[i+1:end]), member.Username) {
+				continue
+			}
+			if end < len(runes) && isMentionRune(runes[end]) {
+				continue
+			}
+			mentions = append(mentions, models.CommentMention{
+				Offset: i,
+				Length: end - i,
+				UserID: member.ID,
+			})
+			i = end - 1
+			break
+		}
+	}
+	return mentions
+}
+
+func isMentionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// mentionRecipients returns the users to notify about the mentions, skipping the author
+// and anyone in alreadyNotified
+func mentionRecipients(mentions []models.CommentMention, authorID int64, alreadyNotified []models.CommentMention) []int64 {
+	skip := map[int64]bool{authorID: true}
+	for _, m := range alreadyNotified {
+		skip[m.UserID] = true
+	}
+	recipients := make([]int64, 0, len(mentions))
+	for _, m := range mentions {
+		if skip[m.UserID] {
+			continue
+		}
+		skip[m.UserID] = true
+		recipients = append(recipients, m.UserID)
+	}
+	return recipients
+}
+
+// notifyMentions notifies every recipient that the comment mentioned them
+func (s *listService) notifyMentions(comment *models.Comment, recipients []int64) {
+	s.notify(models.NotificationMention, comment.ListID, comment.UserID, recipients, models.MentionPayload{
+		ListID:    comment.ListID,
+		MovieID:   comment.MovieID,
+		CommentID: comment.ID,
+		Excerpt:   commentExcerpt(comment.Content),
+	})
+}
+
+// notifyComment tells the other members about a new comment, except those it mentions
+func (s *listService) notifyComment(comment *models.Comment, members []models.ListMember, mentioned []int64) {
+	exclude := append([]int64{comment.UserID}, mentioned...)
+	s.notify(models.NotificationComment, comment.ListID, comment.UserID, otherMembers(members, exclude...), models.CommentPayload{
+		ListID:    comment.ListID,
+		MovieID:   comment.MovieID,
+		CommentID: comment.ID,
+		ParentID:  comment.ParentID,
+		Excerpt:   commentExcerpt(comment.Content),
+	})
+}
+
+// commentExcerpt shortens comment content for notifications
+func commentExcerpt(content string) string {
+	excerpt := []rune(content)
+	if len(excerpt) > mentionExcerptLength {
+		excerpt = append(excerpt[:mentionExcerptLength], '…')
+	}
+	return string(excerpt)
+}
+
+// memberUsers returns the users who can be mentioned in the list
+func (s *listService) memberUsers(listID int64) ([]models.User, error) {
+	members, err := s.lists.FindMembersWithUser(listID)
+	if err != nil {
+		return nil, err
+	}
+	return usersOf(members), nil
+}
+
+func usersOf(members []models.ListMember) []models.User {
+	users := make([]models.User, 0, len(members))
+	for _, m := range members {
+		users = append(users, m.User)
+	}
+	return users
+}
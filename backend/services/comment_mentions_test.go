@@ -0,0 +1,67 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	members := []models.User{
+		{ID: 1, Username: "ana"},
+		{ID: 2, Username: "ana paula"},
+		{ID: 3, Username: "João"},
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected []models.CommentMention
+	}{
+		{
+			name:     "simple mention",
+			content:  "@ana viu?",
+			expected: []models.CommentMention{{Offset: 0, Length: 4, UserID: 1}},
+		},
+		{
+			name:     "longest username wins",
+			content:  "oi @Ana Paula!",
+			expected: []models.CommentMention{{Offset: 3, Length: 10, UserID: 2}},
+		},
+		{
+			name:     "offsets count characters",
+			content:  "é @joão e @ana",
+			expected: []models.CommentMention{{Offset: 2, Length: 5, UserID: 3}, {Offset: 10, Length: 4, UserID: 1}},
+		},
+		{
+			name:     "email is not a mention",
+			content:  "mande para x@ana.com",
+			expected: []models.CommentMention{},
+		},
+		{
+			name:     "partial username is not a mention",
+			content:  "@anabela e @someone",
+			expected: []models.CommentMention{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseMentions(tt.content, members))
+		})
+	}
+}
+
+func TestMentionRecipients(t *testing.T) {
+	mentions := []models.CommentMention{
+		{Offset: 0, UserID: 1},
+		{Offset: 5, UserID: 2},
+		{Offset: 10, UserID: 2},
+		{Offset: 15, UserID: 3},
+	}
+	previous := []models.CommentMention{{UserID: 3}}
+
+	assert.Equal(t, []int64{2}, mentionRecipients(mentions, 1, previous))
+	assert.Equal(t, []int64{1, 2, 3}, mentionRecipients(mentions, 9, nil))
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrModeratorOnly           = errors.New("moderator_only")
+	ErrInvalidModerationAction = errors.New("invalid_moderation_action")
+	ErrModerationNotApplicable = errors.New("moderation_not_applicable")
+	ErrModerationReasonTooLong = errors.New("moderation_reason_too_long")
+	ErrCommentHidden           = errors.New("comment_hidden")
+)
+
+const maxModerationReasonLength = 500
+
+func (s *listService) ModerateComment(listID, moderatorID int64, movieID *int64, commentID int64, action models.ModerationAction, reason *string) (*models.Comment, error) {
+	switch action {
+	case models.ModerationHide, models.ModerationUnhide, models.ModerationDelete, models.ModerationRestore:
+	default:
+		return nil, ErrInvalidModerationAction
+	}
+	if reason != nil {
+		trimmed := strings.TrimSpace(*reason)
+		if len([]rune(trimmed)) > maxModerationReasonLength {
+			return nil, ErrModerationReasonTooLong
+		}
+		if trimmed == "" {
+			reason = nil
+		} else {
+			reason = &trimmed
+		}
+	}
+
+	// Check list exists
+	if _, err := s.lists.FindByID(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+
+	// Only the list owner moderates
+	membership, err := s.lists.FindMembership(listID, moderatorID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrForbiddenMembership
+		}
+		return nil, err
+	}
+	if membership.Role != models.RoleOwner {
+		return nil, ErrModeratorOnly
+	}
+
+	comment, err := s.lists.FindCommentByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	if !comment.InScope(listID, movieID) {
+		return nil, ErrCommentNotFound
+	}
+	if err := checkModerationApplicable(comment, action); err != nil {
+		return nil, err
+	}
+
+	moderated, err := s.lists.ModerateComment(&models.CommentModerationLog{
+		ListID:      listID,
+		CommentID:   &comment.ID,
+		ModeratorID: moderatorID,
+		AuthorID:    comment.UserID,
+		Action:      action,
+		Reason:      reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	eventType := models.StreamCommentUpdated
+	if action == models.ModerationDelete {
+		eventType = models.StreamCommentDeleted
+	}
+	s.publishComment(eventType, moderated, moderatorID)
+	return moderated, nil
+}
+
+// checkModerationApplicable rejects actions that would not change the comment. Comments
+// their authors deleted stay deleted: moderators only undo their own deletions.
+func checkModerationApplicable(comment *models.Comment, action models.ModerationAction) error {
+	switch action {
+	case models.ModerationHide:
+		if comment.IsHidden() || comment.IsDeleted() {
+			return ErrModerationNotApplicable
+		}
+	case models.ModerationUnhide:
+		if !comment.IsHidden() || comment.IsDeleted() {
+			return ErrModerationNotApplicable
+		}
+	case models.ModerationDelete:
+		if comment.IsDeleted() {
+			return ErrModerationNotApplicable
+		}
+	case models.ModerationRestore:
+		if !comment.IsDeleted() || comment.IsSelfDeleted() {
+			return ErrModerationNotApplicable
+		}
+	default:
+		return ErrInvalidModerationAction
+	}
+	return nil
+}
+
+func (s *listService) GetCommentRevisions(listID, userID int64, movieID *int64, commentID int64) (*models.Comment, []models.CommentRevision, error) {
+	role, err := s.commentReaderRole(listID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	comment, err := s.lists.FindCommentByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrCommentNotFound
+		}
+		return nil, nil, err
+	}
+	if !comment.InScope(listID, movieID) || comment.IsDeleted() {
+		return nil, nil, ErrCommentNotFound
+	}
+	// Earlier versions of a hidden comment are as private as the comment itself
+	if comment.IsHidden() && role != models.RoleOwner && comment.UserID != userID {
+		return nil, nil, ErrCommentHidden
+	}
+
+	revisions, err := s.lists.FindCommentRevisions(comment.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return comment, revisions, nil
+}
+
+func (s *listService) GetModerationLog(listID, userID int64, limit, offset int) ([]models.CommentModerationLog, int64, error) {
+	role, err := s.commentReaderRole(listID, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if role != models.RoleOwner {
+		return nil, 0, ErrModeratorOnly
+	}
+
+	// Sanitize pagination
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.lists.FindModerationLog(listID, limit, offset)
+}
+
+// commentReaderRole checks the list exists and returns the caller's role in it
+func (s *listService) commentReaderRole(listID, userID int64) (models.ListMemberRole, error) {
+	if _, err := s.lists.FindByID(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrListNotFound
+		}
+		return "", err
+	}
+
+	membership, err := s.lists.FindMembership(listID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrForbiddenMembership
+		}
+		return "", err
+	}
+	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
+		return "", ErrForbiddenMembership
+	}
+	return membership.Role, nil
+}
+
+// redactHiddenComments marks hidden comments the reader may not see. The list owner and
+// the comment's author still see the content.
+func redactHiddenComments(comments []models.Comment, viewerID int64, role models.ListMemberRole) {
+	if role == models.RoleOwner {
+		return
+	}
+	for i := range comments {
+		if comments[i].IsHidden() && comments[i].UserID != viewerID {
+			comments[i].Redacted = true
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckModerationApplicable(t *testing.T) {
+	now := time.Now()
+	author := int64(2)
+	owner := int64(1)
+
+	live := &models.Comment{UserID: author}
+	hidden := &models.Comment{UserID: author, HiddenAt: &now, HiddenBy: &owner}
+	deletedByOwner := &models.Comment{UserID: author, DeletedAt: &now, DeletedBy: &owner}
+	deletedByAuthor := &models.Comment{UserID: author, DeletedAt: &now, DeletedBy: &author}
+	authorReason := models.CommentDeletedByAuthor
+	deletedByAuthorWithReason := &models.Comment{UserID: author, DeletedAt: &now, DeletedBy: &author, DeletedReason: &authorReason}
+	memberLeft := models.CommentDeletedMemberLeft
+	deletedOnLeave := &models.Comment{UserID: author, DeletedAt: &now, DeletedBy: &author, DeletedReason: &memberLeft}
+
+	tests := []struct {
+		name     string
+		comment  *models.Comment
+		action   models.ModerationAction
+		expected error
+	}{
+		{name: "hide live", comment: live, action: models.ModerationHide},
+		{name: "hide twice", comment: hidden, action: models.ModerationHide, expected: ErrModerationNotApplicable},
+		{name: "unhide hidden", comment: hidden, action: models.ModerationUnhide},
+		{name: "unhide live", comment: live, action: models.ModerationUnhide, expected: ErrModerationNotApplicable},
+		{name: "delete hidden", comment: hidden, action: models.ModerationDelete},
+		{name: "delete twice", comment: deletedByOwner, action: models.ModerationDelete, expected: ErrModerationNotApplicable},
+		{name: "restore moderator deletion", comment: deletedByOwner, action: models.ModerationRestore},
+		{name: "restore author deletion", comment: deletedByAuthor, action: models.ModerationRestore, expected: ErrModerationNotApplicable},
+		{name: "restore author deletion with reason", comment: deletedByAuthorWithReason, action: models.ModerationRestore, expected: ErrModerationNotApplicable},
+		{name: "restore comment of member who left", comment: deletedOnLeave, action: models.ModerationRestore},
+		{name: "restore live", comment: live, action: models.ModerationRestore, expected: ErrModerationNotApplicable},
+		{name: "unknown action", comment: live, action: "ban", expected: ErrInvalidModerationAction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, checkModerationApplicable(tt.comment, tt.action))
+		})
+	}
+}
+
+func TestRedactHiddenComments(t *testing.T) {
+	now := time.Now()
+	newComments := func() []models.Comment {
+		return []models.Comment{
+			{ID: 1, UserID: 2, HiddenAt: &now},
+			{ID: 2, UserID: 3, HiddenAt: &now},
+			{ID: 3, UserID: 3},
+		}
+	}
+
+	comments := newComments()
+	redactHiddenComments(comments, 3, models.RoleParticipant)
+	assert.True(t, comments[0].Redacted)
+	// Authors keep seeing their own hidden comments
+	assert.False(t, comments[1].Redacted)
+	assert.False(t, comments[2].Redacted)
+
+	comments = newComments()
+	redactHiddenComments(comments, 1, models.RoleOwner)
+	for _, comment := range comments {
+		assert.False(t, comment.Redacted)
+	}
+}
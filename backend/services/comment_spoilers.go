@@ -0,0 +1,74 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/8bury/list2gether/models"
+)
+
+// Inline spoiler markup: ||hidden text||
+const spoilerDelimiter = "||"
+
+type CommentSegmentType string
+
+const (
+	SegmentText    CommentSegmentType = "text"
+	SegmentSpoiler CommentSegmentType = "spoiler"
+)
+
+// CommentSegment is a run of comment text that is either shown or hidden as a spoiler
+type CommentSegment struct {
+	Type CommentSegmentType `json:"type"`
+	Text string             `json:"text"`
+}
+
+// SplitSpoilers splits comment content on ||spoiler|| markup. An unmatched delimiter is
+// kept as plain text and empty spoilers are dropped.
+func SplitSpoilers(content string) []CommentSegment {
+	segments := make([]CommentSegment, 0, 1)
+	appendSegment := func(kind CommentSegmentType, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(segments); n > 0 && segments[n-1].Type == kind {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, CommentSegment{Type: kind, Text: text})
+	}
+
+	rest := content
+	for {
+		start := strings.Index(rest, spoilerDelimiter)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start+len(spoilerDelimiter):], spoilerDelimiter)
+		if end < 0 {
+			break
+		}
+		end += start + len(spoilerDelimiter)
+		appendSegment(SegmentText, rest[:start])
+		appendSegment(SegmentSpoiler, rest[start+len(spoilerDelimiter):end])
+		rest = rest[end+len(spoilerDelimiter):]
+	}
+	appendSegment(SegmentText, rest)
+	return segments
+}
+
+// markAutoSpoilers flags comments written by members who watched the title when the
+// reader has not watched it yet
+func markAutoSpoilers(comments []models.Comment, watches []models.ListMovieWatch, readerID int64) {
+	watched := make(map[int64]bool, len(watches))
+	for _, w := range watches {
+		watched[w.UserID] = true
+	}
+	if watched[readerID] {
+		return
+	}
+	for i := range comments {
+		if comments[i].UserID != readerID && watched[comments[i].UserID] {
+			comments[i].AutoSpoiler = true
+		}
+	}
+}
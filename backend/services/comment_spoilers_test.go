@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSpoilers(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []CommentSegment
+	}{
+		{
+			name:     "no markup",
+			content:  "que filme",
+			expected: []CommentSegment{{Type: SegmentText, Text: "que filme"}},
+		},
+		{
+			name:    "inline spoiler",
+			content: "no fim ||ele morre|| acredita?",
+			expected: []CommentSegment{
+				{Type: SegmentText, Text: "no fim "},
+				{Type: SegmentSpoiler, Text: "ele morre"},
+				{Type: SegmentText, Text: " acredita?"},
+			},
+		},
+		{
+			name:    "adjacent spoilers merge",
+			content: "||a||||b||",
+			expected: []CommentSegment{
+				{Type: SegmentSpoiler, Text: "ab"},
+			},
+		},
+		{
+			name:     "unmatched delimiter stays text",
+			content:  "a || b",
+			expected: []CommentSegment{{Type: SegmentText, Text: "a || b"}},
+		},
+		{
+			name:     "empty content",
+			content:  "",
+			expected: []CommentSegment{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SplitSpoilers(tt.content))
+		})
+	}
+}
+
+func TestMarkAutoSpoilers(t *testing.T) {
+	watches := []models.ListMovieWatch{{UserID: 1}, {UserID: 2}}
+
+	t.Run("reader has not watched", func(t *testing.T) {
+		comments := []models.Comment{{ID: 1, UserID: 1}, {ID: 2, UserID: 3}}
+		markAutoSpoilers(comments, watches, 3)
+		assert.True(t, comments[0].AutoSpoiler)
+		assert.False(t, comments[1].AutoSpoiler)
+	})
+
+	t.Run("reader has watched", func(t *testing.T) {
+		comments := []models.Comment{{ID: 1, UserID: 1}}
+		markAutoSpoilers(comments, watches, 2)
+		assert.False(t, comments[0].AutoSpoiler)
+	})
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newThreadTestService(parent *models.Comment, replies []models.Comment) ListService {
+	lists := &mocks.MockMovieListDAO{}
+	lists.On("FindByID", int64(1)).Return(&models.MovieList{ID: 1}, nil)
+	lists.On("FindMembership", int64(1), int64(2)).Return(&models.ListMember{ListID: 1, UserID: 2, Role: models.RoleParticipant}, nil)
+	lists.On("FindCommentByID", parent.ID).Return(parent, nil)
+	lists.On("FindCommentReplies", parent.ID, 50, 0).Return(replies, int64(len(replies)), nil)
+	lists.On("FindMovieWatches", int64(1), int64(7)).Return([]models.ListMovieWatch{}, nil)
+	return NewListService(lists, nil, nil, nil, nil, nil, nil, "")
+}
+
+func TestGetCommentThread_DeletedParentWithRepliesIsPlaceholder(t *testing.T) {
+	movieID := int64(7)
+	deletedAt := time.Now()
+	parent := &models.Comment{ID: 10, ListID: 1, MovieID: &movieID, UserID: 3, Content: "Que final", DeletedAt: &deletedAt}
+	replies := []models.Comment{{ID: 11, ListID: 1, MovieID: &movieID, UserID: 2, ParentID: &parent.ID, Content: "Concordo"}}
+	service := newThreadTestService(parent, replies)
+
+	thread, got, total, err := service.GetCommentThread(1, 2, &movieID, parent.ID, 0, 0)
+
+	assert.NoError(t, err)
+	assert.True(t, thread.IsDeleted())
+	assert.Equal(t, int64(1), thread.ReplyCount)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, got, 1)
+}
+
+func TestGetCommentThread_DeletedParentWithoutRepliesIsGone(t *testing.T) {
+	movieID := int64(7)
+	deletedAt := time.Now()
+	parent := &models.Comment{ID: 10, ListID: 1, MovieID: &movieID, UserID: 3, Content: "Que final", DeletedAt: &deletedAt}
+	service := newThreadTestService(parent, []models.Comment{})
+
+	_, _, _, err := service.GetCommentThread(1, 2, &movieID, parent.ID, 0, 0)
+
+	assert.ErrorIs(t, err, ErrCommentNotFound)
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+)
+
+const (
+	// DigestPeriod is how often each list's digest is emailed
+	DigestPeriod = 7 * 24 * time.Hour
+	// Titles shown in the digest's top-rated section
+	digestTopRated = 3
+)
+
+// DigestService emails each list's members a weekly summary of its activity
+type DigestService interface {
+	SendDueDigests(now time.Time) (int, error)
+	StartDigestScheduler(interval time.Duration) (stop func())
+}
+
+type digestService struct {
+	lists         daos.MovieListDAO
+	notifications daos.NotificationDAO
+	mailer        Mailer
+	tokens        *UnsubscribeTokens
+	links         EmailLinks
+	mu            sync.Mutex
+}
+
+func NewDigestService(lists daos.MovieListDAO, notifications daos.NotificationDAO, mailer Mailer, tokens *UnsubscribeTokens, links EmailLinks) DigestService {
+	return &digestService{
+		lists:         lists,
+		notifications: notifications,
+		mailer:        mailer,
+		tokens:        tokens,
+		links:         links,
+	}
+}
+
+// DigestTitle is one title in a digest section
+type DigestTitle struct {
+	MovieID  int64
+	Title    string
+	Username string
+	Rating   float64
+}
+
+// ListDigest is what happened in a list during one digest period
+type ListDigest struct {
+	ListID   int64
+	ListName string
+	Added    []DigestTitle
+	Watched  []DigestTitle
+	TopRated []DigestTitle
+}
+
+// IsEmpty reports whether nothing happened; top-rated titles alone are not news
+func (d ListDigest) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Watched) == 0
+}
+
+type digestEmailData struct {
+	ListDigest
+	ListURL        string
+	UnsubscribeURL string
+}
+
+// SendDueDigests emails the digest of every list last covered more than a period ago
+// and returns how many lists were processed. A list with no activity is marked as
+// covered without sending anything.
+func (s *digestService) SendDueDigests(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lists, err := s.lists.FindListsDueForDigest(now.Add(-DigestPeriod))
+	if err != nil {
+		return 0, err
+	}
+	processed := 0
+	for _, list := range lists {
+		if err := s.sendDigest(list, now); err != nil {
+			log.Printf("digest failed list=%d: %v", list.ID, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+func (s *digestService) sendDigest(list models.MovieList, now time.Time) error {
+	since := digestPeriodStart(list, now)
+	added, err := s.lists.FindListMoviesAddedSince(list.ID, since)
+	if err != nil {
+		return err
+	}
+	watched, err := s.lists.FindListMoviesWatchedSince(list.ID, since)
+	if err != nil {
+		return err
+	}
+	topRated, err := s.lists.FindTopRatedListMovies(list.ID, digestTopRated)
+	if err != nil {
+		return err
+	}
+
+	digest := buildListDigest(list, added, watched, topRated)
+	if !digest.IsEmpty() {
+		if err := s.mailDigest(digest); err != nil {
+			return err
+		}
+	}
+	return s.lists.MarkDigestSent(list.ID, now)
+}
+
+// mailDigest sends the digest to every member who has not turned it off. A failure for
+// one member is logged so the others still get theirs.
+func (s *digestService) mailDigest(digest ListDigest) error {
+	members, err := s.lists.FindMembersWithUser(digest.ListID)
+	if err != nil {
+		return err
+	}
+	optedOut, err := s.notifications.FindOptedOutUsers(otherMembers(members), models.NotificationWeeklyDigest, models.NotificationChannelEmail)
+	if err != nil {
+		return err
+	}
+	skip := make(map[int64]bool, len(optedOut))
+	for _, userID := range optedOut {
+		skip[userID] = true
+	}
+
+	for _, m := range members {
+		if skip[m.UserID] || m.User.Email == "" {
+			continue
+		}
+		unsubscribe := s.links.unsubscribe(s.tokens.Sign(m.UserID, models.NotificationWeeklyDigest))
+		msg, err := digestEmail.render(m.User.PreferredLanguage(), m.User.Email, digestEmailData{
+			ListDigest:     digest,
+			ListURL:        s.links.list(digest.ListID),
+			UnsubscribeURL: unsubscribe,
+		})
+		if err != nil {
+			return err
+		}
+		msg.Headers = unsubscribeHeaders(unsubscribe)
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("digest failed list=%d user=%d: %v", digest.ListID, m.UserID, err)
+		}
+	}
+	return nil
+}
+
+// StartDigestScheduler checks for due digests immediately and then on every interval
+// until stopped
+func (s *digestService) StartDigestScheduler(interval time.Duration) func() {
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if count, err := s.SendDueDigests(time.Now().UTC()); err != nil {
+				log.Printf("digest_schedule failed: %v", err)
+			} else if count > 0 {
+				log.Printf("digest_schedule success lists=%d", count)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// digestPeriodStart returns where the list's next digest starts: its last digest or
+// creation, but never more than a period back so a pause in sending does not produce
+// one huge digest
+func digestPeriodStart(list models.MovieList, now time.Time) time.Time {
+	since := list.CreatedAt
+	if list.LastDigestAt != nil {
+		since = *list.LastDigestAt
+	}
+	if earliest := now.Add(-DigestPeriod); since.Before(earliest) {
+		since = earliest
+	}
+	return since
+}
+
+func buildListDigest(list models.MovieList, added, watched []models.ListMovie, topRated []daos.RatedListMovie) ListDigest {
+	digest := ListDigest{ListID: list.ID, ListName: list.Name}
+	for _, lm := range added {
+		title := DigestTitle{MovieID: lm.MovieID, Title: lm.Movie.Title}
+		if lm.AddedByUser != nil {
+			title.Username = lm.AddedByUser.Username
+		}
+		digest.Added = append(digest.Added, title)
+	}
+	for _, lm := range watched {
+		digest.Watched = append(digest.Watched, DigestTitle{MovieID: lm.MovieID, Title: lm.Movie.Title})
+	}
+	for _, r := range topRated {
+		digest.TopRated = append(digest.TopRated, DigestTitle{MovieID: r.MovieID, Title: r.Title, Rating: r.AvgRating})
+	}
+	return digest
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestPeriodStart(t *testing.T) {
+	now := time.Date(2024, 11, 9, 12, 0, 0, 0, time.UTC)
+	lastDigest := now.Add(-8 * 24 * time.Hour)
+	recentDigest := now.Add(-2 * 24 * time.Hour)
+
+	assert.Equal(t, now.Add(-DigestPeriod), digestPeriodStart(models.MovieList{CreatedAt: now.Add(-30 * 24 * time.Hour)}, now))
+	assert.Equal(t, now.Add(-DigestPeriod), digestPeriodStart(models.MovieList{LastDigestAt: &lastDigest}, now))
+	assert.Equal(t, recentDigest, digestPeriodStart(models.MovieList{LastDigestAt: &recentDigest}, now))
+}
+
+func TestBuildListDigest(t *testing.T) {
+	list := models.MovieList{ID: 7, Name: "Sexta"}
+	ana := &models.User{Username: "ana"}
+
+	digest := buildListDigest(list,
+		[]models.ListMovie{{MovieID: 1, Movie: models.Movie{Title: "Alien"}, AddedByUser: ana}},
+		[]models.ListMovie{{MovieID: 2, Movie: models.Movie{Title: "Heat"}}},
+		[]daos.RatedListMovie{{MovieID: 2, Title: "Heat", AvgRating: 9.5}},
+	)
+	assert.False(t, digest.IsEmpty())
+	assert.Equal(t, []DigestTitle{{MovieID: 1, Title: "Alien", Username: "ana"}}, digest.Added)
+	assert.Equal(t, []DigestTitle{{MovieID: 2, Title: "Heat"}}, digest.Watched)
+	assert.Equal(t, []DigestTitle{{MovieID: 2, Title: "Heat", Rating: 9.5}}, digest.TopRated)
+
+	quiet := buildListDigest(list, nil, nil, []daos.RatedListMovie{{MovieID: 2, Title: "Heat", AvgRating: 9.5}})
+	assert.True(t, quiet.IsEmpty())
+}
+
+func TestDigestEmailIsLocalized(t *testing.T) {
+	data := digestEmailData{
+		ListDigest: ListDigest{
+			ListID:   7,
+			ListName: "Sexta",
+			Added:    []DigestTitle{{Title: "Alien", Username: "ana"}},
+			TopRated: []DigestTitle{{Title: "Heat <1995>", Rating: 9.5}},
+		},
+		ListURL:        "https://app.example.com/list/7",
+		UnsubscribeURL: "https://api.example.com/api/notifications/unsubscribe?token=abc",
+	}
+
+	pt, err := digestEmail.render(models.LanguagePortuguese, "ana@example.com", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Resumo semanal de Sexta", pt.Subject)
+	assert.Contains(t, pt.Text, "- Alien (adicionado por ana)")
+	assert.Contains(t, pt.Text, "- Heat <1995> (9.5/10)")
+	assert.NotContains(t, pt.Text, "Assistidos")
+	assert.Contains(t, pt.HTML, "Heat &lt;1995&gt;")
+
+	en, err := digestEmail.render(models.LanguageEnglish, "ana@example.com", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Weekly digest for Sexta", en.Subject)
+	assert.Contains(t, en.Text, "Top rated")
+	assert.Contains(t, en.Text, data.UnsubscribeURL)
+
+	invitation, err := invitationEmail.render("fr", "bia@example.com", invitationEmailData{InviterName: "ana", ListName: "Sexta", InviteCode: "ABC123"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ana convidou você para a lista Sexta", invitation.Subject)
+	assert.Equal(t, "bia@example.com", invitation.To)
+}
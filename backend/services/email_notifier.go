@@ -0,0 +1,125 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+)
+
+// EmailLinks builds the URLs placed in emails: pages of the web app and the API's
+// unsubscribe endpoint
+type EmailLinks struct {
+	AppURL string
+	APIURL string
+}
+
+func (l EmailLinks) list(listID int64) string {
+	return strings.TrimRight(l.AppURL, "/") + "/list/" + strconv.FormatInt(listID, 10)
+}
+
+func (l EmailLinks) join(inviteCode string) string {
+	return strings.TrimRight(l.AppURL, "/") + "/join/" + url.PathEscape(inviteCode)
+}
+
+func (l EmailLinks) unsubscribe(token string) string {
+	return strings.TrimRight(l.APIURL, "/") + "/api/notifications/unsubscribe?token=" + url.QueryEscape(token)
+}
+
+// unsubscribeHeaders lets mail clients offer one-click unsubscribe (RFC 8058)
+func unsubscribeHeaders(link string) map[string]string {
+	return map[string]string{
+		"List-Unsubscribe":      "<" + link + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
+type invitationEmailData struct {
+	InviterName    string
+	ListName       string
+	InviteCode     string
+	JoinURL        string
+	UnsubscribeURL string
+}
+
+type emailNotifier struct {
+	mailer        Mailer
+	users         daos.UserDAO
+	notifications daos.NotificationDAO
+	tokens        *UnsubscribeTokens
+	links         EmailLinks
+}
+
+// NewEmailNotifier emails invitations as soon as they are sent. Other activity reaches
+// email only through the weekly digest.
+func NewEmailNotifier(mailer Mailer, users daos.UserDAO, notifications daos.NotificationDAO, tokens *UnsubscribeTokens, links EmailLinks) Notifier {
+	return &emailNotifier{
+		mailer:        mailer,
+		users:         users,
+		notifications: notifications,
+		tokens:        tokens,
+		links:         links,
+	}
+}
+
+func (n *emailNotifier) Notify(notifications []models.Notification) {
+	invitations := make([]models.Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		if notification.Type == models.NotificationInvitationReceived {
+			invitations = append(invitations, notification)
+		}
+	}
+	if len(invitations) == 0 {
+		return
+	}
+	// SMTP can be slow; the request that caused the notification does not wait for it
+	go n.send(invitations)
+}
+
+func (n *emailNotifier) send(notifications []models.Notification) {
+	deliver, err := filterOptedOut(n.notifications, notifications, models.NotificationChannelEmail)
+	if err != nil {
+		log.Printf("email_notification failed: %v", err)
+		return
+	}
+	for _, notification := range deliver {
+		if err := n.sendInvitation(notification); err != nil {
+			log.Printf("email_notification failed user=%d: %v", notification.UserID, err)
+		}
+	}
+}
+
+func (n *emailNotifier) sendInvitation(notification models.Notification) error {
+	var payload models.InvitationPayload
+	if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+		return err
+	}
+	user, err := n.users.FindByID(notification.UserID)
+	if err != nil {
+		return err
+	}
+	var inviterName string
+	if notification.ActorID != nil {
+		if inviter, err := n.users.FindByID(*notification.ActorID); err == nil {
+			inviterName = inviter.Username
+		}
+	}
+
+	unsubscribe := n.links.unsubscribe(n.tokens.Sign(user.ID, models.NotificationInvitationReceived))
+	msg, err := invitationEmail.render(user.PreferredLanguage(), user.Email, invitationEmailData{
+		InviterName:    inviterName,
+		ListName:       payload.ListName,
+		InviteCode:     payload.InviteCode,
+		JoinURL:        n.links.join(payload.InviteCode),
+		UnsubscribeURL: unsubscribe,
+	})
+	if err != nil {
+		return err
+	}
+	msg.Headers = unsubscribeHeaders(unsubscribe)
+	return n.mailer.Send(msg)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/8bury/list2gether/models"
+)
+
+// messageStrings holds the translated text of every email and push message, by
+// language. Values are fmt formats filled in by translate.
+var messageStrings = map[string]map[string]string{
+	models.LanguagePortuguese: {
+		"invitation.subject":   "%s convidou você para a lista %s",
+		"invitation.intro":     "%s convidou você para participar da lista \"%s\" no list2gether.",
+		"invitation.cta":       "Entrar na lista",
+		"invitation.code":      "Código de convite: %s",
+		"digest.subject":       "Resumo semanal de %s",
+		"digest.intro":         "Veja o que aconteceu na lista \"%s\" nesta semana.",
+		"digest.added":         "Novos títulos",
+		"digest.added_by":      "adicionado por %s",
+		"digest.watched":       "Assistidos",
+		"digest.top_rated":     "Mais bem avaliados",
+		"digest.cta":           "Abrir a lista",
+		"footer.unsubscribe":   "Não quer mais receber estes emails? Cancele a inscrição: %s",
+		"footer.unsubscribe_h": "Cancelar inscrição",
+		"push.title_added":     "%s adicionou %s",
+		"push.mention":         "%s mencionou você",
+		"push.test.title":      "Notificações ativadas",
+		"push.test.body":       "Este dispositivo vai receber alertas do list2gether.",
+	},
+	models.LanguageEnglish: {
+		"invitation.subject":   "%s invited you to %s",
+		"invitation.intro":     "%s invited you to join the list \"%s\" on list2gether.",
+		"invitation.cta":       "Join the list",
+		"invitation.code":      "Invite code: %s",
+		"digest.subject":       "Weekly digest for %s",
+		"digest.intro":         "Here is what happened in \"%s\" this week.",
+		"digest.added":         "New titles",
+		"digest.added_by":      "added by %s",
+		"digest.watched":       "Watched",
+		"digest.top_rated":     "Top rated",
+		"digest.cta":           "Open the list",
+		"footer.unsubscribe":   "Don't want these emails? Unsubscribe: %s",
+		"footer.unsubscribe_h": "Unsubscribe",
+		"push.title_added":     "%s added %s",
+		"push.mention":         "%s mentioned you",
+		"push.test.title":      "Notifications enabled",
+		"push.test.body":       "This device will receive list2gether alerts.",
+	},
+}
+
+// translate formats the string for key in the language, falling back to the default
+// language and then to the key itself
+func translate(language, key string, args ...interface{}) string {
+	format, ok := messageStrings[language][key]
+	if !ok {
+		format, ok = messageStrings[models.DefaultLanguage][key]
+	}
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+const invitationTextTemplate = `{{define "subject"}}{{t "invitation.subject" .InviterName .ListName}}{{end}}
+{{- define "text"}}{{t "invitation.intro" .InviterName .ListName}}
+
+{{t "invitation.cta"}}: {{.JoinURL}}
+{{t "invitation.code" .InviteCode}}
+
+--
+{{t "footer.unsubscribe" .UnsubscribeURL}}
+{{end}}`
+
+const invitationHTMLTemplate = `{{define "html"}}<p>{{t "invitation.intro" .InviterName .ListName}}</p>
+<p><a href="{{.JoinURL}}">{{t "invitation.cta"}}</a></p>
+<p>{{t "invitation.code" .InviteCode}}</p>
+<hr>
+<p style="font-size:12px;color:#666"><a href="{{.UnsubscribeURL}}">{{t "footer.unsubscribe_h"}}</a></p>
+{{end}}`
+
+const digestTextTemplate = `{{define "subject"}}{{t "digest.subject" .ListName}}{{end}}
+{{- define "text"}}{{t "digest.intro" .ListName}}
+{{if .Added}}
+{{t "digest.added"}}
+{{range .Added}}- {{.Title}}{{if .Username}} ({{t "digest.added_by" .Username}}){{end}}
+{{end}}{{end}}{{if .Watched}}
+{{t "digest.watched"}}
+{{range .Watched}}- {{.Title}}
+{{end}}{{end}}{{if .TopRated}}
+{{t "digest.top_rated"}}
+{{range .TopRated}}- {{.Title}} ({{printf "%.1f" .Rating}}/10)
+{{end}}{{end}}
+{{t "digest.cta"}}: {{.ListURL}}
+
+--
+{{t "footer.unsubscribe" .UnsubscribeURL}}
+{{end}}`
+
+const digestHTMLTemplate = `{{define "html"}}<p>{{t "digest.intro" .ListName}}</p>
+{{if .Added}}<h3>{{t "digest.added"}}</h3>
+<ul>{{range .Added}}<li>{{.Title}}{{if .Username}} <small>({{t "digest.added_by" .Username}})</small>{{end}}</li>{{end}}</ul>
+{{end}}{{if .Watched}}<h3>{{t "digest.watched"}}</h3>
+<ul>{{range .Watched}}<li>{{.Title}}</li>{{end}}</ul>
+{{end}}{{if .TopRated}}<h3>{{t "digest.top_rated"}}</h3>
+<ul>{{range .TopRated}}<li>{{.Title}} ({{printf "%.1f" .Rating}}/10)</li>{{end}}</ul>
+{{end}}<p><a href="{{.ListURL}}">{{t "digest.cta"}}</a></p>
+<hr>
+<p style="font-size:12px;color:#666"><a href="{{.UnsubscribeURL}}">{{t "footer.unsubscribe_h"}}</a></p>
+{{end}}`
+
+// emailTemplate renders one kind of email; the text templates define "subject" and
+// "text", the HTML template defines "html"
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var (
+	invitationEmail = mustEmailTemplate("invitation", invitationTextTemplate, invitationHTMLTemplate)
+	digestEmail     = mustEmailTemplate("digest", digestTextTemplate, digestHTMLTemplate)
+)
+
+func mustEmailTemplate(name, text, html string) emailTemplate {
+	// t is bound to the recipient's language when rendering
+	placeholder := map[string]interface{}{"t": func(string, ...interface{}) string { return "" }}
+	return emailTemplate{
+		text: texttemplate.Must(texttemplate.New(name).Funcs(placeholder).Parse(text)),
+		html: htmltemplate.Must(htmltemplate.New(name).Funcs(placeholder).Parse(html)),
+	}
+}
+
+// render builds the message in the language for the recipient
+func (e emailTemplate) render(language, to string, data interface{}) (EmailMessage, error) {
+	funcs := map[string]interface{}{
+		"t": func(key string, args ...interface{}) string { return translate(language, key, args...) },
+	}
+	text, err := e.text.Clone()
+	if err != nil {
+		return EmailMessage{}, err
+	}
+	html, err := e.html.Clone()
+	if err != nil {
+		return EmailMessage{}, err
+	}
+	text.Funcs(funcs)
+	html.Funcs(funcs)
+
+	var subject, textBody, htmlBody bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return EmailMessage{}, err
+	}
+	if err := text.ExecuteTemplate(&textBody, "text", data); err != nil {
+		return EmailMessage{}, err
+	}
+	if err := html.ExecuteTemplate(&htmlBody, "html", data); err != nil {
+		return EmailMessage{}, err
+	}
+	return EmailMessage{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    textBody.String(),
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/8bury/list2gether/models"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid_unsubscribe_token")
+
+// UnsubscribeTokens signs the links in emails that turn one email type off without
+// logging in. Tokens do not expire, so links in old emails keep working.
+type UnsubscribeTokens struct {
+	key []byte
+}
+
+// NewUnsubscribeTokens derives the signing key from the app secret so tokens cannot be
+// confused with other values signed by it
+func NewUnsubscribeTokens(secret []byte) *UnsubscribeTokens {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("list2gether-unsubscribe"))
+	return &UnsubscribeTokens{key: mac.Sum(nil)}
+}
+
+func (t *UnsubscribeTokens) Sign(userID int64, notificationType models.NotificationType) string {
+	body := strconv.FormatInt(userID, 10) + "." + string(notificationType)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(body))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(t.mac(encoded))
+}
+
+// Verify returns the user and email type the token was signed for
+func (t *UnsubscribeTokens) Verify(token string) (int64, models.NotificationType, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	given, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(given, t.mac(encoded)) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	rawID, rawType, ok := strings.Cut(string(body), ".")
+	if !ok {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	userID, err := strconv.ParseInt(rawID, 10, 64)
+	notificationType := models.NotificationType(rawType)
+	if err != nil || userID <= 0 || !models.NotificationChannelEmail.Delivers(notificationType) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	return userID, notificationType, nil
+}
+
+func (t *UnsubscribeTokens) mac(encoded string) []byte {
+	mac := hmac.New(sha256.New, t.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
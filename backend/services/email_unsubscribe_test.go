@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnsubscribeTokens(t *testing.T) {
+	tokens := NewUnsubscribeTokens([]byte("secret"))
+
+	token := tokens.Sign(42, models.NotificationWeeklyDigest)
+	userID, notificationType, err := tokens.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), userID)
+	assert.Equal(t, models.NotificationWeeklyDigest, notificationType)
+
+	// Signed with another secret
+	_, _, err = NewUnsubscribeTokens([]byte("other")).Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+
+	// Body swapped for another user's
+	forged := tokens.Sign(7, models.NotificationWeeklyDigest)
+	_, _, err = tokens.Verify(forged[:len(forged)-5] + token[len(token)-5:])
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+
+	// Types that are not sent by email cannot be unsubscribed from
+	_, _, err = tokens.Verify(tokens.Sign(42, models.NotificationComment))
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+
+	for _, bad := range []string{"", "abc", "abc.def", "."} {
+		_, _, err = tokens.Verify(bad)
+		assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken, bad)
+	}
+}
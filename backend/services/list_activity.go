@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+// GetActivity returns a page of the list's activity feed, newest first. Pass the
+// returned cursor as beforeID to fetch the next page; it is 0 on the last page.
+func (s *listService) GetActivity(listID, userID, beforeID int64, limit int) ([]models.ListEvent, int64, error) {
+	if _, err := s.lists.FindByID(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrListNotFound
+		}
+		return nil, 0, err
+	}
+
+	membership, err := s.lists.FindMembership(listID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, 0, ErrForbiddenMembership
+		}
+		return nil, 0, err
+	}
+	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
+		return nil, 0, ErrForbiddenMembership
+	}
+
+	// Sanitize pagination
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if beforeID < 0 {
+		beforeID = 0
+	}
+
+	// Fetch one extra event to know whether another page exists
+	events, err := s.lists.FindListEvents(listID, beforeID, limit+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	var next int64
+	if len(events) > limit {
+		events = events[:limit]
+		next = events[limit-1].ID
+	}
+	return events, next, nil
+}
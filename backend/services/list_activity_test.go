@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newActivityTestService() (ListService, *mocks.MockMovieListDAO) {
+	lists := &mocks.MockMovieListDAO{}
+	lists.On("FindByID", int64(1)).Return(&models.MovieList{ID: 1}, nil)
+	lists.On("FindMembership", int64(1), int64(2)).Return(&models.ListMember{ListID: 1, UserID: 2, Role: models.RoleParticipant}, nil)
+	lists.On("FindMembership", int64(1), int64(9)).Return(nil, gorm.ErrRecordNotFound)
+	return NewListService(lists, nil, nil, nil, nil, nil, nil, ""), lists
+}
+
+func listEvents(ids ...int64) []models.ListEvent {
+	events := make([]models.ListEvent, 0, len(ids))
+	for _, id := range ids {
+		events = append(events, models.ListEvent{ID: id, ListID: 1, Verb: models.EventMovieAdded})
+	}
+	return events
+}
+
+func TestGetActivity_PageWithMore(t *testing.T) {
+	service, lists := newActivityTestService()
+	lists.On("FindListEvents", int64(1), int64(0), 3).Return(listEvents(9, 8, 7), nil)
+
+	events, next, err := service.GetActivity(1, 2, 0, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, int64(8), next)
+}
+
+func TestGetActivity_LastPage(t *testing.T) {
+	service, lists := newActivityTestService()
+	lists.On("FindListEvents", int64(1), int64(8), 3).Return(listEvents(7), nil)
+
+	events, next, err := service.GetActivity(1, 2, 8, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, int64(0), next)
+}
+
+func TestGetActivity_ClampsLimit(t *testing.T) {
+	service, lists := newActivityTestService()
+	lists.On("FindListEvents", int64(1), int64(0), 51).Return(listEvents(), nil)
+	lists.On("FindListEvents", int64(1), int64(0), 101).Return(listEvents(), nil)
+
+	_, _, err := service.GetActivity(1, 2, -5, 0)
+	assert.NoError(t, err)
+	_, _, err = service.GetActivity(1, 2, 0, 1000)
+	assert.NoError(t, err)
+
+	lists.AssertCalled(t, "FindListEvents", int64(1), int64(0), 51)
+	lists.AssertCalled(t, "FindListEvents", int64(1), int64(0), 101)
+}
+
+func TestGetActivity_NotAMember(t *testing.T) {
+	service, lists := newActivityTestService()
+
+	_, _, err := service.GetActivity(1, 9, 0, 10)
+
+	assert.ErrorIs(t, err, ErrForbiddenMembership)
+	lists.AssertNotCalled(t, "FindListEvents")
+}
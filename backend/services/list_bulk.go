@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+)
+
+// MaxBulkItems caps how many titles one bulk operation can touch
+const MaxBulkItems = 500
+
+var (
+	ErrInvalidBulkOperation = errors.New("invalid_bulk_operation")
+	ErrInvalidBulkItems     = errors.New("invalid_bulk_items")
+	ErrInvalidBulkStatus    = errors.New("invalid_bulk_status")
+	ErrInvalidBulkTag       = errors.New("invalid_bulk_tag")
+	// ErrInvalidBulkTarget is a copy or move without a target list, or to the same list
+	ErrInvalidBulkTarget         = errors.New("invalid_bulk_target")
+	ErrTargetListNotFound        = errors.New("target_list_not_found")
+	ErrForbiddenTargetMembership = errors.New("forbidden_target_membership")
+)
+
+// BulkUpdateMovies applies one operation to many titles of the list in one transaction.
+// The user must be able to edit the list, and for a copy or move the target list too.
+func (s *listService) BulkUpdateMovies(listID, userID int64, op daos.BulkListMovieOperation) ([]models.BulkItemResult, error) {
+	op.MovieIDs = uniqueMovieIDs(op.MovieIDs)
+	op.Tag = strings.TrimSpace(op.Tag)
+	if err := validateBulkOperation(listID, op); err != nil {
+		return nil, err
+	}
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	if op.Operation.NeedsTarget() {
+		if err := s.checkListWriter(op.TargetListID, userID); err != nil {
+			switch err {
+			case ErrListNotFound:
+				return nil, ErrTargetListNotFound
+			case ErrForbiddenMembership:
+				return nil, ErrForbiddenTargetMembership
+			}
+			return nil, err
+		}
+	}
+
+	results, err := s.lists.ApplyBulkOperation(listID, userID, op)
+	if err != nil {
+		return nil, err
+	}
+	applied := appliedMovieIDs(results)
+	log.Printf("bulk_update list=%d user=%d operation=%s items=%d applied=%d", listID, userID, op.Operation, len(results), len(applied))
+	if len(applied) == 0 {
+		return results, nil
+	}
+
+	// One event per list, so open clients refetch once instead of per title
+	data := map[string]interface{}{"operation": op.Operation, "movie_ids": applied}
+	switch op.Operation {
+	case models.BulkSetStatus:
+		data["status"] = op.Status
+	case models.BulkTag, models.BulkUntag:
+		data["tag"] = op.Tag
+	case models.BulkCopy, models.BulkMove:
+		data["target_list_id"] = op.TargetListID
+		s.publish(op.TargetListID, userID, models.StreamMoviesBulkUpdated, map[string]interface{}{
+			"operation":      op.Operation,
+			"movie_ids":      applied,
+			"source_list_id": listID,
+		})
+	}
+	s.publish(listID, userID, models.StreamMoviesBulkUpdated, data)
+
+	for _, result := range results {
+		if result.Outcome != models.BulkItemApplied {
+			continue
+		}
+		switch op.Operation {
+		case models.BulkSetStatus:
+			s.dispatchWebhook(WebhookEvent{
+				Type:           models.WebhookStatusChanged,
+				ListID:         listID,
+				ActorID:        userID,
+				MovieID:        result.MovieID,
+				PreviousStatus: result.PreviousStatus,
+			})
+		case models.BulkCopy, models.BulkMove:
+			s.dispatchWebhook(WebhookEvent{Type: models.WebhookMovieAdded, ListID: op.TargetListID, ActorID: userID, MovieID: result.MovieID})
+		}
+	}
+	if op.Operation.NeedsTarget() {
+		s.notifyTitlesAdded(op.TargetListID, applied, userID)
+	}
+	return results, nil
+}
+
+func validateBulkOperation(listID int64, op daos.BulkListMovieOperation) error {
+	if !op.Operation.IsValid() {
+		return ErrInvalidBulkOperation
+	}
+	if len(op.MovieIDs) == 0 || len(op.MovieIDs) > MaxBulkItems {
+		return ErrInvalidBulkItems
+	}
+	if op.Operation == models.BulkSetStatus {
+		switch op.Status {
+		case models.StatusNotWatched, models.StatusWatching, models.StatusWatched, models.StatusDropped:
+		default:
+			return ErrInvalidBulkStatus
+		}
+	}
+	if op.Operation.NeedsTarget() && (op.TargetListID <= 0 || op.TargetListID == listID) {
+		return ErrInvalidBulkTarget
+	}
+	if op.Operation.NeedsTag() && (op.Tag == "" || utf8.RuneCountInString(op.Tag) > models.MaxTagLength) {
+		return ErrInvalidBulkTag
+	}
+	return nil
+}
+
+// uniqueMovieIDs drops repeated and invalid ids, keeping the order they were given in
+func uniqueMovieIDs(movieIDs []int64) []int64 {
+	seen := make(map[int64]bool, len(movieIDs))
+	unique := make([]int64, 0, len(movieIDs))
+	for _, id := range movieIDs {
+		if id <= 0 || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+func appliedMovieIDs(results []models.BulkItemResult) []int64 {
+	var ids []int64
+	for _, result := range results {
+		if result.Outcome == models.BulkItemApplied {
+			ids = append(ids, result.MovieID)
+		}
+	}
+	return ids
+}
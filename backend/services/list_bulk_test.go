@@ -0,0 +1,76 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUniqueMovieIDsKeepsOrder(t *testing.T) {
+	assert.Equal(t, []int64{3, 1, 2}, uniqueMovieIDs([]int64{3, 1, 3, 0, 2, -4, 1}))
+	assert.Empty(t, uniqueMovieIDs(nil))
+}
+
+func TestValidateBulkOperation(t *testing.T) {
+	ids := []int64{1, 2}
+
+	assert.NoError(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkRemove, MovieIDs: ids}))
+	assert.NoError(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkSetStatus, MovieIDs: ids, Status: models.StatusWatched}))
+	assert.NoError(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkMove, MovieIDs: ids, TargetListID: 8}))
+
+	assert.NoError(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkTag, MovieIDs: ids, Tag: "halloween"}))
+
+	assert.ErrorIs(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: "label", MovieIDs: ids}), ErrInvalidBulkOperation)
+	assert.ErrorIs(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkTag, MovieIDs: ids}), ErrInvalidBulkTag)
+	assert.ErrorIs(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkUntag, MovieIDs: ids, Tag: strings.Repeat("a", models.MaxTagLength+1)}), ErrInvalidBulkTag)
+	assert.ErrorIs(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkRemove}), ErrInvalidBulkItems)
+	assert.ErrorIs(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkRemove, MovieIDs: make([]int64, MaxBulkItems+1)}), ErrInvalidBulkItems)
+	assert.ErrorIs(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkSetStatus, MovieIDs: ids, Status: "seen"}), ErrInvalidBulkStatus)
+	assert.ErrorIs(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkCopy, MovieIDs: ids}), ErrInvalidBulkTarget)
+	assert.ErrorIs(t, validateBulkOperation(7, daos.BulkListMovieOperation{Operation: models.BulkMove, MovieIDs: ids, TargetListID: 7}), ErrInvalidBulkTarget)
+}
+
+func TestAppliedMovieIDs(t *testing.T) {
+	results := []models.BulkItemResult{
+		{MovieID: 1, Outcome: models.BulkItemApplied},
+		{MovieID: 2, Outcome: models.BulkItemNotInList},
+		{MovieID: 3, Outcome: models.BulkItemUnchanged},
+		{MovieID: 4, Outcome: models.BulkItemAlreadyInTarget},
+		{MovieID: 5, Outcome: models.BulkItemApplied},
+	}
+	assert.Equal(t, []int64{1, 5}, appliedMovieIDs(results))
+}
+
+func TestBulkCopyNotifiesTargetMembers(t *testing.T) {
+	lists := &mocks.MockMovieListDAO{}
+	movies := &mocks.MockMovieDAO{}
+	notifier := &recordingNotifier{}
+	lists.On("FindByID", int64(1)).Return(&models.MovieList{ID: 1, Name: "Origem"}, nil)
+	lists.On("FindByID", int64(2)).Return(&models.MovieList{ID: 2, Name: "Destino"}, nil)
+	lists.On("FindMembership", mock.Anything, int64(5)).Return(&models.ListMember{UserID: 5, Role: models.RoleOwner}, nil)
+	op := daos.BulkListMovieOperation{Operation: models.BulkCopy, MovieIDs: []int64{10, 11}, TargetListID: 2}
+	lists.On("ApplyBulkOperation", int64(1), int64(5), op).Return([]models.BulkItemResult{
+		{MovieID: 10, Outcome: models.BulkItemApplied},
+		{MovieID: 11, Outcome: models.BulkItemAlreadyInTarget},
+	}, nil)
+	lists.On("FindMembersWithUser", int64(2)).Return([]models.ListMember{
+		{ListID: 2, UserID: 5},
+		{ListID: 2, UserID: 6},
+	}, nil)
+	movies.On("FindByID", int64(10)).Return(&models.Movie{ID: 10, Title: "Heat", MediaType: "movie"}, nil)
+	service := NewListService(lists, movies, nil, notifier, nil, nil, nil, "")
+
+	_, err := service.BulkUpdateMovies(1, 5, op)
+
+	assert.NoError(t, err)
+	// Only the title that was copied is announced, and not to whoever copied it
+	assert.Len(t, notifier.notifications, 1)
+	assert.Equal(t, int64(6), notifier.notifications[0].UserID)
+	assert.Equal(t, models.NotificationTitleAdded, notifier.notifications[0].Type)
+	movies.AssertNotCalled(t, "FindByID", int64(11))
+}
@@ -0,0 +1,405 @@
+package services
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidExportFormat = errors.New("invalid_export_format")
+
+// ListExportVersion is the version of the JSON export layout
+const ListExportVersion = 1
+
+// Titles read from the database at a time while an export is written
+const exportBatchSize = 200
+
+// ListExport is a list export ready to be streamed. Access is checked when it is
+// created; the titles are read page by page while it is written.
+type ListExport struct {
+	List    *models.MovieList
+	Format  models.ExportFormat
+	members []models.ListMember
+	userID  int64
+	s       *listService
+}
+
+// ExportList prepares an export of the list for one of its members
+func (s *listService) ExportList(listID, userID int64, format models.ExportFormat) (*ListExport, error) {
+	if !format.IsValid() {
+		return nil, ErrInvalidExportFormat
+	}
+	list, err := s.lists.FindByID(listID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrListNotFound
+		}
+		return nil, err
+	}
+	membership, err := s.lists.FindMembership(listID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrForbiddenMembership
+		}
+		return nil, err
+	}
+	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
+		return nil, ErrForbiddenMembership
+	}
+	members, err := s.lists.FindMembersWithUser(listID)
+	if err != nil {
+		return nil, err
+	}
+	return &ListExport{List: list, Format: format, members: members, userID: userID, s: s}, nil
+}
+
+func (e *ListExport) ContentType() string {
+	if e.Format == models.ExportJSON {
+		return "application/json; charset=utf-8"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename names the download after the list and the day it was exported
+func (e *ListExport) Filename() string {
+	name := exportSlug(e.List.Name)
+	if name == "" {
+		name = "list-" + strconv.FormatInt(e.List.ID, 10)
+	}
+	name += "-" + time.Now().UTC().Format("2006-01-02")
+	switch e.Format {
+	case models.ExportJSON:
+		return name + ".json"
+	case models.ExportLetterboxd:
+		return name + "-letterboxd.csv"
+	}
+	return name + ".csv"
+}
+
+// Write streams the export to w
+func (e *ListExport) Write(w io.Writer) error {
+	switch e.Format {
+	case models.ExportJSON:
+		return e.writeJSON(w)
+	case models.ExportLetterboxd:
+		return e.writeLetterboxd(w)
+	}
+	return e.writeCSV(w)
+}
+
+// eachItem calls fn with every active title of the list in display order and its
+// 1-based position
+func (e *ListExport) eachItem(fn func(lm models.ListMovie, position int) error) error {
+	position := 0
+	var afterOrder *int
+	var afterID int64
+	for {
+		batch, err := e.s.lists.FindListMoviesForExport(e.List.ID, afterOrder, afterID, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, lm := range batch {
+			position++
+			if err := fn(lm, position); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		afterOrder, afterID = last.DisplayOrder, last.ID
+	}
+}
+
+// exportedListEntry is one member's history with a title in the JSON export
+type exportedListEntry struct {
+	UserID    int64      `json:"user_id"`
+	Username  string     `json:"username"`
+	Rating    *int       `json:"rating"`
+	Notes     *string    `json:"notes"`
+	WatchedAt *time.Time `json:"watched_at"`
+}
+
+// exportedListItem is a title in the JSON export. The import of list2gether files reads
+// the same structure back.
+type exportedListItem struct {
+	Position  int                 `json:"position"`
+	TMDBID    int64               `json:"tmdb_id"`
+	IMDbID    *string             `json:"imdb_id"`
+	MediaType string              `json:"media_type"`
+	Title     string              `json:"title"`
+	Year      int                 `json:"year,omitempty"`
+	Status    models.MovieStatus  `json:"status"`
+	AddedAt   time.Time           `json:"added_at"`
+	WatchedAt *time.Time          `json:"watched_at"`
+	Entries   []exportedListEntry `json:"entries"`
+}
+
+type exportedListMember struct {
+	UserID   int64                 `json:"user_id"`
+	Username string                `json:"username"`
+	Role     models.ListMemberRole `json:"role"`
+}
+
+// exportedListHeader is everything in the JSON export before its items
+type exportedListHeader struct {
+	Format     models.ImportFormat  `json:"format"`
+	Version    int                  `json:"version"`
+	ExportedAt time.Time            `json:"exported_at"`
+	ExportedBy exportedListMember   `json:"exported_by"`
+	List       exportedList         `json:"list"`
+	Members    []exportedListMember `json:"members"`
+}
+
+type exportedList struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func exportListItem(lm models.ListMovie, position int) exportedListItem {
+	watches := make(map[int64]time.Time, len(lm.Watches))
+	for _, w := range lm.Watches {
+		watches[w.UserID] = w.WatchedAt
+	}
+	item := exportedListItem{
+		Position:  position,
+		TMDBID:    lm.Movie.ID,
+		IMDbID:    lm.Movie.IMDbID,
+		MediaType: lm.Movie.MediaType,
+		Title:     lm.Movie.Title,
+		Year:      movieYear(lm.Movie),
+		Status:    lm.Status,
+		AddedAt:   lm.AddedAt,
+		WatchedAt: lm.WatchedAt,
+		Entries:   make([]exportedListEntry, 0, len(lm.UserEntries)+len(lm.Watches)),
+	}
+	seen := make(map[int64]bool, len(lm.UserEntries))
+	for _, entry := range lm.UserEntries {
+		exported := exportedListEntry{UserID: entry.UserID, Username: entry.User.Username, Rating: entry.Rating, Notes: entry.Notes}
+		if at, ok := watches[entry.UserID]; ok {
+			exported.WatchedAt = &at
+		}
+		item.Entries = append(item.Entries, exported)
+		seen[entry.UserID] = true
+	}
+	// Members who watched the title without rating it or leaving notes
+	for _, w := range lm.Watches {
+		if !seen[w.UserID] {
+			at := w.WatchedAt
+			item.Entries = append(item.Entries, exportedListEntry{UserID: w.UserID, WatchedAt: &at})
+		}
+	}
+	return item
+}
+
+func (e *ListExport) writeJSON(w io.Writer) error {
+	header := exportedListHeader{
+		Format:     models.ImportList2gether,
+		Version:    ListExportVersion,
+		ExportedAt: time.Now().UTC(),
+		List: exportedList{
+			ID:          e.List.ID,
+			Name:        e.List.Name,
+			Description: e.List.Description,
+			CreatedAt:   e.List.CreatedAt,
+		},
+		Members: make([]exportedListMember, 0, len(e.members)),
+	}
+	usernames := make(map[int64]string, len(e.members))
+	for _, m := range e.members {
+		member := exportedListMember{UserID: m.UserID, Username: m.User.Username, Role: m.Role}
+		header.Members = append(header.Members, member)
+		usernames[m.UserID] = m.User.Username
+		if m.UserID == e.userID {
+			header.ExportedBy = member
+		}
+	}
+	encoded, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	// The header's closing brace is replaced by the items array, written as it is read
+	out := bufio.NewWriter(w)
+	out.Write(encoded[:len(encoded)-1])
+	out.WriteString(`,"items":[`)
+	err = e.eachItem(func(lm models.ListMovie, position int) error {
+		item := exportListItem(lm, position)
+		for i := range item.Entries {
+			if item.Entries[i].Username == "" {
+				item.Entries[i].Username = usernames[item.Entries[i].UserID]
+			}
+		}
+		encoded, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if position > 1 {
+			out.WriteByte(',')
+		}
+		_, err = out.Write(encoded)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	out.WriteString("]}\n")
+	return out.Flush()
+}
+
+// writeCSV writes one row per title with a rating, notes and watched date column for
+// each current member
+func (e *ListExport) writeCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	header := []string{"position", "tmdb_id", "imdb_id", "media_type", "title", "year", "status", "added_at", "watched_at"}
+	for _, m := range e.members {
+		header = append(header, m.User.Username+" rating", m.User.Username+" notes", m.User.Username+" watched_at")
+	}
+	if err := out.Write(header); err != nil {
+		return err
+	}
+	err := e.eachItem(func(lm models.ListMovie, position int) error {
+		item := exportListItem(lm, position)
+		record := []string{
+			strconv.Itoa(item.Position),
+			strconv.FormatInt(item.TMDBID, 10),
+			derefString(item.IMDbID),
+			item.MediaType,
+			csvSafe(item.Title),
+			exportYear(item.Year),
+			string(item.Status),
+			item.AddedAt.UTC().Format(time.RFC3339),
+			exportTime(item.WatchedAt, time.RFC3339),
+		}
+		entries := make(map[int64]exportedListEntry, len(item.Entries))
+		for _, entry := range item.Entries {
+			entries[entry.UserID] = entry
+		}
+		for _, m := range e.members {
+			entry := entries[m.UserID]
+			rating := ""
+			if entry.Rating != nil {
+				rating = strconv.Itoa(*entry.Rating)
+			}
+			record = append(record, rating, csvSafe(derefString(entry.Notes)), exportTime(entry.WatchedAt, time.RFC3339))
+		}
+		return out.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeLetterboxd writes the exporting member's history of the list's films in the
+// columns Letterboxd's CSV importer reads. Letterboxd has no TV, and it logs every row
+// as watched, so series and titles nobody watched or rated are left out.
+func (e *ListExport) writeLetterboxd(w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"tmdbID", "imdbID", "Title", "Year", "Rating10", "WatchedDate", "Review"}); err != nil {
+		return err
+	}
+	err := e.eachItem(func(lm models.ListMovie, position int) error {
+		if lm.Movie.MediaType != "movie" {
+			return nil
+		}
+		item := exportListItem(lm, position)
+		var own exportedListEntry
+		for _, entry := range item.Entries {
+			if entry.UserID == e.userID {
+				own = entry
+			}
+		}
+		watchedAt := own.WatchedAt
+		if watchedAt == nil && item.Status == models.StatusWatched {
+			watchedAt = item.WatchedAt
+		}
+		if watchedAt == nil && own.Rating == nil && item.Status != models.StatusWatched {
+			return nil
+		}
+		rating := ""
+		if own.Rating != nil {
+			rating = strconv.Itoa(*own.Rating)
+		}
+		return out.Write([]string{
+			strconv.FormatInt(item.TMDBID, 10),
+			derefString(item.IMDbID),
+			item.Title,
+			exportYear(item.Year),
+			rating,
+			exportTime(watchedAt, "2006-01-02"),
+			derefString(own.Notes),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func movieYear(movie models.Movie) int {
+	if movie.ReleaseDate == nil {
+		return 0
+	}
+	return movie.ReleaseDate.Year()
+}
+
+func exportYear(year int) string {
+	if year == 0 {
+		return ""
+	}
+	return strconv.Itoa(year)
+}
+
+func exportTime(t *time.Time, layout string) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(layout)
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// csvSafe keeps spreadsheet apps from running a cell as a formula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// exportSlug turns a list name into a safe file name
+func exportSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.Trim(truncateRunes(b.String(), 60), "-")
+}
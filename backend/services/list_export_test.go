@@ -0,0 +1,149 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportTestListMovie() models.ListMovie {
+	released := time.Date(1995, 12, 15, 0, 0, 0, 0, time.UTC)
+	watched := time.Date(2024, 5, 4, 21, 0, 0, 0, time.UTC)
+	imdb := "tt0113277"
+	rating, otherRating := 9, 6
+	notes := "Diner scene"
+	return models.ListMovie{
+		ListID:    1,
+		MovieID:   949,
+		Status:    models.StatusWatched,
+		AddedAt:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		WatchedAt: &watched,
+		Movie:     models.Movie{ID: 949, Title: "Heat", MediaType: "movie", ReleaseDate: &released, IMDbID: &imdb},
+		UserEntries: []models.ListMovieUserData{
+			{UserID: 7, Rating: &rating, Notes: &notes, User: models.User{ID: 7, Username: "ana"}},
+			{UserID: 8, Rating: &otherRating, User: models.User{ID: 8, Username: "bruno"}},
+		},
+		Watches: []models.ListMovieWatch{
+			{UserID: 7, WatchedAt: watched},
+			{UserID: 9, WatchedAt: watched},
+		},
+	}
+}
+
+func TestExportListItem(t *testing.T) {
+	item := exportListItem(exportTestListMovie(), 3)
+	assert.Equal(t, 3, item.Position)
+	assert.Equal(t, int64(949), item.TMDBID)
+	assert.Equal(t, "tt0113277", *item.IMDbID)
+	assert.Equal(t, 1995, item.Year)
+	assert.Len(t, item.Entries, 3)
+
+	assert.Equal(t, "ana", item.Entries[0].Username)
+	assert.Equal(t, 9, *item.Entries[0].Rating)
+	assert.NotNil(t, item.Entries[0].WatchedAt)
+	assert.Nil(t, item.Entries[1].WatchedAt)
+	// A member who only watched the title still has an entry
+	assert.Equal(t, int64(9), item.Entries[2].UserID)
+	assert.Nil(t, item.Entries[2].Rating)
+}
+
+func TestListExportJSONRoundTripsThroughImport(t *testing.T) {
+	doc := struct {
+		exportedListHeader
+		Items []exportedListItem `json:"items"`
+	}{
+		exportedListHeader: exportedListHeader{
+			Format:     models.ImportList2gether,
+			Version:    ListExportVersion,
+			ExportedBy: exportedListMember{UserID: 7, Username: "ana", Role: models.RoleOwner},
+		},
+		Items: []exportedListItem{exportListItem(exportTestListMovie(), 1)},
+	}
+	data, err := json.Marshal(doc)
+	assert.NoError(t, err)
+
+	format, err := detectImportFormat(data)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportList2gether, format)
+
+	rows, err := parseImportFile(format, data)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	row := rows[0]
+	assert.Equal(t, int64(949), row.TMDBID)
+	assert.Equal(t, "movie", row.MediaType)
+	assert.Equal(t, "tt0113277", row.IMDbID)
+	assert.Equal(t, models.StatusWatched, *row.Status)
+	// The exporting member's own rating and notes come back, not another member's
+	assert.Equal(t, 9, *row.Rating)
+	assert.Equal(t, "Diner scene", *row.Notes)
+	assert.Equal(t, time.Date(2024, 5, 4, 21, 0, 0, 0, time.UTC), *row.WatchedAt)
+	assert.True(t, row.Watched)
+}
+
+func TestListExportImportOnlyWatchesWhatTheExporterWatched(t *testing.T) {
+	doc := struct {
+		exportedListHeader
+		Items []exportedListItem `json:"items"`
+	}{
+		// bruno rated the title but never marked it as watched; the list did
+		exportedListHeader: exportedListHeader{
+			Format:     models.ImportList2gether,
+			Version:    ListExportVersion,
+			ExportedBy: exportedListMember{UserID: 8, Username: "bruno", Role: models.RoleParticipant},
+		},
+		Items: []exportedListItem{exportListItem(exportTestListMovie(), 1)},
+	}
+	data, err := json.Marshal(doc)
+	assert.NoError(t, err)
+
+	rows, err := parseImportFile(models.ImportList2gether, data)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, models.StatusWatched, *rows[0].Status)
+	assert.Equal(t, 6, *rows[0].Rating)
+	assert.False(t, rows[0].Watched)
+}
+
+func TestListExportPagesAfterTheLastTitle(t *testing.T) {
+	lists := &mocks.MockMovieListDAO{}
+	page := make([]models.ListMovie, exportBatchSize)
+	for i := range page {
+		order := i
+		page[i] = models.ListMovie{ID: int64(100 + i), DisplayOrder: &order}
+	}
+	last := page[len(page)-1]
+	lists.On("FindListMoviesForExport", int64(1), (*int)(nil), int64(0), exportBatchSize).Return(page, nil)
+	lists.On("FindListMoviesForExport", int64(1), last.DisplayOrder, last.ID, exportBatchSize).Return([]models.ListMovie{{ID: 7}}, nil)
+	export := &ListExport{List: &models.MovieList{ID: 1}, s: &listService{lists: lists}}
+
+	positions := 0
+	assert.NoError(t, export.eachItem(func(lm models.ListMovie, position int) error {
+		positions = position
+		return nil
+	}))
+	assert.Equal(t, exportBatchSize+1, positions)
+	lists.AssertNumberOfCalls(t, "FindListMoviesForExport", 2)
+}
+
+func TestDetectImportFormatRejectsUnknownJSONObject(t *testing.T) {
+	_, err := detectImportFormat([]byte(`{"format":"something-else","items":[]}`))
+	assert.ErrorIs(t, err, ErrInvalidImportFile)
+}
+
+func TestCSVSafe(t *testing.T) {
+	assert.Equal(t, "'=HYPERLINK(\"x\")", csvSafe("=HYPERLINK(\"x\")"))
+	assert.Equal(t, "'@SUM(A1)", csvSafe("@SUM(A1)"))
+	assert.Equal(t, "Heat", csvSafe("Heat"))
+	assert.Equal(t, "", csvSafe(""))
+}
+
+func TestExportSlug(t *testing.T) {
+	assert.Equal(t, "filmes-de-sexta", exportSlug("  Filmes de Sexta! "))
+	assert.Equal(t, "top-10-2024", exportSlug("Top 10 (2024)"))
+	assert.Equal(t, "", exportSlug("★★★"))
+}
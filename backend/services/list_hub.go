@@ -0,0 +1,144 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+)
+
+// Events queued per subscriber before it counts as too slow and is dropped
+const listSubscriberBuffer = 64
+
+// ListBroker carries stream events between server instances. The hub publishes every
+// event to the broker and fans out whatever the broker delivers to its own subscribers,
+// so a shared broker (Redis pub/sub, NATS...) lets several instances serve one list.
+type ListBroker interface {
+	Publish(event models.ListStreamEvent) error
+	// Subscribe registers deliver for every published event until unsubscribe is called
+	Subscribe(deliver func(models.ListStreamEvent)) (unsubscribe func())
+}
+
+type localListBroker struct {
+	mu       sync.RWMutex
+	handlers map[int]func(models.ListStreamEvent)
+	next     int
+}
+
+// NewLocalListBroker returns a broker that only delivers within this process
+func NewLocalListBroker() ListBroker {
+	return &localListBroker{handlers: make(map[int]func(models.ListStreamEvent))}
+}
+
+func (b *localListBroker) Publish(event models.ListStreamEvent) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, deliver := range b.handlers {
+		deliver(event)
+	}
+	return nil
+}
+
+func (b *localListBroker) Subscribe(deliver func(models.ListStreamEvent)) func() {
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.handlers[id] = deliver
+	b.mu.Unlock()
+	return func() {
+		b.mu.Lock()
+		delete(b.handlers, id)
+		b.mu.Unlock()
+	}
+}
+
+// ListHub fans list events out to the clients streaming them from this instance
+type ListHub interface {
+	Publish(event models.ListStreamEvent)
+	Subscribe(listID, userID int64) *ListSubscription
+}
+
+// ListSubscription receives the events of one list. Events is closed when the
+// subscription is closed or dropped for falling behind.
+type ListSubscription struct {
+	ListID int64
+	UserID int64
+	Events <-chan models.ListStreamEvent
+
+	events chan models.ListStreamEvent
+	hub    *listHub
+}
+
+// Close stops the subscription; it is safe to call more than once
+func (s *ListSubscription) Close() {
+	s.hub.remove(s)
+}
+
+type listHub struct {
+	broker      ListBroker
+	mu          sync.RWMutex
+	subscribers map[int64]map[*ListSubscription]struct{}
+}
+
+func NewListHub(broker ListBroker) ListHub {
+	h := &listHub{broker: broker, subscribers: make(map[int64]map[*ListSubscription]struct{})}
+	broker.Subscribe(h.deliver)
+	return h
+}
+
+// Publish hands the event to the broker. Failures are only logged: the change that
+// produced the event has already been saved.
+func (h *listHub) Publish(event models.ListStreamEvent) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if err := h.broker.Publish(event); err != nil {
+		log.Printf("list_stream publish failed list=%d type=%s: %v", event.ListID, event.Type, err)
+	}
+}
+
+func (h *listHub) Subscribe(listID, userID int64) *ListSubscription {
+	events := make(chan models.ListStreamEvent, listSubscriberBuffer)
+	sub := &ListSubscription{ListID: listID, UserID: userID, Events: events, events: events, hub: h}
+	h.mu.Lock()
+	if h.subscribers[listID] == nil {
+		h.subscribers[listID] = make(map[*ListSubscription]struct{})
+	}
+	h.subscribers[listID][sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// deliver queues the event for every local subscriber of its list. A subscriber whose
+// queue is full is dropped rather than blocking the others; its client reconnects
+// and refetches.
+func (h *listHub) deliver(event models.ListStreamEvent) {
+	var slow []*ListSubscription
+	h.mu.RLock()
+	for sub := range h.subscribers[event.ListID] {
+		select {
+		case sub.events <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	h.mu.RUnlock()
+	for _, sub := range slow {
+		h.remove(sub)
+	}
+}
+
+func (h *listHub) remove(sub *ListSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs := h.subscribers[sub.ListID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.ListID)
+	}
+	close(sub.events)
+}
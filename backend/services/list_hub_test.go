@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListHub_DeliversToSubscribersOfTheList(t *testing.T) {
+	hub := NewListHub(NewLocalListBroker())
+	first := hub.Subscribe(1, 10)
+	second := hub.Subscribe(1, 11)
+	other := hub.Subscribe(2, 10)
+	defer first.Close()
+	defer second.Close()
+	defer other.Close()
+
+	hub.Publish(models.ListStreamEvent{Type: models.StreamMovieAdded, ListID: 1, ActorID: 10})
+
+	for _, sub := range []*ListSubscription{first, second} {
+		event := <-sub.Events
+		assert.Equal(t, models.StreamMovieAdded, event.Type)
+		assert.False(t, event.OccurredAt.IsZero())
+	}
+	assert.Len(t, other.Events, 0)
+}
+
+func TestListHub_SharedBrokerReachesOtherHubs(t *testing.T) {
+	broker := NewLocalListBroker()
+	a := NewListHub(broker)
+	b := NewListHub(broker)
+	sub := b.Subscribe(1, 10)
+	defer sub.Close()
+
+	a.Publish(models.ListStreamEvent{Type: models.StreamCommentCreated, ListID: 1, ActorID: 11})
+
+	event := <-sub.Events
+	assert.Equal(t, models.StreamCommentCreated, event.Type)
+	assert.Equal(t, int64(11), event.ActorID)
+}
+
+func TestListHub_DropsSlowSubscriber(t *testing.T) {
+	hub := NewListHub(NewLocalListBroker())
+	slow := hub.Subscribe(1, 10)
+
+	for i := 0; i <= listSubscriberBuffer; i++ {
+		hub.Publish(models.ListStreamEvent{Type: models.StreamMovieUpdated, ListID: 1})
+	}
+
+	received := 0
+	for range slow.Events {
+		received++
+	}
+	assert.Equal(t, listSubscriberBuffer, received)
+	slow.Close()
+}
+
+func TestListSubscription_CloseIsIdempotent(t *testing.T) {
+	hub := NewListHub(NewLocalListBroker())
+	sub := hub.Subscribe(1, 10)
+
+	sub.Close()
+	sub.Close()
+
+	_, ok := <-sub.Events
+	assert.False(t, ok)
+	hub.Publish(models.ListStreamEvent{Type: models.StreamMovieAdded, ListID: 1})
+}
@@ -0,0 +1,426 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidImportFormat = errors.New("invalid_import_format")
+	ErrInvalidImportFile   = errors.New("invalid_import_file")
+	ErrImportTooLarge      = errors.New("import_too_large")
+	ErrImportInProgress    = errors.New("import_in_progress")
+	ErrImportNotFound      = errors.New("import_not_found")
+)
+
+const (
+	// MaxImportRows caps the rows of one uploaded export
+	MaxImportRows = 10000
+	// MaxImportFileSize caps the size of one uploaded export, in bytes
+	MaxImportFileSize = 10 << 20
+	// Unmatched rows kept in an import's report
+	maxUnmatchedReport = 500
+	// Progress is saved after this many titles
+	importProgressEvery = 20
+	// Pause between TMDB lookups, to stay well under its rate limit
+	importLookupInterval = 50 * time.Millisecond
+)
+
+// Reasons an import row did not become a list title
+const (
+	unmatchedNotFound    = "not_found"
+	unmatchedNoTitle     = "missing_title"
+	unmatchedLookup      = "lookup_failed"
+	unmatchedUnsupported = "unsupported"
+)
+
+// ImportList reads an export file and starts importing it into the list in the
+// background. An empty format is detected from the file. Rows that do not say whether
+// the title was watched get defaultStatus.
+func (s *listService) ImportList(listID, userID int64, format models.ImportFormat, data []byte, defaultStatus models.MovieStatus) (*models.ListImport, error) {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImportFileSize {
+		return nil, ErrImportTooLarge
+	}
+	if format == "" {
+		detected, err := detectImportFormat(data)
+		if err != nil {
+			return nil, err
+		}
+		format = detected
+	}
+	if !format.IsValid() {
+		return nil, ErrInvalidImportFormat
+	}
+	rows, err := parseImportFile(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ListImport{
+		ListID:    listID,
+		UserID:    userID,
+		Format:    format,
+		Status:    models.ImportRunning,
+		TotalRows: len(rows),
+	}
+	started, err := s.lists.StartImport(job)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrImportInProgress
+	}
+	jobCopy := *job
+	go s.runImport(&jobCopy, rows, defaultStatus)
+	return job, nil
+}
+
+func (s *listService) GetImport(listID, userID, importID int64) (*models.ListImport, error) {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	job, err := s.lists.FindImport(listID, importID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// runImport matches every row to a TMDB title and adds the titles to the list, saving
+// its progress as it goes
+func (s *listService) runImport(job *models.ListImport, rows []importRow, defaultStatus models.MovieStatus) {
+	// A panic would otherwise take the server down and leave the job running until it
+	// goes stale
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("list_import panic import=%d list=%d: %v\n%s", job.ID, job.ListID, r, debug.Stack())
+			now := time.Now().UTC()
+			message := "internal_error"
+			job.Status = models.ImportFailed
+			job.Error = &message
+			job.FinishedAt = &now
+			if err := s.lists.SaveImportProgress(job); err != nil {
+				log.Printf("list_import progress failed import=%d: %v", job.ID, err)
+			}
+		}
+	}()
+	ctx := context.Background()
+	var unmatched []models.UnmatchedImportRow
+	report := func(row importRow, reason string) {
+		job.UnmatchedCount++
+		if len(unmatched) < maxUnmatchedReport {
+			unmatched = append(unmatched, models.UnmatchedImportRow{
+				Line:   row.Line,
+				Title:  truncateRunes(row.Title, 200),
+				Year:   row.Year,
+				IMDbID: row.IMDbID,
+				Reason: reason,
+			})
+		}
+	}
+	save := func() {
+		if encoded, err := json.Marshal(unmatched); err == nil && len(unmatched) > 0 {
+			job.Unmatched = string(encoded)
+		}
+		if err := s.lists.SaveImportProgress(job); err != nil {
+			log.Printf("list_import progress failed import=%d: %v", job.ID, err)
+		}
+	}
+
+	// Match every row first so rows about the same title, like diary rewatches, become
+	// one list entry
+	matched := make(map[string]*importRow)
+	order := make([]string, 0, len(rows))
+	cache := make(map[string]importMatch)
+	for i, row := range rows {
+		match, reason := s.matchImportRow(ctx, row, cache)
+		if reason != "" {
+			report(row, reason)
+			job.ProcessedRows++
+		} else {
+			key := match.MediaType + ":" + strconv.FormatInt(match.MovieID, 10)
+			if existing, ok := matched[key]; ok {
+				mergeImportRows(existing, row)
+				job.ProcessedRows++
+			} else {
+				row.TMDBID = match.MovieID
+				row.MediaType = match.MediaType
+				matched[key] = &row
+				order = append(order, key)
+			}
+		}
+		if (i+1)%importProgressEvery == 0 {
+			save()
+		}
+	}
+
+	for i, key := range order {
+		row := matched[key]
+		status := defaultStatus
+		if row.Status != nil {
+			status = *row.Status
+		}
+		created, err := s.lists.ImportListMovie(job.ListID, job.UserID, daos.ImportedListMovie{
+			MovieID:   row.TMDBID,
+			Status:    status,
+			Watched:   row.Watched || (row.Status == nil && status == models.StatusWatched),
+			WatchedAt: row.WatchedAt,
+			Rating:    row.Rating,
+			Notes:     row.Notes,
+		})
+		switch {
+		case err != nil:
+			log.Printf("list_import row failed import=%d movie=%d: %v", job.ID, row.TMDBID, err)
+			report(*row, unmatchedLookup)
+		case created:
+			job.AddedCount++
+		default:
+			job.UpdatedCount++
+		}
+		job.ProcessedRows++
+		if (i+1)%importProgressEvery == 0 {
+			save()
+		}
+	}
+
+	now := time.Now().UTC()
+	job.Status = models.ImportCompleted
+	job.ProcessedRows = job.TotalRows
+	job.FinishedAt = &now
+	save()
+	log.Printf("list_import success import=%d list=%d added=%d updated=%d unmatched=%d", job.ID, job.ListID, job.AddedCount, job.UpdatedCount, job.UnmatchedCount)
+	s.publish(job.ListID, job.UserID, models.StreamListImported, map[string]interface{}{
+		"import_id": job.ID,
+		"added":     job.AddedCount,
+		"updated":   job.UpdatedCount,
+	})
+}
+
+// importMatch is the stored title a row was matched to
+type importMatch struct {
+	MovieID   int64
+	MediaType string
+}
+
+// matchImportRow finds the TMDB title of a row through its TMDB id, its IMDb id or a
+// title and year search, storing the title if the app has not seen it yet. It returns
+// the reason when the row cannot be matched. Lookups are cached by what was looked up.
+func (s *listService) matchImportRow(ctx context.Context, row importRow, cache map[string]importMatch) (importMatch, string) {
+	if row.Unsupported != "" {
+		return importMatch{}, unmatchedUnsupported
+	}
+	if row.TMDBID == 0 && row.IMDbID == "" && strings.TrimSpace(row.Title) == "" {
+		return importMatch{}, unmatchedNoTitle
+	}
+	key := row.MediaType + "|" + strconv.FormatInt(row.TMDBID, 10) + "|" + row.IMDbID + "|" + strings.ToLower(row.Title) + "|" + strconv.Itoa(row.Year)
+	if match, ok := cache[key]; ok {
+		if match.MovieID == 0 {
+			return importMatch{}, unmatchedNotFound
+		}
+		return match, ""
+	}
+
+	match, err := s.resolveImportRow(ctx, row)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		cache[key] = importMatch{}
+		return importMatch{}, unmatchedNotFound
+	case err != nil:
+		log.Printf("list_import lookup failed title=%q: %v", row.Title, err)
+		return importMatch{}, unmatchedLookup
+	}
+	cache[key] = match
+	return match, ""
+}
+
+func (s *listService) resolveImportRow(ctx context.Context, row importRow) (importMatch, error) {
+	if row.TMDBID != 0 && row.MediaType != "" {
+		return s.storeImportedTitle(ctx, row.TMDBID, row.MediaType, row.IMDbID)
+	}
+	if row.IMDbID != "" {
+		if movie, err := s.movies.FindByIMDbID(row.IMDbID); err == nil {
+			return importMatch{MovieID: movie.ID, MediaType: movie.MediaType}, nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return importMatch{}, err
+		}
+		id, mediaType, err := s.findTMDBByIMDbID(ctx, row.IMDbID, row.MediaType)
+		if err == nil {
+			return s.storeImportedTitle(ctx, id, mediaType, row.IMDbID)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) || row.Title == "" {
+			return importMatch{}, err
+		}
+	}
+
+	mediaTypes := []string{row.MediaType}
+	if row.MediaType == "" {
+		mediaTypes = []string{"movie", "tv"}
+	}
+	for _, mediaType := range mediaTypes {
+		id, err := s.searchTMDBTitle(ctx, row.Title, row.Year, mediaType)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return importMatch{}, err
+		}
+		return s.storeImportedTitle(ctx, id, mediaType, row.IMDbID)
+	}
+	return importMatch{}, gorm.ErrRecordNotFound
+}
+
+// storeImportedTitle makes sure the title is stored, as adding it by hand would, and
+// records its IMDb id when the export had one
+func (s *listService) storeImportedTitle(ctx context.Context, id int64, mediaType, imdbID string) (importMatch, error) {
+	movie, err := s.movies.FindByIDAndType(id, mediaType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		time.Sleep(importLookupInterval)
+		movie, err = s.fetchAndStoreFromTMDB(ctx, id, mediaType)
+	}
+	if err != nil {
+		return importMatch{}, err
+	}
+	if imdbID != "" && movie.IMDbID == nil {
+		if err := s.movies.SetIMDbID(movie.ID, imdbID); err != nil {
+			return importMatch{}, err
+		}
+	}
+	return importMatch{MovieID: movie.ID, MediaType: movie.MediaType}, nil
+}
+
+type tmdbFindResponse struct {
+	MovieResults []struct {
+		ID int64 `json:"id"`
+	} `json:"movie_results"`
+	TVResults []struct {
+		ID int64 `json:"id"`
+	} `json:"tv_results"`
+}
+
+// findTMDBByIMDbID resolves an IMDb id, preferring the media type the export gave
+func (s *listService) findTMDBByIMDbID(ctx context.Context, imdbID, mediaType string) (int64, string, error) {
+	var found tmdbFindResponse
+	values := url.Values{}
+	values.Set("external_source", "imdb_id")
+	if err := s.getTMDB(ctx, "/3/find/"+url.PathEscape(imdbID), values, &found); err != nil {
+		return 0, "", err
+	}
+	movie := len(found.MovieResults) > 0
+	tv := len(found.TVResults) > 0
+	switch {
+	case tv && (mediaType == "tv" || !movie):
+		return found.TVResults[0].ID, "tv", nil
+	case movie:
+		return found.MovieResults[0].ID, "movie", nil
+	}
+	return 0, "", gorm.ErrRecordNotFound
+}
+
+type tmdbSearchResult struct {
+	ID           int64  `json:"id"`
+	ReleaseDate  string `json:"release_date"`
+	FirstAirDate string `json:"first_air_date"`
+}
+
+// searchTMDBTitle looks a title up by name and picks the result from the row's year
+func (s *listService) searchTMDBTitle(ctx context.Context, title string, year int, mediaType string) (int64, error) {
+	var found struct {
+		Results []tmdbSearchResult `json:"results"`
+	}
+	values := url.Values{}
+	values.Set("query", title)
+	values.Set("include_adult", "false")
+	if err := s.getTMDB(ctx, "/3/search/"+mediaType, values, &found); err != nil {
+		return 0, err
+	}
+	id, ok := pickSearchResult(found.Results, year)
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return id, nil
+}
+
+// pickSearchResult takes the most relevant result released in the given year, or a
+// year off to allow for festival and regional releases. Without a year the most
+// relevant result wins.
+func pickSearchResult(results []tmdbSearchResult, year int) (int64, bool) {
+	if len(results) == 0 {
+		return 0, false
+	}
+	if year == 0 {
+		return results[0].ID, true
+	}
+	resultYear := func(r tmdbSearchResult) int {
+		date := r.ReleaseDate
+		if date == "" {
+			date = r.FirstAirDate
+		}
+		if len(date) < 4 {
+			return 0
+		}
+		y, _ := strconv.Atoi(date[:4])
+		return y
+	}
+	for _, r := range results {
+		if resultYear(r) == year {
+			return r.ID, true
+		}
+	}
+	for _, r := range results {
+		if y := resultYear(r); y == year-1 || y == year+1 {
+			return r.ID, true
+		}
+	}
+	return 0, false
+}
+
+// getTMDB decodes a TMDB API response. A 404 is reported as gorm.ErrRecordNotFound.
+func (s *listService) getTMDB(ctx context.Context, path string, values url.Values, out interface{}) error {
+	time.Sleep(importLookupInterval)
+	endpoint := url.URL{Scheme: "https", Host: "api.themoviedb.org", Path: path, RawQuery: values.Encode()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.tmdbToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.tmdbToken)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return gorm.ErrRecordNotFound
+	case resp.StatusCode != http.StatusOK:
+		return ErrTMDBUnavailable
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
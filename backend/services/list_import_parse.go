@@ -0,0 +1,367 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+)
+
+// importRow is one title read from an export file, before it is matched to TMDB
+type importRow struct {
+	Line   int
+	Title  string
+	Year   int
+	IMDbID string
+	TMDBID int64
+	// MediaType is "movie" or "tv", or empty when the export does not say
+	MediaType string
+	// Status is nil when the export does not say whether the title was watched
+	Status    *models.MovieStatus
+	WatchedAt *time.Time
+	// Watched is set when the member who made the export watched the title. In a
+	// list2gether export the status is the list's, so only their own entry says so.
+	Watched bool
+	Rating  *int
+	Notes   *string
+	// Unsupported explains why a row cannot be imported at all, such as a single episode
+	Unsupported string
+}
+
+// detectImportFormat guesses the service an export came from by its shape
+func detectImportFormat(data []byte) (models.ImportFormat, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return "", ErrInvalidImportFile
+	}
+	if trimmed[0] == '{' {
+		var header struct {
+			Format models.ImportFormat `json:"format"`
+		}
+		if json.Unmarshal(trimmed, &header) == nil && header.Format == models.ImportList2gether {
+			return models.ImportList2gether, nil
+		}
+		return "", ErrInvalidImportFile
+	}
+	if trimmed[0] == '[' {
+		return models.ImportTrakt, nil
+	}
+	firstLine, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	header := strings.ToLower(string(firstLine))
+	switch {
+	case strings.Contains(header, "letterboxd uri"):
+		return models.ImportLetterboxd, nil
+	case strings.Contains(header, "const"):
+		return models.ImportIMDb, nil
+	}
+	return "", ErrInvalidImportFile
+}
+
+// parseImportFile reads the rows of an export in the given format
+func parseImportFile(format models.ImportFormat, data []byte) ([]importRow, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	var (
+		rows []importRow
+		err  error
+	)
+	switch format {
+	case models.ImportLetterboxd:
+		rows, err = parseLetterboxdCSV(data)
+	case models.ImportIMDb:
+		rows, err = parseIMDbCSV(data)
+	case models.ImportTrakt:
+		rows, err = parseTraktJSON(data)
+	case models.ImportList2gether:
+		rows, err = parseList2getherJSON(data)
+	default:
+		return nil, ErrInvalidImportFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrInvalidImportFile
+	}
+	if len(rows) > MaxImportRows {
+		return nil, ErrImportTooLarge
+	}
+	return rows, nil
+}
+
+// csvRecords reads a CSV export with a header row and calls fn with each record keyed
+// by lowercase column name and its line number
+func csvRecords(data []byte, required []string, fn func(record map[string]string, line int)) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	header, err := reader.Read()
+	if err != nil {
+		return ErrInvalidImportFile
+	}
+	columns := make([]string, len(header))
+	present := make(map[string]bool, len(header))
+	for i, name := range header {
+		columns[i] = strings.ToLower(strings.TrimSpace(name))
+		present[columns[i]] = true
+	}
+	for _, name := range required {
+		if !present[name] {
+			return ErrInvalidImportFile
+		}
+	}
+
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return ErrInvalidImportFile
+		}
+		line, _ := reader.FieldPos(0)
+		record := make(map[string]string, len(columns))
+		for i, value := range fields {
+			if i < len(columns) {
+				record[columns[i]] = strings.TrimSpace(value)
+			}
+		}
+		fn(record, line)
+	}
+}
+
+// parseLetterboxdCSV reads any of Letterboxd's export files: diary, ratings, reviews,
+// watched and watchlist. Ratings are half stars from 0.5 to 5.
+func parseLetterboxdCSV(data []byte) ([]importRow, error) {
+	var rows []importRow
+	err := csvRecords(data, []string{"name"}, func(record map[string]string, line int) {
+		row := importRow{Line: line, Title: record["name"], Year: parseImportYear(record["year"]), MediaType: "movie"}
+		if v, err := strconv.ParseFloat(record["rating"], 64); err == nil {
+			row.Rating = scaleImportRating(v, 5)
+		}
+		row.WatchedAt = parseImportDate(record["watched date"])
+		if row.WatchedAt != nil || row.Rating != nil {
+			if row.WatchedAt == nil {
+				row.WatchedAt = parseImportDate(record["date"])
+			}
+			watched := models.StatusWatched
+			row.Status = &watched
+			row.Watched = true
+		}
+		if review := record["review"]; review != "" {
+			row.Notes = &review
+		}
+		rows = append(rows, row)
+	})
+	return rows, err
+}
+
+// parseIMDbCSV reads IMDb's ratings and watchlist exports. Ratings are already 1 to 10.
+func parseIMDbCSV(data []byte) ([]importRow, error) {
+	var rows []importRow
+	err := csvRecords(data, []string{"const", "title"}, func(record map[string]string, line int) {
+		row := importRow{Line: line, Title: record["title"], Year: parseImportYear(record["year"]), IMDbID: record["const"]}
+		switch record["title type"] {
+		case "tvSeries", "tvMiniSeries":
+			row.MediaType = "tv"
+		case "tvEpisode":
+			row.Unsupported = "episode"
+		case "":
+		default:
+			row.MediaType = "movie"
+		}
+		if v, err := strconv.ParseFloat(record["your rating"], 64); err == nil {
+			row.Rating = scaleImportRating(v, 10)
+			row.WatchedAt = parseImportDate(record["date rated"])
+			watched := models.StatusWatched
+			row.Status = &watched
+			row.Watched = true
+		}
+		rows = append(rows, row)
+	})
+	return rows, err
+}
+
+type traktExportItem struct {
+	Type          string           `json:"type"`
+	Rating        *float64         `json:"rating"`
+	RatedAt       string           `json:"rated_at"`
+	WatchedAt     string           `json:"watched_at"`
+	LastWatchedAt string           `json:"last_watched_at"`
+	Plays         int              `json:"plays"`
+	Notes         string           `json:"notes"`
+	Movie         *traktExportItem `json:"movie"`
+	Show          *traktExportItem `json:"show"`
+	Title         string           `json:"title"`
+	Year          int              `json:"year"`
+	IDs           struct {
+		IMDb string `json:"imdb"`
+		TMDB int64  `json:"tmdb"`
+	} `json:"ids"`
+}
+
+// parseTraktJSON reads Trakt's history, watched, ratings and watchlist exports. Each is
+// an array of entries holding a movie or a show; episode and season entries count as
+// their show.
+func parseTraktJSON(data []byte) ([]importRow, error) {
+	var items []traktExportItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, ErrInvalidImportFile
+	}
+	rows := make([]importRow, 0, len(items))
+	for i, item := range items {
+		row := importRow{Line: i + 1}
+		media := item.Movie
+		row.MediaType = "movie"
+		if media == nil {
+			media = item.Show
+			row.MediaType = "tv"
+		}
+		if media == nil {
+			row.Unsupported = "unknown_entry"
+			rows = append(rows, row)
+			continue
+		}
+		row.Title = media.Title
+		row.Year = media.Year
+		row.IMDbID = media.IDs.IMDb
+		row.TMDBID = media.IDs.TMDB
+
+		if item.Rating != nil {
+			row.Rating = scaleImportRating(*item.Rating, 10)
+		}
+		row.WatchedAt = parseImportDate(item.WatchedAt)
+		if row.WatchedAt == nil {
+			row.WatchedAt = parseImportDate(item.LastWatchedAt)
+		}
+		switch {
+		// A show with a rating was finished; one with watch history may still be going
+		case row.MediaType == "tv" && row.Rating == nil && (row.WatchedAt != nil || item.Plays > 0):
+			watching := models.StatusWatching
+			row.Status = &watching
+		case row.WatchedAt != nil || item.Plays > 0 || row.Rating != nil:
+			watched := models.StatusWatched
+			row.Status = &watched
+			row.Watched = true
+			if row.WatchedAt == nil {
+				row.WatchedAt = parseImportDate(item.RatedAt)
+			}
+		}
+		if item.Notes != "" {
+			notes := item.Notes
+			row.Notes = &notes
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// parseList2getherJSON reads the app's own JSON list export. The rows keep the list's
+// order and status; ratings, notes and watch dates come from the member who made the
+// export.
+func parseList2getherJSON(data []byte) ([]importRow, error) {
+	var export struct {
+		exportedListHeader
+		Items []exportedListItem `json:"items"`
+	}
+	if err := json.Unmarshal(data, &export); err != nil || export.Format != models.ImportList2gether {
+		return nil, ErrInvalidImportFile
+	}
+	rows := make([]importRow, 0, len(export.Items))
+	for i, item := range export.Items {
+		row := importRow{
+			Line:      i + 1,
+			Title:     item.Title,
+			Year:      item.Year,
+			IMDbID:    derefString(item.IMDbID),
+			TMDBID:    item.TMDBID,
+			MediaType: item.MediaType,
+			WatchedAt: item.WatchedAt,
+		}
+		if row.MediaType != "movie" && row.MediaType != "tv" {
+			row.MediaType = ""
+		}
+		switch item.Status {
+		case models.StatusNotWatched, models.StatusWatching, models.StatusWatched, models.StatusDropped:
+			status := item.Status
+			row.Status = &status
+		}
+		for _, entry := range item.Entries {
+			if entry.UserID != export.ExportedBy.UserID {
+				continue
+			}
+			if entry.Rating != nil && *entry.Rating >= 1 && *entry.Rating <= 10 {
+				row.Rating = entry.Rating
+			}
+			row.Notes = entry.Notes
+			if entry.WatchedAt != nil {
+				row.WatchedAt = entry.WatchedAt
+				row.Watched = true
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// scaleImportRating maps a rating on a 0..max scale to the app's 1..10
+func scaleImportRating(value, max float64) *int {
+	if value <= 0 || max <= 0 {
+		return nil
+	}
+	rating := int(math.Round(value / max * 10))
+	if rating < 1 {
+		rating = 1
+	}
+	if rating > 10 {
+		rating = 10
+	}
+	return &rating
+}
+
+func parseImportYear(value string) int {
+	year, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || year < 1870 || year > 2200 {
+		return 0
+	}
+	return year
+}
+
+// parseImportDate accepts the plain dates of CSV exports and Trakt's timestamps
+func parseImportDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+// mergeImportRows folds rows about the same title into one, as a diary lists every
+// rewatch: the latest watch date and rating win and a watched row beats an unwatched one
+func mergeImportRows(into *importRow, row importRow) {
+	if row.Status != nil && (into.Status == nil || *row.Status == models.StatusWatched) {
+		into.Status = row.Status
+	}
+	if row.WatchedAt != nil && (into.WatchedAt == nil || row.WatchedAt.After(*into.WatchedAt)) {
+		into.WatchedAt = row.WatchedAt
+	}
+	into.Watched = into.Watched || row.Watched
+	if row.Rating != nil {
+		into.Rating = row.Rating
+	}
+	if row.Notes != nil {
+		into.Notes = row.Notes
+	}
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDetectImportFormat(t *testing.T) {
+	cases := map[string]models.ImportFormat{
+		"Date,Name,Year,Letterboxd URI,Rating\n":                  models.ImportLetterboxd,
+		"\xef\xbb\xbfConst,Your Rating,Date Rated,Title,Year\n":   models.ImportIMDb,
+		`[{"type":"movie","movie":{"title":"Heat","year":1995}}]`: models.ImportTrakt,
+	}
+	for data, want := range cases {
+		format, err := detectImportFormat([]byte(data))
+		assert.NoError(t, err)
+		assert.Equal(t, want, format)
+	}
+
+	_, err := detectImportFormat([]byte("title,year\nHeat,1995\n"))
+	assert.ErrorIs(t, err, ErrInvalidImportFile)
+	_, err = detectImportFormat([]byte("  \n"))
+	assert.ErrorIs(t, err, ErrInvalidImportFile)
+}
+
+func TestParseLetterboxdDiary(t *testing.T) {
+	data := "Date,Name,Year,Letterboxd URI,Rating,Rewatch,Tags,Watched Date\n" +
+		"2024-03-02,Heat,1995,https://boxd.it/abc,4.5,,,2024-03-01\n" +
+		"2024-03-05,\"Crouching Tiger, Hidden Dragon\",2000,https://boxd.it/def,,,,\n"
+	rows, err := parseImportFile(models.ImportLetterboxd, []byte(data))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	heat := rows[0]
+	assert.Equal(t, 2, heat.Line)
+	assert.Equal(t, "Heat", heat.Title)
+	assert.Equal(t, 1995, heat.Year)
+	assert.Equal(t, "movie", heat.MediaType)
+	assert.Equal(t, 9, *heat.Rating)
+	assert.Equal(t, models.StatusWatched, *heat.Status)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), *heat.WatchedAt)
+
+	tiger := rows[1]
+	assert.Equal(t, "Crouching Tiger, Hidden Dragon", tiger.Title)
+	assert.Nil(t, tiger.Rating)
+	assert.Nil(t, tiger.Status)
+}
+
+func TestParseIMDbRatings(t *testing.T) {
+	data := "Const,Your Rating,Date Rated,Title,URL,Title Type,IMDb Rating,Runtime (mins),Year\n" +
+		"tt0113277,8,2023-11-20,Heat,https://www.imdb.com/title/tt0113277/,movie,8.3,170,1995\n" +
+		"tt0903747,10,2022-01-02,Breaking Bad,https://www.imdb.com/title/tt0903747/,tvSeries,9.5,49,2008\n" +
+		"tt2301451,10,2022-01-03,Ozymandias,https://www.imdb.com/title/tt2301451/,tvEpisode,10,47,2013\n"
+	rows, err := parseImportFile(models.ImportIMDb, []byte(data))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+
+	assert.Equal(t, "tt0113277", rows[0].IMDbID)
+	assert.Equal(t, "movie", rows[0].MediaType)
+	assert.Equal(t, 8, *rows[0].Rating)
+	assert.Equal(t, models.StatusWatched, *rows[0].Status)
+	assert.Equal(t, "tv", rows[1].MediaType)
+	assert.Equal(t, "episode", rows[2].Unsupported)
+}
+
+func TestParseIMDbRequiresConstColumn(t *testing.T) {
+	_, err := parseImportFile(models.ImportIMDb, []byte("Title,Year\nHeat,1995\n"))
+	assert.ErrorIs(t, err, ErrInvalidImportFile)
+}
+
+func TestParseTraktExport(t *testing.T) {
+	data := `[
+		{"rated_at":"2024-01-05T20:00:00.000Z","rating":7,"type":"movie","movie":{"title":"Heat","year":1995,"ids":{"tmdb":949,"imdb":"tt0113277"}}},
+		{"watched_at":"2024-02-01T21:00:00.000Z","type":"episode","show":{"title":"Severance","year":2022,"ids":{"tmdb":95396}}},
+		{"listed_at":"2024-02-02T10:00:00.000Z","type":"movie","movie":{"title":"Dune","year":2021,"ids":{"tmdb":438631}}},
+		{"type":"person","person":{"name":"Someone"}}
+	]`
+	rows, err := parseImportFile(models.ImportTrakt, []byte(data))
+	assert.NoError(t, err)
+	assert.Len(t, rows, 4)
+
+	assert.Equal(t, int64(949), rows[0].TMDBID)
+	assert.Equal(t, "tt0113277", rows[0].IMDbID)
+	assert.Equal(t, 7, *rows[0].Rating)
+	assert.Equal(t, models.StatusWatched, *rows[0].Status)
+	assert.Equal(t, time.Date(2024, 1, 5, 20, 0, 0, 0, time.UTC), *rows[0].WatchedAt)
+
+	assert.Equal(t, "tv", rows[1].MediaType)
+	assert.Equal(t, models.StatusWatching, *rows[1].Status)
+
+	assert.Nil(t, rows[2].Status)
+	assert.Equal(t, "unknown_entry", rows[3].Unsupported)
+}
+
+func TestParseImportFileRejectsEmptyAndMalformed(t *testing.T) {
+	_, err := parseImportFile(models.ImportTrakt, []byte(`{"not":"an array"}`))
+	assert.ErrorIs(t, err, ErrInvalidImportFile)
+	_, err = parseImportFile(models.ImportLetterboxd, []byte("Date,Name,Year,Letterboxd URI\n"))
+	assert.ErrorIs(t, err, ErrInvalidImportFile)
+	_, err = parseImportFile(models.ImportFormat("netflix"), []byte("x"))
+	assert.ErrorIs(t, err, ErrInvalidImportFormat)
+}
+
+func TestScaleImportRating(t *testing.T) {
+	assert.Equal(t, 1, *scaleImportRating(0.5, 5))
+	assert.Equal(t, 7, *scaleImportRating(3.5, 5))
+	assert.Equal(t, 10, *scaleImportRating(5, 5))
+	assert.Equal(t, 6, *scaleImportRating(6, 10))
+	assert.Nil(t, scaleImportRating(0, 5))
+}
+
+func TestMergeImportRowsKeepsLatestWatch(t *testing.T) {
+	watched := models.StatusWatched
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	rewatch := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	firstRating, rewatchRating := 6, 8
+
+	row := importRow{Title: "Heat", Status: &watched, WatchedAt: &rewatch, Rating: &firstRating}
+	mergeImportRows(&row, importRow{Title: "Heat", Status: &watched, WatchedAt: &first, Rating: &rewatchRating})
+	assert.Equal(t, rewatch, *row.WatchedAt)
+	assert.Equal(t, 8, *row.Rating)
+
+	unwatched := importRow{Title: "Heat"}
+	mergeImportRows(&unwatched, importRow{Status: &watched})
+	assert.Equal(t, models.StatusWatched, *unwatched.Status)
+}
+
+func TestPickSearchResultMatchesYear(t *testing.T) {
+	results := []tmdbSearchResult{
+		{ID: 1, ReleaseDate: "2019-05-01"},
+		{ID: 2, ReleaseDate: "1995-12-15"},
+		{ID: 3, FirstAirDate: "2008-01-20"},
+	}
+	id, ok := pickSearchResult(results, 1995)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), id)
+
+	id, ok = pickSearchResult(results, 2009)
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), id)
+
+	_, ok = pickSearchResult(results, 1970)
+	assert.False(t, ok)
+
+	id, ok = pickSearchResult(results, 0)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), id)
+
+	_, ok = pickSearchResult(nil, 1995)
+	assert.False(t, ok)
+}
+
+func TestImportListRejectsSecondRunningImport(t *testing.T) {
+	lists := &mocks.MockMovieListDAO{}
+	lists.On("FindByID", int64(1)).Return(&models.MovieList{ID: 1}, nil)
+	lists.On("FindMembership", int64(1), int64(2)).Return(&models.ListMember{ListID: 1, UserID: 2, Role: models.RoleParticipant}, nil)
+	lists.On("StartImport", mock.Anything).Return(false, nil)
+	service := NewListService(lists, nil, nil, nil, nil, nil, nil, "")
+
+	data := []byte("Date,Name,Year,Letterboxd URI,Rating\n2024-03-02,Heat,1995,https://boxd.it/abc,4.5\n")
+	job, err := service.ImportList(1, 2, "", data, models.StatusNotWatched)
+	assert.ErrorIs(t, err, ErrImportInProgress)
+	assert.Nil(t, job)
+}
+
+func TestRunImportMarksJobFailedOnPanic(t *testing.T) {
+	lists := &mocks.MockMovieListDAO{}
+	movies := &mocks.MockMovieDAO{}
+	movies.On("FindByIDAndType", int64(949), "movie").Return(&models.Movie{ID: 949, MediaType: "movie"}, nil)
+	lists.On("ImportListMovie", int64(1), int64(2), mock.Anything).Run(func(mock.Arguments) {
+		panic("boom")
+	})
+	var saved models.ListImport
+	lists.On("SaveImportProgress", mock.Anything).Run(func(args mock.Arguments) {
+		saved = *args.Get(0).(*models.ListImport)
+	}).Return(nil)
+	service := NewListService(lists, movies, nil, nil, nil, nil, nil, "").(*listService)
+
+	job := &models.ListImport{ID: 5, ListID: 1, UserID: 2, Status: models.ImportRunning, TotalRows: 1}
+	assert.NotPanics(t, func() {
+		service.runImport(job, []importRow{{Line: 2, TMDBID: 949, MediaType: "movie", Title: "Heat"}}, models.StatusNotWatched)
+	})
+	assert.Equal(t, models.ImportFailed, saved.Status)
+	assert.NotNil(t, saved.Error)
+	assert.NotNil(t, saved.FinishedAt)
+}
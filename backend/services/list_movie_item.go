@@ -0,0 +1,166 @@
+package services
+
+import (
+	"slices"
+
+	"github.com/8bury/list2gether/models"
+)
+
+// ListMovieItem renders a list title the way GET /api/lists/:id/movies returns it, with
+// the viewer's own entry, reactions and watched flag filled in for viewerID. The title
+// must have its movie, genres, user entries, watches and adder loaded.
+func ListMovieItem(lm models.ListMovie, viewerID int64) map[string]interface{} {
+	return listMovieItem(lm, viewerID, true)
+}
+
+// listMovieItem renders the item; without emails the members' addresses are left out,
+// for payloads that leave the app
+func listMovieItem(lm models.ListMovie, viewerID int64, emails bool) map[string]interface{} {
+	movie := lm.Movie
+	posterURL := (*string)(nil)
+	if movie.PosterPath != nil && *movie.PosterPath != "" {
+		u := "https://image.tmdb.org/t/p/w500" + *movie.PosterPath
+		posterURL = &u
+	}
+	m := map[string]interface{}{
+		"id":             movie.ID,
+		"title":          movie.Title,
+		"original_title": movie.OriginalTitle,
+		"original_lang":  movie.OriginalLang,
+		"overview":       movie.Overview,
+		"release_date":   movie.ReleaseDate,
+		"poster_url":     posterURL,
+		"media_type":     movie.MediaType,
+		"seasons_count":  movie.SeasonsCount,
+		"episodes_count": movie.EpisodesCount,
+		"series_status":  movie.SeriesStatus,
+	}
+	if len(movie.Genres) > 0 {
+		genres := make([]map[string]interface{}, 0, len(movie.Genres))
+		for _, g := range movie.Genres {
+			genres = append(genres, map[string]interface{}{"id": g.ID, "name": g.Name})
+		}
+		m["genres"] = genres
+	}
+
+	var (
+		sumRatings   int
+		countRatings int
+		yourEntry    map[string]interface{}
+		ratingCompat *int
+		notesCompat  *string
+		userEntries  = make([]map[string]interface{}, 0, len(lm.UserEntries))
+	)
+	for _, entry := range lm.UserEntries {
+		if entry.Rating != nil {
+			sumRatings += *entry.Rating
+			countRatings++
+		}
+		entryPayload := listMovieEntryPayload(entry, emails)
+		userEntries = append(userEntries, entryPayload)
+
+		if viewerID != 0 && entry.UserID == viewerID {
+			yourEntry = entryPayload
+			ratingCompat = entry.Rating
+			notesCompat = entry.Notes
+		}
+	}
+
+	var averageRating *float64
+	if countRatings > 0 {
+		avg := float64(sumRatings) / float64(countRatings)
+		averageRating = &avg
+	}
+
+	var addedByUser map[string]interface{}
+	if lm.AddedByUser != nil && lm.AddedByUser.ID != 0 {
+		addedByUser = listMovieUserPayload(*lm.AddedByUser, emails)
+	}
+
+	return map[string]interface{}{
+		"id":              lm.ID,
+		"list_id":         lm.ListID,
+		"movie_id":        lm.MovieID,
+		"status":          lm.Status,
+		"added_by":        lm.AddedBy,
+		"added_by_user":   addedByUser,
+		"added_at":        lm.AddedAt,
+		"watched_at":      lm.WatchedAt,
+		"updated_at":      lm.UpdatedAt,
+		"display_order":   lm.DisplayOrder,
+		"rating":          ratingCompat,
+		"notes":           notesCompat,
+		"average_rating":  averageRating,
+		"your_entry":      yourEntry,
+		"user_entries":    userEntries,
+		"reactions":       ReactionsPayload(lm.Reactions, viewerID),
+		"watched_by":      WatchedBy(lm.Watches),
+		"you_watched":     viewerID != 0 && HasWatched(lm.Watches, viewerID),
+		"tags":            TagNames(lm.Tags),
+		"unread_comments": lm.UnreadComments,
+		"movie":           m,
+	}
+}
+
+func listMovieEntryPayload(entry models.ListMovieUserData, emails bool) map[string]interface{} {
+	payload := map[string]interface{}{
+		"user_id":    entry.UserID,
+		"rating":     entry.Rating,
+		"notes":      entry.Notes,
+		"created_at": entry.CreatedAt,
+		"updated_at": entry.UpdatedAt,
+	}
+	if entry.User.ID != 0 {
+		payload["user"] = listMovieUserPayload(entry.User, emails)
+	}
+	return payload
+}
+
+func listMovieUserPayload(user models.User, emails bool) map[string]interface{} {
+	payload := map[string]interface{}{
+		"id":         user.ID,
+		"username":   user.Username,
+		"avatar_url": user.AvatarURL,
+	}
+	if emails {
+		payload["email"] = user.Email
+	}
+	return payload
+}
+
+// ReactionsPayload renders reaction counts and whether the viewer left each one
+func ReactionsPayload(summaries []models.ReactionSummary, viewerID int64) []map[string]interface{} {
+	payload := make([]map[string]interface{}, 0, len(summaries))
+	for _, summary := range summaries {
+		payload = append(payload, map[string]interface{}{
+			"kind":    summary.Kind,
+			"emoji":   summary.Emoji,
+			"count":   summary.Count,
+			"reacted": viewerID != 0 && slices.Contains(summary.UserIDs, viewerID),
+		})
+	}
+	return payload
+}
+
+// WatchedBy lists the members who marked the title as seen
+func WatchedBy(watches []models.ListMovieWatch) []int64 {
+	userIDs := make([]int64, 0, len(watches))
+	for _, w := range watches {
+		userIDs = append(userIDs, w.UserID)
+	}
+	return userIDs
+}
+
+// TagNames lists the title's tags in the order they were loaded
+func TagNames(tags []models.ListMovieTag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Tag)
+	}
+	return names
+}
+
+// HasWatched reports whether the member marked the title as seen
+func HasWatched(watches []models.ListMovieWatch, userID int64) bool {
+	return slices.ContainsFunc(watches, func(w models.ListMovieWatch) bool { return w.UserID == userID })
+}
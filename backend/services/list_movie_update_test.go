@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newUpdateMovieTestService(current, next models.MovieStatus) (ListService, *mocks.MockMovieListDAO) {
+	lists := &mocks.MockMovieListDAO{}
+	movies := &mocks.MockMovieDAO{}
+	lists.On("FindByID", int64(1)).Return(&models.MovieList{ID: 1}, nil)
+	lists.On("FindMembership", int64(1), int64(2)).Return(&models.ListMember{ListID: 1, UserID: 2, Role: models.RoleParticipant}, nil)
+	lists.On("FindListMovieByListAndMovie", int64(1), int64(7)).Return(&models.ListMovie{ListID: 1, MovieID: 7, Status: current}, nil)
+	lists.On("UpdateMovie", int64(1), int64(7), mock.Anything, int64(2)).Return(&models.ListMovie{ListID: 1, MovieID: 7, Status: next}, nil)
+	lists.On("SetMovieWatched", int64(1), int64(7), int64(2), mock.Anything).Return(nil)
+	lists.On("FindMovieWatches", int64(1), int64(7)).Return([]models.ListMovieWatch{{ListID: 1, MovieID: 7, UserID: 2}}, nil)
+	movies.On("FindByID", int64(7)).Return(&models.Movie{ID: 7, Title: "Heat"}, nil)
+	return NewListService(lists, movies, nil, nil, nil, nil, nil, ""), lists
+}
+
+func TestUpdateMovie_StatusToWatchingKeepsMyWatch(t *testing.T) {
+	service, lists := newUpdateMovieTestService(models.StatusWatched, models.StatusWatching)
+	status := models.StatusWatching
+
+	_, _, _, _, _, _, err := service.UpdateMovie(1, 2, 7, &status, nil, false, nil)
+
+	assert.NoError(t, err)
+	lists.AssertNotCalled(t, "SetMovieWatched", int64(1), int64(7), int64(2), mock.Anything)
+}
+
+func TestUpdateMovie_StatusToWatchedAddsMyWatch(t *testing.T) {
+	service, lists := newUpdateMovieTestService(models.StatusWatching, models.StatusWatched)
+	status := models.StatusWatched
+
+	_, _, _, _, _, _, err := service.UpdateMovie(1, 2, 7, &status, nil, false, nil)
+
+	assert.NoError(t, err)
+	lists.AssertCalled(t, "SetMovieWatched", int64(1), int64(7), int64(2), true)
+}
+
+func TestUpdateMovie_ExplicitUnwatchClearsMyWatch(t *testing.T) {
+	service, lists := newUpdateMovieTestService(models.StatusWatched, models.StatusWatching)
+	status := models.StatusWatching
+	watched := false
+
+	_, _, _, _, _, _, err := service.UpdateMovie(1, 2, 7, &status, nil, false, &watched)
+
+	assert.NoError(t, err)
+	lists.AssertCalled(t, "SetMovieWatched", int64(1), int64(7), int64(2), false)
+}
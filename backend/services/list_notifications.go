@@ -0,0 +1,249 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInviteOwnerOnly      = errors.New("invite_owner_only")
+	ErrInviteRateLimited    = errors.New("invite_rate_limited")
+	ErrInviteeAlreadyMember = errors.New("invitee_already_member")
+)
+
+const (
+	// MaxInvitesPerHour caps how many invitations one user sends, so invites cannot be
+	// used to spam people or to probe which usernames exist
+	MaxInvitesPerHour = 20
+	// availabilityRepeatWindow is how long the same providers for a title and region
+	// are not announced again
+	availabilityRepeatWindow = 7 * 24 * time.Hour
+)
+
+// InviteUser sends the list owner's invitation to join the list to another user. The
+// invitation carries the list's invite code; the user joins with it as usual. Unknown
+// usernames succeed like known ones, so the answer does not tell whether they exist.
+func (s *listService) InviteUser(listID, inviterID int64, username string) error {
+	list, err := s.lists.FindByID(listID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrListNotFound
+		}
+		return err
+	}
+	membership, err := s.lists.FindMembership(listID, inviterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrForbiddenMembership
+		}
+		return err
+	}
+	if membership.Role != models.RoleOwner {
+		return ErrInviteOwnerOnly
+	}
+	if !s.invites.Allow(strconv.FormatInt(inviterID, 10), MaxInvitesPerHour, time.Hour, time.Now()) {
+		return ErrInviteRateLimited
+	}
+
+	invitee, err := s.users.FindByUsername(strings.TrimSpace(username))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if invitee.DeletedAt != nil {
+		return nil
+	}
+	// Members are listed to the owner already, so saying so reveals nothing
+	if _, err := s.lists.FindMembership(listID, invitee.ID); err == nil {
+		return ErrInviteeAlreadyMember
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	s.notify(models.NotificationInvitationReceived, listID, inviterID, []int64{invitee.ID}, models.InvitationPayload{
+		ListID:     list.ID,
+		ListName:   list.Name,
+		InviteCode: list.InviteCode,
+	})
+	return nil
+}
+
+// NotifyAvailabilityChange tells the members of every list holding the title that it
+// became available on new streaming providers.
+// The same providers for a title and region are announced once per window, however
+// many readers refresh the cached providers at the same time.
+func (s *listService) NotifyAvailabilityChange(movieID int64, region string, providers []string) {
+	if len(providers) == 0 || s.notifier == nil {
+		return
+	}
+	if !s.availability.Allow(availabilityKey(movieID, region, providers), 1, availabilityRepeatWindow, time.Now()) {
+		return
+	}
+	movie, err := s.movies.FindByID(movieID)
+	if err != nil {
+		log.Printf("availability_notification failed movie=%d: %v", movieID, err)
+		return
+	}
+	lists, err := s.lists.FindListsWithMovie(movieID)
+	if err != nil {
+		log.Printf("availability_notification failed movie=%d: %v", movieID, err)
+		return
+	}
+	for _, list := range lists {
+		members, err := s.lists.FindMembersWithUser(list.ID)
+		if err != nil {
+			log.Printf("availability_notification failed list=%d: %v", list.ID, err)
+			continue
+		}
+		notifications, err := buildNotifications(models.NotificationAvailabilityChanged, &list.ID, nil, models.AvailabilityChangedPayload{
+			ListID:    list.ID,
+			ListName:  list.Name,
+			MovieID:   movie.ID,
+			Title:     movie.Title,
+			Region:    region,
+			Providers: providers,
+		}, otherMembers(members))
+		if err != nil {
+			log.Printf("availability_notification failed list=%d: %v", list.ID, err)
+			continue
+		}
+		s.notifier.Notify(notifications)
+	}
+}
+
+// availabilityKey identifies a set of providers for a title and region, in any order
+func availabilityKey(movieID int64, region string, providers []string) string {
+	sorted := append([]string(nil), providers...)
+	sort.Strings(sorted)
+	return strconv.FormatInt(movieID, 10) + "|" + region + "|" + strings.Join(sorted, ",")
+}
+
+const slidingWindowSweepSize = 10000
+
+// slidingWindow counts recent uses of a key. Like the in-memory presence store it only
+// sees this instance's traffic.
+type slidingWindow struct {
+	mu   sync.Mutex
+	uses map[string][]time.Time
+}
+
+func newSlidingWindow() *slidingWindow {
+	return &slidingWindow{uses: make(map[string][]time.Time)}
+}
+
+// Allow records a use of key at now and reports true, unless limit uses already fell
+// within the window before it
+func (w *slidingWindow) Allow(key string, limit int, window time.Duration, now time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	since := now.Add(-window)
+	recent := w.uses[key][:0]
+	for _, at := range w.uses[key] {
+		if at.After(since) {
+			recent = append(recent, at)
+		}
+	}
+	if len(recent) >= limit {
+		w.uses[key] = recent
+		return false
+	}
+	w.uses[key] = append(recent, now)
+	// Keys nobody used within the window are dropped once there are many of them
+	if len(w.uses) > slidingWindowSweepSize {
+		for k, times := range w.uses {
+			if !times[len(times)-1].After(since) {
+				delete(w.uses, k)
+			}
+		}
+	}
+	return true
+}
+
+func (s *listService) notifyMemberJoined(list *models.MovieList, userID int64) {
+	members, err := s.lists.FindMembersWithUser(list.ID)
+	if err != nil {
+		log.Printf("member_joined_notification failed list=%d: %v", list.ID, err)
+		return
+	}
+	payload := models.MemberJoinedPayload{ListID: list.ID, ListName: list.Name, UserID: userID}
+	for _, m := range members {
+		if m.UserID == userID {
+			payload.Username = m.User.Username
+		}
+	}
+	s.notify(models.NotificationMemberJoined, list.ID, userID, otherMembers(members, userID), payload)
+}
+
+func (s *listService) notifyTitleAdded(list *models.MovieList, movie *models.Movie, userID int64) {
+	members, err := s.lists.FindMembersWithUser(list.ID)
+	if err != nil {
+		log.Printf("title_added_notification failed list=%d: %v", list.ID, err)
+		return
+	}
+	s.notify(models.NotificationTitleAdded, list.ID, userID, otherMembers(members, userID), models.TitleAddedPayload{
+		ListID:    list.ID,
+		ListName:  list.Name,
+		MovieID:   movie.ID,
+		Title:     movie.Title,
+		MediaType: movie.MediaType,
+	})
+}
+
+// notifyTitlesAdded tells the other members about each title a bulk copy or move added
+// to their list, as if the titles had been added one by one
+func (s *listService) notifyTitlesAdded(listID int64, movieIDs []int64, userID int64) {
+	if len(movieIDs) == 0 || s.notifier == nil {
+		return
+	}
+	list, err := s.lists.FindByID(listID)
+	if err != nil {
+		log.Printf("title_added_notification failed list=%d: %v", listID, err)
+		return
+	}
+	members, err := s.lists.FindMembersWithUser(listID)
+	if err != nil {
+		log.Printf("title_added_notification failed list=%d: %v", listID, err)
+		return
+	}
+	recipients := otherMembers(members, userID)
+	for _, movieID := range movieIDs {
+		movie, err := s.movies.FindByID(movieID)
+		if err != nil {
+			log.Printf("title_added_notification failed list=%d movie=%d: %v", listID, movieID, err)
+			continue
+		}
+		s.notify(models.NotificationTitleAdded, listID, userID, recipients, models.TitleAddedPayload{
+			ListID:    list.ID,
+			ListName:  list.Name,
+			MovieID:   movie.ID,
+			Title:     movie.Title,
+			MediaType: movie.MediaType,
+		})
+	}
+}
+
+// NewStreamingProviders returns the flatrate providers in current that were not in the
+// previously cached region data
+func NewStreamingProviders(previous models.WatchProviderData, current WatchProviderRegion) []string {
+	known := make(map[int]bool, len(previous.Flatrate))
+	for _, p := range previous.Flatrate {
+		known[p.ProviderID] = true
+	}
+	var added []string
+	for _, p := range current.Flatrate {
+		if !known[p.ProviderID] {
+			added = append(added, p.ProviderName)
+		}
+	}
+	return added
+}
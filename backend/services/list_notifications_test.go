@@ -0,0 +1,169 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func TestNewStreamingProviders(t *testing.T) {
+	previous := models.WatchProviderData{
+		Flatrate: []models.WatchProviderEntry{{ProviderID: 8, ProviderName: "Netflix"}},
+		Rent:     []models.WatchProviderEntry{{ProviderID: 2, ProviderName: "Apple TV"}},
+	}
+	current := WatchProviderRegion{
+		Flatrate: []WatchProviderProvider{
+			{ProviderID: 8, ProviderName: "Netflix"},
+			{ProviderID: 337, ProviderName: "Disney Plus"},
+			{ProviderID: 2, ProviderName: "Apple TV"},
+		},
+	}
+
+	assert.Equal(t, []string{"Disney Plus", "Apple TV"}, NewStreamingProviders(previous, current))
+	assert.Empty(t, NewStreamingProviders(previous, WatchProviderRegion{}))
+}
+
+func TestMergePreferences(t *testing.T) {
+	preferences := mergePreferences(models.NotificationChannelInApp, []models.NotificationPreference{
+		{Type: models.NotificationComment, Enabled: false},
+		{Type: models.NotificationMention, Enabled: true},
+		{Type: "unknown", Enabled: false},
+	})
+
+	assert.Len(t, preferences, len(models.NotificationTypes))
+	assert.False(t, preferences[models.NotificationComment])
+	assert.True(t, preferences[models.NotificationMention])
+	assert.True(t, preferences[models.NotificationTitleAdded])
+	assert.NotContains(t, preferences, models.NotificationType("unknown"))
+
+	email := mergePreferences(models.NotificationChannelEmail, []models.NotificationPreference{
+		{Type: models.NotificationWeeklyDigest, Enabled: false},
+		{Type: models.NotificationComment, Enabled: false},
+	})
+	assert.Equal(t, map[models.NotificationType]bool{
+		models.NotificationInvitationReceived: true,
+		models.NotificationWeeklyDigest:       false,
+	}, email)
+}
+
+func TestOtherMembers(t *testing.T) {
+	members := []models.ListMember{{UserID: 1}, {UserID: 2}, {UserID: 3}}
+
+	assert.Equal(t, []int64{2, 3}, otherMembers(members, 1))
+	assert.Equal(t, []int64{3}, otherMembers(members, 1, 2))
+	assert.Equal(t, []int64{1, 2, 3}, otherMembers(members))
+}
+
+func TestBuildNotifications(t *testing.T) {
+	listID, actorID := int64(7), int64(1)
+	payload := models.TitleAddedPayload{ListID: listID, ListName: "Sexta", MovieID: 42, Title: "Alien"}
+
+	notifications, err := buildNotifications(models.NotificationTitleAdded, &listID, &actorID, payload, []int64{2, 3})
+	assert.NoError(t, err)
+	if assert.Len(t, notifications, 2) {
+		assert.Equal(t, int64(2), notifications[0].UserID)
+		assert.Equal(t, int64(3), notifications[1].UserID)
+		for _, n := range notifications {
+			assert.Equal(t, models.NotificationTitleAdded, n.Type)
+			assert.Equal(t, &listID, n.ListID)
+			assert.Equal(t, &actorID, n.ActorID)
+
+			var decoded models.TitleAddedPayload
+			assert.NoError(t, json.Unmarshal([]byte(n.Payload), &decoded))
+			assert.Equal(t, payload, decoded)
+		}
+	}
+}
+
+type recordingNotifier struct {
+	notifications []models.Notification
+}
+
+func (n *recordingNotifier) Notify(notifications []models.Notification) {
+	n.notifications = append(n.notifications, notifications...)
+}
+
+func newInviteTestService(inviterRole models.ListMemberRole) (ListService, *mocks.MockUserDAO, *recordingNotifier) {
+	lists := &mocks.MockMovieListDAO{}
+	users := &mocks.MockUserDAO{}
+	notifier := &recordingNotifier{}
+	lists.On("FindByID", int64(1)).Return(&models.MovieList{ID: 1, Name: "Sexta", InviteCode: "ABCDE12345"}, nil)
+	lists.On("FindMembership", int64(1), int64(2)).Return(&models.ListMember{ListID: 1, UserID: 2, Role: inviterRole}, nil)
+	lists.On("FindMembership", int64(1), int64(5)).Return(nil, gorm.ErrRecordNotFound)
+	users.On("FindByUsername", "bia").Return(&models.User{ID: 5, Username: "bia"}, nil)
+	users.On("FindByUsername", "ninguem").Return(nil, gorm.ErrRecordNotFound)
+	return NewListService(lists, nil, users, notifier, nil, nil, nil, ""), users, notifier
+}
+
+func TestInviteUser_OwnerOnly(t *testing.T) {
+	service, users, notifier := newInviteTestService(models.RoleParticipant)
+
+	assert.ErrorIs(t, service.InviteUser(1, 2, "bia"), ErrInviteOwnerOnly)
+	users.AssertNotCalled(t, "FindByUsername", mock.Anything)
+	assert.Empty(t, notifier.notifications)
+}
+
+func TestInviteUser_SameAnswerForUnknownUsers(t *testing.T) {
+	service, _, notifier := newInviteTestService(models.RoleOwner)
+
+	assert.NoError(t, service.InviteUser(1, 2, "bia"))
+	assert.NoError(t, service.InviteUser(1, 2, "ninguem"))
+	if assert.Len(t, notifier.notifications, 1) {
+		assert.Equal(t, int64(5), notifier.notifications[0].UserID)
+	}
+}
+
+func TestInviteUser_RateLimited(t *testing.T) {
+	service, _, _ := newInviteTestService(models.RoleOwner)
+
+	for i := 0; i < MaxInvitesPerHour; i++ {
+		assert.NoError(t, service.InviteUser(1, 2, "ninguem"))
+	}
+	assert.ErrorIs(t, service.InviteUser(1, 2, "bia"), ErrInviteRateLimited)
+}
+
+func TestSlidingWindowAllow(t *testing.T) {
+	window := newSlidingWindow()
+	start := time.Now()
+
+	assert.True(t, window.Allow("a", 2, time.Hour, start))
+	assert.True(t, window.Allow("a", 2, time.Hour, start.Add(time.Minute)))
+	assert.False(t, window.Allow("a", 2, time.Hour, start.Add(2*time.Minute)))
+	assert.True(t, window.Allow("b", 2, time.Hour, start.Add(2*time.Minute)))
+	// The first use leaves the window
+	assert.True(t, window.Allow("a", 2, time.Hour, start.Add(61*time.Minute)))
+}
+
+func TestAvailabilityKeyIgnoresProviderOrder(t *testing.T) {
+	assert.Equal(t, availabilityKey(7, "BR", []string{"Netflix", "Max"}), availabilityKey(7, "BR", []string{"Max", "Netflix"}))
+	assert.NotEqual(t, availabilityKey(7, "BR", []string{"Netflix"}), availabilityKey(7, "US", []string{"Netflix"}))
+	assert.NotEqual(t, availabilityKey(7, "BR", []string{"Netflix"}), availabilityKey(7, "BR", []string{"Netflix", "Max"}))
+}
+
+func TestNotifyAvailabilityChange_OncePerProviders(t *testing.T) {
+	lists := &mocks.MockMovieListDAO{}
+	movies := &mocks.MockMovieDAO{}
+	notifier := &recordingNotifier{}
+	movies.On("FindByID", int64(7)).Return(&models.Movie{ID: 7, Title: "Heat"}, nil)
+	lists.On("FindListsWithMovie", int64(7)).Return([]models.MovieList{{ID: 1, Name: "Sexta"}}, nil)
+	lists.On("FindMembersWithUser", int64(1)).Return([]models.ListMember{
+		{ListID: 1, UserID: 2, User: models.User{ID: 2}},
+		{ListID: 1, UserID: 3, User: models.User{ID: 3}},
+	}, nil)
+	service := NewListService(lists, movies, nil, notifier, nil, nil, nil, "")
+
+	service.NotifyAvailabilityChange(7, "BR", []string{"Netflix", "Max"})
+	service.NotifyAvailabilityChange(7, "BR", []string{"Max", "Netflix"})
+
+	recipients := []int64{}
+	for _, n := range notifier.notifications {
+		recipients = append(recipients, n.UserID)
+	}
+	assert.Equal(t, []int64{2, 3}, recipients)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+)
+
+// Members who have not sent a heartbeat for this long are no longer viewing the list.
+// Clients should beat at least twice per window.
+const PresenceTTL = 45 * time.Second
+
+// PresenceStore tracks which members have a list open. The in-memory store only sees
+// heartbeats sent to this instance; a shared store would back several instances.
+type PresenceStore interface {
+	// Touch records a heartbeat and reports whether the user was not present before
+	Touch(listID, userID int64, at time.Time) (joined bool)
+	// Leave removes the user and reports whether they were present
+	Leave(listID, userID int64) (left bool)
+	// Active returns the present users of the list with their last heartbeat
+	Active(listID int64, since time.Time) map[int64]time.Time
+	// Expire removes everyone whose last heartbeat is before the given time and
+	// returns them by list
+	Expire(before time.Time) map[int64][]int64
+}
+
+type memoryPresenceStore struct {
+	mu    sync.Mutex
+	lists map[int64]map[int64]time.Time
+}
+
+func NewMemoryPresenceStore() PresenceStore {
+	return &memoryPresenceStore{lists: make(map[int64]map[int64]time.Time)}
+}
+
+func (p *memoryPresenceStore) Touch(listID, userID int64, at time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	users := p.lists[listID]
+	if users == nil {
+		users = make(map[int64]time.Time)
+		p.lists[listID] = users
+	}
+	_, present := users[userID]
+	users[userID] = at
+	return !present
+}
+
+func (p *memoryPresenceStore) Leave(listID, userID int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	users := p.lists[listID]
+	if _, present := users[userID]; !present {
+		return false
+	}
+	delete(users, userID)
+	if len(users) == 0 {
+		delete(p.lists, listID)
+	}
+	return true
+}
+
+func (p *memoryPresenceStore) Active(listID int64, since time.Time) map[int64]time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	active := make(map[int64]time.Time)
+	for userID, seen := range p.lists[listID] {
+		if !seen.Before(since) {
+			active[userID] = seen
+		}
+	}
+	return active
+}
+
+func (p *memoryPresenceStore) Expire(before time.Time) map[int64][]int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	expired := make(map[int64][]int64)
+	for listID, users := range p.lists {
+		for userID, seen := range users {
+			if seen.Before(before) {
+				delete(users, userID)
+				expired[listID] = append(expired[listID], userID)
+			}
+		}
+		if len(users) == 0 {
+			delete(p.lists, listID)
+		}
+	}
+	return expired
+}
+
+// PresentMember is a member currently viewing the list
+type PresentMember struct {
+	UserID     int64
+	Username   string
+	AvatarURL  *string
+	Role       models.ListMemberRole
+	LastSeenAt time.Time
+}
+
+// Heartbeat marks the user as viewing the list and returns everyone viewing it
+func (s *listService) Heartbeat(listID, userID int64) ([]PresentMember, error) {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	if s.presence.Touch(listID, userID, time.Now().UTC()) {
+		s.publishPresence(listID, userID, true)
+	}
+	return s.presentMembers(listID)
+}
+
+// LeavePresence marks the user as no longer viewing the list
+func (s *listService) LeavePresence(listID, userID int64) error {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return err
+	}
+	if s.presence.Leave(listID, userID) {
+		s.publishPresence(listID, userID, false)
+	}
+	return nil
+}
+
+// GetPresence returns the members viewing the list
+func (s *listService) GetPresence(listID, userID int64) ([]PresentMember, error) {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	return s.presentMembers(listID)
+}
+
+func (s *listService) presentMembers(listID int64) ([]PresentMember, error) {
+	active := s.presence.Active(listID, time.Now().UTC().Add(-PresenceTTL))
+	if len(active) == 0 {
+		return []PresentMember{}, nil
+	}
+	members, err := s.lists.FindMembersWithUser(listID)
+	if err != nil {
+		return nil, err
+	}
+	return presentFromMembers(members, active), nil
+}
+
+// presentFromMembers keeps the members with a recent heartbeat, most recent first.
+// Heartbeats from users who are no longer members are ignored.
+func presentFromMembers(members []models.ListMember, active map[int64]time.Time) []PresentMember {
+	present := make([]PresentMember, 0, len(active))
+	for _, m := range members {
+		seen, ok := active[m.UserID]
+		if !ok {
+			continue
+		}
+		present = append(present, PresentMember{
+			UserID:     m.UserID,
+			Username:   m.User.Username,
+			AvatarURL:  m.User.AvatarURL,
+			Role:       m.Role,
+			LastSeenAt: seen,
+		})
+	}
+	sort.SliceStable(present, func(i, j int) bool {
+		return present[i].LastSeenAt.After(present[j].LastSeenAt)
+	})
+	return present
+}
+
+func (s *listService) publishPresence(listID, userID int64, present bool) {
+	s.publish(listID, userID, models.StreamPresenceChanged, map[string]interface{}{
+		"user_id": userID,
+		"present": present,
+	})
+}
+
+// StartPresenceSweeper expires members whose heartbeats stopped, on every interval
+// until stopped
+func (s *listService) StartPresenceSweeper(interval time.Duration) func() {
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			expired := s.presence.Expire(time.Now().UTC().Add(-PresenceTTL))
+			count := 0
+			for listID, userIDs := range expired {
+				for _, userID := range userIDs {
+					s.publishPresence(listID, userID, false)
+					count++
+				}
+			}
+			if count > 0 {
+				log.Printf("presence_sweep expired=%d", count)
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryPresenceStore(t *testing.T) {
+	store := NewMemoryPresenceStore()
+	now := time.Date(2024, 11, 9, 21, 0, 0, 0, time.UTC)
+
+	assert.True(t, store.Touch(1, 10, now.Add(-time.Minute)))
+	assert.False(t, store.Touch(1, 10, now), "a second heartbeat is not a join")
+	assert.True(t, store.Touch(1, 11, now.Add(-time.Minute)))
+	assert.True(t, store.Touch(2, 10, now))
+
+	active := store.Active(1, now.Add(-PresenceTTL))
+	assert.Equal(t, map[int64]time.Time{10: now}, active)
+
+	expired := store.Expire(now.Add(-PresenceTTL))
+	assert.Equal(t, map[int64][]int64{1: {11}}, expired)
+	assert.Empty(t, store.Expire(now.Add(-PresenceTTL)))
+
+	assert.True(t, store.Leave(1, 10))
+	assert.False(t, store.Leave(1, 10))
+	assert.Empty(t, store.Active(1, time.Time{}))
+	assert.Len(t, store.Active(2, time.Time{}), 1)
+}
+
+func TestPresentFromMembers(t *testing.T) {
+	now := time.Date(2024, 11, 9, 21, 0, 0, 0, time.UTC)
+	avatar := "https://example.com/a.png"
+	members := []models.ListMember{
+		{UserID: 1, Role: models.RoleOwner, User: models.User{ID: 1, Username: "ana", AvatarURL: &avatar}},
+		{UserID: 2, Role: models.RoleParticipant, User: models.User{ID: 2, Username: "bia"}},
+		{UserID: 3, Role: models.RoleParticipant, User: models.User{ID: 3, Username: "caio"}},
+	}
+	active := map[int64]time.Time{
+		1: now.Add(-10 * time.Second),
+		3: now,
+		// No longer a member
+		4: now,
+	}
+
+	present := presentFromMembers(members, active)
+
+	if assert.Len(t, present, 2) {
+		assert.Equal(t, int64(3), present[0].UserID)
+		assert.Equal(t, int64(1), present[1].UserID)
+		assert.Equal(t, &avatar, present[1].AvatarURL)
+		assert.Equal(t, models.RoleOwner, present[1].Role)
+	}
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/daos/mocks"
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newMarkReadTestService() (ListService, *mocks.MockMovieListDAO) {
+	lists := &mocks.MockMovieListDAO{}
+	lists.On("FindByID", int64(1)).Return(&models.MovieList{ID: 1}, nil)
+	lists.On("FindMembership", int64(1), int64(2)).Return(&models.ListMember{ListID: 1, UserID: 2, Role: models.RoleParticipant}, nil)
+	lists.On("FindMembership", int64(1), int64(9)).Return(nil, gorm.ErrRecordNotFound)
+	lists.On("FindListMovieByListAndMovie", int64(1), int64(7)).Return(&models.ListMovie{ListID: 1, MovieID: 7}, nil)
+	lists.On("FindListMovieByListAndMovie", int64(1), int64(8)).Return(nil, gorm.ErrRecordNotFound)
+	lists.On("MarkRead", int64(1), mock.Anything, mock.Anything).Return(nil)
+	return NewListService(lists, nil, nil, nil, nil, nil, nil, ""), lists
+}
+
+func TestMarkRead_WholeListUsesMovieZero(t *testing.T) {
+	service, lists := newMarkReadTestService()
+
+	assert.NoError(t, service.MarkRead(1, 2, nil))
+	lists.AssertCalled(t, "MarkRead", int64(1), int64(2), int64(0))
+}
+
+func TestMarkRead_Thread(t *testing.T) {
+	service, lists := newMarkReadTestService()
+	movieID := int64(7)
+
+	assert.NoError(t, service.MarkRead(1, 2, &movieID))
+	lists.AssertCalled(t, "MarkRead", int64(1), int64(2), int64(7))
+}
+
+func TestMarkRead_MovieNotInList(t *testing.T) {
+	service, lists := newMarkReadTestService()
+	movieID := int64(8)
+
+	assert.ErrorIs(t, service.MarkRead(1, 2, &movieID), ErrMovieNotInList)
+	lists.AssertNotCalled(t, "MarkRead", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMarkRead_NotAMember(t *testing.T) {
+	service, lists := newMarkReadTestService()
+
+	assert.ErrorIs(t, service.MarkRead(1, 9, nil), ErrForbiddenMembership)
+	lists.AssertNotCalled(t, "MarkRead", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+// Removed titles stay restorable, with their comments and ratings, for this long
+const RemovalUndoWindow = 7 * 24 * time.Hour
+
+var ErrRemovalNotFound = errors.New("removal_not_found")
+
+// GetRecentlyRemoved returns the titles removed from the list that can still be restored
+func (s *listService) GetRecentlyRemoved(listID, userID int64) ([]models.ListMovie, error) {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	return s.lists.FindRemovedListMovies(listID, time.Now().UTC().Add(-RemovalUndoWindow))
+}
+
+// RestoreMovie puts a removed title back on the list with its display order, ratings,
+// watches and comments
+func (s *listService) RestoreMovie(listID, userID, movieID int64) (*models.ListMovie, error) {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	removed, err := s.lists.FindRemovedListMovies(listID, time.Now().UTC().Add(-RemovalUndoWindow))
+	if err != nil {
+		return nil, err
+	}
+	for _, lm := range removed {
+		if lm.MovieID == movieID {
+			return s.restoreRemoved(listID, userID, movieID)
+		}
+	}
+	return nil, ErrRemovalNotFound
+}
+
+// UndoLastRemoval restores the title the user removed most recently
+func (s *listService) UndoLastRemoval(listID, userID int64) (*models.ListMovie, error) {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	removed, err := s.lists.FindRemovedListMovies(listID, time.Now().UTC().Add(-RemovalUndoWindow))
+	if err != nil {
+		return nil, err
+	}
+	last := lastRemovalBy(removed, userID)
+	if last == nil {
+		return nil, ErrRemovalNotFound
+	}
+	return s.restoreRemoved(listID, userID, last.MovieID)
+}
+
+func (s *listService) restoreRemoved(listID, userID, movieID int64) (*models.ListMovie, error) {
+	listMovie, err := s.lists.RestoreListMovie(listID, movieID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRemovalNotFound
+		}
+		return nil, err
+	}
+	s.publish(listID, userID, models.StreamMovieRestored, map[string]interface{}{
+		"movie_id":      movieID,
+		"status":        listMovie.Status,
+		"display_order": listMovie.DisplayOrder,
+	})
+	return listMovie, nil
+}
+
+// checkListWriter allows the owner and participants of an existing list
+func (s *listService) checkListWriter(listID, userID int64) error {
+	if _, err := s.lists.FindByID(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrListNotFound
+		}
+		return err
+	}
+
+	membership, err := s.lists.FindMembership(listID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrForbiddenMembership
+		}
+		return err
+	}
+	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
+		return ErrForbiddenMembership
+	}
+	return nil
+}
+
+// lastRemovalBy picks the most recent removal made by the user, or nil if there is none
+func lastRemovalBy(removed []models.ListMovie, userID int64) *models.ListMovie {
+	var last *models.ListMovie
+	for i := range removed {
+		lm := &removed[i]
+		if lm.RemovedAt == nil || lm.RemovedBy == nil || *lm.RemovedBy != userID {
+			continue
+		}
+		if last == nil || lm.RemovedAt.After(*last.RemovedAt) {
+			last = lm
+		}
+	}
+	return last
+}
+
+// StartRemovalPurger deletes titles whose undo window has passed, immediately and then
+// on every interval until stopped
+func (s *listService) StartRemovalPurger(interval time.Duration) func() {
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if count, err := s.lists.PurgeRemovedListMovies(time.Now().UTC().Add(-RemovalUndoWindow)); err != nil {
+				log.Printf("removal_purge failed: %v", err)
+			} else if count > 0 {
+				log.Printf("removal_purge success titles=%d", count)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastRemovalBy(t *testing.T) {
+	alice, bob := int64(1), int64(2)
+	base := time.Date(2024, 11, 2, 18, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		t := base.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+
+	removed := []models.ListMovie{
+		{MovieID: 10, RemovedAt: at(5), RemovedBy: &bob},
+		{MovieID: 11, RemovedAt: at(1), RemovedBy: &alice},
+		{MovieID: 12, RemovedAt: at(3), RemovedBy: &alice},
+		{MovieID: 13, RemovedAt: nil, RemovedBy: &alice},
+	}
+
+	last := lastRemovalBy(removed, alice)
+	if assert.NotNil(t, last) {
+		assert.Equal(t, int64(12), last.MovieID)
+	}
+	last = lastRemovalBy(removed, bob)
+	if assert.NotNil(t, last) {
+		assert.Equal(t, int64(10), last.MovieID)
+	}
+	assert.Nil(t, lastRemovalBy(removed, 3))
+	assert.Nil(t, lastRemovalBy(nil, alice))
+}
@@ -22,29 +22,62 @@ type ListService interface {
 	JoinListByInviteCode(inviteCode string, userID int64) (*models.MovieList, models.ListMemberRole, bool, int64, error)
 	DeleteList(listID int64, userID int64) error
 	LeaveList(listID int64, userID int64) error
-	ListUserLists(userID int64, role *models.ListMemberRole, limit int, offset int) ([]models.ListMember, map[int64]int64, map[int64]int64, int64, error)
+	ListUserLists(userID int64, role *models.ListMemberRole, limit int, offset int) ([]models.ListMember, map[int64]int64, map[int64]int64, map[int64]models.UnreadCounts, int64, error)
 	AddMediaToList(ctx context.Context, listID int64, userID int64, mediaID int64, mediaType string) (*models.ListMovie, *models.Movie, error)
+	MarkMediaSeen(ctx context.Context, listID, userID, mediaID int64, mediaType string, feedback *models.RecommendationFeedback) (*models.ListMovie, error)
 	RemoveMovieFromList(listID int64, userID int64, movieID int64) (*models.Movie, error)
-	UpdateMovie(listID int64, userID int64, movieID int64, status *models.MovieStatus, rating *int, ratingProvided bool) (*models.ListMovie, *models.Movie, *models.MovieStatus, *models.ListMovieUserData, *models.ListMovieUserData, *float64, error)
+	GetRecentlyRemoved(listID, userID int64) ([]models.ListMovie, error)
+	RestoreMovie(listID, userID, movieID int64) (*models.ListMovie, error)
+	UndoLastRemoval(listID, userID int64) (*models.ListMovie, error)
+	StartRemovalPurger(interval time.Duration) (stop func())
+	SubscribeToList(listID, userID int64) (*ListSubscription, error)
+	InviteUser(listID, inviterID int64, username string) error
+	NotifyAvailabilityChange(movieID int64, region string, providers []string)
+	Heartbeat(listID, userID int64) ([]PresentMember, error)
+	LeavePresence(listID, userID int64) error
+	GetPresence(listID, userID int64) ([]PresentMember, error)
+	StartPresenceSweeper(interval time.Duration) (stop func())
+	UpdateMovie(listID int64, userID int64, movieID int64, status *models.MovieStatus, rating *int, ratingProvided bool, watched *bool) (*models.ListMovie, *models.Movie, *models.MovieStatus, *models.ListMovieUserData, *models.ListMovieUserData, *float64, error)
 	ListMovies(listID int64, userID int64, status *models.MovieStatus) ([]models.ListMovie, error)
 	SearchListMovies(listID int64, userID int64, query string, limit int, offset int) ([]models.ListMovie, int64, error)
 	ReorderMovies(listID int64, userID int64, orderMap map[int64]int) error
+	BulkUpdateMovies(listID, userID int64, op daos.BulkListMovieOperation) ([]models.BulkItemResult, error)
+	MarkRead(listID, userID int64, movieID *int64) error
+	GetActivity(listID, userID, beforeID int64, limit int) ([]models.ListEvent, int64, error)
 	// Comment methods
-	CreateComment(listID, userID, movieID int64, content string) (*models.Comment, error)
+	CreateComment(listID, userID int64, movieID *int64, parentID *int64, content string, spoiler bool) (*models.Comment, error)
 	GetComments(listID, userID, movieID int64, limit, offset int) ([]models.Comment, int64, error)
-	UpdateComment(listID, userID, commentID int64, content string) (*models.Comment, error)
-	DeleteComment(listID, userID, commentID int64) error
+	GetCommentThread(listID, userID int64, movieID *int64, commentID int64, limit, offset int) (*models.Comment, []models.Comment, int64, error)
+	UpdateComment(listID, userID, commentID int64, content string, spoiler *bool) (*models.Comment, error)
+	DeleteComment(listID, userID, commentID int64) (bool, error)
+	GetBoardComments(listID, userID int64, cursor string, limit int) ([]models.Comment, string, error)
+	GetCommentRevisions(listID, userID int64, movieID *int64, commentID int64) (*models.Comment, []models.CommentRevision, error)
+	// Moderation methods
+	ModerateComment(listID, moderatorID int64, movieID *int64, commentID int64, action models.ModerationAction, reason *string) (*models.Comment, error)
+	GetModerationLog(listID, userID int64, limit, offset int) ([]models.CommentModerationLog, int64, error)
+	// Import methods
+	ImportList(listID, userID int64, format models.ImportFormat, data []byte, defaultStatus models.MovieStatus) (*models.ListImport, error)
+	GetImport(listID, userID, importID int64) (*models.ListImport, error)
+	ExportList(listID, userID int64, format models.ExportFormat) (*ListExport, error)
 }
 
 type listService struct {
 	lists      daos.MovieListDAO
 	movies     daos.MovieDAO
+	users      daos.UserDAO
+	notifier   Notifier
+	hub        ListHub
+	webhooks   WebhookDispatcher
+	presence   PresenceStore
 	httpClient *http.Client
 	tmdbToken  string
+	// invites throttles invitations per inviter; availability dedupes provider announcements
+	invites      *slidingWindow
+	availability *slidingWindow
 }
 
-func NewListService(lists daos.MovieListDAO, movies daos.MovieDAO, tmdbToken string) ListService {
-	return &listService{lists: lists, movies: movies, httpClient: &http.Client{Timeout: 5 * time.Second}, tmdbToken: tmdbToken}
+func NewListService(lists daos.MovieListDAO, movies daos.MovieDAO, users daos.UserDAO, notifier Notifier, hub ListHub, webhooks WebhookDispatcher, presence PresenceStore, tmdbToken string) ListService {
+	return &listService{lists: lists, movies: movies, users: users, notifier: notifier, hub: hub, webhooks: webhooks, presence: presence, httpClient: &http.Client{Timeout: 5 * time.Second}, tmdbToken: tmdbToken, invites: newSlidingWindow(), availability: newSlidingWindow()}
 }
 
 func (s *listService) CreateList(name string, description *string, createdBy int64) (*models.MovieList, error) {
@@ -126,6 +159,8 @@ func (s *listService) JoinListByInviteCode(inviteCode string, userID int64) (*mo
 		if inserted {
 			alreadyMember = false
 			role = models.RoleParticipant
+			s.publish(list.ID, userID, models.StreamMemberJoined, map[string]interface{}{"user_id": userID})
+			s.notifyMemberJoined(list, userID)
 		} else {
 			m2, err2 := s.lists.FindMembership(list.ID, userID)
 			if err2 != nil {
@@ -164,6 +199,7 @@ func (s *listService) DeleteList(listID int64, userID int64) error {
 		}
 		return err
 	}
+	s.publish(listID, userID, models.StreamListDeleted, nil)
 	return nil
 }
 
@@ -191,14 +227,16 @@ func (s *listService) LeaveList(listID int64, userID int64) error {
 	if err := s.lists.RemoveMember(listID, userID); err != nil {
 		return err
 	}
+	s.publish(listID, userID, models.StreamMemberLeft, map[string]interface{}{"user_id": userID})
+	s.presence.Leave(listID, userID)
 
 	return nil
 }
 
-func (s *listService) ListUserLists(userID int64, role *models.ListMemberRole, limit int, offset int) ([]models.ListMember, map[int64]int64, map[int64]int64, int64, error) {
+func (s *listService) ListUserLists(userID int64, role *models.ListMemberRole, limit int, offset int) ([]models.ListMember, map[int64]int64, map[int64]int64, map[int64]models.UnreadCounts, int64, error) {
 	memberships, err := s.lists.FindUserMemberships(userID, role, limit, offset)
 	if err != nil {
-		return nil, nil, nil, 0, err
+		return nil, nil, nil, nil, 0, err
 	}
 
 	listIDs := make([]int64, 0, len(memberships))
@@ -208,19 +246,23 @@ func (s *listService) ListUserLists(userID int64, role *models.ListMemberRole, l
 
 	memberCounts, err := s.lists.CountMembersBatch(listIDs)
 	if err != nil {
-		return nil, nil, nil, 0, err
+		return nil, nil, nil, nil, 0, err
 	}
 	movieCounts, err := s.lists.CountMoviesBatch(listIDs)
 	if err != nil {
-		return nil, nil, nil, 0, err
+		return nil, nil, nil, nil, 0, err
+	}
+	unreadCounts, err := s.lists.CountUnreadBatch(userID, listIDs)
+	if err != nil {
+		return nil, nil, nil, nil, 0, err
 	}
 
 	total, err := s.lists.CountUserMemberships(userID, role)
 	if err != nil {
-		return nil, nil, nil, 0, err
+		return nil, nil, nil, nil, 0, err
 	}
 
-	return memberships, memberCounts, movieCounts, total, nil
+	return memberships, memberCounts, movieCounts, unreadCounts, total, nil
 }
 
 var (
@@ -241,6 +283,7 @@ type tmdbMovieResponse struct {
 	ReleaseDate      string   `json:"release_date"`
 	PosterPath       *string  `json:"poster_path"`
 	Popularity       *float64 `json:"popularity"`
+	IMDbID           string   `json:"imdb_id"`
 	Genres           []struct {
 		ID   int64  `json:"id"`
 		Name string `json:"name"`
@@ -263,13 +306,17 @@ type tmdbTVResponse struct {
 		ID   int64  `json:"id"`
 		Name string `json:"name"`
 	} `json:"genres"`
+	ExternalIDs struct {
+		IMDbID string `json:"imdb_id"`
+	} `json:"external_ids"`
 }
 
 func (s *listService) AddMediaToList(ctx context.Context, listID int64, userID int64, mediaID int64, mediaType string) (*models.ListMovie, *models.Movie, error) {
 	if mediaType != "movie" && mediaType != "tv" {
 		return nil, nil, ErrInvalidMediaType
 	}
-	if _, err := s.lists.FindByID(listID); err != nil {
+	list, err := s.lists.FindByID(listID)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil, ErrListNotFound
 		}
@@ -313,15 +360,84 @@ func (s *listService) AddMediaToList(ctx context.Context, listID int64, userID i
 	if err != nil {
 		return nil, nil, err
 	}
+	s.publish(listID, userID, models.StreamMovieAdded, map[string]interface{}{
+		"movie_id":      movie.ID,
+		"media_type":    movie.MediaType,
+		"status":        lm.Status,
+		"display_order": lm.DisplayOrder,
+	})
+	s.notifyTitleAdded(list, movie, userID)
+	s.dispatchWebhook(WebhookEvent{Type: models.WebhookMovieAdded, ListID: listID, ActorID: userID, MovieID: movie.ID})
 	return lm, movie, nil
 }
 
+// MarkMediaSeen stores the member's feedback, adds the title to the list if needed and
+// marks it watched by them in one transaction, so a failure leaves none of it behind
+func (s *listService) MarkMediaSeen(ctx context.Context, listID, userID, mediaID int64, mediaType string, feedback *models.RecommendationFeedback) (*models.ListMovie, error) {
+	if mediaType != "movie" && mediaType != "tv" {
+		return nil, ErrInvalidMediaType
+	}
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	list, err := s.lists.FindByID(listID)
+	if err != nil {
+		return nil, err
+	}
+
+	movie, err := s.movies.FindByIDAndType(mediaID, mediaType)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		movie, err = s.fetchAndStoreFromTMDB(ctx, mediaID, mediaType)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMediaNotFound
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	lm, created, previous, err := s.lists.AddSeenMovie(listID, movie.ID, userID, feedback)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		s.publish(listID, userID, models.StreamMovieAdded, map[string]interface{}{
+			"movie_id":      movie.ID,
+			"media_type":    movie.MediaType,
+			"status":        lm.Status,
+			"display_order": lm.DisplayOrder,
+		})
+		s.notifyTitleAdded(list, movie, userID)
+		s.dispatchWebhook(WebhookEvent{Type: models.WebhookMovieAdded, ListID: listID, ActorID: userID, MovieID: movie.ID})
+	} else {
+		watches, err := s.lists.FindMovieWatches(listID, movie.ID)
+		if err != nil {
+			return nil, err
+		}
+		s.publish(listID, userID, models.StreamMovieUpdated, map[string]interface{}{
+			"movie_id":   movie.ID,
+			"status":     lm.Status,
+			"watched_by": WatchedBy(watches),
+		})
+		if *previous != lm.Status {
+			s.dispatchWebhook(WebhookEvent{
+				Type:           models.WebhookStatusChanged,
+				ListID:         listID,
+				ActorID:        userID,
+				MovieID:        movie.ID,
+				PreviousStatus: previous,
+			})
+		}
+	}
+	return lm, nil
+}
+
 func (s *listService) fetchAndStoreFromTMDB(ctx context.Context, id int64, mediaType string) (*models.Movie, error) {
 	var url string
 	if mediaType == "movie" {
 		url = fmt.Sprintf("https://api.themoviedb.org/3/movie/%d?language=pt-BR", id)
 	} else {
-		url = fmt.Sprintf("https://api.themoviedb.org/3/tv/%d?language=pt-BR", id)
+		url = fmt.Sprintf("https://api.themoviedb.org/3/tv/%d?language=pt-BR&append_to_response=external_ids", id)
 	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -367,6 +483,7 @@ func (s *listService) fetchAndStoreFromTMDB(ctx context.Context, id int64, media
 			ReleaseDate:   release,
 			PosterPath:    m.PosterPath,
 			Popularity:    m.Popularity,
+			IMDbID:        optionalString(m.IMDbID),
 		}
 		genres := make([]models.Genre, 0, len(m.Genres))
 		for _, g := range m.Genres {
@@ -401,6 +518,7 @@ func (s *listService) fetchAndStoreFromTMDB(ctx context.Context, id int64, media
 		SeasonsCount:  tv.NumberOfSeasons,
 		EpisodesCount: tv.NumberOfEpisodes,
 		SeriesStatus:  tv.Status,
+		IMDbID:        optionalString(tv.ExternalIDs.IMDbID),
 	}
 	genres := make([]models.Genre, 0, len(tv.Genres))
 	for _, g := range tv.Genres {
@@ -484,14 +602,15 @@ func (s *listService) RemoveMovieFromList(listID int64, userID int64, movieID in
 		return nil, err
 	}
 
-	if err := s.lists.RemoveMovieFromList(listID, movieID); err != nil {
+	if err := s.lists.RemoveMovieFromList(listID, movieID, userID); err != nil {
 		return nil, err
 	}
+	s.publish(listID, userID, models.StreamMovieRemoved, map[string]interface{}{"movie_id": movieID})
 
 	return movie, nil
 }
 
-func (s *listService) UpdateMovie(listID int64, userID int64, movieID int64, status *models.MovieStatus, rating *int, ratingProvided bool) (*models.ListMovie, *models.Movie, *models.MovieStatus, *models.ListMovieUserData, *models.ListMovieUserData, *float64, error) {
+func (s *listService) UpdateMovie(listID int64, userID int64, movieID int64, status *models.MovieStatus, rating *int, ratingProvided bool, watched *bool) (*models.ListMovie, *models.Movie, *models.MovieStatus, *models.ListMovieUserData, *models.ListMovieUserData, *float64, error) {
 	_, err := s.lists.FindByID(listID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -521,6 +640,7 @@ func (s *listService) UpdateMovie(listID int64, userID int64, movieID int64, sta
 	}
 
 	oldStatus := &existingListMovie.Status
+	previousStatus := existingListMovie.Status
 
 	var oldEntry *models.ListMovieUserData
 	if ratingProvided {
@@ -533,7 +653,7 @@ func (s *listService) UpdateMovie(listID int64, userID int64, movieID int64, sta
 
 	var updatedListMovie *models.ListMovie
 	if status != nil {
-		updatedListMovie, err = s.lists.UpdateMovie(listID, movieID, status)
+		updatedListMovie, err = s.lists.UpdateMovie(listID, movieID, status, userID)
 		if err != nil {
 			return nil, nil, nil, nil, nil, nil, err
 		}
@@ -541,6 +661,24 @@ func (s *listService) UpdateMovie(listID int64, userID int64, movieID int64, sta
 		updatedListMovie = existingListMovie
 	}
 
+	// Moving the title to watched marks it as seen by whoever did it, unless the
+	// member said otherwise explicitly. Other statuses never clear a watch; only an
+	// explicit watched:false does.
+	if watched == nil && status != nil && *status == models.StatusWatched {
+		seen := true
+		watched = &seen
+	}
+	if watched != nil {
+		if err := s.lists.SetMovieWatched(listID, movieID, userID, *watched); err != nil {
+			return nil, nil, nil, nil, nil, nil, err
+		}
+	}
+	watches, err := s.lists.FindMovieWatches(listID, movieID)
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, err
+	}
+	updatedListMovie.Watches = watches
+
 	var newEntry *models.ListMovieUserData
 	if ratingProvided {
 		upserted, upsertErr := s.lists.UpsertMovieUserData(listID, movieID, userID, rating, ratingProvided)
@@ -569,6 +707,25 @@ func (s *listService) UpdateMovie(listID int64, userID int64, movieID int64, sta
 		return nil, nil, nil, nil, nil, nil, err
 	}
 
+	changes := map[string]interface{}{"movie_id": movieID, "status": updatedListMovie.Status}
+	if watched != nil {
+		changes["watched_by"] = WatchedBy(watches)
+	}
+	if ratingProvided {
+		changes["rating"] = rating
+		changes["average_rating"] = averageRating
+	}
+	s.publish(listID, userID, models.StreamMovieUpdated, changes)
+	if updatedListMovie.Status != previousStatus {
+		s.dispatchWebhook(WebhookEvent{
+			Type:           models.WebhookStatusChanged,
+			ListID:         listID,
+			ActorID:        userID,
+			MovieID:        movieID,
+			PreviousStatus: &previousStatus,
+		})
+	}
+
 	return updatedListMovie, movie, oldStatus, oldEntry, newEntry, averageRating, nil
 }
 
@@ -595,9 +752,49 @@ func (s *listService) ListMovies(listID int64, userID int64, status *models.Movi
 	if err != nil {
 		return nil, err
 	}
+
+	unread, err := s.lists.CountUnreadCommentsByMovie(listID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].UnreadComments = unread[items[i].MovieID]
+	}
 	return items, nil
 }
 
+// MarkRead marks the whole list as read, or only a movie's comment thread when movieID is set
+func (s *listService) MarkRead(listID, userID int64, movieID *int64) error {
+	if _, err := s.lists.FindByID(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrListNotFound
+		}
+		return err
+	}
+
+	membership, err := s.lists.FindMembership(listID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrForbiddenMembership
+		}
+		return err
+	}
+	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
+		return ErrForbiddenMembership
+	}
+
+	if movieID == nil {
+		return s.lists.MarkRead(listID, userID, 0)
+	}
+	if _, err := s.lists.FindListMovieByListAndMovie(listID, *movieID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMovieNotInList
+		}
+		return err
+	}
+	return s.lists.MarkRead(listID, userID, *movieID)
+}
+
 func (s *listService) SearchListMovies(listID int64, userID int64, query string, limit int, offset int) ([]models.ListMovie, int64, error) {
 	if _, err := s.lists.FindByID(listID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -654,7 +851,11 @@ func (s *listService) ReorderMovies(listID int64, userID int64, orderMap map[int
 		return ErrForbiddenMembership
 	}
 
-	return s.lists.UpdateMovieOrders(listID, orderMap)
+	if err := s.lists.UpdateMovieOrders(listID, orderMap, userID); err != nil {
+		return err
+	}
+	s.publish(listID, userID, models.StreamMoviesReordered, map[string]interface{}{"orders": streamOrders(orderMap)})
+	return nil
 }
 
 var (
@@ -662,9 +863,10 @@ var (
 	ErrCommentNotOwned = errors.New("comment_not_owned")
 	ErrCommentEmpty    = errors.New("comment_empty")
 	ErrCommentTooLong  = errors.New("comment_too_long")
+	ErrParentNotFound  = errors.New("parent_comment_not_found")
 )
 
-func (s *listService) CreateComment(listID, userID, movieID int64, content string) (*models.Comment, error) {
+func (s *listService) CreateComment(listID, userID int64, movieID *int64, parentID *int64, content string, spoiler bool) (*models.Comment, error) {
 	// Validate content
 	content = strings.TrimSpace(content)
 	if content == "" {
@@ -673,6 +875,9 @@ func (s *listService) CreateComment(listID, userID, movieID int64, content strin
 	if len(content) > 2000 {
 		return nil, ErrCommentTooLong
 	}
+	if err := validateCommentFormat(content); err != nil {
+		return nil, err
+	}
 
 	// Check list exists
 	if _, err := s.lists.FindByID(listID); err != nil {
@@ -694,15 +899,54 @@ func (s *listService) CreateComment(listID, userID, movieID int64, content strin
 		return nil, ErrForbiddenMembership
 	}
 
-	// Check movie is in list
-	if _, err := s.lists.FindListMovieByListAndMovie(listID, movieID); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrMovieNotInList
+	// Check movie is in list; comments without a movie go to the list's board
+	if movieID != nil {
+		if _, err := s.lists.FindListMovieByListAndMovie(listID, *movieID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrMovieNotInList
+			}
+			return nil, err
 		}
+	}
+
+	// Replies only go one level deep: replying to a reply joins the parent's thread
+	if parentID != nil {
+		parent, err := s.lists.FindCommentByID(*parentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrParentNotFound
+			}
+			return nil, err
+		}
+		if !parent.InScope(listID, movieID) || parent.IsDeleted() || parent.IsHidden() {
+			return nil, ErrParentNotFound
+		}
+		if parent.ParentID != nil {
+			parentID = parent.ParentID
+		}
+	}
+
+	// Resolve @mentions against the list's members
+	members, err := s.lists.FindMembersWithUser(listID)
+	if err != nil {
 		return nil, err
 	}
+	mentions := parseMentions(content, usersOf(members))
 
-	return s.lists.CreateComment(listID, movieID, userID, content)
+	comment, err := s.lists.CreateComment(listID, movieID, userID, parentID, content, spoiler, mentions)
+	if err != nil {
+		return nil, err
+	}
+	mentioned := mentionRecipients(mentions, userID, nil)
+	s.notifyMentions(comment, mentioned)
+	s.notifyComment(comment, members, mentioned)
+	s.publishComment(models.StreamCommentCreated, comment, userID)
+	event := WebhookEvent{Type: models.WebhookCommentCreated, ListID: listID, ActorID: userID, Comment: comment}
+	if comment.MovieID != nil {
+		event.MovieID = *comment.MovieID
+	}
+	s.dispatchWebhook(event)
+	return comment, nil
 }
 
 func (s *listService) GetComments(listID, userID, movieID int64, limit, offset int) ([]models.Comment, int64, error) {
@@ -745,10 +989,104 @@ func (s *listService) GetComments(listID, userID, movieID int64, limit, offset i
 		offset = 0
 	}
 
-	return s.lists.FindComments(listID, movieID, limit, offset)
+	comments, total, err := s.lists.FindComments(listID, movieID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Protect readers who have not watched the title yet
+	watches, err := s.lists.FindMovieWatches(listID, movieID)
+	if err != nil {
+		return nil, 0, err
+	}
+	markAutoSpoilers(comments, watches, userID)
+	redactHiddenComments(comments, userID, membership.Role)
+	return comments, total, nil
+}
+
+func (s *listService) GetCommentThread(listID, userID int64, movieID *int64, commentID int64, limit, offset int) (*models.Comment, []models.Comment, int64, error) {
+	// Check list exists
+	if _, err := s.lists.FindByID(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, 0, ErrListNotFound
+		}
+		return nil, nil, 0, err
+	}
+
+	// Check user is a member
+	membership, err := s.lists.FindMembership(listID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, 0, ErrForbiddenMembership
+		}
+		return nil, nil, 0, err
+	}
+	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
+		return nil, nil, 0, ErrForbiddenMembership
+	}
+
+	comment, err := s.lists.FindCommentByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, 0, ErrCommentNotFound
+		}
+		return nil, nil, 0, err
+	}
+	if !comment.InScope(listID, movieID) {
+		return nil, nil, 0, ErrCommentNotFound
+	}
+	if comment.ParentID != nil && comment.IsDeleted() {
+		return nil, nil, 0, ErrCommentNotFound
+	}
+
+	// A reply's thread is the one it belongs to
+	if comment.ParentID != nil {
+		comment, err = s.lists.FindCommentByID(*comment.ParentID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, 0, ErrCommentNotFound
+			}
+			return nil, nil, 0, err
+		}
+	}
+
+	// Sanitize pagination
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	replies, total, err := s.lists.FindCommentReplies(comment.ID, limit, offset)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	comment.ReplyCount = total
+	// A deleted comment only stays reachable while it has replies
+	if comment.IsDeleted() && total == 0 {
+		return nil, nil, 0, ErrCommentNotFound
+	}
+
+	// Protect readers who have not watched the title yet
+	thread := []models.Comment{*comment}
+	if movieID != nil {
+		watches, err := s.lists.FindMovieWatches(listID, *movieID)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		markAutoSpoilers(thread, watches, userID)
+		markAutoSpoilers(replies, watches, userID)
+	}
+	redactHiddenComments(thread, userID, membership.Role)
+	redactHiddenComments(replies, userID, membership.Role)
+	return &thread[0], replies, total, nil
 }
 
-func (s *listService) UpdateComment(listID, userID, commentID int64, content string) (*models.Comment, error) {
+func (s *listService) UpdateComment(listID, userID, commentID int64, content string, spoiler *bool) (*models.Comment, error) {
 	// Validate content
 	content = strings.TrimSpace(content)
 	if content == "" {
@@ -757,6 +1095,9 @@ func (s *listService) UpdateComment(listID, userID, commentID int64, content str
 	if len(content) > 2000 {
 		return nil, ErrCommentTooLong
 	}
+	if err := validateCommentFormat(content); err != nil {
+		return nil, err
+	}
 
 	// Check list exists
 	if _, err := s.lists.FindByID(listID); err != nil {
@@ -787,8 +1128,8 @@ func (s *listService) UpdateComment(listID, userID, commentID int64, content str
 		return nil, err
 	}
 
-	// Check comment belongs to this list
-	if comment.ListID != listID {
+	// Check comment belongs to this list and was not deleted
+	if comment.ListID != listID || comment.IsDeleted() {
 		return nil, ErrCommentNotFound
 	}
 
@@ -797,48 +1138,85 @@ func (s *listService) UpdateComment(listID, userID, commentID int64, content str
 		return nil, ErrCommentNotOwned
 	}
 
-	return s.lists.UpdateComment(commentID, content)
+	// Hidden comments stay as the owner left them until they are unhidden
+	if comment.IsHidden() {
+		return nil, ErrCommentHidden
+	}
+
+	// Resolve @mentions again, notifying only newly mentioned members
+	members, err := s.memberUsers(listID)
+	if err != nil {
+		return nil, err
+	}
+	mentions := parseMentions(content, members)
+
+	updated, err := s.lists.UpdateComment(commentID, content, spoiler, mentions)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyMentions(updated, mentionRecipients(mentions, userID, comment.Mentions))
+	s.publishComment(models.StreamCommentUpdated, updated, userID)
+	return updated, nil
 }
 
-func (s *listService) DeleteComment(listID, userID, commentID int64) error {
+func (s *listService) DeleteComment(listID, userID, commentID int64) (bool, error) {
 	// Check list exists
 	if _, err := s.lists.FindByID(listID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrListNotFound
+			return false, ErrListNotFound
 		}
-		return err
+		return false, err
 	}
 
 	// Check user is a member
 	membership, err := s.lists.FindMembership(listID, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrForbiddenMembership
+			return false, ErrForbiddenMembership
 		}
-		return err
+		return false, err
 	}
 	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
-		return ErrForbiddenMembership
+		return false, ErrForbiddenMembership
 	}
 
 	// Check comment exists
 	comment, err := s.lists.FindCommentByID(commentID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrCommentNotFound
+			return false, ErrCommentNotFound
 		}
-		return err
+		return false, err
 	}
 
-	// Check comment belongs to this list
-	if comment.ListID != listID {
-		return ErrCommentNotFound
+	// Check comment belongs to this list and was not deleted already
+	if comment.ListID != listID || comment.IsDeleted() {
+		return false, ErrCommentNotFound
 	}
 
-	// Check user owns the comment
+	// The list owner may delete anyone's comment, which is recorded as moderation
 	if comment.UserID != userID {
-		return ErrCommentNotOwned
+		if membership.Role != models.RoleOwner {
+			return false, ErrCommentNotOwned
+		}
+		moderated, err := s.lists.ModerateComment(&models.CommentModerationLog{
+			ListID:      listID,
+			CommentID:   &comment.ID,
+			ModeratorID: userID,
+			AuthorID:    comment.UserID,
+			Action:      models.ModerationDelete,
+		})
+		if err != nil {
+			return false, err
+		}
+		s.publishComment(models.StreamCommentDeleted, moderated, userID)
+		return moderated.ReplyCount > 0, nil
 	}
 
-	return s.lists.DeleteComment(commentID)
+	placeholder, err := s.lists.DeleteComment(commentID, userID)
+	if err != nil {
+		return false, err
+	}
+	s.publishComment(models.StreamCommentDeleted, comment, userID)
+	return placeholder, nil
 }
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/8bury/list2gether/models"
+)
+
+// SubscribeToList opens a live event stream of the list for one of its members. The
+// caller must close the subscription when the client goes away.
+func (s *listService) SubscribeToList(listID, userID int64) (*ListSubscription, error) {
+	if err := s.checkListWriter(listID, userID); err != nil {
+		return nil, err
+	}
+	return s.hub.Subscribe(listID, userID), nil
+}
+
+// publish pushes a change to the members streaming the list
+func (s *listService) publish(listID, actorID int64, eventType models.ListStreamEventType, data map[string]interface{}) {
+	if s.hub == nil {
+		return
+	}
+	s.hub.Publish(models.ListStreamEvent{Type: eventType, ListID: listID, ActorID: actorID, Data: data})
+}
+
+// dispatchWebhook hands a change to the list's webhooks
+func (s *listService) dispatchWebhook(event WebhookEvent) {
+	if s.webhooks == nil {
+		return
+	}
+	s.webhooks.Dispatch(event)
+}
+
+func (s *listService) publishComment(eventType models.ListStreamEventType, comment *models.Comment, actorID int64) {
+	s.publish(comment.ListID, actorID, eventType, map[string]interface{}{
+		"comment_id": comment.ID,
+		"movie_id":   comment.MovieID,
+		"parent_id":  comment.ParentID,
+	})
+}
+
+// streamOrders keys display orders by movie id, as JSON objects need string keys
+func streamOrders(orderMap map[int64]int) map[string]int {
+	orders := make(map[string]int, len(orderMap))
+	for movieID, order := range orderMap {
+		orders[strconv.FormatInt(movieID, 10)] = order
+	}
+	return orders
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildEmail(t *testing.T) {
+	date := time.Date(2024, 11, 2, 18, 0, 0, 0, time.UTC)
+
+	raw, err := buildEmail("list2gether <no-reply@example.com>", EmailMessage{
+		To:      "ana@example.com",
+		Subject: "Resumo semanal de Sexta",
+		Text:    "Olá\nmundo",
+		Headers: map[string]string{"List-Unsubscribe": "<https://api.example.com/u>\r\nBcc: evil@example.com"},
+	}, date)
+	assert.NoError(t, err)
+	email := string(raw)
+	assert.Contains(t, email, "To: ana@example.com\r\n")
+	assert.Contains(t, email, "Content-Type: text/plain; charset=utf-8\r\n")
+	assert.Contains(t, email, "Ol=C3=A1\r\nmundo")
+	// A header value cannot start another header
+	assert.NotContains(t, email, "\r\nBcc:")
+
+	raw, err = buildEmail("no-reply@example.com", EmailMessage{To: "ana@example.com", Subject: "Oi", Text: "texto", HTML: "<p>html</p>"}, date)
+	assert.NoError(t, err)
+	email = string(raw)
+	assert.Contains(t, email, "multipart/alternative")
+	assert.Equal(t, 3, strings.Count(email, "--"+boundaryOf(email)))
+
+	_, err = buildEmail("no-reply@example.com", EmailMessage{To: "ana@example.com\r\nBcc: evil@example.com"}, date)
+	assert.Error(t, err)
+}
+
+func TestEnvelopeAddress(t *testing.T) {
+	assert.Equal(t, "no-reply@example.com", envelopeAddress("list2gether <no-reply@example.com>"))
+	assert.Equal(t, "no-reply@example.com", envelopeAddress("no-reply@example.com"))
+}
+
+func boundaryOf(email string) string {
+	_, rest, _ := strings.Cut(email, `boundary="`)
+	boundary, _, _ := strings.Cut(rest, `"`)
+	return boundary
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+)
+
+var (
+	ErrNotificationNotFound       = errors.New("notification_not_found")
+	ErrInvalidNotificationType    = errors.New("invalid_notification_type")
+	ErrInvalidNotificationChannel = errors.New("invalid_notification_channel")
+)
+
+type NotificationService interface {
+	ListNotifications(userID int64, unreadOnly bool, limit, offset int) ([]models.Notification, int64, int64, error)
+	MarkRead(userID, notificationID int64) error
+	MarkAllRead(userID int64) (int64, error)
+	GetPreferences(userID int64, channel models.NotificationChannel) (map[models.NotificationType]bool, error)
+	UpdatePreferences(userID int64, channel models.NotificationChannel, preferences map[models.NotificationType]bool) (map[models.NotificationType]bool, error)
+	CheckUnsubscribe(token string) (models.NotificationType, error)
+	Unsubscribe(token string) (models.NotificationType, error)
+}
+
+type notificationService struct {
+	notifications daos.NotificationDAO
+	tokens        *UnsubscribeTokens
+}
+
+func NewNotificationService(notifications daos.NotificationDAO, tokens *UnsubscribeTokens) NotificationService {
+	return &notificationService{notifications: notifications, tokens: tokens}
+}
+
+// ListNotifications returns a page of the user's notifications, newest first, with the
+// total matching and the number still unread
+func (s *notificationService) ListNotifications(userID int64, unreadOnly bool, limit, offset int) ([]models.Notification, int64, int64, error) {
+	// Sanitize pagination
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	notifications, total, err := s.notifications.FindByUser(userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	unread, err := s.notifications.CountUnread(userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return notifications, total, unread, nil
+}
+
+func (s *notificationService) MarkRead(userID, notificationID int64) error {
+	found, err := s.notifications.MarkRead(userID, notificationID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(userID int64) (int64, error) {
+	return s.notifications.MarkAllRead(userID)
+}
+
+// GetPreferences returns whether each notification type is delivered on the channel
+func (s *notificationService) GetPreferences(userID int64, channel models.NotificationChannel) (map[models.NotificationType]bool, error) {
+	if !channel.IsValid() {
+		return nil, ErrInvalidNotificationChannel
+	}
+	stored, err := s.notifications.FindPreferences(userID, channel)
+	if err != nil {
+		return nil, err
+	}
+	return mergePreferences(channel, stored), nil
+}
+
+// UpdatePreferences turns the given types on or off; types left out keep their setting
+func (s *notificationService) UpdatePreferences(userID int64, channel models.NotificationChannel, preferences map[models.NotificationType]bool) (map[models.NotificationType]bool, error) {
+	if !channel.IsValid() {
+		return nil, ErrInvalidNotificationChannel
+	}
+	rows := make([]models.NotificationPreference, 0, len(preferences))
+	for notificationType, enabled := range preferences {
+		if !channel.Delivers(notificationType) {
+			return nil, ErrInvalidNotificationType
+		}
+		rows = append(rows, models.NotificationPreference{
+			UserID:  userID,
+			Channel: channel,
+			Type:    notificationType,
+			Enabled: enabled,
+		})
+	}
+	if err := s.notifications.UpsertPreferences(rows); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(userID, channel)
+}
+
+// CheckUnsubscribe reports the email type an unsubscribe link turns off, without
+// changing anything
+func (s *notificationService) CheckUnsubscribe(token string) (models.NotificationType, error) {
+	_, notificationType, err := s.tokens.Verify(token)
+	return notificationType, err
+}
+
+// Unsubscribe turns off the email type an unsubscribe link was signed for
+func (s *notificationService) Unsubscribe(token string) (models.NotificationType, error) {
+	userID, notificationType, err := s.tokens.Verify(token)
+	if err != nil {
+		return "", err
+	}
+	if err := s.notifications.UpsertPreferences([]models.NotificationPreference{{
+		UserID:  userID,
+		Channel: models.NotificationChannelEmail,
+		Type:    notificationType,
+		Enabled: false,
+	}}); err != nil {
+		return "", err
+	}
+	return notificationType, nil
+}
+
+// mergePreferences fills in every type of the channel, enabled unless a stored row says otherwise
+func mergePreferences(channel models.NotificationChannel, stored []models.NotificationPreference) map[models.NotificationType]bool {
+	types := channel.Types()
+	preferences := make(map[models.NotificationType]bool, len(types))
+	for _, notificationType := range types {
+		preferences[notificationType] = true
+	}
+	for _, p := range stored {
+		if channel.Delivers(p.Type) {
+			preferences[p.Type] = p.Enabled
+		}
+	}
+	return preferences
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+)
+
+// Notifier delivers notifications to their recipients. Producers emit every notification
+// through it so further channels (email, push) can be added behind it. Delivery
+// failures are logged by the channel, never returned: the change that caused the
+// notification has already been saved.
+type Notifier interface {
+	Notify(notifications []models.Notification)
+}
+
+type multiNotifier []Notifier
+
+// NewMultiNotifier hands every notification to each of the given channels
+func NewMultiNotifier(notifiers ...Notifier) Notifier {
+	return multiNotifier(notifiers)
+}
+
+func (m multiNotifier) Notify(notifications []models.Notification) {
+	for _, n := range m {
+		n.Notify(notifications)
+	}
+}
+
+type inAppNotifier struct {
+	notifications daos.NotificationDAO
+}
+
+// NewInAppNotifier stores notifications for the in-app notification center
+func NewInAppNotifier(notifications daos.NotificationDAO) Notifier {
+	return &inAppNotifier{notifications: notifications}
+}
+
+func (n *inAppNotifier) Notify(notifications []models.Notification) {
+	deliver, err := filterOptedOut(n.notifications, notifications, models.NotificationChannelInApp)
+	if err != nil {
+		log.Printf("in_app_notification failed: %v", err)
+		return
+	}
+	if err := n.notifications.CreateBatch(deliver); err != nil {
+		log.Printf("in_app_notification failed: %v", err)
+	}
+}
+
+// filterOptedOut drops the notifications whose recipients turned their type off for the channel
+func filterOptedOut(dao daos.NotificationDAO, notifications []models.Notification, channel models.NotificationChannel) ([]models.Notification, error) {
+	recipients := make(map[models.NotificationType][]int64)
+	for _, n := range notifications {
+		recipients[n.Type] = append(recipients[n.Type], n.UserID)
+	}
+	optedOut := make(map[models.NotificationType]map[int64]bool)
+	for notificationType, userIDs := range recipients {
+		users, err := dao.FindOptedOutUsers(userIDs, notificationType, channel)
+		if err != nil {
+			return nil, err
+		}
+		optedOut[notificationType] = make(map[int64]bool, len(users))
+		for _, userID := range users {
+			optedOut[notificationType][userID] = true
+		}
+	}
+
+	deliver := make([]models.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		if !optedOut[n.Type][n.UserID] {
+			deliver = append(deliver, n)
+		}
+	}
+	return deliver, nil
+}
+
+// buildNotifications addresses the same payload to every recipient
+func buildNotifications(notificationType models.NotificationType, listID, actorID *int64, payload interface{}, recipients []int64) ([]models.Notification, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	notifications := make([]models.Notification, 0, len(recipients))
+	for _, userID := range recipients {
+		notifications = append(notifications, models.Notification{
+			UserID:  userID,
+			Type:    notificationType,
+			ListID:  listID,
+			ActorID: actorID,
+			Payload: string(encoded),
+		})
+	}
+	return notifications, nil
+}
+
+// notify emits a notification of the list to the recipients
+func (s *listService) notify(notificationType models.NotificationType, listID, actorID int64, recipients []int64, payload interface{}) {
+	if len(recipients) == 0 || s.notifier == nil {
+		return
+	}
+	notifications, err := buildNotifications(notificationType, &listID, &actorID, payload, recipients)
+	if err != nil {
+		log.Printf("%s_notification failed list=%d: %v", notificationType, listID, err)
+		return
+	}
+	s.notifier.Notify(notifications)
+}
+
+// otherMembers returns the list's members except the excluded users
+func otherMembers(members []models.ListMember, exclude ...int64) []int64 {
+	skip := make(map[int64]bool, len(exclude))
+	for _, userID := range exclude {
+		skip[userID] = true
+	}
+	userIDs := make([]int64, 0, len(members))
+	for _, m := range members {
+		if !skip[m.UserID] {
+			userIDs = append(userIDs, m.UserID)
+		}
+	}
+	return userIDs
+}
@@ -0,0 +1,348 @@
+package services
+
+import (
+	"crypto/ecdh"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+)
+
+var (
+	ErrPushNotConfigured        = errors.New("push_not_configured")
+	ErrInvalidPushSubscription  = errors.New("invalid_push_subscription")
+	ErrPushSubscriptionNotFound = errors.New("push_subscription_not_found")
+)
+
+// PushSubscriptionInput is the subscription a browser returns from pushManager.subscribe
+type PushSubscriptionInput struct {
+	Endpoint   string
+	P256dh     string
+	Auth       string
+	DeviceName string
+	UserAgent  string
+}
+
+// PushMessage is the JSON payload the service worker receives and displays
+type PushMessage struct {
+	Type   models.NotificationType `json:"type"`
+	Title  string                  `json:"title"`
+	Body   string                  `json:"body"`
+	URL    string                  `json:"url"`
+	ListID *int64                  `json:"list_id,omitempty"`
+	// Tag lets the device replace an older alert about the same thing
+	Tag string `json:"tag,omitempty"`
+}
+
+type PushService interface {
+	PublicKey() (string, error)
+	Subscribe(userID int64, input PushSubscriptionInput) (*models.PushSubscription, error)
+	ListSubscriptions(userID int64) ([]models.PushSubscription, error)
+	Unsubscribe(userID, subscriptionID int64) error
+	SendTest(userID int64) (int, error)
+}
+
+type pushService struct {
+	subscriptions daos.PushSubscriptionDAO
+	users         daos.UserDAO
+	keys          *VAPIDKeys
+	sender        PushSender
+	// allowLocalEndpoints accepts http endpoints on loopback hosts, for the mock push service
+	allowLocalEndpoints bool
+}
+
+// NewPushService manages the user's push subscriptions. With nil keys push is not
+// configured and every operation but listing and removing fails with ErrPushNotConfigured.
+func NewPushService(subscriptions daos.PushSubscriptionDAO, users daos.UserDAO, keys *VAPIDKeys, sender PushSender, allowLocalEndpoints bool) PushService {
+	return &pushService{
+		subscriptions:       subscriptions,
+		users:               users,
+		keys:                keys,
+		sender:              sender,
+		allowLocalEndpoints: allowLocalEndpoints,
+	}
+}
+
+func (s *pushService) PublicKey() (string, error) {
+	if s.keys == nil {
+		return "", ErrPushNotConfigured
+	}
+	return s.keys.PublicKey, nil
+}
+
+func (s *pushService) Subscribe(userID int64, input PushSubscriptionInput) (*models.PushSubscription, error) {
+	if s.keys == nil {
+		return nil, ErrPushNotConfigured
+	}
+	if err := validatePushSubscription(input, s.allowLocalEndpoints); err != nil {
+		return nil, err
+	}
+	subscription := &models.PushSubscription{
+		UserID:       userID,
+		Endpoint:     input.Endpoint,
+		EndpointHash: hashPushEndpoint(input.Endpoint),
+		P256dh:       input.P256dh,
+		Auth:         input.Auth,
+		DeviceName:   truncateRunes(strings.TrimSpace(input.DeviceName), 100),
+		UserAgent:    truncateRunes(input.UserAgent, 255),
+	}
+	if err := s.subscriptions.Upsert(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (s *pushService) ListSubscriptions(userID int64) ([]models.PushSubscription, error) {
+	return s.subscriptions.FindByUser(userID)
+}
+
+func (s *pushService) Unsubscribe(userID, subscriptionID int64) error {
+	found, err := s.subscriptions.DeleteForUser(userID, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrPushSubscriptionNotFound
+	}
+	return nil
+}
+
+// SendTest pushes a confirmation to each of the user's devices and returns how many
+// accepted it
+func (s *pushService) SendTest(userID int64) (int, error) {
+	if s.keys == nil {
+		return 0, ErrPushNotConfigured
+	}
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return 0, err
+	}
+	language := user.PreferredLanguage()
+	subscriptions, err := s.subscriptions.FindByUser(userID)
+	if err != nil {
+		return 0, err
+	}
+	payload, err := json.Marshal(PushMessage{
+		Type:  "test",
+		Title: translate(language, "push.test.title"),
+		Body:  translate(language, "push.test.body"),
+		URL:   "/home",
+	})
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for _, subscription := range subscriptions {
+		if deliverPush(s.subscriptions, s.sender, subscription, payload) == nil {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+type pushNotifier struct {
+	subscriptions daos.PushSubscriptionDAO
+	users         daos.UserDAO
+	notifications daos.NotificationDAO
+	sender        PushSender
+}
+
+// NewPushNotifier pushes the notification types the push channel delivers to every
+// device of the recipient
+func NewPushNotifier(subscriptions daos.PushSubscriptionDAO, users daos.UserDAO, notifications daos.NotificationDAO, sender PushSender) Notifier {
+	return &pushNotifier{
+		subscriptions: subscriptions,
+		users:         users,
+		notifications: notifications,
+		sender:        sender,
+	}
+}
+
+func (n *pushNotifier) Notify(notifications []models.Notification) {
+	pushed := make([]models.Notification, 0, len(notifications))
+	for _, notification := range notifications {
+		if models.NotificationChannelPush.Delivers(notification.Type) {
+			pushed = append(pushed, notification)
+		}
+	}
+	if len(pushed) == 0 {
+		return
+	}
+	// Push services can be slow; the request that caused the notification does not wait
+	go n.send(pushed)
+}
+
+func (n *pushNotifier) send(notifications []models.Notification) {
+	deliver, err := filterOptedOut(n.notifications, notifications, models.NotificationChannelPush)
+	if err != nil {
+		log.Printf("push_notification failed: %v", err)
+		return
+	}
+	userIDs := make([]int64, 0, len(deliver))
+	for _, notification := range deliver {
+		userIDs = append(userIDs, notification.UserID)
+	}
+	subscriptions, err := n.subscriptions.FindByUsers(userIDs)
+	if err != nil {
+		log.Printf("push_notification failed: %v", err)
+		return
+	}
+	devices := make(map[int64][]models.PushSubscription)
+	for _, subscription := range subscriptions {
+		devices[subscription.UserID] = append(devices[subscription.UserID], subscription)
+	}
+
+	users := make(map[int64]*models.User)
+	findUser := func(userID int64) *models.User {
+		if user, ok := users[userID]; ok {
+			return user
+		}
+		user, err := n.users.FindByID(userID)
+		if err != nil {
+			user = nil
+		}
+		users[userID] = user
+		return user
+	}
+
+	for _, notification := range deliver {
+		if len(devices[notification.UserID]) == 0 {
+			continue
+		}
+		language := models.DefaultLanguage
+		if recipient := findUser(notification.UserID); recipient != nil {
+			language = recipient.PreferredLanguage()
+		}
+		var actorName string
+		if notification.ActorID != nil {
+			if actor := findUser(*notification.ActorID); actor != nil {
+				actorName = actor.Username
+			}
+		}
+		message, err := buildPushMessage(notification, language, actorName)
+		if err != nil {
+			log.Printf("push_notification failed user=%d: %v", notification.UserID, err)
+			continue
+		}
+		payload, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("push_notification failed user=%d: %v", notification.UserID, err)
+			continue
+		}
+		for _, subscription := range devices[notification.UserID] {
+			_ = deliverPush(n.subscriptions, n.sender, subscription, payload)
+		}
+	}
+}
+
+// deliverPush sends the payload to one device, deleting the subscription when the push
+// service reports it expired
+func deliverPush(subscriptions daos.PushSubscriptionDAO, sender PushSender, subscription models.PushSubscription, payload []byte) error {
+	err := sender.Send(PushTarget{Endpoint: subscription.Endpoint, P256dh: subscription.P256dh, Auth: subscription.Auth}, payload)
+	switch {
+	case errors.Is(err, ErrPushSubscriptionGone):
+		if err := subscriptions.DeleteByEndpointHash(subscription.EndpointHash); err != nil {
+			log.Printf("push_prune failed subscription=%d: %v", subscription.ID, err)
+		} else {
+			log.Printf("push_prune success subscription=%d user=%d", subscription.ID, subscription.UserID)
+		}
+		return err
+	case err != nil:
+		log.Printf("push_delivery failed subscription=%d: %v", subscription.ID, err)
+		return err
+	}
+	if err := subscriptions.MarkUsed(subscription.ID, time.Now().UTC()); err != nil {
+		log.Printf("push_delivery mark_used failed subscription=%d: %v", subscription.ID, err)
+	}
+	return nil
+}
+
+// buildPushMessage renders the alert for a notification in the recipient's language
+func buildPushMessage(notification models.Notification, language, actorName string) (PushMessage, error) {
+	message := PushMessage{Type: notification.Type, ListID: notification.ListID}
+	switch notification.Type {
+	case models.NotificationTitleAdded:
+		var payload models.TitleAddedPayload
+		if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+			return PushMessage{}, err
+		}
+		message.Title = payload.ListName
+		message.Body = translate(language, "push.title_added", actorName, payload.Title)
+		message.URL = "/list/" + strconv.FormatInt(payload.ListID, 10)
+		message.Tag = "title-" + strconv.FormatInt(payload.ListID, 10) + "-" + strconv.FormatInt(payload.MovieID, 10)
+	case models.NotificationMention:
+		var payload models.MentionPayload
+		if err := json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+			return PushMessage{}, err
+		}
+		message.Title = translate(language, "push.mention", actorName)
+		message.Body = payload.Excerpt
+		message.URL = "/list/" + strconv.FormatInt(payload.ListID, 10)
+		message.Tag = "comment-" + strconv.FormatInt(payload.CommentID, 10)
+	default:
+		return PushMessage{}, errors.New("notification type is not pushed: " + string(notification.Type))
+	}
+	return message, nil
+}
+
+// validatePushSubscription checks the endpoint is a push service URL the server may
+// post to, never a private address, and that the keys are a P-256 point and a 16-byte secret
+func validatePushSubscription(input PushSubscriptionInput, allowLocal bool) error {
+	u, err := url.Parse(input.Endpoint)
+	if err != nil || u.Host == "" || len(input.Endpoint) > 2048 {
+		return ErrInvalidPushSubscription
+	}
+	loopback := isLoopbackHost(u.Hostname())
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !loopback && !isPublicIP(ip) {
+		return ErrInvalidPushSubscription
+	}
+	switch {
+	case u.Scheme == "https" && !loopback:
+	case allowLocal && loopback && (u.Scheme == "http" || u.Scheme == "https"):
+	default:
+		return ErrInvalidPushSubscription
+	}
+
+	p256dh, err := decodeBase64URL(input.P256dh)
+	if err != nil {
+		return ErrInvalidPushSubscription
+	}
+	if _, err := ecdh.P256().NewPublicKey(p256dh); err != nil {
+		return ErrInvalidPushSubscription
+	}
+	auth, err := decodeBase64URL(input.Auth)
+	if err != nil || len(auth) != 16 {
+		return ErrInvalidPushSubscription
+	}
+	return nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func hashPushEndpoint(endpoint string) string {
+	sum := sha256.Sum256([]byte(endpoint))
+	return hex.EncodeToString(sum[:])
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
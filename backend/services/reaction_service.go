@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+type ReactionService interface {
+	ToggleCommentReaction(listID, userID int64, movieID *int64, commentID int64, reaction string) (bool, []models.ReactionSummary, error)
+	ToggleListMovieReaction(listID, userID, movieID int64, reaction string) (bool, []models.ReactionSummary, error)
+}
+
+type reactionService struct {
+	lists     daos.MovieListDAO
+	reactions daos.ReactionDAO
+}
+
+func NewReactionService(lists daos.MovieListDAO, reactions daos.ReactionDAO) ReactionService {
+	return &reactionService{lists: lists, reactions: reactions}
+}
+
+var ErrInvalidReaction = errors.New("invalid_reaction")
+
+func (s *reactionService) ToggleCommentReaction(listID, userID int64, movieID *int64, commentID int64, reaction string) (bool, []models.ReactionSummary, error) {
+	kind, err := parseReactionKind(reaction)
+	if err != nil {
+		return false, nil, err
+	}
+	if err := s.ensureMember(listID, userID); err != nil {
+		return false, nil, err
+	}
+
+	comment, err := s.lists.FindCommentByID(commentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil, ErrCommentNotFound
+		}
+		return false, nil, err
+	}
+	if !comment.InScope(listID, movieID) || comment.IsDeleted() || comment.IsHidden() {
+		return false, nil, ErrCommentNotFound
+	}
+
+	return s.toggle(&models.Reaction{
+		ListID:     listID,
+		TargetType: models.ReactionTargetComment,
+		TargetID:   comment.ID,
+		UserID:     userID,
+		Kind:       kind,
+	})
+}
+
+func (s *reactionService) ToggleListMovieReaction(listID, userID, movieID int64, reaction string) (bool, []models.ReactionSummary, error) {
+	kind, err := parseReactionKind(reaction)
+	if err != nil {
+		return false, nil, err
+	}
+	if err := s.ensureMember(listID, userID); err != nil {
+		return false, nil, err
+	}
+
+	listMovie, err := s.lists.FindListMovieByListAndMovie(listID, movieID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil, ErrMovieNotInList
+		}
+		return false, nil, err
+	}
+
+	return s.toggle(&models.Reaction{
+		ListID:     listID,
+		TargetType: models.ReactionTargetListMovie,
+		TargetID:   listMovie.ID,
+		UserID:     userID,
+		Kind:       kind,
+	})
+}
+
+// toggle flips the reaction and returns the target's updated summary
+func (s *reactionService) toggle(reaction *models.Reaction) (bool, []models.ReactionSummary, error) {
+	added, err := s.reactions.Toggle(reaction)
+	if err != nil {
+		return false, nil, err
+	}
+	summaries, err := s.reactions.FindSummaries(reaction.TargetType, []int64{reaction.TargetID})
+	if err != nil {
+		return false, nil, err
+	}
+	return added, summaries[reaction.TargetID], nil
+}
+
+func (s *reactionService) ensureMember(listID, userID int64) error {
+	// Check list exists
+	if _, err := s.lists.FindByID(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrListNotFound
+		}
+		return err
+	}
+
+	// Check user is a member
+	membership, err := s.lists.FindMembership(listID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrForbiddenMembership
+		}
+		return err
+	}
+	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
+		return ErrForbiddenMembership
+	}
+	return nil
+}
+
+// parseReactionKind accepts either a reaction kind or its emoji, with or without the
+// emoji variation selector some keyboards leave out
+func parseReactionKind(value string) (models.ReactionKind, error) {
+	value = strings.TrimSpace(value)
+	for _, kind := range models.ReactionKinds {
+		if value == string(kind) || stripVariationSelector(value) == stripVariationSelector(models.ReactionEmojis[kind]) {
+			return kind, nil
+		}
+	}
+	return "", ErrInvalidReaction
+}
+
+func stripVariationSelector(s string) string {
+	return strings.ReplaceAll(s, "\ufe0f", "")
+}
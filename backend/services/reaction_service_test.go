@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReactionKind(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected models.ReactionKind
+		wantErr  bool
+	}{
+		{"kind", "popcorn", models.ReactionPopcorn, false},
+		{"emoji", "👍", models.ReactionThumbsUp, false},
+		{"emoji with variation selector", "❤️", models.ReactionHeart, false},
+		{"emoji without variation selector", "❤", models.ReactionHeart, false},
+		{"surrounding spaces", " 😂 ", models.ReactionLaugh, false},
+		{"unsupported emoji", "🔥", "", true},
+		{"empty", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, err := parseReactionKind(tt.input)
+
+			if tt.wantErr {
+				assert.Equal(t, ErrInvalidReaction, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, kind)
+		})
+	}
+}
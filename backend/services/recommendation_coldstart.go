@@ -0,0 +1,238 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/8bury/list2gether/models"
+)
+
+type RecommendationStrategy string
+
+const (
+	// StrategyListContent seeds from the titles in the list itself
+	StrategyListContent RecommendationStrategy = "list_content"
+	// StrategyMemberRatings seeds from titles the members rated highly in their other lists
+	StrategyMemberRatings RecommendationStrategy = "member_ratings"
+	// StrategyMemberLists seeds from titles recently added to the members' other lists
+	StrategyMemberLists RecommendationStrategy = "member_lists"
+	// StrategyTrending uses trending titles filtered by the members' favourite genres
+	StrategyTrending RecommendationStrategy = "trending"
+)
+
+const (
+	// Titles from the members' other lists considered for cold start
+	coldStartHistoryLimit = 200
+	// Minimum rating for a title from another list to be used as a seed
+	coldStartMinRating = 7
+)
+
+// coldStartRecommendations recommends for lists with fewer than two titles, trying the
+// members' ratings, then their other lists, then trending titles
+func (s *recommendationService) coldStartRecommendations(ctx context.Context, key recommendationCacheKey, listMovies []models.ListMovie) ([]RecommendationItem, RecommendationStrategy, error) {
+	members, err := s.lists.FindMembersWithUser(key.listID)
+	if err != nil {
+		return nil, "", err
+	}
+	userIDs := make([]int64, 0, len(members))
+	for _, m := range members {
+		userIDs = append(userIDs, m.UserID)
+	}
+
+	history, err := s.lists.FindMembersOtherListMovies(userIDs, key.listID, coldStartHistoryLimit)
+	if err != nil {
+		return nil, "", err
+	}
+	history, watched := mergeHistory(history, userIDs)
+
+	basis := make([]models.ListMovie, 0, len(listMovies)+len(history))
+	basis = append(basis, listMovies...)
+	basis = append(basis, history...)
+
+	// Titles the members already watched elsewhere are not worth recommending
+	exclude := make([]models.ListMovie, 0, len(listMovies)+len(watched))
+	exclude = append(exclude, listMovies...)
+	exclude = append(exclude, watched...)
+
+	var profiles []memberProfile
+	if key.mode == RecommendationModeGroup {
+		profiles = buildMemberProfiles(members, basis)
+	}
+
+	if rated := highlyRated(history, coldStartMinRating); len(rated) > 0 {
+		var seeds []models.ListMovie
+		if len(profiles) > 0 {
+			seeds = s.selectFairSeedMovies(append(listMovies, rated...), profiles, 5)
+		} else {
+			seeds = s.selectSeedMovies(append(listMovies, rated...), 5)
+		}
+		items, err := s.recommendFromSeeds(ctx, seeds, basis, exclude, profiles, key.fairness)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(items) > 0 {
+			return items, StrategyMemberRatings, nil
+		}
+	}
+
+	if len(history) > 0 {
+		seeds := append([]models.ListMovie{}, listMovies...)
+		for _, lm := range history {
+			if len(seeds) >= 5 {
+				break
+			}
+			seeds = append(seeds, lm)
+		}
+		items, err := s.recommendFromSeeds(ctx, seeds, basis, exclude, profiles, key.fairness)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(items) > 0 {
+			return items, StrategyMemberLists, nil
+		}
+	}
+
+	prefs, err := s.users.FindGenrePreferences(userIDs)
+	if err != nil {
+		return nil, "", err
+	}
+	preferred := make(map[int64]bool, len(prefs))
+	for _, p := range prefs {
+		preferred[p.GenreID] = true
+	}
+
+	trending := filterByGenres(s.fetchTrendingFromTMDB(ctx), preferred)
+	if len(trending) == 0 {
+		return nil, "", ErrInsufficientMovies
+	}
+
+	// Preferred genres count as matches, just like the genres of titles in the list
+	genreBasis := models.ListMovie{}
+	for genreID := range preferred {
+		genreBasis.Movie.Genres = append(genreBasis.Movie.Genres, models.Genre{ID: genreID})
+	}
+	items := s.rankCandidates(trending, append(basis, genreBasis), nil, exclude, profiles, key.fairness)
+	return items, StrategyTrending, nil
+}
+
+// mergeHistory collapses titles present in several of the members' lists into one entry,
+// keeping only the members' ratings, and returns the titles any member list marked watched
+func mergeHistory(history []models.ListMovie, userIDs []int64) ([]models.ListMovie, []models.ListMovie) {
+	isMember := make(map[int64]bool, len(userIDs))
+	for _, id := range userIDs {
+		isMember[id] = true
+	}
+
+	merged := make([]models.ListMovie, 0, len(history))
+	index := make(map[int64]int)
+	watched := make([]models.ListMovie, 0)
+	watchedIDs := make(map[int64]bool)
+	for _, lm := range history {
+		entries := make([]models.ListMovieUserData, 0, len(lm.UserEntries))
+		for _, entry := range lm.UserEntries {
+			if isMember[entry.UserID] {
+				entries = append(entries, entry)
+			}
+		}
+		if i, ok := index[lm.MovieID]; ok {
+			merged[i].UserEntries = append(merged[i].UserEntries, entries...)
+		} else {
+			lm.UserEntries = entries
+			index[lm.MovieID] = len(merged)
+			merged = append(merged, lm)
+		}
+		if lm.Status == models.StatusWatched && !watchedIDs[lm.MovieID] {
+			watchedIDs[lm.MovieID] = true
+			watched = append(watched, lm)
+		}
+	}
+	return merged, watched
+}
+
+// highlyRated returns the titles whose best rating reaches minRating, best first
+func highlyRated(history []models.ListMovie, minRating int) []models.ListMovie {
+	type ratedMovie struct {
+		movie models.ListMovie
+		best  int
+	}
+	rated := make([]ratedMovie, 0)
+	for _, lm := range history {
+		best := 0
+		for _, entry := range lm.UserEntries {
+			if entry.Rating != nil && *entry.Rating > best {
+				best = *entry.Rating
+			}
+		}
+		if best >= minRating {
+			rated = append(rated, ratedMovie{movie: lm, best: best})
+		}
+	}
+	sort.SliceStable(rated, func(i, j int) bool {
+		return rated[i].best > rated[j].best
+	})
+
+	result := make([]models.ListMovie, 0, len(rated))
+	for _, r := range rated {
+		result = append(result, r.movie)
+	}
+	return result
+}
+
+// filterByGenres keeps candidates sharing a preferred genre, or all of them when none do
+func filterByGenres(candidates []tmdbRecommendationResult, preferred map[int64]bool) []tmdbRecommendationResult {
+	if len(preferred) == 0 {
+		return candidates
+	}
+	filtered := make([]tmdbRecommendationResult, 0, len(candidates))
+	for _, c := range candidates {
+		for _, genreID := range c.GenreIDs {
+			if preferred[genreID] {
+				filtered = append(filtered, c)
+				break
+			}
+		}
+	}
+	if len(filtered) == 0 {
+		return candidates
+	}
+	return filtered
+}
+
+// fetchTrendingFromTMDB returns this week's trending movies and series
+func (s *recommendationService) fetchTrendingFromTMDB(ctx context.Context) []tmdbRecommendationResult {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.themoviedb.org/3/trending/all/week?language=pt-BR", nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.tmdbToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.tmdbToken)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var tmdbResp tmdbRecommendationsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tmdbResp); err != nil {
+		return nil
+	}
+
+	results := make([]tmdbRecommendationResult, 0, len(tmdbResp.Results))
+	for _, r := range tmdbResp.Results {
+		if r.MediaType != "movie" && r.MediaType != "tv" {
+			continue
+		}
+		r.Source = RecommendationSourceTMDB
+		results = append(results, r)
+	}
+	return results
+}
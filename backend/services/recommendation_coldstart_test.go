@@ -0,0 +1,76 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeHistory(t *testing.T) {
+	history := []models.ListMovie{
+		{ListID: 1, MovieID: 10, Status: models.StatusWatched, UserEntries: []models.ListMovieUserData{
+			{UserID: 1, Rating: ratingPtr(9)},
+			{UserID: 5, Rating: ratingPtr(2)},
+		}},
+		{ListID: 2, MovieID: 10, UserEntries: []models.ListMovieUserData{
+			{UserID: 2, Rating: ratingPtr(8)},
+		}},
+		{ListID: 2, MovieID: 11},
+	}
+
+	merged, watched := mergeHistory(history, []int64{1, 2})
+
+	assert.Len(t, merged, 2)
+	assert.Equal(t, int64(10), merged[0].MovieID)
+	// Ratings from people outside the list are dropped
+	assert.Len(t, merged[0].UserEntries, 2)
+	assert.Equal(t, int64(1), merged[0].UserEntries[0].UserID)
+	assert.Equal(t, int64(2), merged[0].UserEntries[1].UserID)
+	assert.Len(t, watched, 1)
+	assert.Equal(t, int64(10), watched[0].MovieID)
+}
+
+func TestHighlyRated(t *testing.T) {
+	history := []models.ListMovie{
+		{MovieID: 1, UserEntries: []models.ListMovieUserData{{UserID: 1, Rating: ratingPtr(7)}}},
+		{MovieID: 2, UserEntries: []models.ListMovieUserData{{UserID: 1, Rating: ratingPtr(6)}}},
+		{MovieID: 3, UserEntries: []models.ListMovieUserData{{UserID: 1, Rating: ratingPtr(5)}, {UserID: 2, Rating: ratingPtr(10)}}},
+		{MovieID: 4},
+	}
+
+	rated := highlyRated(history, 7)
+
+	assert.Len(t, rated, 2)
+	assert.Equal(t, int64(3), rated[0].MovieID)
+	assert.Equal(t, int64(1), rated[1].MovieID)
+}
+
+func TestFilterByGenres(t *testing.T) {
+	candidates := []tmdbRecommendationResult{
+		{ID: 1, GenreIDs: []int64{27}},
+		{ID: 2, GenreIDs: []int64{35, 18}},
+	}
+
+	tests := []struct {
+		name      string
+		preferred map[int64]bool
+		expected  []int64
+	}{
+		{"no preferences", nil, []int64{1, 2}},
+		{"matching genre", map[int64]bool{18: true}, []int64{2}},
+		{"nothing matches", map[int64]bool{99: true}, []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filterByGenres(candidates, tt.preferred)
+
+			ids := make([]int64, 0, len(filtered))
+			for _, c := range filtered {
+				ids = append(ids, c.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"math"
+	"sort"
+
+	"github.com/8bury/list2gether/models"
+)
+
+type RecommendationMode string
+
+const (
+	// RecommendationModeDefault seeds from the list's best-rated titles
+	RecommendationModeDefault RecommendationMode = "default"
+	// RecommendationModeGroup balances the taste of every member
+	RecommendationModeGroup RecommendationMode = "group"
+)
+
+type FairnessStrategy string
+
+const (
+	// FairnessLeastMisery ranks by the fit of the least satisfied member
+	FairnessLeastMisery FairnessStrategy = "least_misery"
+	// FairnessAverageMinusVariance ranks by the mean fit penalized by disagreement
+	FairnessAverageMinusVariance FairnessStrategy = "average_minus_variance"
+)
+
+// MemberFit is the predicted fit of a recommendation for one member, from 0 to 1
+type MemberFit struct {
+	UserID   int64   `json:"user_id"`
+	Username string  `json:"username"`
+	Fit      float64 `json:"fit"`
+}
+
+// memberProfile is a member's taste derived from their ratings in the list
+type memberProfile struct {
+	userID        int64
+	username      string
+	ratings       map[int64]int     // movieID -> rating
+	genreAffinity map[int64]float64 // genreID -> affinity in [-1, 1]
+}
+
+// buildMemberProfiles derives a taste profile for each member. Ratings are
+// centered on the member's own average so generous raters don't dominate.
+func buildMemberProfiles(members []models.ListMember, listMovies []models.ListMovie) []memberProfile {
+	profiles := make([]memberProfile, 0, len(members))
+	for _, member := range members {
+		profile := memberProfile{
+			userID:        member.UserID,
+			username:      member.User.Username,
+			ratings:       make(map[int64]int),
+			genreAffinity: make(map[int64]float64),
+		}
+
+		var total int
+		for _, lm := range listMovies {
+			for _, entry := range lm.UserEntries {
+				if entry.UserID == member.UserID && entry.Rating != nil {
+					profile.ratings[lm.MovieID] = *entry.Rating
+					total += *entry.Rating
+				}
+			}
+		}
+
+		if len(profile.ratings) > 0 {
+			mean := float64(total) / float64(len(profile.ratings))
+			sums := make(map[int64]float64)
+			counts := make(map[int64]int)
+			for _, lm := range listMovies {
+				rating, ok := profile.ratings[lm.MovieID]
+				if !ok {
+					continue
+				}
+				var deviation float64
+				if len(profile.ratings) > 1 {
+					deviation = (float64(rating) - mean) / 4.5
+				} else {
+					deviation = normalizeRating(rating)
+				}
+				for _, genre := range lm.Movie.Genres {
+					sums[genre.ID] += deviation
+					counts[genre.ID]++
+				}
+			}
+			for genreID, sum := range sums {
+				profile.genreAffinity[genreID] = clamp(sum/float64(counts[genreID]), -1, 1)
+			}
+		}
+
+		profiles = append(profiles, profile)
+	}
+	return profiles
+}
+
+// selectFairSeedMovies picks seeds round-robin from each member's favourite titles,
+// filling any remaining slots with the default seed selection
+func (s *recommendationService) selectFairSeedMovies(listMovies []models.ListMovie, profiles []memberProfile, maxSeeds int) []models.ListMovie {
+	byID := make(map[int64]models.ListMovie, len(listMovies))
+	for _, lm := range listMovies {
+		byID[lm.MovieID] = lm
+	}
+
+	favourites := make([][]int64, len(profiles))
+	for i, profile := range profiles {
+		ids := make([]int64, 0, len(profile.ratings))
+		for movieID := range profile.ratings {
+			ids = append(ids, movieID)
+		}
+		sort.Slice(ids, func(a, b int) bool {
+			ra, rb := profile.ratings[ids[a]], profile.ratings[ids[b]]
+			if ra != rb {
+				return ra > rb
+			}
+			return byID[ids[a]].AddedAt.After(byID[ids[b]].AddedAt)
+		})
+		favourites[i] = ids
+	}
+
+	result := make([]models.ListMovie, 0, maxSeeds)
+	picked := make(map[int64]bool)
+	for round := 0; len(result) < maxSeeds; round++ {
+		progressed := false
+		for _, ids := range favourites {
+			if round >= len(ids) || len(result) >= maxSeeds {
+				continue
+			}
+			progressed = true
+			if !picked[ids[round]] {
+				picked[ids[round]] = true
+				result = append(result, byID[ids[round]])
+			}
+		}
+		if !progressed {
+			break
+		}
+	}
+
+	for _, lm := range s.selectSeedMovies(listMovies, maxSeeds) {
+		if len(result) >= maxSeeds {
+			break
+		}
+		if !picked[lm.MovieID] {
+			picked[lm.MovieID] = true
+			result = append(result, lm)
+		}
+	}
+	return result
+}
+
+// predictFit estimates how much a member would enjoy a recommendation based on
+// their genre affinity and how they rated the seeds that produced it
+func predictFit(profile memberProfile, item RecommendationItem) float64 {
+	var genreTerm float64
+	if len(item.Genres) > 0 {
+		var sum float64
+		for _, genre := range item.Genres {
+			sum += profile.genreAffinity[genre.ID]
+		}
+		genreTerm = sum / float64(len(item.Genres))
+	}
+
+	var seedTerm float64
+	var seedCount int
+	for _, seed := range item.Reasons.BecauseYouLiked {
+		if rating, ok := profile.ratings[seed.ID]; ok {
+			seedTerm += normalizeRating(rating)
+			seedCount++
+		}
+	}
+	if seedCount > 0 {
+		seedTerm /= float64(seedCount)
+	}
+
+	return clamp(0.5+0.3*genreTerm+0.2*seedTerm, 0, 1)
+}
+
+// rankForGroup scores every item per member and orders them by the fairness aggregate,
+// leaving Score and its contributions as they were
+func rankForGroup(items []RecommendationItem, profiles []memberProfile, fairness FairnessStrategy) []RecommendationItem {
+	ranked := make([]RecommendationItem, 0, len(items))
+	for _, item := range items {
+		fits := make([]MemberFit, 0, len(profiles))
+		values := make([]float64, 0, len(profiles))
+		for _, profile := range profiles {
+			fit := predictFit(profile, item)
+			fits = append(fits, MemberFit{UserID: profile.userID, Username: profile.username, Fit: fit})
+			values = append(values, fit)
+		}
+		item.MemberFits = fits
+		groupScore := aggregateFairness(values, fairness)
+		item.GroupScore = &groupScore
+		ranked = append(ranked, item)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return *ranked[i].GroupScore > *ranked[j].GroupScore
+	})
+	return ranked
+}
+
+func aggregateFairness(values []float64, fairness FairnessStrategy) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	if fairness == FairnessLeastMisery {
+		lowest := values[0]
+		for _, v := range values[1:] {
+			lowest = math.Min(lowest, v)
+		}
+		return lowest
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+	return mean - variance
+}
+
+// normalizeRating maps a 1-10 rating to [-1, 1]
+func normalizeRating(rating int) float64 {
+	return (float64(rating) - 5.5) / 4.5
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func ratingPtr(v int) *int {
+	return &v
+}
+
+func TestBuildMemberProfiles_CentersOnMemberMean(t *testing.T) {
+	members := []models.ListMember{
+		{UserID: 1, User: models.User{ID: 1, Username: "ana"}},
+		{UserID: 2, User: models.User{ID: 2, Username: "bia"}},
+	}
+	listMovies := []models.ListMovie{
+		{MovieID: 10, Movie: models.Movie{ID: 10, Genres: []models.Genre{{ID: 27}}}, UserEntries: []models.ListMovieUserData{
+			{UserID: 1, Rating: ratingPtr(10)},
+			{UserID: 2, Rating: ratingPtr(3)},
+		}},
+		{MovieID: 11, Movie: models.Movie{ID: 11, Genres: []models.Genre{{ID: 35}}}, UserEntries: []models.ListMovieUserData{
+			{UserID: 1, Rating: ratingPtr(9)},
+			{UserID: 2, Rating: ratingPtr(8)},
+		}},
+	}
+
+	profiles := buildMemberProfiles(members, listMovies)
+
+	assert.Len(t, profiles, 2)
+	assert.Equal(t, "ana", profiles[0].username)
+	// Ana loves everything, so her preference between genres is small
+	assert.InDelta(t, 0.111, profiles[0].genreAffinity[27], 0.001)
+	assert.InDelta(t, -0.111, profiles[0].genreAffinity[35], 0.001)
+	// Bia clearly prefers comedy over horror
+	assert.Less(t, profiles[1].genreAffinity[27], -0.5)
+	assert.Greater(t, profiles[1].genreAffinity[35], 0.5)
+}
+
+func TestBuildMemberProfiles_MemberWithoutRatings(t *testing.T) {
+	members := []models.ListMember{{UserID: 3}}
+
+	profiles := buildMemberProfiles(members, nil)
+
+	assert.Len(t, profiles, 1)
+	assert.Empty(t, profiles[0].ratings)
+	assert.InDelta(t, 0.5, predictFit(profiles[0], RecommendationItem{Genres: []models.Genre{{ID: 27}}}), 1e-9)
+}
+
+func TestRankForGroup_PrefersBalancedCandidates(t *testing.T) {
+	profiles := []memberProfile{
+		{userID: 1, ratings: map[int64]int{}, genreAffinity: map[int64]float64{27: 1, 35: 0.2}},
+		{userID: 2, ratings: map[int64]int{}, genreAffinity: map[int64]float64{27: -1, 35: 0.2}},
+	}
+	items := []RecommendationItem{
+		{ID: 100, Score: 5, Genres: []models.Genre{{ID: 27}}},
+		{ID: 200, Score: 1, Genres: []models.Genre{{ID: 35}}},
+	}
+
+	for _, fairness := range []FairnessStrategy{FairnessLeastMisery, FairnessAverageMinusVariance} {
+		t.Run(string(fairness), func(t *testing.T) {
+			ranked := rankForGroup(items, profiles, fairness)
+
+			assert.Equal(t, int64(200), ranked[0].ID)
+			// The content score and its explanation are kept apart from the group score
+			assert.Equal(t, float64(1), ranked[0].Score)
+			assert.NotNil(t, ranked[0].GroupScore)
+			assert.Len(t, ranked[0].MemberFits, 2)
+			assert.InDelta(t, 0.56, ranked[0].MemberFits[0].Fit, 1e-9)
+		})
+	}
+}
+
+func TestAggregateFairness(t *testing.T) {
+	values := []float64{0.2, 0.8}
+
+	assert.InDelta(t, 0.2, aggregateFairness(values, FairnessLeastMisery), 1e-9)
+	assert.InDelta(t, 0.41, aggregateFairness(values, FairnessAverageMinusVariance), 1e-9)
+	assert.Equal(t, float64(0), aggregateFairness(nil, FairnessLeastMisery))
+}
+
+func TestSelectFairSeedMovies_RoundRobin(t *testing.T) {
+	service := &recommendationService{}
+	listMovies := []models.ListMovie{
+		{MovieID: 1}, {MovieID: 2}, {MovieID: 3}, {MovieID: 4},
+	}
+	profiles := []memberProfile{
+		{userID: 1, ratings: map[int64]int{1: 10, 2: 9, 3: 8}},
+		{userID: 2, ratings: map[int64]int{4: 6}},
+	}
+
+	seeds := service.selectFairSeedMovies(listMovies, profiles, 3)
+
+	ids := make([]int64, 0, len(seeds))
+	for _, seed := range seeds {
+		ids = append(ids, seed.MovieID)
+	}
+	assert.Equal(t, []int64{1, 4, 2}, ids)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -16,114 +17,409 @@ import (
 )
 
 type RecommendationService interface {
-	GetListRecommendations(ctx context.Context, listID int64, userID int64, limit int) ([]RecommendationItem, error)
+	GetListRecommendations(ctx context.Context, listID int64, userID int64, limit int, mode RecommendationMode, fairness FairnessStrategy) (*RecommendationResult, error)
+	SubmitFeedback(ctx context.Context, listID int64, userID int64, movieID int64, mediaType string, action models.RecommendationFeedbackAction, addToList bool) (*models.RecommendationFeedback, *models.ListMovie, error)
+	RemoveFeedback(listID int64, userID int64, movieID int64) error
+	GenrePreferencesChanged(userID int64)
+}
+
+// RecommendationResult is a ranked set of recommendations and the strategy that produced it
+type RecommendationResult struct {
+	Items    []RecommendationItem   `json:"items"`
+	Strategy RecommendationStrategy `json:"strategy"`
 }
 
 type RecommendationItem struct {
-	ID         int64          `json:"id"`
-	Title      string         `json:"title"`
-	MediaType  string         `json:"media_type"`
-	PosterURL  *string        `json:"poster_url"`
-	Overview   *string        `json:"overview"`
-	Score      float64        `json:"score"`
-	Popularity float64        `json:"popularity"`
-	Genres     []models.Genre `json:"genres"`
+	ID        int64   `json:"id"`
+	Title     string  `json:"title"`
+	MediaType string  `json:"media_type"`
+	PosterURL *string `json:"poster_url"`
+	Overview  *string `json:"overview"`
+	Score     float64 `json:"score"`
+	// GroupScore is the fairness aggregate of the member fits; in group mode titles are
+	// ranked by it, while Score stays the sum of the contributions
+	GroupScore *float64              `json:"group_score,omitempty"`
+	Popularity float64               `json:"popularity"`
+	Genres     []models.Genre        `json:"genres"`
+	Reasons    RecommendationReasons `json:"reasons"`
+	MemberFits []MemberFit           `json:"member_fits,omitempty"`
+}
+
+// RecommendationReasons explains why an item was recommended
+type RecommendationReasons struct {
+	Sources         []string              `json:"sources"`
+	BecauseYouLiked []RecommendationSeed  `json:"because_you_liked"`
+	MatchedGenres   []models.Genre        `json:"matched_genres"`
+	Contributions   RecommendationFactors `json:"contributions"`
+}
+
+// RecommendationSeed identifies a title from the list that produced a recommendation
+type RecommendationSeed struct {
+	ID        int64  `json:"id"`
+	Title     string `json:"title"`
+	MediaType string `json:"media_type"`
+}
+
+// RecommendationFactors holds how much each factor added to the final score
+type RecommendationFactors struct {
+	Popularity      float64 `json:"popularity"`
+	SeedFrequency   float64 `json:"seed_frequency"`
+	GenreMatch      float64 `json:"genre_match"`
+	LocalSimilarity float64 `json:"local_similarity"`
 }
 
+const (
+	RecommendationSourceTMDB  = "tmdb"
+	RecommendationSourceLocal = "local"
+
+	// Weight of the local item-item similarity relative to the TMDB signals
+	localSimilarityWeight = 2.0
+	// Local neighbours considered per seed
+	localNeighboursPerSeed = 10
+)
+
 type recommendationService struct {
-	lists      daos.MovieListDAO
-	httpClient *http.Client
-	tmdbToken  string
-	cache      sync.Map // Simple in-memory cache: listID -> cached recommendations
+	lists        daos.MovieListDAO
+	users        daos.UserDAO
+	feedback     daos.RecommendationFeedbackDAO
+	similarities daos.MovieSimilarityDAO
+	listSvc      ListService
+	httpClient   *http.Client
+	tmdbToken    string
+	cache        sync.Map // Simple in-memory cache: recommendationCacheKey -> cached recommendations
+}
+
+type recommendationCacheKey struct {
+	listID   int64
+	mode     RecommendationMode
+	fairness FairnessStrategy
 }
 
 type cachedRecommendations struct {
 	items     []RecommendationItem
+	strategy  RecommendationStrategy
 	timestamp time.Time
 }
 
-func NewRecommendationService(lists daos.MovieListDAO, tmdbToken string) RecommendationService {
+func NewRecommendationService(lists daos.MovieListDAO, users daos.UserDAO, feedback daos.RecommendationFeedbackDAO, similarities daos.MovieSimilarityDAO, listSvc ListService, tmdbToken string) RecommendationService {
 	return &recommendationService{
-		lists:      lists,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
-		tmdbToken:  tmdbToken,
+		lists:        lists,
+		users:        users,
+		feedback:     feedback,
+		similarities: similarities,
+		listSvc:      listSvc,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		tmdbToken:    tmdbToken,
 	}
 }
 
 var (
-	ErrListNotFoundRec        = errors.New("list not found")
-	ErrForbiddenMembershipRec = errors.New("forbidden: not a member of this list")
-	ErrInsufficientMovies     = errors.New("list must have at least 2 movies for recommendations")
+	ErrListNotFoundRec           = errors.New("list not found")
+	ErrForbiddenMembershipRec    = errors.New("forbidden: not a member of this list")
+	ErrInsufficientMovies        = errors.New("list must have at least 2 movies for recommendations")
+	ErrInvalidFeedbackAction     = errors.New("invalid feedback action")
+	ErrFeedbackNotFound          = errors.New("feedback not found")
+	ErrInvalidRecommendationMode = errors.New("invalid recommendation mode")
+	ErrInvalidFairnessStrategy   = errors.New("invalid fairness strategy")
 )
 
+// Score multipliers applied when other members left feedback on a recommendation.
+// Feedback from the requesting user always removes the title from their results.
+var memberFeedbackPenalty = map[models.RecommendationFeedbackAction]float64{
+	models.FeedbackDismissed:     0.9,
+	models.FeedbackNotInterested: 0.5,
+	models.FeedbackAlreadySeen:   0.75,
+}
+
 // GetListRecommendations generates movie recommendations based on the list's content
-func (s *recommendationService) GetListRecommendations(ctx context.Context, listID int64, userID int64, limit int) ([]RecommendationItem, error) {
+func (s *recommendationService) GetListRecommendations(ctx context.Context, listID int64, userID int64, limit int, mode RecommendationMode, fairness FairnessStrategy) (*RecommendationResult, error) {
+	if mode == "" {
+		mode = RecommendationModeDefault
+	}
+	if mode != RecommendationModeDefault && mode != RecommendationModeGroup {
+		return nil, ErrInvalidRecommendationMode
+	}
+	if mode == RecommendationModeGroup {
+		if fairness == "" {
+			fairness = FairnessAverageMinusVariance
+		}
+		if fairness != FairnessLeastMisery && fairness != FairnessAverageMinusVariance {
+			return nil, ErrInvalidFairnessStrategy
+		}
+	} else {
+		fairness = ""
+	}
+
+	if err := s.ensureMember(listID, userID); err != nil {
+		return nil, err
+	}
+
+	items, strategy, err := s.listRecommendations(ctx, recommendationCacheKey{listID: listID, mode: mode, fairness: fairness})
+	if err != nil {
+		return nil, err
+	}
+
+	feedback, err := s.feedback.FindByList(listID)
+	if err != nil {
+		return nil, err
+	}
+	items = applyFeedback(items, feedback, userID)
+
+	// Return top N results
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return &RecommendationResult{Items: items, Strategy: strategy}, nil
+}
+
+// listRecommendations returns the cached recommendations for a list, generating them when needed
+func (s *recommendationService) listRecommendations(ctx context.Context, key recommendationCacheKey) ([]RecommendationItem, RecommendationStrategy, error) {
 	// Check cache first (24-hour TTL)
-	if cached, ok := s.cache.Load(listID); ok {
+	if cached, ok := s.cache.Load(key); ok {
 		cachedData := cached.(cachedRecommendations)
 		if time.Since(cachedData.timestamp) < 24*time.Hour {
-			if limit > 0 && len(cachedData.items) > limit {
-				return cachedData.items[:limit], nil
-			}
-			return cachedData.items, nil
+			return cachedData.items, cachedData.strategy, nil
 		}
 		// Expired, remove from cache
-		s.cache.Delete(listID)
+		s.cache.Delete(key)
 	}
 
+	// Get all movies from the list with their ratings
+	listMovies, err := s.lists.FindListMoviesWithMovie(key.listID, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var (
+		items    []RecommendationItem
+		strategy RecommendationStrategy
+	)
+	if len(listMovies) < 2 {
+		// Too little to go on: fall back to the members' history or trending titles
+		items, strategy, err = s.coldStartRecommendations(ctx, key, listMovies)
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		strategy = StrategyListContent
+		items, err = s.listContentRecommendations(ctx, key, listMovies)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Cache the results
+	s.cache.Store(key, cachedRecommendations{
+		items:     items,
+		strategy:  strategy,
+		timestamp: time.Now(),
+	})
+
+	return items, strategy, nil
+}
+
+// listContentRecommendations seeds recommendations from the titles already in the list
+func (s *recommendationService) listContentRecommendations(ctx context.Context, key recommendationCacheKey, listMovies []models.ListMovie) ([]RecommendationItem, error) {
+	var profiles []memberProfile
+	if key.mode == RecommendationModeGroup {
+		members, err := s.lists.FindMembersWithUser(key.listID)
+		if err != nil {
+			return nil, err
+		}
+		profiles = buildMemberProfiles(members, listMovies)
+	}
+
+	// Select seeds: top-rated overall, or each member's favourites in group mode
+	var seedMovies []models.ListMovie
+	if len(profiles) > 0 {
+		seedMovies = s.selectFairSeedMovies(listMovies, profiles, 5)
+	} else {
+		seedMovies = s.selectSeedMovies(listMovies, 5)
+	}
+
+	return s.recommendFromSeeds(ctx, seedMovies, listMovies, listMovies, profiles, key.fairness)
+}
+
+// recommendFromSeeds gathers TMDB and local candidates for the seeds, scores them against
+// the basis titles, drops excluded titles and applies group fairness when profiles are given
+func (s *recommendationService) recommendFromSeeds(ctx context.Context, seedMovies []models.ListMovie, basis []models.ListMovie, exclude []models.ListMovie, profiles []memberProfile, fairness FairnessStrategy) ([]RecommendationItem, error) {
+	// Fetch recommendations from TMDB for each seed movie concurrently
+	recommendations := s.fetchRecommendationsFromTMDB(ctx, seedMovies)
+
+	// Blend in what similar lists in this deployment watch
+	localCandidates, err := s.fetchLocalCandidates(seedMovies)
+	if err != nil {
+		return nil, err
+	}
+	recommendations = append(recommendations, localCandidates...)
+
+	return s.rankCandidates(recommendations, basis, seedMovies, exclude, profiles, fairness), nil
+}
+
+// rankCandidates scores, filters and optionally re-ranks candidates for the whole group
+func (s *recommendationService) rankCandidates(candidates []tmdbRecommendationResult, basis []models.ListMovie, seedMovies []models.ListMovie, exclude []models.ListMovie, profiles []memberProfile, fairness FairnessStrategy) []RecommendationItem {
+	// Aggregate, score, and rank recommendations
+	scored := s.scoreAndRankRecommendations(candidates, basis, seedMovies)
+
+	// Filter out movies already in the list
+	filtered := s.filterExistingMovies(scored, exclude)
+
+	// Re-rank by how well each candidate fits every member
+	if len(profiles) > 0 {
+		filtered = rankForGroup(filtered, profiles, fairness)
+	}
+	return filtered
+}
+
+func (s *recommendationService) ensureMember(listID int64, userID int64) error {
 	// Verify list exists
 	if _, err := s.lists.FindByID(listID); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrListNotFoundRec
+			return ErrListNotFoundRec
 		}
-		return nil, err
+		return err
 	}
 
 	// Verify user is a member
 	membership, err := s.lists.FindMembership(listID, userID)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrForbiddenMembershipRec
+			return ErrForbiddenMembershipRec
 		}
-		return nil, err
+		return err
 	}
 	if membership.Role != models.RoleOwner && membership.Role != models.RoleParticipant {
-		return nil, ErrForbiddenMembershipRec
+		return ErrForbiddenMembershipRec
 	}
+	return nil
+}
 
-	// Get all movies from the list with their ratings
-	listMovies, err := s.lists.FindListMoviesWithMovie(listID, nil)
-	if err != nil {
-		return nil, err
+// invalidateCache drops every cached recommendation set of a list
+func (s *recommendationService) invalidateCache(listID int64) {
+	s.cache.Range(func(k, _ any) bool {
+		if key, ok := k.(recommendationCacheKey); ok && key.listID == listID {
+			s.cache.Delete(k)
+		}
+		return true
+	})
+}
+
+// GenrePreferencesChanged drops the cached trending recommendations of the user's lists,
+// the only ones built from favourite genres, so they follow the new picks right away
+func (s *recommendationService) GenrePreferencesChanged(userID int64) {
+	s.cache.Range(func(k, v any) bool {
+		key, ok := k.(recommendationCacheKey)
+		if !ok || v.(cachedRecommendations).strategy != StrategyTrending {
+			return true
+		}
+		if _, err := s.lists.FindMembership(key.listID, userID); err == nil {
+			s.cache.Delete(k)
+		}
+		return true
+	})
+}
+
+// SubmitFeedback records a member's reaction to a recommendation. When the title
+// was already seen and addToList is set, it is also added to the list as watched.
+func (s *recommendationService) SubmitFeedback(ctx context.Context, listID int64, userID int64, movieID int64, mediaType string, action models.RecommendationFeedbackAction, addToList bool) (*models.RecommendationFeedback, *models.ListMovie, error) {
+	if action != models.FeedbackDismissed && action != models.FeedbackNotInterested && action != models.FeedbackAlreadySeen {
+		return nil, nil, ErrInvalidFeedbackAction
+	}
+	if mediaType != "movie" && mediaType != "tv" {
+		return nil, nil, ErrInvalidMediaType
+	}
+	if err := s.ensureMember(listID, userID); err != nil {
+		return nil, nil, err
 	}
 
-	if len(listMovies) < 2 {
-		return nil, ErrInsufficientMovies
+	feedback := &models.RecommendationFeedback{
+		ListID:    listID,
+		UserID:    userID,
+		MovieID:   movieID,
+		MediaType: mediaType,
+		Action:    action,
+	}
+	if action != models.FeedbackAlreadySeen || !addToList {
+		if err := s.feedback.Upsert(feedback); err != nil {
+			return nil, nil, err
+		}
+		return feedback, nil, nil
 	}
 
-	// Calculate average ratings and select top movies as seeds
-	seedMovies := s.selectSeedMovies(listMovies, 5)
+	listMovie, err := s.listSvc.MarkMediaSeen(ctx, listID, userID, movieID, mediaType, feedback)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The list content changed, so seeds and exclusions must be recomputed
+	s.invalidateCache(listID)
+	return feedback, listMovie, nil
+}
 
-	// Fetch recommendations from TMDB for each seed movie concurrently
-	recommendations := s.fetchRecommendationsFromTMDB(ctx, seedMovies)
+// RemoveFeedback clears a member's feedback so the title can be recommended again
+func (s *recommendationService) RemoveFeedback(listID int64, userID int64, movieID int64) error {
+	if err := s.ensureMember(listID, userID); err != nil {
+		return err
+	}
+	removed, err := s.feedback.Delete(listID, userID, movieID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrFeedbackNotFound
+	}
+	return nil
+}
 
-	// Aggregate, score, and rank recommendations
-	scored := s.scoreAndRankRecommendations(recommendations, listMovies, seedMovies)
+// applyFeedback hides titles the user gave feedback on and down-ranks titles
+// other members reacted to. The input slice is not modified.
+func applyFeedback(items []RecommendationItem, feedback []models.RecommendationFeedback, userID int64) []RecommendationItem {
+	if len(feedback) == 0 {
+		return items
+	}
 
-	// Filter out movies already in the list
-	filtered := s.filterExistingMovies(scored, listMovies)
+	excluded := make(map[int64]bool)
+	penalties := make(map[int64]float64)
+	for _, f := range feedback {
+		if f.UserID == userID {
+			excluded[f.MovieID] = true
+			continue
+		}
+		if penalty, ok := memberFeedbackPenalty[f.Action]; ok {
+			if current, seen := penalties[f.MovieID]; seen {
+				penalties[f.MovieID] = current * penalty
+			} else {
+				penalties[f.MovieID] = penalty
+			}
+		}
+	}
 
-	// Cache the results
-	s.cache.Store(listID, cachedRecommendations{
-		items:     filtered,
-		timestamp: time.Now(),
+	result := make([]RecommendationItem, 0, len(items))
+	for _, item := range items {
+		if excluded[item.ID] {
+			continue
+		}
+		if penalty, ok := penalties[item.ID]; ok {
+			item.Score *= penalty
+			if item.GroupScore != nil {
+				groupScore := *item.GroupScore * penalty
+				item.GroupScore = &groupScore
+			}
+		}
+		result = append(result, item)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].rankingScore() > result[j].rankingScore()
 	})
+	return result
+}
 
-	// Return top N results
-	if limit > 0 && len(filtered) > limit {
-		return filtered[:limit], nil
+// rankingScore is what the item is ordered by: the group score when there is one
+func (item RecommendationItem) rankingScore() float64 {
+	if item.GroupScore != nil {
+		return *item.GroupScore
 	}
-	return filtered, nil
+	return item.Score
 }
 
 // selectSeedMovies chooses the top-rated movies to use as recommendation seeds
@@ -194,6 +490,13 @@ type tmdbRecommendationResult struct {
 	Popularity float64 `json:"popularity"`
 	GenreIDs   []int64 `json:"genre_ids"`
 	MediaType  string  `json:"media_type"`
+
+	// SeedMovieID is the list title whose recommendations returned this result
+	SeedMovieID int64 `json:"-"`
+	// Source tells whether the candidate came from TMDB or the local similarity model
+	Source string `json:"-"`
+	// LocalScore is the item-item similarity for local candidates
+	LocalScore float64 `json:"-"`
 }
 
 // fetchRecommendationsFromTMDB calls TMDB API concurrently for seed movies
@@ -238,11 +541,13 @@ func (s *recommendationService) fetchRecommendationsFromTMDB(ctx context.Context
 				return
 			}
 
-			// Set media type for all results
+			// Set media type and originating seed for all results
 			for i := range tmdbResp.Results {
 				if tmdbResp.Results[i].MediaType == "" {
 					tmdbResp.Results[i].MediaType = movie.Movie.MediaType
 				}
+				tmdbResp.Results[i].SeedMovieID = movie.MovieID
+				tmdbResp.Results[i].Source = RecommendationSourceTMDB
 			}
 
 			mu.Lock()
@@ -255,31 +560,107 @@ func (s *recommendationService) fetchRecommendationsFromTMDB(ctx context.Context
 	return allRecommendations
 }
 
+// fetchLocalCandidates turns the stored item-item neighbours of each seed into candidates
+func (s *recommendationService) fetchLocalCandidates(seedMovies []models.ListMovie) ([]tmdbRecommendationResult, error) {
+	if s.similarities == nil || len(seedMovies) == 0 {
+		return nil, nil
+	}
+	seedIDs := make([]int64, 0, len(seedMovies))
+	for _, seed := range seedMovies {
+		seedIDs = append(seedIDs, seed.MovieID)
+	}
+	neighbours, err := s.similarities.FindSimilar(seedIDs, localNeighboursPerSeed)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]tmdbRecommendationResult, 0, len(neighbours))
+	for _, n := range neighbours {
+		movie := n.SimilarMovie
+		if movie.ID == 0 {
+			continue
+		}
+		candidate := tmdbRecommendationResult{
+			ID:          movie.ID,
+			Title:       movie.Title,
+			PosterPath:  movie.PosterPath,
+			MediaType:   movie.MediaType,
+			SeedMovieID: n.MovieID,
+			Source:      RecommendationSourceLocal,
+			LocalScore:  n.Score,
+		}
+		if movie.Overview != nil {
+			candidate.Overview = *movie.Overview
+		}
+		if movie.Popularity != nil {
+			candidate.Popularity = *movie.Popularity
+		}
+		for _, g := range movie.Genres {
+			candidate.GenreIDs = append(candidate.GenreIDs, g.ID)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
 // scoreAndRankRecommendations aggregates recommendations and calculates scores
 func (s *recommendationService) scoreAndRankRecommendations(recommendations []tmdbRecommendationResult, listMovies []models.ListMovie, seedMovies []models.ListMovie) []RecommendationItem {
 	// Count frequency and aggregate data
 	type aggData struct {
-		result    tmdbRecommendationResult
-		frequency int
+		result     tmdbRecommendationResult
+		frequency  int
+		localScore float64
+		seedIDs    []int64
+		sources    []string
 	}
 
 	aggregated := make(map[int64]*aggData)
 	for _, rec := range recommendations {
-		if existing, ok := aggregated[rec.ID]; ok {
-			existing.frequency++
+		agg, ok := aggregated[rec.ID]
+		if !ok {
+			agg = &aggData{result: rec}
+			aggregated[rec.ID] = agg
+		}
+		source := rec.Source
+		if source == "" {
+			source = RecommendationSourceTMDB
+		}
+		if source == RecommendationSourceLocal {
+			agg.localScore += rec.LocalScore
 		} else {
-			aggregated[rec.ID] = &aggData{
-				result:    rec,
-				frequency: 1,
+			// Prefer TMDB metadata, which is localized and always carries popularity
+			if agg.frequency == 0 {
+				agg.result = rec
 			}
+			agg.frequency++
+		}
+		if !containsString(agg.sources, source) {
+			agg.sources = append(agg.sources, source)
+		}
+		if rec.SeedMovieID != 0 && !slices.Contains(agg.seedIDs, rec.SeedMovieID) {
+			agg.seedIDs = append(agg.seedIDs, rec.SeedMovieID)
 		}
 	}
 
-	// Calculate genre frequencies in the list
+	// Index seeds so reasons can reference their titles
+	seedsByID := make(map[int64]RecommendationSeed, len(seedMovies))
+	for _, seed := range seedMovies {
+		seedsByID[seed.MovieID] = RecommendationSeed{
+			ID:        seed.MovieID,
+			Title:     seed.Movie.Title,
+			MediaType: seed.Movie.MediaType,
+		}
+	}
+
+	// Calculate genre frequencies in the list and remember genre names
 	genreFreq := make(map[int64]int)
+	genreNames := make(map[int64]string)
 	for _, lm := range listMovies {
 		for _, genre := range lm.Movie.Genres {
 			genreFreq[genre.ID]++
+			if genre.Name != "" {
+				genreNames[genre.ID] = genre.Name
+			}
 		}
 	}
 
@@ -287,19 +668,27 @@ func (s *recommendationService) scoreAndRankRecommendations(recommendations []tm
 	scored := make([]RecommendationItem, 0, len(aggregated))
 	for _, agg := range aggregated {
 		// Base score from TMDB popularity (normalized)
-		score := agg.result.Popularity / 100.0
+		popularityScore := agg.result.Popularity / 100.0
 
 		// Frequency bonus: appears in multiple seed recommendations
-		score += float64(agg.frequency-1) * 0.5
+		frequencyScore := 0.0
+		if agg.frequency > 1 {
+			frequencyScore = float64(agg.frequency-1) * 0.5
+		}
+
+		// Local bonus: titles that similar lists in this deployment also hold
+		localScore := agg.localScore * localSimilarityWeight
 
 		// Genre match bonus
-		genreMatches := 0
+		matchedGenres := make([]models.Genre, 0)
 		for _, genreID := range agg.result.GenreIDs {
 			if genreFreq[genreID] > 0 {
-				genreMatches++
+				matchedGenres = append(matchedGenres, models.Genre{ID: genreID, Name: genreNames[genreID]})
 			}
 		}
-		score += float64(genreMatches) * 0.3
+		genreScore := float64(len(matchedGenres)) * 0.3
+
+		score := popularityScore + frequencyScore + genreScore + localScore
 
 		// Create poster URL
 		var posterURL *string
@@ -320,10 +709,17 @@ func (s *recommendationService) scoreAndRankRecommendations(recommendations []tm
 			overview = &agg.result.Overview
 		}
 
-		// Convert genre IDs to Genre objects (simplified, just IDs for now)
+		// Convert genre IDs to Genre objects, using names known from the list
 		genres := make([]models.Genre, len(agg.result.GenreIDs))
 		for i, gid := range agg.result.GenreIDs {
-			genres[i] = models.Genre{ID: gid}
+			genres[i] = models.Genre{ID: gid, Name: genreNames[gid]}
+		}
+
+		seeds := make([]RecommendationSeed, 0, len(agg.seedIDs))
+		for _, seedID := range agg.seedIDs {
+			if seed, ok := seedsByID[seedID]; ok {
+				seeds = append(seeds, seed)
+			}
 		}
 
 		scored = append(scored, RecommendationItem{
@@ -335,6 +731,17 @@ func (s *recommendationService) scoreAndRankRecommendations(recommendations []tm
 			Score:      score,
 			Popularity: agg.result.Popularity,
 			Genres:     genres,
+			Reasons: RecommendationReasons{
+				Sources:         agg.sources,
+				BecauseYouLiked: seeds,
+				MatchedGenres:   matchedGenres,
+				Contributions: RecommendationFactors{
+					Popularity:      popularityScore,
+					SeedFrequency:   frequencyScore,
+					GenreMatch:      genreScore,
+					LocalSimilarity: localScore,
+				},
+			},
 		})
 	}
 
@@ -346,6 +753,15 @@ func (s *recommendationService) scoreAndRankRecommendations(recommendations []tm
 	return scored
 }
 
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
 // filterExistingMovies removes movies that are already in the list
 func (s *recommendationService) filterExistingMovies(recommendations []RecommendationItem, listMovies []models.ListMovie) []RecommendationItem {
 	existingIDs := make(map[int64]bool)
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoreAndRankRecommendations_Reasons(t *testing.T) {
+	service := &recommendationService{}
+
+	listMovies := []models.ListMovie{
+		{MovieID: 1, Movie: models.Movie{ID: 1, Title: "Alien", MediaType: "movie", Genres: []models.Genre{{ID: 27, Name: "Horror"}, {ID: 878, Name: "Science Fiction"}}}},
+		{MovieID: 2, Movie: models.Movie{ID: 2, Title: "Heat", MediaType: "movie", Genres: []models.Genre{{ID: 80, Name: "Crime"}}}},
+	}
+
+	recommendations := []tmdbRecommendationResult{
+		{ID: 10, Title: "Aliens", Popularity: 50, GenreIDs: []int64{27, 878, 28}, MediaType: "movie", SeedMovieID: 1},
+		{ID: 10, Title: "Aliens", Popularity: 50, GenreIDs: []int64{27, 878, 28}, MediaType: "movie", SeedMovieID: 2},
+		{ID: 11, Title: "Ronin", Popularity: 20, GenreIDs: []int64{80}, MediaType: "movie", SeedMovieID: 2},
+	}
+
+	items := service.scoreAndRankRecommendations(recommendations, listMovies, listMovies)
+
+	assert.Len(t, items, 2)
+	top := items[0]
+	assert.Equal(t, int64(10), top.ID)
+	assert.Len(t, top.Reasons.BecauseYouLiked, 2)
+	assert.Equal(t, "Alien", top.Reasons.BecauseYouLiked[0].Title)
+	assert.Equal(t, "Heat", top.Reasons.BecauseYouLiked[1].Title)
+	assert.Equal(t, []models.Genre{{ID: 27, Name: "Horror"}, {ID: 878, Name: "Science Fiction"}}, top.Reasons.MatchedGenres)
+	assert.InDelta(t, 0.5, top.Reasons.Contributions.Popularity, 1e-9)
+	assert.InDelta(t, 0.5, top.Reasons.Contributions.SeedFrequency, 1e-9)
+	assert.InDelta(t, 0.6, top.Reasons.Contributions.GenreMatch, 1e-9)
+
+	factors := top.Reasons.Contributions
+	assert.InDelta(t, top.Score, factors.Popularity+factors.SeedFrequency+factors.GenreMatch, 1e-9)
+}
+
+func TestScoreAndRankRecommendations_UnknownSeedIgnored(t *testing.T) {
+	service := &recommendationService{}
+
+	recommendations := []tmdbRecommendationResult{
+		{ID: 10, Title: "Aliens", Popularity: 10, MediaType: "movie", SeedMovieID: 99},
+	}
+
+	items := service.scoreAndRankRecommendations(recommendations, nil, nil)
+
+	assert.Len(t, items, 1)
+	assert.Empty(t, items[0].Reasons.BecauseYouLiked)
+	assert.Empty(t, items[0].Reasons.MatchedGenres)
+}
+
+func TestApplyFeedback(t *testing.T) {
+	items := []RecommendationItem{
+		{ID: 1, Score: 3},
+		{ID: 2, Score: 2},
+		{ID: 3, Score: 1},
+	}
+	feedback := []models.RecommendationFeedback{
+		{UserID: 7, MovieID: 2, Action: models.FeedbackDismissed},
+		{UserID: 8, MovieID: 1, Action: models.FeedbackNotInterested},
+		{UserID: 9, MovieID: 1, Action: models.FeedbackNotInterested},
+	}
+
+	result := applyFeedback(items, feedback, 7)
+
+	assert.Len(t, result, 2)
+	assert.Equal(t, int64(3), result[0].ID)
+	assert.Equal(t, int64(1), result[1].ID)
+	assert.InDelta(t, 0.75, result[1].Score, 1e-9)
+	// Cached input must stay untouched
+	assert.Equal(t, float64(3), items[0].Score)
+}
+
+func TestApplyFeedback_KeepsGroupOrder(t *testing.T) {
+	high, low := 0.9, 0.4
+	items := []RecommendationItem{
+		{ID: 1, Score: 1, GroupScore: &high},
+		{ID: 2, Score: 5, GroupScore: &low},
+	}
+	feedback := []models.RecommendationFeedback{
+		{UserID: 8, MovieID: 1, Action: models.FeedbackNotInterested},
+	}
+
+	result := applyFeedback(items, feedback, 7)
+
+	assert.Equal(t, int64(1), result[0].ID)
+	assert.InDelta(t, 0.45, *result[0].GroupScore, 1e-9)
+	assert.InDelta(t, 0.9, *items[0].GroupScore, 1e-9)
+}
+
+func TestApplyFeedback_NoFeedback(t *testing.T) {
+	items := []RecommendationItem{{ID: 1, Score: 3}}
+
+	result := applyFeedback(items, nil, 7)
+
+	assert.Equal(t, items, result)
+}
+
+func TestScoreAndRankRecommendations_BlendsLocalCandidates(t *testing.T) {
+	service := &recommendationService{}
+	seeds := []models.ListMovie{{MovieID: 1, Movie: models.Movie{ID: 1, Title: "Alien", MediaType: "movie"}}}
+
+	recommendations := []tmdbRecommendationResult{
+		{ID: 10, Title: "Aliens", Popularity: 50, MediaType: "movie", SeedMovieID: 1, Source: RecommendationSourceTMDB},
+		{ID: 10, Title: "Aliens (local)", MediaType: "movie", SeedMovieID: 1, Source: RecommendationSourceLocal, LocalScore: 0.5},
+		{ID: 20, Title: "Solaris", MediaType: "movie", SeedMovieID: 1, Source: RecommendationSourceLocal, LocalScore: 0.9},
+	}
+
+	items := service.scoreAndRankRecommendations(recommendations, seeds, seeds)
+
+	assert.Len(t, items, 2)
+	assert.Equal(t, int64(20), items[0].ID)
+	assert.Equal(t, []string{RecommendationSourceLocal}, items[0].Reasons.Sources)
+	assert.InDelta(t, 1.8, items[0].Reasons.Contributions.LocalSimilarity, 1e-9)
+	assert.Equal(t, float64(0), items[0].Reasons.Contributions.SeedFrequency)
+
+	blended := items[1]
+	assert.Equal(t, "Aliens", blended.Title)
+	assert.Equal(t, []string{RecommendationSourceTMDB, RecommendationSourceLocal}, blended.Reasons.Sources)
+	assert.InDelta(t, 1.5, blended.Score, 1e-9)
+	assert.Len(t, blended.Reasons.BecauseYouLiked, 1)
+}
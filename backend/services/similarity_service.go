@@ -0,0 +1,157 @@
+package services
+
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+)
+
+// SimilarityService maintains the item-item model built from every list in the deployment
+type SimilarityService interface {
+	Rebuild() (int, error)
+	StartRefresher(interval time.Duration) (stop func())
+}
+
+type similarityService struct {
+	similarities daos.MovieSimilarityDAO
+	mu           sync.Mutex
+}
+
+func NewSimilarityService(similarities daos.MovieSimilarityDAO) SimilarityService {
+	return &similarityService{similarities: similarities}
+}
+
+const (
+	// Pairs seen together in fewer lists than this are treated as noise
+	minSimilarityCoOccurrences = 2
+	// Neighbours kept per title
+	maxSimilarNeighbours = 20
+)
+
+// Rebuild recomputes all similarities and replaces the stored model, returning the number of pairs
+func (s *similarityService) Rebuild() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	signals, err := s.similarities.FindListMovieSignals()
+	if err != nil {
+		return 0, err
+	}
+	similarities := computeItemSimilarities(signals, minSimilarityCoOccurrences, maxSimilarNeighbours, time.Now())
+	if err := s.similarities.ReplaceAll(similarities); err != nil {
+		return 0, err
+	}
+	return len(similarities), nil
+}
+
+// StartRefresher rebuilds the model immediately and then on every interval until stopped
+func (s *similarityService) StartRefresher(interval time.Duration) func() {
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if count, err := s.Rebuild(); err != nil {
+				log.Printf("similarity_rebuild failed: %v", err)
+			} else {
+				log.Printf("similarity_rebuild success pairs=%d", count)
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// signalWeight turns a list's average rating into how strongly the list endorses a title.
+// Unrated titles count as a neutral endorsement.
+func signalWeight(avgRating *float64) float64 {
+	if avgRating == nil {
+		return 1
+	}
+	return math.Max(*avgRating, 1) / 5.5
+}
+
+// computeItemSimilarities builds a cosine similarity between titles, treating each list as a
+// rater whose weight for a title comes from its members' ratings
+func computeItemSimilarities(signals []daos.ListMovieSignal, minCoOccurrences int, maxNeighbours int, now time.Time) []models.MovieSimilarity {
+	byList := make(map[int64]map[int64]float64)
+	norms := make(map[int64]float64)
+	for _, signal := range signals {
+		w := signalWeight(signal.AvgRating)
+		if byList[signal.ListID] == nil {
+			byList[signal.ListID] = make(map[int64]float64)
+		}
+		byList[signal.ListID][signal.MovieID] = w
+		norms[signal.MovieID] += w * w
+	}
+
+	type pairKey struct{ a, b int64 }
+	dots := make(map[pairKey]float64)
+	counts := make(map[pairKey]int)
+	for _, movies := range byList {
+		ids := make([]int64, 0, len(movies))
+		for id := range movies {
+			ids = append(ids, id)
+		}
+		for i := 0; i < len(ids); i++ {
+			for j := 0; j < len(ids); j++ {
+				if i == j {
+					continue
+				}
+				key := pairKey{ids[i], ids[j]}
+				dots[key] += movies[ids[i]] * movies[ids[j]]
+				counts[key]++
+			}
+		}
+	}
+
+	neighbours := make(map[int64][]models.MovieSimilarity)
+	for key, dot := range dots {
+		if counts[key] < minCoOccurrences {
+			continue
+		}
+		denominator := math.Sqrt(norms[key.a]) * math.Sqrt(norms[key.b])
+		if denominator == 0 {
+			continue
+		}
+		neighbours[key.a] = append(neighbours[key.a], models.MovieSimilarity{
+			MovieID:        key.a,
+			SimilarMovieID: key.b,
+			Score:          dot / denominator,
+			CoOccurrences:  counts[key],
+			UpdatedAt:      now,
+		})
+	}
+
+	movieIDs := make([]int64, 0, len(neighbours))
+	for id := range neighbours {
+		movieIDs = append(movieIDs, id)
+	}
+	sort.Slice(movieIDs, func(i, j int) bool { return movieIDs[i] < movieIDs[j] })
+
+	result := make([]models.MovieSimilarity, 0)
+	for _, id := range movieIDs {
+		list := neighbours[id]
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			return list[i].SimilarMovieID < list[j].SimilarMovieID
+		})
+		if maxNeighbours > 0 && len(list) > maxNeighbours {
+			list = list[:maxNeighbours]
+		}
+		result = append(result, list...)
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/stretchr/testify/assert"
+)
+
+func avg(v float64) *float64 {
+	return &v
+}
+
+func TestComputeItemSimilarities(t *testing.T) {
+	signals := []daos.ListMovieSignal{
+		{ListID: 1, MovieID: 10}, {ListID: 1, MovieID: 20}, {ListID: 1, MovieID: 30},
+		{ListID: 2, MovieID: 10}, {ListID: 2, MovieID: 20},
+		{ListID: 3, MovieID: 10}, {ListID: 3, MovieID: 30, AvgRating: avg(2)},
+		{ListID: 4, MovieID: 40},
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	sims := computeItemSimilarities(signals, 2, 20, now)
+
+	byPair := make(map[[2]int64]float64)
+	for _, sim := range sims {
+		byPair[[2]int64{sim.MovieID, sim.SimilarMovieID}] = sim.Score
+		assert.Equal(t, now, sim.UpdatedAt)
+		assert.GreaterOrEqual(t, sim.CoOccurrences, 2)
+	}
+
+	// 10 and 20 always appear together with neutral weights
+	assert.InDelta(t, 0.816, byPair[[2]int64{10, 20}], 0.001)
+	assert.Equal(t, byPair[[2]int64{10, 20}], byPair[[2]int64{20, 10}])
+	// 10 and 30 co-occur twice but 30 was rated poorly in one list
+	assert.Less(t, byPair[[2]int64{10, 30}], byPair[[2]int64{10, 20}])
+	// 20 and 30 only share one list and 40 shares none
+	_, found := byPair[[2]int64{20, 30}]
+	assert.False(t, found)
+	for pair := range byPair {
+		assert.NotEqual(t, int64(40), pair[0])
+	}
+}
+
+func TestComputeItemSimilarities_LimitsNeighbours(t *testing.T) {
+	signals := make([]daos.ListMovieSignal, 0)
+	for list := int64(1); list <= 2; list++ {
+		for movie := int64(1); movie <= 5; movie++ {
+			signals = append(signals, daos.ListMovieSignal{ListID: list, MovieID: movie})
+		}
+	}
+
+	sims := computeItemSimilarities(signals, 2, 2, time.Now())
+
+	counts := make(map[int64]int)
+	for _, sim := range sims {
+		counts[sim.MovieID]++
+	}
+	assert.Len(t, counts, 5)
+	for _, c := range counts {
+		assert.Equal(t, 2, c)
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrPushSubscriptionGone means the push service no longer knows the subscription
+	// (HTTP 404 or 410); it should be deleted
+	ErrPushSubscriptionGone = errors.New("push_subscription_gone")
+	ErrInvalidVAPIDKeys     = errors.New("invalid_vapid_keys")
+)
+
+const (
+	// Push services accept at most 4096 bytes of encrypted content per message
+	pushRecordSize = 4096
+	// MaxPushPayload leaves room for the encryption header, delimiter and tag
+	MaxPushPayload = pushRecordSize - 16 - 4 - 1 - 65 - 1 - 16
+	// How long the push service keeps a message for an offline device
+	pushTTL = 24 * time.Hour
+	// VAPID tokens may be valid for at most a day; renew well before that
+	vapidTokenLifetime = 12 * time.Hour
+)
+
+// VAPIDKeys identify this server to push services (RFC 8292). Keys are the base64url
+// encodings of the uncompressed P-256 public point and the private scalar, the format
+// browsers and other Web Push libraries use.
+type VAPIDKeys struct {
+	PublicKey  string
+	Subject    string
+	privateKey *ecdsa.PrivateKey
+}
+
+// ParseVAPIDKeys checks that the keys form a P-256 pair. Subject is a mailto: or
+// https: contact for the push service operator.
+func ParseVAPIDKeys(publicKey, privateKey, subject string) (*VAPIDKeys, error) {
+	rawPrivate, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, ErrInvalidVAPIDKeys
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(rawPrivate)
+	if err != nil {
+		return nil, ErrInvalidVAPIDKeys
+	}
+	rawPublic := ecdhKey.PublicKey().Bytes()
+	if publicKey != "" {
+		given, err := decodeBase64URL(publicKey)
+		if err != nil || !bytes.Equal(given, rawPublic) {
+			return nil, ErrInvalidVAPIDKeys
+		}
+	}
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, fmt.Errorf("%w: subject must be a mailto: or https: URL", ErrInvalidVAPIDKeys)
+	}
+
+	// rawPublic is 0x04 || X || Y
+	return &VAPIDKeys{
+		PublicKey: base64.RawURLEncoding.EncodeToString(rawPublic),
+		Subject:   subject,
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(rawPublic[1:33]),
+				Y:     new(big.Int).SetBytes(rawPublic[33:65]),
+			},
+			D: new(big.Int).SetBytes(rawPrivate),
+		},
+	}, nil
+}
+
+// GenerateVAPIDKeys creates a new key pair, returned as (public, private)
+func GenerateVAPIDKeys() (string, string, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// authorization returns the VAPID Authorization header for the endpoint's push service
+func (k *VAPIDKeys) authorization(endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidTokenLifetime).Unix(),
+		"sub": k.Subject,
+	})
+	signed, err := token.SignedString(k.privateKey)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + signed + ", k=" + k.PublicKey, nil
+}
+
+// PushTarget is where a push message goes: the subscription's endpoint and keys
+type PushTarget struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// PushSender delivers one encrypted message to a push service
+type PushSender interface {
+	Send(target PushTarget, payload []byte) error
+}
+
+type webPushSender struct {
+	keys   *VAPIDKeys
+	client *http.Client
+}
+
+// NewWebPushSender signs and encrypts messages with the keys. With a nil client it uses
+// one that only connects to public addresses, or also to loopback when allowLocal is set
+// for the mock push service.
+func NewWebPushSender(keys *VAPIDKeys, client *http.Client, allowLocal bool) PushSender {
+	if client == nil {
+		allowed := isPublicIP
+		if allowLocal {
+			allowed = func(ip net.IP) bool { return ip.IsLoopback() || isPublicIP(ip) }
+		}
+		client = newOutboundClient(10*time.Second, allowed)
+	}
+	return &webPushSender{keys: keys, client: client}
+}
+
+func (s *webPushSender) Send(target PushTarget, payload []byte) error {
+	if len(payload) > MaxPushPayload {
+		return fmt.Errorf("push payload of %d bytes exceeds %d", len(payload), MaxPushPayload)
+	}
+	body, err := encryptPushPayload(target, payload)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.keys.authorization(target.Endpoint, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(pushTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrPushSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("push service responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encryptPushPayload encrypts the payload for the subscription as a single aes128gcm
+// record (RFC 8291, RFC 8188)
+func encryptPushPayload(target PushTarget, payload []byte) ([]byte, error) {
+	uaPublicRaw, err := decodeBase64URL(target.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64URL(target.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, errors.New("invalid auth secret")
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	shared, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	cek, nonce, err := derivePushKeys(shared, uaPublicRaw, asPublic, authSecret, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	// 0x02 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, pushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// derivePushKeys computes the content encryption key and nonce from the ECDH secret of
+// the user agent's and the application server's keys. The receiving side derives the
+// same values, which is how the mock push service decrypts messages.
+func derivePushKeys(shared, uaPublic, asPublic, authSecret, salt []byte) ([]byte, []byte, error) {
+	keyInfo := "WebPush: info\x00" + string(uaPublic) + string(asPublic)
+	prkKey, err := hkdf.Extract(sha256.New, shared, authSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// MockPushService stands in for a browser's push service during development and tests.
+// It hands out subscriptions whose endpoints point at itself, decrypts what it receives
+// with the subscription's keys and keeps the plaintext for inspection.
+type MockPushService struct {
+	baseURL string
+	mu      sync.Mutex
+	devices map[string]*mockPushDevice
+}
+
+type mockPushDevice struct {
+	private  *ecdh.PrivateKey
+	auth     []byte
+	gone     bool
+	messages [][]byte
+}
+
+// NewMockPushService serves endpoints of the form baseURL/{id}
+func NewMockPushService(baseURL string) *MockPushService {
+	return &MockPushService{baseURL: strings.TrimRight(baseURL, "/"), devices: make(map[string]*mockPushDevice)}
+}
+
+// NewSubscription creates a device and returns what a browser would hand the app
+func (m *MockPushService) NewSubscription() (PushTarget, error) {
+	private, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return PushTarget{}, err
+	}
+	auth := make([]byte, 16)
+	if _, err := rand.Read(auth); err != nil {
+		return PushTarget{}, err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return PushTarget{}, err
+	}
+
+	m.mu.Lock()
+	m.devices[id] = &mockPushDevice{private: private, auth: auth}
+	m.mu.Unlock()
+	return PushTarget{
+		Endpoint: m.baseURL + "/" + id,
+		P256dh:   base64.RawURLEncoding.EncodeToString(private.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}, nil
+}
+
+// Messages returns the decrypted payloads the device received, oldest first
+func (m *MockPushService) Messages(id string) ([][]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	device, ok := m.devices[id]
+	if !ok {
+		return nil, false
+	}
+	return append([][]byte{}, device.messages...), true
+}
+
+// Expire makes the device answer 410 Gone, like a subscription the user revoked
+func (m *MockPushService) Expire(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	device, ok := m.devices[id]
+	if ok {
+		device.gone = true
+	}
+	return ok
+}
+
+// ServeHTTP accepts a push message for the device named by the last path segment
+func (m *MockPushService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Header.Get("Content-Encoding") != "aes128gcm" || !strings.HasPrefix(r.Header.Get("Authorization"), "vapid t=") {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	id := path.Base(r.URL.Path)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	device, ok := m.devices[id]
+	switch {
+	case !ok:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	case device.gone:
+		w.WriteHeader(http.StatusGone)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, pushRecordSize+1))
+	if err != nil || len(body) > pushRecordSize {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		return
+	}
+	plaintext, err := device.decrypt(body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	device.messages = append(device.messages, plaintext)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// decrypt reverses encryptPushPayload with the device's private key
+func (d *mockPushDevice) decrypt(body []byte) ([]byte, error) {
+	const fixedHeader = 16 + 4 + 1
+	if len(body) < fixedHeader {
+		return nil, errors.New("short push message")
+	}
+	salt := body[:16]
+	keyLen := int(body[20])
+	if len(body) < fixedHeader+keyLen {
+		return nil, errors.New("short push message")
+	}
+	asPublicRaw := body[fixedHeader : fixedHeader+keyLen]
+	ciphertext := body[fixedHeader+keyLen:]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicRaw)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := d.private.ECDH(asPublic)
+	if err != nil {
+		return nil, err
+	}
+	cek, nonce, err := derivePushKeys(shared, d.private.PublicKey().Bytes(), asPublicRaw, d.auth, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Strip the padding delimiter of the last record
+	if len(plaintext) == 0 || plaintext[len(plaintext)-1] != 0x02 {
+		return nil, errors.New("missing record delimiter")
+	}
+	return plaintext[:len(plaintext)-1], nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/8bury/list2gether/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVAPIDKeys(t *testing.T) *VAPIDKeys {
+	public, private, err := GenerateVAPIDKeys()
+	assert.NoError(t, err)
+	keys, err := ParseVAPIDKeys(public, private, "mailto:admin@example.com")
+	assert.NoError(t, err)
+	return keys
+}
+
+func TestWebPushSenderDeliversToMockService(t *testing.T) {
+	mock := NewMockPushService("")
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	mock.baseURL = server.URL + "/push"
+
+	keys := newTestVAPIDKeys(t)
+	sender := NewWebPushSender(keys, server.Client(), false)
+	target, err := mock.NewSubscription()
+	assert.NoError(t, err)
+
+	payload := []byte(`{"title":"Sexta","body":"ana adicionou Alien"}`)
+	assert.NoError(t, sender.Send(target, payload))
+
+	messages, ok := mock.Messages(path.Base(target.Endpoint))
+	assert.True(t, ok)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, payload, messages[0])
+
+	// An expired subscription is reported so it can be pruned
+	mock.Expire(path.Base(target.Endpoint))
+	assert.ErrorIs(t, sender.Send(target, payload), ErrPushSubscriptionGone)
+
+	unknown := target
+	unknown.Endpoint = server.URL + "/push/unknown"
+	assert.ErrorIs(t, sender.Send(unknown, payload), ErrPushSubscriptionGone)
+
+	assert.Error(t, sender.Send(target, make([]byte, MaxPushPayload+1)))
+}
+
+func TestVAPIDAuthorization(t *testing.T) {
+	keys := newTestVAPIDKeys(t)
+	now := time.Now()
+
+	header, err := keys.authorization("https://fcm.googleapis.com/fcm/send/abc", now)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(header, "vapid t="))
+	token, publicKey, ok := strings.Cut(strings.TrimPrefix(header, "vapid t="), ", k=")
+	assert.True(t, ok)
+	assert.Equal(t, keys.PublicKey, publicKey)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return &keys.privateKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://fcm.googleapis.com", claims["aud"])
+	assert.Equal(t, "mailto:admin@example.com", claims["sub"])
+}
+
+func TestParseVAPIDKeys(t *testing.T) {
+	public, private, err := GenerateVAPIDKeys()
+	assert.NoError(t, err)
+	otherPublic, _, err := GenerateVAPIDKeys()
+	assert.NoError(t, err)
+
+	_, err = ParseVAPIDKeys(otherPublic, private, "mailto:admin@example.com")
+	assert.ErrorIs(t, err, ErrInvalidVAPIDKeys)
+	_, err = ParseVAPIDKeys(public, private, "admin@example.com")
+	assert.ErrorIs(t, err, ErrInvalidVAPIDKeys)
+	_, err = ParseVAPIDKeys(public, "not-a-key", "mailto:admin@example.com")
+	assert.ErrorIs(t, err, ErrInvalidVAPIDKeys)
+
+	// The public key is optional and derived from the private one
+	keys, err := ParseVAPIDKeys("", private, "https://list2gether.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, public, keys.PublicKey)
+}
+
+func TestWebPushSenderRefusesPrivateTargets(t *testing.T) {
+	mock := NewMockPushService("")
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	mock.baseURL = server.URL + "/push"
+	keys := newTestVAPIDKeys(t)
+	target, err := mock.NewSubscription()
+	assert.NoError(t, err)
+	payload := []byte(`{"title":"Sexta"}`)
+
+	// The test server listens on loopback, which only the mock push setup may reach
+	err = NewWebPushSender(keys, nil, false).Send(target, payload)
+	assert.ErrorIs(t, err, errTargetNotAllowed)
+
+	assert.NoError(t, NewWebPushSender(keys, nil, true).Send(target, payload))
+}
+
+func TestValidatePushSubscription(t *testing.T) {
+	mock := NewMockPushService("https://push.example.com/send")
+	target, err := mock.NewSubscription()
+	assert.NoError(t, err)
+	valid := PushSubscriptionInput{Endpoint: target.Endpoint, P256dh: target.P256dh, Auth: target.Auth}
+
+	assert.NoError(t, validatePushSubscription(valid, false))
+
+	local := valid
+	local.Endpoint = "http://localhost:8080/api/push/mock/endpoints/abc"
+	assert.ErrorIs(t, validatePushSubscription(local, false), ErrInvalidPushSubscription)
+	assert.NoError(t, validatePushSubscription(local, true))
+
+	for _, endpoint := range []string{"https://10.0.0.5/send/abc", "https://169.254.169.254/latest", "https://[fd00::1]/send"} {
+		private := valid
+		private.Endpoint = endpoint
+		assert.ErrorIs(t, validatePushSubscription(private, true), ErrInvalidPushSubscription, endpoint)
+	}
+
+	plain := valid
+	plain.Endpoint = "http://push.example.com/send/abc"
+	assert.ErrorIs(t, validatePushSubscription(plain, true), ErrInvalidPushSubscription)
+
+	badKey := valid
+	badKey.P256dh = "AAAA"
+	assert.ErrorIs(t, validatePushSubscription(badKey, false), ErrInvalidPushSubscription)
+
+	badAuth := valid
+	badAuth.Auth = "AAAA"
+	assert.ErrorIs(t, validatePushSubscription(badAuth, false), ErrInvalidPushSubscription)
+}
+
+func TestBuildPushMessage(t *testing.T) {
+	listID := int64(7)
+	titleAdded, err := json.Marshal(models.TitleAddedPayload{ListID: 7, ListName: "Sexta", MovieID: 42, Title: "Alien"})
+	assert.NoError(t, err)
+
+	message, err := buildPushMessage(models.Notification{
+		Type:    models.NotificationTitleAdded,
+		ListID:  &listID,
+		Payload: string(titleAdded),
+	}, models.LanguageEnglish, "ana")
+	assert.NoError(t, err)
+	assert.Equal(t, "Sexta", message.Title)
+	assert.Equal(t, "ana added Alien", message.Body)
+	assert.Equal(t, "/list/7", message.URL)
+	assert.Equal(t, "title-7-42", message.Tag)
+
+	mention, err := json.Marshal(models.MentionPayload{ListID: 7, CommentID: 9, Excerpt: "@bia olha esse"})
+	assert.NoError(t, err)
+	message, err = buildPushMessage(models.Notification{
+		Type:    models.NotificationMention,
+		Payload: string(mention),
+	}, models.LanguagePortuguese, "ana")
+	assert.NoError(t, err)
+	assert.Equal(t, "ana mencionou você", message.Title)
+	assert.Equal(t, "@bia olha esse", message.Body)
+
+	_, err = buildPushMessage(models.Notification{Type: models.NotificationComment, Payload: "{}"}, models.LanguagePortuguese, "ana")
+	assert.Error(t, err)
+}
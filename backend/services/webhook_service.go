@@ -0,0 +1,686 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/8bury/list2gether/daos"
+	"github.com/8bury/list2gether/models"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook_not_found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook_delivery_not_found")
+	ErrWebhookOwnerOnly        = errors.New("webhook_owner_only")
+	ErrInvalidWebhookURL       = errors.New("invalid_webhook_url")
+	ErrInvalidWebhookEvents    = errors.New("invalid_webhook_events")
+	ErrTooManyWebhooks         = errors.New("too_many_webhooks")
+	// errTargetNotAllowed is returned when a webhook or push delivery would connect to
+	// an address it is not allowed to reach
+	errTargetNotAllowed = errors.New("target address is not allowed")
+)
+
+const (
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the body,
+	// keyed with the webhook's secret
+	WebhookSignatureHeader = "X-List2gether-Signature"
+	WebhookEventHeader     = "X-List2gether-Event"
+	// WebhookDeliveryHeader carries the event id, which stays the same across retries
+	WebhookDeliveryHeader = "X-List2gether-Delivery"
+	// MaxWebhooksPerList caps how many URLs an owner can register on one list
+	MaxWebhooksPerList = 10
+	// WebhookMaxAttempts is how many times a delivery is tried before it is given up
+	WebhookMaxAttempts = 8
+	// WebhookLogRetention is how long finished deliveries stay in the log
+	WebhookLogRetention = 30 * 24 * time.Hour
+
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+	webhookTimeout     = 10 * time.Second
+	webhookBatchSize   = 50
+	// webhookWorkers is how many lists have deliveries sent at the same time
+	webhookWorkers = 8
+	// webhookMaxPerList caps one list's deliveries in a batch, so a list with a backlog
+	// does not fill the batch for everyone else
+	webhookMaxPerList = 5
+)
+
+// WebhookInput is what the owner sets on a webhook. On update a nil Active or empty
+// URL or Events keeps the current value.
+type WebhookInput struct {
+	URL          string
+	Events       []models.WebhookEventType
+	Active       *bool
+	RotateSecret bool
+}
+
+// WebhookEvent is a change to a list that webhooks may subscribe to
+type WebhookEvent struct {
+	Type    models.WebhookEventType
+	ListID  int64
+	ActorID int64
+	// MovieID is the title the event is about; 0 for comments on the discussion board
+	MovieID        int64
+	PreviousStatus *models.MovieStatus
+	Comment        *models.Comment
+}
+
+// WebhookDispatcher queues an event for the list's webhooks without blocking the caller
+type WebhookDispatcher interface {
+	Dispatch(event WebhookEvent)
+}
+
+type WebhookService interface {
+	WebhookDispatcher
+	CreateWebhook(listID, userID int64, input WebhookInput) (*models.ListWebhook, string, error)
+	ListWebhooks(listID, userID int64) ([]models.ListWebhook, error)
+	UpdateWebhook(listID, userID, webhookID int64, input WebhookInput) (*models.ListWebhook, string, error)
+	DeleteWebhook(listID, userID, webhookID int64) error
+	ListDeliveries(listID, userID, webhookID int64, limit, offset int) ([]models.WebhookDelivery, int64, error)
+	Redeliver(listID, userID, webhookID, deliveryID int64) (*models.WebhookDelivery, error)
+	DeliverDue(now time.Time) (int, error)
+	StartDeliveryWorker(interval time.Duration) (stop func())
+}
+
+type webhookService struct {
+	webhooks daos.WebhookDAO
+	lists    daos.MovieListDAO
+	users    daos.UserDAO
+	client   *http.Client
+	// allowPrivate accepts URLs on loopback and private networks, for self-hosted
+	// instances that call home automation on the LAN
+	allowPrivate bool
+}
+
+// NewWebhookService manages list webhooks and delivers their events. With a nil client
+// deliveries use one that refuses to connect to private addresses unless allowPrivate
+// is set.
+func NewWebhookService(webhooks daos.WebhookDAO, lists daos.MovieListDAO, users daos.UserDAO, client *http.Client, allowPrivate bool) WebhookService {
+	if client == nil {
+		client = newWebhookClient(allowPrivate)
+	}
+	return &webhookService{webhooks: webhooks, lists: lists, users: users, client: client, allowPrivate: allowPrivate}
+}
+
+// webhookPayload is the JSON body posted for every event
+type webhookPayload struct {
+	ID             string                  `json:"id"`
+	Event          models.WebhookEventType `json:"event"`
+	CreatedAt      time.Time               `json:"created_at"`
+	List           webhookList             `json:"list"`
+	Actor          *webhookActor           `json:"actor"`
+	Item           map[string]interface{}  `json:"item,omitempty"`
+	PreviousStatus *models.MovieStatus     `json:"previous_status,omitempty"`
+	Comment        *webhookComment         `json:"comment,omitempty"`
+}
+
+type webhookList struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type webhookActor struct {
+	ID        int64   `json:"id"`
+	Username  string  `json:"username"`
+	AvatarURL *string `json:"avatar_url"`
+}
+
+type webhookComment struct {
+	ID        int64     `json:"id"`
+	MovieID   *int64    `json:"movie_id"`
+	ParentID  *int64    `json:"parent_id"`
+	UserID    int64     `json:"user_id"`
+	Content   string    `json:"content"`
+	Spoiler   bool      `json:"spoiler"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (s *webhookService) CreateWebhook(listID, userID int64, input WebhookInput) (*models.ListWebhook, string, error) {
+	if err := s.checkOwner(listID, userID); err != nil {
+		return nil, "", err
+	}
+	if err := validateWebhookURL(input.URL, s.allowPrivate); err != nil {
+		return nil, "", err
+	}
+	events, err := joinWebhookEvents(input.Events)
+	if err != nil {
+		return nil, "", err
+	}
+	count, err := s.webhooks.CountByList(listID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= MaxWebhooksPerList {
+		return nil, "", ErrTooManyWebhooks
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, "", err
+	}
+	webhook := &models.ListWebhook{
+		ListID:    listID,
+		URL:       strings.TrimSpace(input.URL),
+		Secret:    secret,
+		Events:    events,
+		Active:    input.Active == nil || *input.Active,
+		CreatedBy: userID,
+	}
+	if err := s.webhooks.Create(webhook); err != nil {
+		return nil, "", err
+	}
+	return webhook, secret, nil
+}
+
+func (s *webhookService) ListWebhooks(listID, userID int64) ([]models.ListWebhook, error) {
+	if err := s.checkOwner(listID, userID); err != nil {
+		return nil, err
+	}
+	return s.webhooks.FindByList(listID)
+}
+
+// UpdateWebhook changes the webhook and returns the new secret when it was rotated
+func (s *webhookService) UpdateWebhook(listID, userID, webhookID int64, input WebhookInput) (*models.ListWebhook, string, error) {
+	webhook, err := s.findOwnedWebhook(listID, userID, webhookID)
+	if err != nil {
+		return nil, "", err
+	}
+	if strings.TrimSpace(input.URL) != "" {
+		if err := validateWebhookURL(input.URL, s.allowPrivate); err != nil {
+			return nil, "", err
+		}
+		webhook.URL = strings.TrimSpace(input.URL)
+	}
+	if input.Events != nil {
+		events, err := joinWebhookEvents(input.Events)
+		if err != nil {
+			return nil, "", err
+		}
+		webhook.Events = events
+	}
+	if input.Active != nil {
+		webhook.Active = *input.Active
+	}
+	var secret string
+	if input.RotateSecret {
+		if secret, err = randomHex(32); err != nil {
+			return nil, "", err
+		}
+		webhook.Secret = secret
+	}
+	if err := s.webhooks.Update(webhook); err != nil {
+		return nil, "", err
+	}
+	return webhook, secret, nil
+}
+
+func (s *webhookService) DeleteWebhook(listID, userID, webhookID int64) error {
+	if _, err := s.findOwnedWebhook(listID, userID, webhookID); err != nil {
+		return err
+	}
+	return s.webhooks.Delete(webhookID)
+}
+
+func (s *webhookService) ListDeliveries(listID, userID, webhookID int64, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	if _, err := s.findOwnedWebhook(listID, userID, webhookID); err != nil {
+		return nil, 0, err
+	}
+	return s.webhooks.FindDeliveries(webhookID, limit, offset)
+}
+
+// Redeliver queues the payload of a past delivery again, as a new entry in the log
+// with the same event id
+func (s *webhookService) Redeliver(listID, userID, webhookID, deliveryID int64) (*models.WebhookDelivery, error) {
+	if _, err := s.findOwnedWebhook(listID, userID, webhookID); err != nil {
+		return nil, err
+	}
+	previous, err := s.webhooks.FindDelivery(webhookID, deliveryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	now := time.Now().UTC()
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       previous.EventID,
+		Event:         previous.Event,
+		Payload:       previous.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+	}
+	deliveries := []models.WebhookDelivery{delivery}
+	if err := s.webhooks.CreateDeliveries(deliveries); err != nil {
+		return nil, err
+	}
+	return &deliveries[0], nil
+}
+
+func (s *webhookService) checkOwner(listID, userID int64) error {
+	if _, err := s.lists.FindByID(listID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrListNotFound
+		}
+		return err
+	}
+	membership, err := s.lists.FindMembership(listID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrForbiddenMembership
+		}
+		return err
+	}
+	if membership.Role != models.RoleOwner {
+		return ErrWebhookOwnerOnly
+	}
+	return nil
+}
+
+func (s *webhookService) findOwnedWebhook(listID, userID, webhookID int64) (*models.ListWebhook, error) {
+	if err := s.checkOwner(listID, userID); err != nil {
+		return nil, err
+	}
+	webhook, err := s.webhooks.FindByID(webhookID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	if webhook.ListID != listID {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) Dispatch(event WebhookEvent) {
+	// Building the payload takes a few queries; the request that caused the event does
+	// not wait for them
+	go func() {
+		if err := s.enqueue(event, time.Now().UTC()); err != nil {
+			log.Printf("webhook_enqueue failed list=%d event=%s: %v", event.ListID, event.Type, err)
+		}
+	}()
+}
+
+// enqueue stores one pending delivery per active webhook subscribed to the event
+func (s *webhookService) enqueue(event WebhookEvent, now time.Time) error {
+	webhooks, err := s.webhooks.FindActiveByList(event.ListID)
+	if err != nil {
+		return err
+	}
+	subscribed := make([]models.ListWebhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event.Type) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	list, err := s.lists.FindByID(event.ListID)
+	if err != nil {
+		return err
+	}
+	var actor *models.User
+	if event.ActorID != 0 {
+		if actor, err = s.users.FindByID(event.ActorID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	var item *models.ListMovie
+	if event.MovieID != 0 {
+		if item, err = s.lists.FindListMovieWithMovie(event.ListID, event.MovieID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	id, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(buildWebhookPayload(id, event, list, actor, item, now))
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(subscribed))
+	for _, webhook := range subscribed {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       id,
+			Event:         event.Type,
+			Payload:       string(body),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+	return s.webhooks.CreateDeliveries(deliveries)
+}
+
+func buildWebhookPayload(id string, event WebhookEvent, list *models.MovieList, actor *models.User, item *models.ListMovie, now time.Time) webhookPayload {
+	payload := webhookPayload{
+		ID:             id,
+		Event:          event.Type,
+		CreatedAt:      now,
+		List:           webhookList{ID: list.ID, Name: list.Name},
+		PreviousStatus: event.PreviousStatus,
+	}
+	if actor != nil {
+		payload.Actor = &webhookActor{ID: actor.ID, Username: actor.Username, AvatarURL: actor.AvatarURL}
+	}
+	if item != nil {
+		// Webhooks leave the app, so the members' emails stay out of the item
+		payload.Item = listMovieItem(*item, 0, false)
+	}
+	if c := event.Comment; c != nil {
+		payload.Comment = &webhookComment{
+			ID:        c.ID,
+			MovieID:   c.MovieID,
+			ParentID:  c.ParentID,
+			UserID:    c.UserID,
+			Content:   c.Content,
+			Spoiler:   c.Spoiler,
+			CreatedAt: c.CreatedAt,
+		}
+	}
+	return payload
+}
+
+// DeliverDue attempts every delivery whose turn has come and returns how many were
+// attempted. Lists are served by a bounded pool of workers, each sending one list's
+// deliveries in order, so a slow endpoint only holds up its own list.
+func (s *webhookService) DeliverDue(now time.Time) (int, error) {
+	due, err := s.webhooks.FindDueDeliveries(now, webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var order []int64
+	byList := make(map[int64][]*models.WebhookDelivery)
+	for i := range due {
+		listID := due[i].Webhook.ListID
+		if _, seen := byList[listID]; !seen {
+			order = append(order, listID)
+		}
+		if len(byList[listID]) < webhookMaxPerList {
+			byList[listID] = append(byList[listID], &due[i])
+		}
+	}
+
+	queue := make(chan []*models.WebhookDelivery)
+	var (
+		mu        sync.Mutex
+		attempted int
+		firstErr  error
+		wg        sync.WaitGroup
+	)
+	workers := min(webhookWorkers, len(order))
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for deliveries := range queue {
+				count, err := s.deliverInOrder(deliveries)
+				mu.Lock()
+				attempted += count
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, listID := range order {
+		queue <- byList[listID]
+	}
+	close(queue)
+	wg.Wait()
+	return attempted, firstErr
+}
+
+// deliverInOrder sends one list's deliveries one after another, stopping at the first
+// storage error
+func (s *webhookService) deliverInOrder(deliveries []*models.WebhookDelivery) (int, error) {
+	attempted := 0
+	for _, delivery := range deliveries {
+		// Another instance may be working through the same batch. The claim starts when
+		// the delivery is sent, as earlier ones in the list may have taken a while.
+		claimed, err := s.webhooks.ClaimDelivery(delivery, time.Now().UTC().Add(2*webhookTimeout))
+		if err != nil {
+			return attempted, err
+		}
+		if !claimed {
+			continue
+		}
+
+		var status *int
+		var sendErr error
+		if delivery.Webhook.Active {
+			status, sendErr = postWebhook(s.client, delivery.Webhook, delivery)
+		} else {
+			sendErr = errors.New("webhook is disabled")
+		}
+		recordWebhookAttempt(delivery, status, sendErr, time.Now().UTC())
+		if err := s.webhooks.SaveDeliveryAttempt(delivery); err != nil {
+			return attempted, err
+		}
+		if sendErr != nil {
+			log.Printf("webhook_delivery failed delivery=%d webhook=%d attempt=%d: %v", delivery.ID, delivery.WebhookID, delivery.Attempts, sendErr)
+		}
+		attempted++
+	}
+	return attempted, nil
+}
+
+// StartDeliveryWorker delivers due webhook events on every interval and prunes old
+// entries from the delivery log once a day, until stopped
+func (s *webhookService) StartDeliveryWorker(interval time.Duration) func() {
+	done := make(chan struct{})
+	var once sync.Once
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var lastPurge time.Time
+		for {
+			now := time.Now().UTC()
+			if _, err := s.DeliverDue(now); err != nil {
+				log.Printf("webhook_worker failed: %v", err)
+			}
+			if now.Sub(lastPurge) >= 24*time.Hour {
+				if count, err := s.webhooks.PurgeDeliveries(now.Add(-WebhookLogRetention)); err != nil {
+					log.Printf("webhook_purge failed: %v", err)
+				} else if count > 0 {
+					log.Printf("webhook_purge success deliveries=%d", count)
+				}
+				lastPurge = now
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() { once.Do(func() { close(done) }) }
+}
+
+// postWebhook signs and sends the delivery's payload, returning the response status
+// when the endpoint answered. Any status outside 2xx is an error.
+func postWebhook(client *http.Client, webhook models.ListWebhook, delivery *models.WebhookDelivery) (*int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "list2gether-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(WebhookDeliveryHeader, delivery.EventID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	status := resp.StatusCode
+	if status < 200 || status > 299 {
+		return &status, fmt.Errorf("endpoint answered %d", status)
+	}
+	return &status, nil
+}
+
+// SignWebhookPayload returns the signature header value for a body: "sha256=" followed
+// by the hex HMAC-SHA256 of the body keyed with the webhook's secret
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// recordWebhookAttempt applies the outcome of an attempt: success ends the delivery,
+// a failure schedules a retry with exponential backoff until attempts run out
+func recordWebhookAttempt(delivery *models.WebhookDelivery, status *int, sendErr error, now time.Time) {
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = status
+	if sendErr == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = nil
+		return
+	}
+
+	message := truncateRunes(sendErr.Error(), 500)
+	delivery.LastError = &message
+	if delivery.Attempts >= WebhookMaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+	next := now.Add(webhookBackoff(delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// webhookBackoff is the wait before the retry that follows the given attempt: 30s,
+// 1m, 2m, 4m and so on, capped at six hours
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return backoff
+}
+
+func joinWebhookEvents(events []models.WebhookEventType) (string, error) {
+	if len(events) == 0 {
+		return "", ErrInvalidWebhookEvents
+	}
+	seen := make(map[models.WebhookEventType]bool, len(events))
+	names := make([]string, 0, len(events))
+	for _, event := range events {
+		if !event.IsValid() {
+			return "", ErrInvalidWebhookEvents
+		}
+		if !seen[event] {
+			seen[event] = true
+			names = append(names, string(event))
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, ","), nil
+}
+
+// validateWebhookURL accepts http and https URLs. Unless private targets are allowed,
+// hosts that are loopback or private addresses are rejected up front; the client
+// checks the resolved address again when connecting.
+func validateWebhookURL(raw string, allowPrivate bool) error {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil || len(raw) > 2048 {
+		return ErrInvalidWebhookURL
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return ErrInvalidWebhookURL
+	}
+	if allowPrivate {
+		return nil
+	}
+	if isLoopbackHost(u.Hostname()) {
+		return ErrInvalidWebhookURL
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !isPublicIP(ip) {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast())
+}
+
+// newWebhookClient does not follow redirects or use a proxy, and unless allowPrivate
+// is set it refuses to connect to non-public addresses, whatever the host resolves to
+func newWebhookClient(allowPrivate bool) *http.Client {
+	if allowPrivate {
+		return newOutboundClient(webhookTimeout, nil)
+	}
+	return newOutboundClient(webhookTimeout, isPublicIP)
+}
+
+// newOutboundClient builds the client for requests to URLs users gave us. It does not
+// follow redirects or use a proxy, and with a non-nil allowed it checks the address
+// each connection is made to, so a name resolving to a private address is refused too.
+func newOutboundClient(timeout time.Duration, allowed func(net.IP) bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if allowed != nil {
+		dialer.ControlContext = func(_ context.Context, _, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !allowed(ip) {
+				return errTargetNotAllowed
+			}
+			return nil
+		}
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConnsPerHost:   2,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
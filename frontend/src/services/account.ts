@@ -0,0 +1,54 @@
+import { apiBaseUrl, requestJson, type ApiError, type ApiException } from './api'
+import { clearStoredAuth } from './auth_storage'
+
+export interface DeleteAccountBodyDTO {
+  password: string
+  // What happens to lists you own: handed to their longest-standing member or deleted
+  owned_lists?: 'transfer' | 'delete'
+  // Whether your comments stay under an anonymous name or lose their text
+  comments?: 'anonymize' | 'delete'
+}
+
+export interface DeleteAccountResponseDTO {
+  success: boolean
+  message?: string
+  transferred_lists: { list_id: number; new_owner_id: number }[]
+  deleted_list_ids: number[] | null
+  comments_deleted: boolean
+}
+
+// downloadAccountExport fetches the ZIP with all of the user's data and hands it to the
+// browser as a file download
+export async function downloadAccountExport(): Promise<void> {
+  const token = localStorage.getItem('access_token')
+  const res = await fetch(`${apiBaseUrl}/api/account/export`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+  if (!res.ok) {
+    const payload = (await res.json().catch(() => undefined)) as ApiError | undefined
+    const error = new Error(payload?.error || `Request failed with status ${res.status}`) as ApiException
+    error.payload = payload
+    error.status = res.status
+    throw error
+  }
+  const disposition = res.headers.get('content-disposition') || ''
+  const match = /filename="?([^";]+)"?/.exec(disposition)
+  const url = URL.createObjectURL(await res.blob())
+  const link = document.createElement('a')
+  link.href = url
+  link.download = match ? match[1] : 'list2gether-account.zip'
+  link.click()
+  URL.revokeObjectURL(url)
+}
+
+export async function deleteAccount(body: DeleteAccountBodyDTO): Promise<DeleteAccountResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  const res = await requestJson<DeleteAccountResponseDTO>('/api/account', {
+    method: 'DELETE',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body,
+  })
+  clearStoredAuth()
+  return res
+}
@@ -1,11 +1,13 @@
 import { requestJson } from './api'
 import { clearStoredAuth } from './auth_storage'
+import type { SupportedLanguage } from './preferences'
 
 export interface UserDTO {
   id: number
   username: string
   email: string
   avatar_url?: string
+  language?: SupportedLanguage
   created_at?: string
   updated_at?: string
 }
@@ -94,3 +96,33 @@ export async function updateProfile(body: UpdateProfileBodyDTO): Promise<UpdateP
 }
 
 
+export interface GenrePreferencesResponseDTO {
+  message?: string
+  genre_ids: number[]
+}
+
+export async function getGenrePreferences(): Promise<GenrePreferencesResponseDTO> {
+  const accessToken = localStorage.getItem('access_token')
+  return requestJson<GenrePreferencesResponseDTO>('/auth/preferences/genres', {
+    method: 'GET',
+    headers: accessToken ? { Authorization: `Bearer ${accessToken}` } : undefined,
+  })
+}
+
+export async function updateGenrePreferences(genreIds: number[]): Promise<GenrePreferencesResponseDTO> {
+  const accessToken = localStorage.getItem('access_token')
+  return requestJson<GenrePreferencesResponseDTO>('/auth/preferences/genres', {
+    method: 'PUT',
+    headers: accessToken ? { Authorization: `Bearer ${accessToken}` } : undefined,
+    body: { genre_ids: genreIds },
+  })
+}
+
+export async function updateLanguage(language: SupportedLanguage): Promise<{ message?: string; language: SupportedLanguage }> {
+  const accessToken = localStorage.getItem('access_token')
+  return requestJson<{ message?: string; language: SupportedLanguage }>('/auth/preferences/language', {
+    method: 'PUT',
+    headers: accessToken ? { Authorization: `Bearer ${accessToken}` } : undefined,
+    body: { language },
+  })
+}
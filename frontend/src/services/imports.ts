@@ -0,0 +1,95 @@
+import { apiBaseUrl, requestJson, type ApiError, type ApiException } from './api'
+import type { MovieStatus } from './lists'
+
+export type ImportFormat = 'letterboxd' | 'imdb' | 'trakt' | 'list2gether'
+
+export interface UnmatchedImportRowDTO {
+  line: number
+  title: string
+  year?: number
+  imdb_id?: string
+  reason: 'not_found' | 'missing_title' | 'lookup_failed' | 'unsupported'
+}
+
+export interface ListImportDTO {
+  id: number
+  list_id: number
+  user_id: number
+  format: ImportFormat
+  status: 'running' | 'completed' | 'failed'
+  total_rows: number
+  processed_rows: number
+  added_count: number
+  updated_count: number
+  unmatched_count: number
+  error?: string | null
+  created_at: string
+  updated_at: string
+  finished_at?: string | null
+  // Only returned when fetching a single import
+  unmatched?: UnmatchedImportRowDTO[]
+}
+
+// startListImport uploads an export file. The format is detected when left out; status
+// is given to titles the export does not mark as watched.
+export async function startListImport(
+  listId: number,
+  file: File,
+  options?: { format?: ImportFormat; status?: MovieStatus },
+): Promise<ListImportDTO> {
+  const token = localStorage.getItem('access_token')
+  const form = new FormData()
+  form.append('file', file)
+  if (options?.format) form.append('format', options.format)
+  if (options?.status) form.append('status', options.status)
+
+  const res = await fetch(`${apiBaseUrl}/api/lists/${listId}/imports`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: form,
+  })
+  const data = await res.json().catch(() => undefined)
+  if (!res.ok) {
+    const payload = data as ApiError | undefined
+    const error = new Error(payload?.error || `Request failed with status ${res.status}`) as ApiException
+    error.payload = payload
+    error.status = res.status
+    throw error
+  }
+  return (data as { import: ListImportDTO }).import
+}
+
+export async function getListImport(listId: number, importId: number): Promise<ListImportDTO> {
+  const token = localStorage.getItem('access_token')
+  const res = await requestJson<{ import: ListImportDTO }>(`/api/lists/${listId}/imports/${importId}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+  return res.import
+}
+
+export type ExportFormat = 'csv' | 'json' | 'letterboxd'
+
+// downloadListExport fetches the export and hands it to the browser as a file download
+export async function downloadListExport(listId: number, format: ExportFormat = 'csv'): Promise<void> {
+  const token = localStorage.getItem('access_token')
+  const res = await fetch(`${apiBaseUrl}/api/lists/${listId}/export?format=${format}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+  if (!res.ok) {
+    const payload = (await res.json().catch(() => undefined)) as ApiError | undefined
+    const error = new Error(payload?.error || `Request failed with status ${res.status}`) as ApiException
+    error.payload = payload
+    error.status = res.status
+    throw error
+  }
+  const disposition = res.headers.get('content-disposition') || ''
+  const match = /filename="?([^";]+)"?/.exec(disposition)
+  const url = URL.createObjectURL(await res.blob())
+  const link = document.createElement('a')
+  link.href = url
+  link.download = match ? match[1] : `list-${listId}.${format === 'json' ? 'json' : 'csv'}`
+  link.click()
+  URL.revokeObjectURL(url)
+}
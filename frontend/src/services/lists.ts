@@ -1,4 +1,4 @@
-import { requestJson } from './api'
+import { apiBaseUrl, requestJson } from './api'
 
 export interface UserListDTO {
   id: number
@@ -10,6 +10,14 @@ export interface UserListDTO {
   updated_at: string
   member_count: number
   movie_count: number
+  // changes by other members since you last marked the list as read
+  unread: UnreadCountsDTO
+}
+
+export interface UnreadCountsDTO {
+  comments: number
+  added_titles: number
+  status_changes: number
 }
 
 export interface ListsResponseDTO {
@@ -98,6 +106,21 @@ export async function leaveList(listId: number): Promise<void> {
   })
 }
 
+export interface InviteToListResponseDTO {
+  success: boolean
+  message: string
+  data: { list_id: number; user_id: number; username: string }
+}
+
+export async function inviteToList(listId: number, username: string): Promise<InviteToListResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<InviteToListResponseDTO>(`/api/lists/${listId}/invitations`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: { username },
+  })
+}
+
 
 export type MovieStatus = 'not_watched' | 'watching' | 'watched' | 'dropped'
 
@@ -159,6 +182,11 @@ export interface ListMovieItemDTO {
   average_rating?: number | null
   your_entry?: ListMovieUserEntryDTO | null
   user_entries?: ListMovieUserEntryDTO[]
+  reactions?: ReactionDTO[]
+  watched_by?: number[]
+  you_watched?: boolean
+  tags?: string[]
+  unread_comments?: number
   movie: MovieDTO
 }
 
@@ -192,6 +220,7 @@ export async function addListMovie(listId: number, body: AddListMovieBodyDTO): P
 export interface UpdateListMovieBodyDTO {
   status?: MovieStatus
   rating?: number | null
+  watched?: boolean
 }
 
 export interface UpdateListMovieResponseDTO {
@@ -212,6 +241,8 @@ export interface UpdateListMovieResponseDTO {
     new_entry?: ListMovieUserEntryDTO | null
     average_rating?: number | null
     your_entry?: ListMovieUserEntryDTO | null
+    watched_by?: number[]
+    you_watched?: boolean
   }
 }
 
@@ -233,6 +264,55 @@ export async function deleteListMovie(listId: number, movieId: number): Promise<
   })
 }
 
+// Removed titles can be restored until undo_until
+export interface RemovedListMovieDTO {
+  id: number
+  list_id: number
+  movie_id: number
+  status: MovieStatus
+  added_by?: number | null
+  added_by_user?: { id: number; username: string; avatar_url?: string | null } | null
+  added_at: string
+  display_order?: number | null
+  removed_by?: number | null
+  removed_by_user?: { id: number; username: string; avatar_url?: string | null } | null
+  removed_at: string
+  undo_until: string
+  movie: Pick<MovieDTO, 'id' | 'title' | 'original_title' | 'release_date' | 'poster_url' | 'media_type'>
+}
+
+export interface RestoreListMovieResponseDTO {
+  success: boolean
+  message: string
+  data: { list_id: number; movie_id: number; status: MovieStatus; display_order?: number | null; restored_at: string }
+}
+
+export async function getRemovedListMovies(listId: number): Promise<RemovedListMovieDTO[]> {
+  const token = localStorage.getItem('access_token')
+  const res = await requestJson<{ movies: RemovedListMovieDTO[]; count: number }>(`/api/lists/${listId}/movies/removed`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+  return res.movies
+}
+
+export async function restoreListMovie(listId: number, movieId: number): Promise<RestoreListMovieResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<RestoreListMovieResponseDTO>(`/api/lists/${listId}/movies/${movieId}/restore`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+// Restores the title the current user removed most recently
+export async function undoLastRemoval(listId: number): Promise<RestoreListMovieResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<RestoreListMovieResponseDTO>(`/api/lists/${listId}/movies/removed/undo`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
 export interface ReorderMoviesBodyDTO {
   movie_orders: Array<{
     movie_id: number
@@ -249,6 +329,43 @@ export async function reorderMovies(listId: number, body: ReorderMoviesBodyDTO):
   })
 }
 
+// move takes the titles, with their ratings, notes, watches, comments and tags, out of
+// the source list and into the target list
+export type BulkOperation = 'set_status' | 'remove' | 'copy' | 'move' | 'tag' | 'untag'
+
+export interface BulkMoviesBodyDTO {
+  operation: BulkOperation
+  movie_ids: number[]
+  // Required by set_status
+  status?: MovieStatus
+  // Required by copy and move
+  target_list_id?: number
+  // Required by tag and untag
+  tag?: string
+}
+
+export interface BulkMoviesResponseDTO {
+  success: boolean
+  operation: BulkOperation
+  results: Array<{
+    movie_id: number
+    outcome: 'applied' | 'unchanged' | 'not_in_list' | 'already_in_target'
+    previous_status?: MovieStatus
+  }>
+  applied: number
+  skipped: number
+}
+
+// Applies one operation to many titles of the list in a single transaction
+export async function bulkUpdateMovies(listId: number, body: BulkMoviesBodyDTO): Promise<BulkMoviesResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<BulkMoviesResponseDTO>(`/api/lists/${listId}/movies/bulk`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body,
+  })
+}
+
 export interface SearchListMoviesResponseDTO {
   movies: ListMovieItemDTO[]
   count: number
@@ -266,11 +383,236 @@ export async function searchListMovies(listId: number, query: string, params?: {
   })
 }
 
+// Marks the whole list as read, or only a movie's comments when movieId is given
+export async function markListRead(listId: number, movieId?: number): Promise<void> {
+  const token = localStorage.getItem('access_token')
+  await requestJson<void>(movieId ? `/api/lists/${listId}/movies/${movieId}/read` : `/api/lists/${listId}/read`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+// Live list stream types and functions
+export type ListStreamEventType =
+  | 'movie_added'
+  | 'movie_removed'
+  | 'movie_restored'
+  | 'movie_updated'
+  | 'movies_reordered'
+  | 'comment_created'
+  | 'comment_updated'
+  | 'comment_deleted'
+  | 'member_joined'
+  | 'member_left'
+  | 'list_deleted'
+  | 'presence_changed'
+  | 'list_imported'
+  | 'movies_bulk_updated'
+
+export interface ListStreamEventDTO {
+  type: ListStreamEventType
+  list_id: number
+  actor_id: number
+  data?: Record<string, unknown>
+  occurred_at: string
+}
+
+const STREAM_RECONNECT_DELAY_MS = 3000
+
+// Streams the list's live events over SSE. fetch is used instead of EventSource so the
+// access token can go in the Authorization header. Returns a function that closes the stream.
+export function subscribeToList(listId: number, onEvent: (event: ListStreamEventDTO) => void): () => void {
+  const controller = new AbortController()
+  let stopped = false
+
+  const connect = async () => {
+    const token = localStorage.getItem('access_token')
+    const res = await fetch(`${apiBaseUrl}/api/lists/${listId}/stream`, {
+      headers: token ? { Authorization: `Bearer ${token}`, Accept: 'text/event-stream' } : { Accept: 'text/event-stream' },
+      signal: controller.signal,
+    })
+    if (!res.ok || !res.body) {
+      // Not a member anymore or the list is gone: don't retry
+      if (res.status === 403 || res.status === 404) stopped = true
+      return
+    }
+    const reader = res.body.pipeThrough(new TextDecoderStream()).getReader()
+    let buffer = ''
+    for (;;) {
+      const { value, done } = await reader.read()
+      if (done) return
+      buffer += value
+      let boundary = buffer.indexOf('\n\n')
+      while (boundary >= 0) {
+        const frame = buffer.slice(0, boundary)
+        buffer = buffer.slice(boundary + 2)
+        boundary = buffer.indexOf('\n\n')
+        const data = frame
+          .split('\n')
+          .filter((line) => line.startsWith('data:'))
+          .map((line) => line.slice(5).trimStart())
+          .join('\n')
+        if (!data) continue
+        try {
+          const event = JSON.parse(data) as ListStreamEventDTO
+          if (event.type) onEvent(event)
+          if (event.type === 'list_deleted') stopped = true
+        } catch {}
+      }
+    }
+  }
+
+  const run = async () => {
+    while (!stopped) {
+      try {
+        await connect()
+      } catch {}
+      if (stopped || controller.signal.aborted) return
+      await new Promise((resolve) => setTimeout(resolve, STREAM_RECONNECT_DELAY_MS))
+    }
+  }
+  void run()
+
+  return () => {
+    stopped = true
+    controller.abort()
+  }
+}
+
+// Presence types and functions
+export interface PresentMemberDTO {
+  user_id: number
+  username: string
+  avatar_url?: string | null
+  role: 'owner' | 'participant'
+  last_seen_at: string
+}
+
+export interface PresenceResponseDTO {
+  members: PresentMemberDTO[]
+  count: number
+  ttl_seconds: number
+}
+
+export async function getListPresence(listId: number): Promise<PresenceResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<PresenceResponseDTO>(`/api/lists/${listId}/presence`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+// Reports that the current user has the list open; send it at least twice per ttl_seconds
+export async function sendPresenceHeartbeat(listId: number): Promise<PresenceResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<PresenceResponseDTO>(`/api/lists/${listId}/presence`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function leaveListPresence(listId: number): Promise<void> {
+  const token = localStorage.getItem('access_token')
+  await requestJson<void>(`/api/lists/${listId}/presence`, {
+    method: 'DELETE',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+// Activity feed types and functions
+export type ListEventVerb =
+  | 'list_created'
+  | 'list_deleted'
+  | 'member_joined'
+  | 'member_left'
+  | 'movie_added'
+  | 'movie_removed'
+  | 'movie_restored'
+  | 'status_changed'
+  | 'rating_changed'
+  | 'watch_added'
+  | 'watch_removed'
+  | 'tag_added'
+  | 'tag_removed'
+  | 'movies_reordered'
+  | 'comment_created'
+  | 'comment_edited'
+  | 'comment_deleted'
+  | 'comment_moderated'
+
+export interface ListEventDTO {
+  id: number
+  verb: ListEventVerb
+  actor_id: number | null
+  actor?: { id: number; username: string; avatar_url?: string | null }
+  target_type: 'list' | 'member' | 'movie' | 'comment'
+  target_id: number
+  // snapshots of what changed, e.g. { status: 'watching' } or { title: 'Alien' }
+  before: Record<string, unknown> | null
+  after: Record<string, unknown> | null
+  created_at: string
+}
+
+export interface ListActivityResponseDTO {
+  events: ListEventDTO[]
+  pagination: {
+    limit: number
+    next_before: number | null
+    has_more: boolean
+  }
+}
+
+export async function getListActivity(listId: number, params?: { limit?: number; before?: number }): Promise<ListActivityResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  const searchParams = new URLSearchParams()
+  if (params?.limit) searchParams.set('limit', String(params.limit))
+  if (params?.before) searchParams.set('before', String(params.before))
+  const query = searchParams.toString()
+  return requestJson<ListActivityResponseDTO>(`/api/lists/${listId}/activity${query ? `?${query}` : ''}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
 // Comment types and functions
+export interface CommentSegmentDTO {
+  type: 'text' | 'spoiler'
+  text: string
+}
+
+export type CommentFormatNodeType = 'text' | 'bold' | 'italic' | 'link' | 'spoiler' | 'line_break'
+
+// Server-parsed formatting: **bold**, *italic*, [text](https://…), ||spoiler|| and line breaks
+export interface CommentFormatNodeDTO {
+  type: CommentFormatNodeType
+  text?: string
+  href?: string
+  children?: CommentFormatNodeDTO[]
+}
+
 export interface CommentDTO {
   id: number
-  user_id: number
+  user_id: number | null
+  parent_id: number | null
   content: string
+  reply_count: number
+  deleted: boolean
+  // why it was deleted; "member_left" comments can be restored by the list owner
+  deleted_reason?: 'author' | 'moderator' | 'member_left' | null
+  // hidden by the list owner; content is "[hidden]" unless you are the owner or the author
+  hidden: boolean
+  edited: boolean
+  edited_at: string | null
+  reactions: ReactionDTO[]
+  spoiler: boolean
+  // author: flagged by the author; watched: the author has watched the title and you have not
+  spoiler_reason: 'author' | 'watched' | null
+  segments: CommentSegmentDTO[]
+  nodes: CommentFormatNodeDTO[]
+  // sanitized HTML of the formatted content
+  rendered: string
+  // offset and length count Unicode code points and include the "@"
+  mentions: { user_id: number; username: string; offset: number; length: number }[]
   created_at: string
   updated_at: string
   user?: {
@@ -291,6 +633,17 @@ export interface CommentsResponseDTO {
   }
 }
 
+export interface CommentThreadResponseDTO {
+  comment: CommentDTO
+  replies: CommentDTO[]
+  pagination: {
+    total: number
+    limit: number
+    offset: number
+    has_more: boolean
+  }
+}
+
 export interface CreateCommentResponseDTO {
   success: boolean
   message: string
@@ -315,21 +668,33 @@ export async function getComments(listId: number, movieId: number, params?: { li
   })
 }
 
-export async function createComment(listId: number, movieId: number, content: string): Promise<CreateCommentResponseDTO> {
+export async function createComment(listId: number, movieId: number, content: string, parentId?: number, spoiler?: boolean): Promise<CreateCommentResponseDTO> {
   const token = localStorage.getItem('access_token')
   return requestJson<CreateCommentResponseDTO>(`/api/lists/${listId}/movies/${movieId}/comments`, {
     method: 'POST',
     headers: token ? { Authorization: `Bearer ${token}` } : undefined,
-    body: { content },
+    body: { content, ...(parentId ? { parent_id: parentId } : {}), ...(spoiler ? { spoiler } : {}) },
+  })
+}
+
+export async function getCommentThread(listId: number, movieId: number, commentId: number, params?: { limit?: number; offset?: number }): Promise<CommentThreadResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  const searchParams = new URLSearchParams()
+  if (params?.limit) searchParams.set('limit', String(params.limit))
+  if (params?.offset) searchParams.set('offset', String(params.offset))
+  const query = searchParams.toString()
+  return requestJson<CommentThreadResponseDTO>(`/api/lists/${listId}/movies/${movieId}/comments/${commentId}/thread${query ? `?${query}` : ''}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
   })
 }
 
-export async function updateComment(listId: number, movieId: number, commentId: number, content: string): Promise<UpdateCommentResponseDTO> {
+export async function updateComment(listId: number, movieId: number, commentId: number, content: string, spoiler?: boolean): Promise<UpdateCommentResponseDTO> {
   const token = localStorage.getItem('access_token')
   return requestJson<UpdateCommentResponseDTO>(`/api/lists/${listId}/movies/${movieId}/comments/${commentId}`, {
     method: 'PATCH',
     headers: token ? { Authorization: `Bearer ${token}` } : undefined,
-    body: { content },
+    body: spoiler === undefined ? { content } : { content, spoiler },
   })
 }
 
@@ -341,6 +706,172 @@ export async function deleteComment(listId: number, movieId: number, commentId:
   })
 }
 
+// List discussion board: comments without a movie, paginated by cursor
+export interface BoardCommentsResponseDTO {
+  comments: CommentDTO[]
+  pagination: {
+    limit: number
+    next_cursor: string | null
+    has_more: boolean
+  }
+}
+
+export async function getBoardComments(listId: number, params?: { limit?: number; cursor?: string }): Promise<BoardCommentsResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  const searchParams = new URLSearchParams()
+  if (params?.limit) searchParams.set('limit', String(params.limit))
+  if (params?.cursor) searchParams.set('cursor', params.cursor)
+  const query = searchParams.toString()
+  return requestJson<BoardCommentsResponseDTO>(`/api/lists/${listId}/board/comments${query ? `?${query}` : ''}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function createBoardComment(listId: number, content: string, parentId?: number, spoiler?: boolean): Promise<CreateCommentResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<CreateCommentResponseDTO>(`/api/lists/${listId}/board/comments`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: { content, ...(parentId ? { parent_id: parentId } : {}), ...(spoiler ? { spoiler } : {}) },
+  })
+}
+
+export async function getBoardThread(listId: number, commentId: number, params?: { limit?: number; offset?: number }): Promise<CommentThreadResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  const searchParams = new URLSearchParams()
+  if (params?.limit) searchParams.set('limit', String(params.limit))
+  if (params?.offset) searchParams.set('offset', String(params.offset))
+  const query = searchParams.toString()
+  return requestJson<CommentThreadResponseDTO>(`/api/lists/${listId}/board/comments/${commentId}/thread${query ? `?${query}` : ''}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function updateBoardComment(listId: number, commentId: number, content: string, spoiler?: boolean): Promise<UpdateCommentResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<UpdateCommentResponseDTO>(`/api/lists/${listId}/board/comments/${commentId}`, {
+    method: 'PATCH',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: spoiler === undefined ? { content } : { content, spoiler },
+  })
+}
+
+export async function deleteBoardComment(listId: number, commentId: number): Promise<void> {
+  const token = localStorage.getItem('access_token')
+  await requestJson<void>(`/api/lists/${listId}/board/comments/${commentId}`, {
+    method: 'DELETE',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export interface CommentRevisionDTO {
+  id: number
+  content: string
+  spoiler: boolean
+  segments: CommentSegmentDTO[]
+  rendered: string
+  created_at: string
+}
+
+export interface CommentRevisionsResponseDTO {
+  comment: CommentDTO
+  revisions: CommentRevisionDTO[]
+}
+
+export type ModerationAction = 'hide' | 'unhide' | 'delete' | 'restore'
+
+export interface ModerateCommentResponseDTO {
+  success: boolean
+  message: string
+  comment: CommentDTO
+}
+
+export interface ModerationLogEntryDTO {
+  id: number
+  // null once the comment was purged together with its title
+  comment_id: number | null
+  action: ModerationAction
+  reason: string | null
+  moderator: { id: number; username: string; avatar_url?: string | null }
+  author: { id: number; username: string; avatar_url?: string | null }
+  created_at: string
+}
+
+export interface ModerationLogResponseDTO {
+  entries: ModerationLogEntryDTO[]
+  pagination: {
+    total: number
+    limit: number
+    offset: number
+    has_more: boolean
+  }
+}
+
+export async function getCommentRevisions(listId: number, movieId: number, commentId: number): Promise<CommentRevisionsResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<CommentRevisionsResponseDTO>(`/api/lists/${listId}/movies/${movieId}/comments/${commentId}/revisions`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function moderateComment(listId: number, movieId: number, commentId: number, action: ModerationAction, reason?: string): Promise<ModerateCommentResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<ModerateCommentResponseDTO>(`/api/lists/${listId}/movies/${movieId}/comments/${commentId}/moderation`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: reason ? { action, reason } : { action },
+  })
+}
+
+export async function getModerationLog(listId: number, params?: { limit?: number; offset?: number }): Promise<ModerationLogResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  const searchParams = new URLSearchParams()
+  if (params?.limit) searchParams.set('limit', String(params.limit))
+  if (params?.offset) searchParams.set('offset', String(params.offset))
+  const query = searchParams.toString()
+  return requestJson<ModerationLogResponseDTO>(`/api/lists/${listId}/moderation-log${query ? `?${query}` : ''}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+// Reaction types and functions
+export type ReactionKind = 'thumbs_up' | 'heart' | 'laugh' | 'popcorn'
+
+export interface ReactionDTO {
+  kind: ReactionKind
+  emoji: string
+  count: number
+  reacted: boolean
+}
+
+export interface ToggleReactionResponseDTO {
+  success: boolean
+  added: boolean
+  reactions: ReactionDTO[]
+}
+
+export async function toggleListMovieReaction(listId: number, movieId: number, emoji: string): Promise<ToggleReactionResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<ToggleReactionResponseDTO>(`/api/lists/${listId}/movies/${movieId}/reactions`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: { emoji },
+  })
+}
+
+export async function toggleCommentReaction(listId: number, movieId: number, commentId: number, emoji: string): Promise<ToggleReactionResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<ToggleReactionResponseDTO>(`/api/lists/${listId}/movies/${movieId}/comments/${commentId}/reactions`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: { emoji },
+  })
+}
+
 // Recommendations types and functions
 export interface RecommendationDTO {
   id: number
@@ -349,20 +880,36 @@ export interface RecommendationDTO {
   poster_url?: string | null
   overview?: string | null
   score: number
+  group_score?: number
   popularity: number
-  genres?: { id: number }[]
+  genres?: { id: number; name?: string }[]
+  reasons?: {
+    sources: ('tmdb' | 'local')[]
+    because_you_liked: { id: number; title: string; media_type: 'movie' | 'tv' }[]
+    matched_genres: { id: number; name: string }[]
+    contributions: { popularity: number; seed_frequency: number; genre_match: number; local_similarity: number }
+  }
+  member_fits?: { user_id: number; username: string; fit: number }[]
 }
 
 export interface RecommendationsResponseDTO {
   recommendations: RecommendationDTO[]
   count: number
+  mode: 'default' | 'group'
+  strategy: 'list_content' | 'member_ratings' | 'member_lists' | 'trending'
+  fairness?: 'least_misery' | 'average_minus_variance'
   generated_at: string
 }
 
-export async function getListRecommendations(listId: number, params?: { limit?: number }): Promise<RecommendationsResponseDTO> {
+export async function getListRecommendations(
+  listId: number,
+  params?: { limit?: number; mode?: 'default' | 'group'; fairness?: 'least_misery' | 'average_minus_variance' },
+): Promise<RecommendationsResponseDTO> {
   const token = localStorage.getItem('access_token')
   const searchParams = new URLSearchParams()
   if (params?.limit) searchParams.set('limit', String(params.limit))
+  if (params?.mode) searchParams.set('mode', params.mode)
+  if (params?.fairness) searchParams.set('fairness', params.fairness)
   const query = searchParams.toString()
   return requestJson<RecommendationsResponseDTO>(`/api/lists/${listId}/recommendations${query ? `?${query}` : ''}`, {
     method: 'GET',
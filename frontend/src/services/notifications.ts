@@ -0,0 +1,95 @@
+import { requestJson } from './api'
+
+export type NotificationType =
+  | 'member_joined'
+  | 'title_added'
+  | 'comment'
+  | 'mention'
+  | 'availability_changed'
+  | 'invitation_received'
+  | 'weekly_digest'
+
+export type NotificationChannel = 'in_app' | 'email' | 'push'
+
+export interface NotificationActorDTO {
+  id: number
+  username: string
+  avatar_url?: string | null
+}
+
+export interface NotificationDTO {
+  id: number
+  type: NotificationType
+  list_id?: number | null
+  actor_id?: number | null
+  actor?: NotificationActorDTO
+  payload: Record<string, unknown> | null
+  read: boolean
+  read_at?: string | null
+  created_at: string
+}
+
+export interface NotificationsResponseDTO {
+  notifications: NotificationDTO[]
+  unread_count: number
+  pagination: {
+    total: number
+    limit: number
+    offset: number
+    has_more: boolean
+  }
+}
+
+export type NotificationPreferencesDTO = Partial<Record<NotificationType, boolean>>
+
+export interface NotificationPreferencesResponseDTO {
+  success?: boolean
+  channel: NotificationChannel
+  preferences: NotificationPreferencesDTO
+}
+
+export async function getNotifications(params?: { limit?: number; offset?: number; unread?: boolean }): Promise<NotificationsResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  const query = new URLSearchParams()
+  if (params?.limit) query.set('limit', String(params.limit))
+  if (params?.offset) query.set('offset', String(params.offset))
+  if (params?.unread) query.set('unread', 'true')
+  const qs = query.toString()
+  return requestJson<NotificationsResponseDTO>(`/api/notifications${qs ? `?${qs}` : ''}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function markNotificationRead(notificationId: number): Promise<void> {
+  const token = localStorage.getItem('access_token')
+  await requestJson<void>(`/api/notifications/${notificationId}/read`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function markAllNotificationsRead(): Promise<{ success: boolean; data: { updated: number } }> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<{ success: boolean; data: { updated: number } }>('/api/notifications/read-all', {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function getNotificationPreferences(channel: NotificationChannel = 'in_app'): Promise<NotificationPreferencesResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<NotificationPreferencesResponseDTO>(`/api/notifications/preferences?channel=${channel}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function updateNotificationPreferences(preferences: NotificationPreferencesDTO, channel: NotificationChannel = 'in_app'): Promise<NotificationPreferencesResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<NotificationPreferencesResponseDTO>(`/api/notifications/preferences?channel=${channel}`, {
+    method: 'PUT',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: { preferences },
+  })
+}
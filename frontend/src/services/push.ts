@@ -0,0 +1,72 @@
+import { requestJson } from './api'
+
+export interface PushSubscriptionDTO {
+  id: number
+  device_name: string
+  user_agent: string
+  last_used_at?: string | null
+  created_at: string
+}
+
+function urlBase64ToUint8Array(base64: string): Uint8Array {
+  const padding = '='.repeat((4 - (base64.length % 4)) % 4)
+  const raw = atob((base64 + padding).replace(/-/g, '+').replace(/_/g, '/'))
+  return Uint8Array.from(raw, (c) => c.charCodeAt(0))
+}
+
+export function isPushSupported(): boolean {
+  return 'serviceWorker' in navigator && 'PushManager' in window && 'Notification' in window
+}
+
+export async function getVapidPublicKey(): Promise<string> {
+  const res = await requestJson<{ public_key: string }>('/api/push/vapid-public-key', { method: 'GET' })
+  return res.public_key
+}
+
+export async function getPushSubscriptions(): Promise<{ subscriptions: PushSubscriptionDTO[] }> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<{ subscriptions: PushSubscriptionDTO[] }>('/api/push/subscriptions', {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+// enablePushNotifications asks for permission, subscribes this browser and registers it
+export async function enablePushNotifications(deviceName?: string): Promise<PushSubscriptionDTO> {
+  if (!isPushSupported()) throw new Error('Push notifications are not supported in this browser')
+  const permission = await Notification.requestPermission()
+  if (permission !== 'granted') throw new Error('Notification permission was not granted')
+
+  const registration = await navigator.serviceWorker.register('/push-sw.js')
+  const publicKey = await getVapidPublicKey()
+  const subscription =
+    (await registration.pushManager.getSubscription()) ??
+    (await registration.pushManager.subscribe({
+      userVisibleOnly: true,
+      applicationServerKey: urlBase64ToUint8Array(publicKey),
+    }))
+
+  const token = localStorage.getItem('access_token')
+  const res = await requestJson<{ success: boolean; subscription: PushSubscriptionDTO }>('/api/push/subscriptions', {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body: { ...subscription.toJSON(), device_name: deviceName ?? '' },
+  })
+  return res.subscription
+}
+
+export async function deletePushSubscription(subscriptionId: number): Promise<void> {
+  const token = localStorage.getItem('access_token')
+  await requestJson<void>(`/api/push/subscriptions/${subscriptionId}`, {
+    method: 'DELETE',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function sendTestPush(): Promise<{ success: boolean; data: { delivered: number } }> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<{ success: boolean; data: { delivered: number } }>('/api/push/test', {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
@@ -0,0 +1,109 @@
+import { requestJson } from './api'
+
+export type WebhookEventType = 'movie_added' | 'status_changed' | 'comment_created'
+
+export interface WebhookDTO {
+  id: number
+  list_id: number
+  url: string
+  events: WebhookEventType[]
+  active: boolean
+  created_by: number
+  created_at: string
+  updated_at: string
+  // Only returned when the webhook is created or its secret is rotated
+  secret?: string
+}
+
+export interface WebhookDeliveryDTO {
+  id: number
+  webhook_id: number
+  event_id: string
+  event: WebhookEventType
+  payload: string
+  status: 'pending' | 'succeeded' | 'failed'
+  attempts: number
+  next_attempt_at?: string | null
+  last_attempt_at?: string | null
+  response_status?: number | null
+  last_error?: string | null
+  delivered_at?: string | null
+  created_at: string
+}
+
+export interface WebhookDeliveriesResponseDTO {
+  deliveries: WebhookDeliveryDTO[]
+  pagination: {
+    total: number
+    limit: number
+    offset: number
+    has_more: boolean
+  }
+}
+
+export interface WebhookBodyDTO {
+  url?: string
+  events?: WebhookEventType[]
+  active?: boolean
+  rotate_secret?: boolean
+}
+
+export async function getListWebhooks(listId: number): Promise<{ webhooks: WebhookDTO[]; event_types: WebhookEventType[] }> {
+  const token = localStorage.getItem('access_token')
+  return requestJson<{ webhooks: WebhookDTO[]; event_types: WebhookEventType[] }>(`/api/lists/${listId}/webhooks`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function createListWebhook(listId: number, body: WebhookBodyDTO): Promise<WebhookDTO> {
+  const token = localStorage.getItem('access_token')
+  const res = await requestJson<{ success: boolean; webhook: WebhookDTO }>(`/api/lists/${listId}/webhooks`, {
+    method: 'POST',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body,
+  })
+  return res.webhook
+}
+
+export async function updateListWebhook(listId: number, webhookId: number, body: WebhookBodyDTO): Promise<WebhookDTO> {
+  const token = localStorage.getItem('access_token')
+  const res = await requestJson<{ success: boolean; webhook: WebhookDTO }>(`/api/lists/${listId}/webhooks/${webhookId}`, {
+    method: 'PATCH',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    body,
+  })
+  return res.webhook
+}
+
+export async function deleteListWebhook(listId: number, webhookId: number): Promise<void> {
+  const token = localStorage.getItem('access_token')
+  await requestJson<void>(`/api/lists/${listId}/webhooks/${webhookId}`, {
+    method: 'DELETE',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function getWebhookDeliveries(listId: number, webhookId: number, params?: { limit?: number; offset?: number }): Promise<WebhookDeliveriesResponseDTO> {
+  const token = localStorage.getItem('access_token')
+  const query = new URLSearchParams()
+  if (params?.limit) query.set('limit', String(params.limit))
+  if (params?.offset) query.set('offset', String(params.offset))
+  const qs = query.toString()
+  return requestJson<WebhookDeliveriesResponseDTO>(`/api/lists/${listId}/webhooks/${webhookId}/deliveries${qs ? `?${qs}` : ''}`, {
+    method: 'GET',
+    headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+  })
+}
+
+export async function redeliverWebhook(listId: number, webhookId: number, deliveryId: number): Promise<WebhookDeliveryDTO> {
+  const token = localStorage.getItem('access_token')
+  const res = await requestJson<{ success: boolean; delivery: WebhookDeliveryDTO }>(
+    `/api/lists/${listId}/webhooks/${webhookId}/deliveries/${deliveryId}/redeliver`,
+    {
+      method: 'POST',
+      headers: token ? { Authorization: `Bearer ${token}` } : undefined,
+    },
+  )
+  return res.delivery
+}